@@ -0,0 +1,143 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/rhino11/trafficsim/internal/output"
+	"github.com/rhino11/trafficsim/internal/sim"
+)
+
+// batchOptions controls a faster-than-real-time batch run
+type batchOptions struct {
+	Duration       time.Duration // Simulation time to run; zero runs to the maximum duration
+	Step           time.Duration // Fixed physics step
+	OutputInterval time.Duration // Simulation time between track samples
+	OutputDir      string
+	Formats        []string
+}
+
+// batchResult summarises a finished batch run
+type batchResult struct {
+	SimulatedTime time.Duration
+	Samples       int
+	Files         []string
+	WallTime      time.Duration
+}
+
+// parseFormats splits a comma separated list of track formats
+func parseFormats(list string) []string {
+	var formats []string
+	for _, format := range strings.Split(list, ",") {
+		if format = strings.TrimSpace(format); format != "" {
+			formats = append(formats, format)
+		}
+	}
+	return formats
+}
+
+// runBatchSimulation steps a loaded engine as fast as possible, sampling tracks to files in the output directory
+func runBatchSimulation(engine *sim.Engine, opts batchOptions) (*batchResult, error) {
+	if opts.OutputInterval <= 0 {
+		return nil, fmt.Errorf("output interval must be positive, got %v", opts.OutputInterval)
+	}
+	if len(opts.Formats) == 0 {
+		return nil, fmt.Errorf("at least one output format is required")
+	}
+
+	duration := opts.Duration
+	if maxDuration := engine.GetMaxDuration(); duration <= 0 || (maxDuration > 0 && duration > maxDuration) {
+		duration = maxDuration
+	}
+	if duration <= 0 {
+		return nil, fmt.Errorf("batch runs need a duration or simulation.max_duration")
+	}
+
+	if err := os.MkdirAll(opts.OutputDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	type trackFile struct {
+		file     *os.File
+		buffered *bufio.Writer
+		writer   output.TrackWriter
+	}
+	var files []trackFile
+	defer func() {
+		for _, f := range files {
+			f.file.Close()
+		}
+	}()
+
+	result := &batchResult{}
+	for _, format := range opts.Formats {
+		path := filepath.Join(opts.OutputDir, output.TrackFileName(format))
+		file, err := os.Create(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create %s: %w", path, err)
+		}
+		buffered := bufio.NewWriter(file)
+		writer, err := output.NewTrackWriter(format, buffered)
+		if err != nil {
+			file.Close()
+			return nil, err
+		}
+		files = append(files, trackFile{file: file, buffered: buffered, writer: writer})
+		result.Files = append(result.Files, path)
+	}
+
+	sample := func() error {
+		simTime := engine.GetSimulationTime()
+		timestamp := engine.Now()
+		platforms := engine.GetAllPlatforms()
+		for _, f := range files {
+			if err := f.writer.WriteTracks(timestamp, simTime, platforms); err != nil {
+				return err
+			}
+		}
+		result.Samples++
+		return nil
+	}
+
+	wallStart := time.Now()
+	if err := sample(); err != nil {
+		return nil, err
+	}
+	for result.SimulatedTime < duration {
+		interval := opts.OutputInterval
+		if remaining := duration - result.SimulatedTime; remaining < interval {
+			interval = remaining
+		}
+		ran, err := engine.RunFor(interval, opts.Step)
+		if err != nil {
+			return nil, err
+		}
+		if ran == 0 {
+			break // Reached the engine's maximum duration
+		}
+		result.SimulatedTime += ran
+		if err := sample(); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, f := range files {
+		if err := f.writer.Flush(); err != nil {
+			return nil, err
+		}
+		if err := f.buffered.Flush(); err != nil {
+			return nil, err
+		}
+		if err := f.file.Close(); err != nil {
+			return nil, err
+		}
+	}
+	files = nil
+
+	result.WallTime = time.Since(wallStart)
+	return result, nil
+}
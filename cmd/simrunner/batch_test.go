@@ -0,0 +1,178 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rhino11/trafficsim/internal/config"
+	"github.com/rhino11/trafficsim/internal/sim"
+)
+
+func TestRunBatchSimulation(t *testing.T) {
+	cfg := createTestConfig()
+	cfg.Simulation.MaxDuration = "1m"
+	cfg.Simulation.StartTime = "2024-06-01T12:00:00Z"
+
+	engine := sim.NewEngine(cfg)
+	if err := engine.LoadPlatformsFromConfig(); err != nil {
+		t.Fatalf("LoadPlatformsFromConfig failed: %v", err)
+	}
+
+	dir := t.TempDir()
+	result, err := runBatchSimulation(engine, batchOptions{
+		Duration:       2 * time.Minute, // Capped at max_duration
+		Step:           time.Second,
+		OutputInterval: 15 * time.Second,
+		OutputDir:      dir,
+		Formats:        parseFormats("csv, cot"),
+	})
+	if err != nil {
+		t.Fatalf("runBatchSimulation failed: %v", err)
+	}
+
+	if result.SimulatedTime != time.Minute || result.Samples != 5 {
+		t.Errorf("Expected 1m in 5 samples, got %v in %d", result.SimulatedTime, result.Samples)
+	}
+	if engine.IsRunning() {
+		t.Error("Batch runs should not start the real-time loop")
+	}
+
+	file, err := os.Open(filepath.Join(dir, "tracks.csv"))
+	if err != nil {
+		t.Fatalf("Expected tracks.csv: %v", err)
+	}
+	defer file.Close()
+	records, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		t.Fatalf("tracks.csv is not valid CSV: %v", err)
+	}
+	platforms := engine.GetPlatformCount()
+	if len(records) != 1+5*platforms {
+		t.Errorf("Expected %d rows, got %d", 1+5*platforms, len(records))
+	}
+	if last := records[len(records)-1]; last[0] != "2024-06-01T12:01:00Z" {
+		t.Errorf("Expected final sample at start time + 1m, got %s", last[0])
+	}
+
+	if info, err := os.Stat(filepath.Join(dir, "tracks.cot")); err != nil || info.Size() == 0 {
+		t.Errorf("Expected non-empty tracks.cot: %v", err)
+	}
+}
+
+func TestRunBatchSimulation_Errors(t *testing.T) {
+	engine := sim.NewEngine(createTestConfig()) // No max_duration
+	opts := batchOptions{Step: time.Second, OutputInterval: time.Second, OutputDir: t.TempDir(), Formats: []string{"csv"}}
+
+	if _, err := runBatchSimulation(engine, opts); err == nil {
+		t.Error("Expected error without a duration")
+	}
+
+	opts.Duration = time.Minute
+	opts.Formats = []string{"kml"}
+	if _, err := runBatchSimulation(engine, opts); err == nil {
+		t.Error("Expected error for unknown format")
+	}
+}
+
+// createRandomScenarioConfig creates a scenario whose call signs and movement come from the random source
+func createRandomScenarioConfig() *config.Config {
+	area := config.BoundingBox{North: 37.0, South: 36.0, East: -75.5, West: -76.5}
+	return &config.Config{
+		Simulation: config.SimulationConfig{
+			UpdateInterval: "1s",
+			TimeScale:      1.0,
+			MaxDuration:    "10m",
+			StartTime:      "2024-06-01T12:00:00Z",
+			Scenario:       "wander",
+		},
+		Platforms: config.PlatformRegistry{
+			MaritimeTypes: config.PlatformTypeDefinitions{
+				"patrol_boat": {
+					Class:          "Patrol Boat",
+					Type:           "maritime",
+					Category:       "military",
+					MaxSpeed:       20,
+					CruiseSpeed:    12,
+					Length:         25,
+					Mass:           50000,
+					CallSignPrefix: "PB",
+					CallSignFormat: "{prefix}{number:03d}",
+				},
+			},
+			Scenarios: map[string]config.ScenarioConfig{
+				"wander": {
+					Name: "Wander",
+					Instances: []config.PlatformInstance{
+						{ID: "BOAT1", TypeID: "patrol_boat", StartPos: config.Position{Latitude: 36.5, Longitude: -76.0},
+							Behavior: &config.BehaviorConfig{RandomWalk: &config.RandomWalkBehavior{Area: area, MaxDistance: 5000}}},
+						{ID: "BOAT2", TypeID: "patrol_boat", StartPos: config.Position{Latitude: 36.6, Longitude: -76.1},
+							Behavior: &config.BehaviorConfig{RandomWalk: &config.RandomWalkBehavior{Area: area, MaxDistance: 5000}}},
+					},
+				},
+			},
+		},
+	}
+}
+
+// runSeededBatch runs the random scenario with a seed and returns the track file contents
+func runSeededBatch(t *testing.T, cfg *config.Config, seed int64) []byte {
+	t.Helper()
+	engine := sim.NewEngine(cfg)
+	engine.SetSeed(seed)
+	if err := engine.LoadPlatformsFromConfig(); err != nil {
+		t.Fatalf("LoadPlatformsFromConfig failed: %v", err)
+	}
+
+	dir := t.TempDir()
+	opts := batchOptions{Step: time.Second, OutputInterval: 10 * time.Second, OutputDir: dir, Formats: []string{"csv", "cot"}}
+	if _, err := runBatchSimulation(engine, opts); err != nil {
+		t.Fatalf("runBatchSimulation failed: %v", err)
+	}
+
+	var tracks []byte
+	for _, name := range []string{"tracks.csv", "tracks.cot"} {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", name, err)
+		}
+		tracks = append(tracks, data...)
+	}
+	return tracks
+}
+
+func TestRunBatchSimulation_SeedIsReproducible(t *testing.T) {
+	first := runSeededBatch(t, createRandomScenarioConfig(), 42)
+	second := runSeededBatch(t, createRandomScenarioConfig(), 42)
+	if !bytes.Equal(first, second) {
+		t.Fatal("Expected byte-identical track output for the same seed")
+	}
+
+	if bytes.Equal(first, runSeededBatch(t, createRandomScenarioConfig(), 7)) {
+		t.Error("Expected a different seed to change the track output")
+	}
+}
+
+func TestRunBatchSimulation_SeedWithoutStartTime(t *testing.T) {
+	// Without a start time, seeded runs start at a fixed epoch rather than the wall clock
+	cfg := createRandomScenarioConfig()
+	cfg.Simulation.StartTime = ""
+	first := runSeededBatch(t, cfg, 42)
+	if !bytes.Contains(first, []byte("\n"+sim.SeededStartTime.Format(time.RFC3339)+",")) {
+		t.Errorf("Expected the first sample at %s, got\n%s", sim.SeededStartTime.Format(time.RFC3339), first[:200])
+	}
+
+	time.Sleep(1100 * time.Millisecond) // Past the next wall-clock second
+	if !bytes.Equal(first, runSeededBatch(t, cfg, 42)) {
+		t.Fatal("Expected byte-identical track output for the same seed")
+	}
+
+	// A seed from configuration does the same
+	cfg.Simulation.Seed = 42
+	if engine := sim.NewEngine(cfg); !engine.GetStartTime().Equal(sim.SeededStartTime) {
+		t.Errorf("Expected a configured seed to start at %v, got %v", sim.SeededStartTime, engine.GetStartTime())
+	}
+}
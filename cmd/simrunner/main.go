@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"github.com/rhino11/trafficsim/internal/config"
+	"github.com/rhino11/trafficsim/internal/input"
 	"github.com/rhino11/trafficsim/internal/models"
 	"github.com/rhino11/trafficsim/internal/output"
 	"github.com/rhino11/trafficsim/internal/server"
@@ -21,13 +22,30 @@ import (
 func main() {
 	// Command line flags
 	var (
-		configPath    = flag.String("config", "data/config.yaml", "Path to configuration file")
-		webMode       = flag.Bool("web", false, "Run in web server mode")
-		headlessMode  = flag.Bool("headless", false, "Run in headless mode (command-line only, no web interface)")
-		port          = flag.String("port", "8080", "Port for web server")
-		multicast     = flag.Bool("multicast", false, "Enable multicast transmission of platform updates")
-		multicastAddr = flag.String("multicast-addr", "239.2.3.1", "Multicast address for platform updates")
-		multicastPort = flag.String("multicast-port", "6969", "Multicast port for platform updates")
+		configPath      = flag.String("config", "data/config.yaml", "Path to configuration file")
+		scenario        = flag.String("scenario", "", "Name of the configured scenario to run (defaults to simulation.scenario or the first scenario)")
+		scenarioFile    = flag.String("scenario-file", "", "Path to a scenario file in the data/configs format (overrides -scenario)")
+		webMode         = flag.Bool("web", false, "Run in web server mode")
+		headlessMode    = flag.Bool("headless", false, "Run in headless mode (command-line only, no web interface)")
+		port            = flag.String("port", "8080", "Port for web server")
+		multicast       = flag.Bool("multicast", false, "Enable multicast transmission of platform updates")
+		multicastAddr   = flag.String("multicast-addr", "239.2.3.1", "Multicast address for platform updates")
+		multicastPort   = flag.String("multicast-port", "6969", "Multicast port for platform updates")
+		cotEndpoint     = flag.String("cot-endpoint", "", "Stream CoT to a udp://, tcp:// or ssl:// endpoint such as a TAK Server, using output.cot tls settings")
+		sbsEndpoint     = flag.String("sbs-endpoint", "", "Stream SBS-1 BaseStation CSV for aircraft to a tcp:// endpoint (overrides output.adsb.sbs_endpoint)")
+		gdl90Endpoint   = flag.String("gdl90-endpoint", "", "Send GDL90 traffic reports for aircraft to a udp:// endpoint (overrides output.adsb.gdl90_endpoint)")
+		aisEndpoint     = flag.String("ais-endpoint", "", "Send AIS NMEA sentences for vessels to a udp:// or tcp:// endpoint (overrides output.ais.endpoint)")
+		disEndpoint     = flag.String("dis-endpoint", "", "Send DIS Entity State PDUs for all platforms to a udp:// broadcast or multicast endpoint (overrides output.dis.endpoint)")
+		asterixEndpoint = flag.String("asterix-endpoint", "", "Send ASTERIX CAT062 system tracks and CAT034 north markers to a udp:// endpoint (overrides output.asterix.endpoint)")
+		cotListen       = flag.String("cot-listen", "", "Show live tracks from a udp:// group or a tcp:// or ssl:// CoT feed as read-only platforms")
+		seed            = flag.Int64("seed", 0, "Random seed for reproducible runs (0 uses simulation.seed, or the clock when unset)")
+		terrainDir      = flag.String("terrain-dir", "", "Directory of SRTM .hgt or GeoTIFF elevation tiles (overrides simulation.terrain_dir)")
+		batchMode       = flag.Bool("batch", false, "Run as fast as possible in fixed steps, write track files and exit")
+		duration        = flag.Duration("duration", 0, "Simulation time to run in batch mode (defaults to simulation.max_duration)")
+		step            = flag.Duration("step", time.Second, "Fixed simulation step in batch mode")
+		outputDir       = flag.String("output-dir", "output", "Directory for batch mode track files")
+		outputEvery     = flag.Duration("output-interval", 10*time.Second, "Simulation time between track samples in batch mode")
+		outputFormats   = flag.String("output-format", "csv", "Comma separated batch track formats (csv, cot)")
 	)
 	flag.Parse()
 
@@ -38,6 +56,9 @@ func main() {
 	if *webMode && *headlessMode {
 		log.Fatal("Error: Cannot specify both -web and -headless modes")
 	}
+	if *batchMode && *webMode {
+		log.Fatal("Error: Cannot specify both -web and -batch modes")
+	}
 
 	// Load configuration
 	fmt.Printf("Loading configuration from: %s\n", *configPath)
@@ -48,16 +69,75 @@ func main() {
 
 	// Create simulation engine
 	engine := sim.NewEngine(cfg)
+	if *scenario != "" {
+		engine.SetScenario(*scenario)
+	}
+	if *seed != 0 {
+		engine.SetSeed(*seed)
+	}
+	if *terrainDir != "" {
+		if err := engine.LoadTerrain(*terrainDir); err != nil {
+			log.Fatalf("Failed to load terrain: %v", err)
+		}
+	}
+	fmt.Printf("Random seed: %d\n", engine.GetSeed())
+
+	if *batchMode {
+		if err := loadPlatforms(engine, *scenarioFile); err != nil {
+			log.Fatalf("Failed to load platforms: %v", err)
+		}
+		fmt.Printf("Running batch simulation of %d platforms...\n", engine.GetPlatformCount())
+
+		result, err := runBatchSimulation(engine, batchOptions{
+			Duration:       *duration,
+			Step:           *step,
+			OutputInterval: *outputEvery,
+			OutputDir:      *outputDir,
+			Formats:        parseFormats(*outputFormats),
+		})
+		if err != nil {
+			log.Fatalf("Batch simulation failed: %v", err)
+		}
+		fmt.Printf("Simulated %v in %v (%d samples)\n", result.SimulatedTime, result.WallTime.Round(time.Millisecond), result.Samples)
+		for _, file := range result.Files {
+			fmt.Printf("  wrote %s\n", file)
+		}
+		return
+	}
+
+	// Setup output sinks; an explicit CoT endpoint takes precedence over -multicast
+	cot := *cotEndpoint
+	if cot == "" && *multicast {
+		cot = fmt.Sprintf("udp://%s", net.JoinHostPort(*multicastAddr, *multicastPort))
+	}
+	sinks, err := newSinkManager(cfg.Output, sinkEndpoints{
+		CoT:     cot,
+		SBS:     *sbsEndpoint,
+		GDL90:   *gdl90Endpoint,
+		AIS:     *aisEndpoint,
+		DIS:     *disEndpoint,
+		ASTERIX: *asterixEndpoint,
+	})
+	if err != nil {
+		log.Fatalf("Failed to setup output: %v", err)
+	}
+	defer sinks.Close()
+	sinks.SetClock(engine) // Timestamp messages with simulation time
+	for _, sink := range sinks.Status() {
+		fmt.Printf("%s output enabled to %s every %s\n", sink.Format, sink.Endpoint, sink.Rate)
+	}
 
-	// Setup multicast if enabled
-	var multicastConn *net.UDPConn
-	if *multicast {
-		multicastConn, err = setupMulticast(*multicastAddr, *multicastPort)
+	// Ingest external tracks if requested
+	if *cotListen != "" {
+		listener, err := newCoTListener(cfg.Output.CoT, *cotListen, engine)
 		if err != nil {
-			log.Fatalf("Failed to setup multicast: %v", err)
+			log.Fatalf("Failed to setup CoT input: %v", err)
 		}
-		defer multicastConn.Close()
-		fmt.Printf("Multicast transmission enabled on %s:%s\n", *multicastAddr, *multicastPort)
+		if err := listener.Start(); err != nil {
+			log.Fatalf("Failed to start CoT input: %v", err)
+		}
+		defer listener.Close()
+		fmt.Printf("Ingesting external CoT tracks from %s\n", *cotListen)
 	}
 
 	if *webMode {
@@ -66,7 +146,7 @@ func main() {
 
 		// Load platforms from configuration (needed for web mode)
 		fmt.Println("Loading platforms for web simulation...")
-		if err := engine.LoadPlatformsFromConfig(); err != nil {
+		if err := loadPlatforms(engine, *scenarioFile); err != nil {
 			log.Fatalf("Failed to load platforms: %v", err)
 		}
 
@@ -78,6 +158,8 @@ func main() {
 			log.Fatalf("Failed to start simulation: %v", err)
 		}
 
+		go sinks.Run(context.Background(), engine.SnapshotPlatforms)
+
 		// Create server
 		srv := server.NewServer(cfg, engine)
 
@@ -90,27 +172,77 @@ func main() {
 		if *headlessMode {
 			fmt.Println("Running in headless mode...")
 		}
-		runCLISimulation(engine, cfg, multicastConn)
+		runCLISimulation(engine, *scenarioFile, sinks)
 	}
 }
 
-func setupMulticast(addr, port string) (*net.UDPConn, error) {
-	// Parse multicast address
-	multicastAddr, err := net.ResolveUDPAddr("udp", fmt.Sprintf("%s:%s", addr, port))
-	if err != nil {
-		return nil, fmt.Errorf("failed to resolve multicast address: %v", err)
+// loadPlatforms loads the scenario file when one was given, otherwise the configured scenario
+func loadPlatforms(engine *sim.Engine, scenarioFile string) error {
+	if scenarioFile != "" {
+		fmt.Printf("Loading scenario file: %s\n", scenarioFile)
+		return engine.LoadScenarioFile(scenarioFile)
 	}
+	return engine.LoadPlatformsFromConfig()
+}
 
-	// Create UDP connection
-	conn, err := net.DialUDP("udp", nil, multicastAddr)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create multicast connection: %v", err)
+// sinkEndpoints are the output endpoints given on the command line
+type sinkEndpoints struct {
+	CoT, SBS, GDL90, AIS, DIS, ASTERIX string
+}
+
+// newSinkManager creates the sinks configured in out, with command line endpoints taking precedence
+// over the per-format sections. The CoT sink uses the output.cot settings and update rate.
+func newSinkManager(out config.OutputConfig, endpoints sinkEndpoints) (*output.SinkManager, error) {
+	for _, override := range []struct {
+		endpoint string
+		field    *string
+	}{
+		{endpoints.SBS, &out.ADSB.SBSEndpoint},
+		{endpoints.GDL90, &out.ADSB.GDL90Endpoint},
+		{endpoints.AIS, &out.AIS.Endpoint},
+		{endpoints.DIS, &out.DIS.Endpoint},
+		{endpoints.ASTERIX, &out.ASTERIX.Endpoint},
+	} {
+		if override.endpoint != "" {
+			*override.field = override.endpoint
+		}
 	}
+	if endpoints.CoT != "" {
+		out.Sinks = append(append([]config.SinkConfig(nil), out.Sinks...), config.SinkConfig{
+			Name:     config.SinkFormatCoT,
+			Format:   config.SinkFormatCoT,
+			Endpoint: endpoints.CoT,
+			Rate:     out.CoT.UpdateRate,
+			CoT:      out.CoT,
+		})
+	}
+	return output.NewSinkManager(out)
+}
 
-	return conn, nil
+// newCoTListener creates a listener feeding engine from endpoint; ssl:// feeds use the output.cot TLS settings
+func newCoTListener(cot config.CoTConfig, endpoint string, engine *sim.Engine) (*input.CoTListener, error) {
+	maxBackoff, err := cot.ParseMaxBackoff()
+	if err != nil {
+		return nil, err
+	}
+	opts := input.ListenerOptions{MaxBackoff: maxBackoff}
+
+	if ep, err := output.ParseEndpoint(endpoint); err == nil && ep.Scheme == output.SchemeSSL {
+		opts.TLS, err = output.LoadTLSConfig(output.TLSOptions{
+			CertFile:           cot.TLS.CertFile,
+			KeyFile:            cot.TLS.KeyFile,
+			CAFile:             cot.TLS.CAFile,
+			ServerName:         cot.TLS.ServerName,
+			InsecureSkipVerify: cot.TLS.InsecureSkipVerify,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return input.NewCoTListener(endpoint, engine, opts)
 }
 
-func runCLISimulation(engine *sim.Engine, cfg *config.Config, multicastConn *net.UDPConn) {
+func runCLISimulation(engine *sim.Engine, scenarioFile string, sinks *output.SinkManager) {
 	fmt.Println("Starting traffic simulation...")
 
 	// Create context for graceful shutdown
@@ -128,13 +260,17 @@ func runCLISimulation(engine *sim.Engine, cfg *config.Config, multicastConn *net
 	}()
 
 	// Load platforms from configuration or create examples
-	if err := engine.LoadPlatformsFromConfig(); err != nil {
+	if err := loadPlatforms(engine, scenarioFile); err != nil {
 		cancel() // Cancel context before fatal exit
 		log.Fatalf("Failed to load platforms: %v", err)
 	}
 
 	platforms := engine.GetAllPlatforms()
-	fmt.Printf("Loaded %d platforms\n", len(platforms))
+	if scenarioName := engine.GetScenario(); scenarioName != "" {
+		fmt.Printf("Loaded %d platforms from scenario %s\n", len(platforms), scenarioName)
+	} else {
+		fmt.Printf("Loaded %d platforms\n", len(platforms))
+	}
 
 	// Display platform information
 	for _, platform := range platforms {
@@ -146,12 +282,8 @@ func runCLISimulation(engine *sim.Engine, cfg *config.Config, multicastConn *net
 		log.Fatalf("Failed to start simulation: %v", err)
 	}
 
-	// Create CoT generator for multicast transmission
-	var cotGenerator *output.CoTGenerator
-	if multicastConn != nil {
-		cotGenerator = output.NewCoTGenerator()
-		fmt.Println("CoT message generation enabled for multicast transmission")
-	}
+	// Publish snapshots to the output sinks off the simulation loop
+	go sinks.Run(ctx, engine.SnapshotPlatforms)
 
 	// Run simulation monitoring loop
 	ticker := time.NewTicker(1 * time.Second) // Status updates every second
@@ -177,34 +309,15 @@ func runCLISimulation(engine *sim.Engine, cfg *config.Config, multicastConn *net
 			if len(platforms) > 0 {
 				displayPlatformStatus(platforms)
 			}
-
-			// Send multicast updates if enabled
-			if multicastConn != nil && cotGenerator != nil {
-				sendCoTMulticastUpdates(multicastConn, cotGenerator, platforms)
-			}
+			displaySinkStatus(sinks.Status())
 		}
 	}
 }
 
-func sendCoTMulticastUpdates(conn *net.UDPConn, generator *output.CoTGenerator, platforms []models.Platform) {
-	for _, platform := range platforms {
-		// Convert platform to CoT state
-		cotState := output.PlatformToCoTState(platform)
-
-		// Generate CoT XML message
-		cotMessage, err := generator.GenerateCoTMessage(cotState)
-		if err != nil {
-			log.Printf("Failed to generate CoT message for %s: %v", platform.GetCallSign(), err)
-			continue
-		}
-
-		// Send the CoT XML message
-		_, err = conn.Write(cotMessage)
-		if err != nil {
-			log.Printf("Failed to send CoT message for %s: %v", platform.GetCallSign(), err)
-		} else {
-			log.Printf("Sent CoT message for %s (Type: %s)", platform.GetCallSign(), cotState.CoTType)
-		}
+// displaySinkStatus prints the traffic of each output sink, including reports its policy saved
+func displaySinkStatus(statuses []output.SinkStatus) {
+	for _, sink := range statuses {
+		fmt.Printf("  Sink %s: %d reports, %d saved, %d failed\n", sink.Name, sink.Reports, sink.Saved, sink.Failed)
 	}
 }
 
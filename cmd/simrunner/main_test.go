@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"os"
@@ -12,6 +13,8 @@ import (
 
 	"github.com/gorilla/websocket"
 	"github.com/rhino11/trafficsim/internal/config"
+	"github.com/rhino11/trafficsim/internal/models"
+	"github.com/rhino11/trafficsim/internal/output"
 	"github.com/rhino11/trafficsim/internal/server"
 	"github.com/rhino11/trafficsim/internal/sim"
 )
@@ -353,3 +356,106 @@ func TestSimulationControlAPI(t *testing.T) {
 func isTestEnvironment() bool {
 	return os.Getenv("GO_TESTING") == "1" || strings.Contains(os.Args[0], ".test")
 }
+
+func TestNewSinkManager(t *testing.T) {
+	out := config.OutputConfig{
+		CoT:  config.CoTConfig{UpdateRate: "5s", QueueSize: 10, MaxBackoff: "1s"},
+		ADSB: config.ADSBConfig{GDL90Endpoint: "udp://127.0.0.1:4000"},
+		Sinks: []config.SinkConfig{
+			{Name: "tracks", Format: config.SinkFormatASTERIX, Endpoint: "udp://127.0.0.1:8600", Rate: "2s"},
+		},
+	}
+	sinks, err := newSinkManager(out, sinkEndpoints{CoT: "tcp://127.0.0.1:1", GDL90: "udp://127.0.0.1:4001", DIS: "udp://127.0.0.1:3000"})
+	if err != nil {
+		t.Fatalf("newSinkManager failed: %v", err)
+	}
+	defer sinks.Close()
+
+	want := map[string]string{
+		"tracks": "udp://127.0.0.1:8600 2s",
+		"gdl90":  "udp://127.0.0.1:4001 1s",
+		"dis":    "udp://127.0.0.1:3000 1s",
+		"cot":    "tcp://127.0.0.1:1 5s",
+	}
+	statuses := sinks.Status()
+	if len(statuses) != len(want) {
+		t.Fatalf("Expected %d sinks, got %+v", len(want), statuses)
+	}
+	for _, status := range statuses {
+		if got := status.Endpoint + " " + status.Rate; got != want[status.Name] {
+			t.Errorf("sink %s = %s, want %s", status.Name, got, want[status.Name])
+		}
+	}
+	if len(out.Sinks) != 1 {
+		t.Error("Expected the caller's sink list to be left alone")
+	}
+
+	if _, err := newSinkManager(config.OutputConfig{CoT: config.CoTConfig{Encoding: "mesh"}}, sinkEndpoints{CoT: "tcp://127.0.0.1:1"}); err == nil {
+		t.Error("Expected error for the mesh encoding on a tcp endpoint")
+	}
+	if _, err := newSinkManager(config.OutputConfig{}, sinkEndpoints{SBS: "udp://127.0.0.1:30003"}); err == nil {
+		t.Error("Expected SBS-1 over UDP to be rejected")
+	}
+	missing := config.CoTConfig{TLS: config.CoTTLSConfig{CertFile: "missing.pem", KeyFile: "missing.key"}}
+	if _, err := newSinkManager(config.OutputConfig{CoT: missing}, sinkEndpoints{CoT: "ssl://127.0.0.1:8089"}); err == nil {
+		t.Error("Expected error for missing client certificate files")
+	}
+}
+
+func TestNewCoTListener(t *testing.T) {
+	engine := sim.NewEngine(nil)
+	listener, err := newCoTListener(config.CoTConfig{MaxBackoff: "1s"}, "tcp://127.0.0.1:1", engine)
+	if err != nil {
+		t.Fatalf("Expected tcp listener, got error: %v", err)
+	}
+	listener.Close()
+
+	if _, err := newCoTListener(config.CoTConfig{}, "http://127.0.0.1:8087", engine); err == nil {
+		t.Error("Expected error for an unsupported scheme")
+	}
+
+	missing := config.CoTConfig{TLS: config.CoTTLSConfig{CertFile: "missing.pem", KeyFile: "missing.key"}}
+	if _, err := newCoTListener(missing, "ssl://127.0.0.1:8089", engine); err == nil {
+		t.Error("Expected error for missing client certificate files")
+	}
+}
+
+func TestSinksPublishEngineSnapshots(t *testing.T) {
+	receiver, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer receiver.Close()
+
+	sinks, err := newSinkManager(config.OutputConfig{DIS: config.DISConfig{SiteID: 42, Heartbeat: "1h"}},
+		sinkEndpoints{DIS: "udp://" + receiver.LocalAddr().String()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sinks.Close()
+
+	engine := sim.NewEngine(nil)
+	engine.AddPlatform(models.NewBoeing737_800Universal("UAL123", "UA123", models.Position{Latitude: 40.6, Longitude: -73.8, Altitude: 10000}))
+	engine.AddPlatform(models.NewExternalPlatform("EXT1", "OUTSIDER", models.PlatformTypeLand, "a-f-G", models.ExternalInfo{Source: "cot"}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go sinks.Run(ctx, engine.SnapshotPlatforms)
+
+	// Only the simulated aircraft is published, as soon as the sinks run
+	receiver.SetReadDeadline(time.Now().Add(5 * time.Second))
+	buf := make([]byte, 1500)
+	n, err := receiver.Read(buf)
+	if err != nil {
+		t.Fatalf("No DIS received: %v", err)
+	}
+	state, err := output.DecodeEntityStatePDU(buf[:n])
+	if err != nil || state.ID.Site != 42 || state.Marking != "UA123" {
+		t.Fatalf("Unexpected entity state %+v: %v", state, err)
+	}
+	for deadline := time.Now().Add(5 * time.Second); sinks.Status()[0].Reports != 1; time.Sleep(10 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected one report, got %+v", sinks.Status()[0])
+		}
+	}
+}
@@ -203,6 +203,9 @@ func validateFile(filePath string) ValidationResult {
 		result.Errors = append(result.Errors, errors...)
 	case "scenario_config":
 		errors := validateScenarioConfig(content)
+		if len(errors) == 0 {
+			errors = validateScenarioReferences(content, filePath)
+		}
 		result.Errors = append(result.Errors, errors...)
 	case "unknown":
 		// For unknown files, just validate YAML syntax (already done above)
@@ -277,6 +280,21 @@ func validateMainConfig(content []byte) []string {
 				errors = append(errors, fmt.Sprintf("scenario %s, instance %d: references unknown platform type '%s'",
 					scenarioName, i, instance.TypeID))
 			}
+			if instance.TLE != nil {
+				if err := instance.TLE.Validate(); err != nil {
+					errors = append(errors, fmt.Sprintf("scenario %s, instance %d: %v", scenarioName, i, err))
+				}
+			}
+			if instance.Orbit != nil {
+				if err := instance.Orbit.Validate(); err != nil {
+					errors = append(errors, fmt.Sprintf("scenario %s, instance %d: %v", scenarioName, i, err))
+				}
+			}
+			if instance.Constellation != nil {
+				if err := instance.Constellation.Validate(); err != nil {
+					errors = append(errors, fmt.Sprintf("scenario %s, instance %d: %v", scenarioName, i, err))
+				}
+			}
 		}
 	}
 
@@ -324,6 +342,14 @@ func validatePlatformType(typeName string, platform models.PlatformTypeDefinitio
 		errors = append(errors, fmt.Sprintf("platform '%s': category is required", typeName))
 	}
 
+	if err := platform.CoTConf.Validate(); err != nil {
+		errors = append(errors, fmt.Sprintf("platform '%s': cot_config: %v", typeName, err))
+	}
+
+	if err := platform.DISConf.Validate(); err != nil {
+		errors = append(errors, fmt.Sprintf("platform '%s': dis_config: %v", typeName, err))
+	}
+
 	// Performance validation
 	if platform.Performance.MaxSpeed <= 0 {
 		errors = append(errors, fmt.Sprintf("platform '%s': max_speed must be positive", typeName))
@@ -396,66 +422,40 @@ func validateDomainSpecific(typeName string, platform models.PlatformTypeDefinit
 
 // validateScenarioConfig validates scenario configuration files
 func validateScenarioConfig(content []byte) []string {
-	var errors []string
-
-	var scenarioData struct {
-		Metadata struct {
-			Name        string `yaml:"name"`
-			Description string `yaml:"description"`
-			Duration    int    `yaml:"duration"`
-		} `yaml:"metadata"`
-		Platforms []struct {
-			ID            string `yaml:"id"`
-			Type          string `yaml:"type"`
-			StartPosition struct {
-				Latitude  float64 `yaml:"latitude"`
-				Longitude float64 `yaml:"longitude"`
-				Altitude  float64 `yaml:"altitude"`
-			} `yaml:"start_position"`
-		} `yaml:"platforms"`
-	}
-
-	if err := yaml.Unmarshal(content, &scenarioData); err != nil {
-		errors = append(errors, fmt.Sprintf("Failed to parse scenario config: %v", err))
-		return errors
+	scenarioFile, err := config.ParseScenarioFile(content)
+	if err != nil {
+		return []string{fmt.Sprintf("Failed to parse scenario config: %v", err)}
 	}
 
-	// Validate metadata
-	if scenarioData.Metadata.Name == "" {
-		errors = append(errors, "metadata.name is required")
-	}
+	return errorMessages(scenarioFile.Validate())
+}
 
-	if scenarioData.Metadata.Duration <= 0 {
-		errors = append(errors, "metadata.duration must be positive")
+// validateScenarioReferences resolves the source_file and route_id references of a scenario file.
+// Source files are resolved against the platforms directory next to the scenario's directory.
+func validateScenarioReferences(content []byte, filePath string) []string {
+	scenarioFile, err := config.ParseScenarioFile(content)
+	if err != nil {
+		return []string{fmt.Sprintf("Failed to parse scenario config: %v", err)}
 	}
 
-	// Validate platforms
-	platformIDs := make(map[string]bool)
-	for i, platform := range scenarioData.Platforms {
-		if platform.ID == "" {
-			errors = append(errors, fmt.Sprintf("platform %d: id is required", i))
-		} else {
-			if platformIDs[platform.ID] {
-				errors = append(errors, fmt.Sprintf("platform %d: duplicate id '%s'", i, platform.ID))
-			}
-			platformIDs[platform.ID] = true
-		}
-
-		if platform.Type == "" {
-			errors = append(errors, fmt.Sprintf("platform %d (%s): type is required", i, platform.ID))
-		}
+	_, err = config.NewScenarioLoader(config.PlatformsDirFor(filePath)).Resolve(scenarioFile)
+	return errorMessages(err)
+}
 
-		// Validate coordinates
-		if platform.StartPosition.Latitude < -90 || platform.StartPosition.Latitude > 90 {
-			errors = append(errors, fmt.Sprintf("platform %d (%s): latitude must be between -90 and 90", i, platform.ID))
-		}
+// errorMessages flattens a joined error into one message per problem
+func errorMessages(err error) []string {
+	if err == nil {
+		return nil
+	}
 
-		if platform.StartPosition.Longitude < -180 || platform.StartPosition.Longitude > 180 {
-			errors = append(errors, fmt.Sprintf("platform %d (%s): longitude must be between -180 and 180", i, platform.ID))
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		var messages []string
+		for _, inner := range joined.Unwrap() {
+			messages = append(messages, errorMessages(inner)...)
 		}
+		return messages
 	}
-
-	return errors
+	return []string{err.Error()}
 }
 
 // printSummary prints the validation results
@@ -2,6 +2,7 @@ package main
 
 import (
 	"bytes"
+	"fmt"
 	"io"
 	"os"
 	"path/filepath"
@@ -474,6 +475,24 @@ platforms:
 	}
 }
 
+func TestValidateScenarioReferences(t *testing.T) {
+	scenarioPath := "../../data/configs/military_exercise.yaml"
+	content, err := os.ReadFile(scenarioPath)
+	if err != nil {
+		t.Fatalf("Failed to read scenario: %v", err)
+	}
+
+	if errors := validateScenarioReferences(content, scenarioPath); len(errors) > 0 {
+		t.Errorf("Expected no reference errors, got: %v", errors)
+	}
+
+	broken := strings.Replace(string(content), "airborne/military/f16_fighting_falcon.yaml", "airborne/military/missing.yaml", 1)
+	errors := validateScenarioReferences([]byte(broken), scenarioPath)
+	if len(errors) != 1 || !strings.Contains(errors[0], "VIPER01") {
+		t.Errorf("Expected one error for VIPER01, got: %v", errors)
+	}
+}
+
 func TestValidatePlatformDefinition(t *testing.T) {
 	// Valid platform definition
 	validPlatform := `
@@ -565,6 +584,42 @@ platform_types:
 	}
 }
 
+func TestValidatePlatformDefinition_CoTConfig(t *testing.T) {
+	platform := `
+platform_types:
+  mq9:
+    class: "MQ-9 Reaper"
+    category: "military_uav"
+    cot_config:
+      type: "%s"
+      affiliation: "%s"
+    performance:
+      max_speed: 134
+      cruise_speed: 85
+      max_altitude: 15240
+      climb_rate: 10
+    physical:
+      length: 11
+      width: 20
+      mass: 4760
+`
+
+	if errors := validatePlatformDefinition([]byte(fmt.Sprintf(platform, "a-f-A-M-U", "friend")), "data/platforms/airborne/military/mq9.yaml"); len(errors) > 0 {
+		t.Errorf("Expected no errors for a valid cot_config, got: %v", errors)
+	}
+
+	for _, tt := range []struct{ cotType, affiliation, wantErr string }{
+		{"a-f-A-M-U", "hostile", "does not match affiliation"},
+		{"a-f-A-M-U", "enemy", "unknown affiliation"},
+		{"MQ9", "friend", "must be an atom"},
+	} {
+		errors := validatePlatformDefinition([]byte(fmt.Sprintf(platform, tt.cotType, tt.affiliation)), "data/platforms/airborne/military/mq9.yaml")
+		if len(errors) != 1 || !strings.Contains(errors[0], tt.wantErr) {
+			t.Errorf("Expected a %q error for %s/%s, got: %v", tt.wantErr, tt.cotType, tt.affiliation, errors)
+		}
+	}
+}
+
 func TestValidatePlatformType(t *testing.T) {
 	// Test with minimal platform type that will fail validation
 	emptyPlatform := models.PlatformTypeDefinition{}
@@ -2,11 +2,16 @@ package config
 
 import (
 	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"time"
 
 	"gopkg.in/yaml.v3"
+
+	"github.com/rhino11/trafficsim/internal/models"
 )
 
 // Config represents the main configuration structure
@@ -23,7 +28,45 @@ type SimulationConfig struct {
 	TimeScale      float64      `yaml:"time_scale" default:"1.0"`
 	MaxDuration    string       `yaml:"max_duration" default:"1h"`
 	StartTime      string       `yaml:"start_time,omitempty"`
+	Seed           int64        `yaml:"seed,omitempty"`     // Random seed for reproducible runs; 0 seeds from the clock
+	Workers        int          `yaml:"workers,omitempty"`  // Goroutines sharing each update step; 0 uses GOMAXPROCS
+	Scenario       string       `yaml:"scenario,omitempty"` // Default scenario from platforms.scenarios
 	BoundingBox    *BoundingBox `yaml:"bounding_box,omitempty"`
+
+	TerrainDir       string  `yaml:"terrain_dir,omitempty"`       // SRTM .hgt or GeoTIFF elevation tiles; unset keeps the ground flat
+	TerrainClearance float64 `yaml:"terrain_clearance,omitempty"` // Meters aircraft keep above the ground en route; 0 uses 150
+}
+
+// ParseMaxDuration returns the longest a simulation may run in simulation time; zero means unlimited
+func (s SimulationConfig) ParseMaxDuration() (time.Duration, error) {
+	if s.MaxDuration == "" {
+		return 0, nil
+	}
+	duration, err := time.ParseDuration(s.MaxDuration)
+	if err != nil {
+		return 0, fmt.Errorf("invalid max_duration %q: %w", s.MaxDuration, err)
+	}
+	if duration < 0 {
+		return 0, fmt.Errorf("max_duration cannot be negative: %s", s.MaxDuration)
+	}
+	return duration, nil
+}
+
+// ParseStartTime returns the simulated date and time at t=0 (RFC 3339); the zero time when unset
+func (s SimulationConfig) ParseStartTime() (time.Time, error) {
+	return parseStartTime(s.StartTime)
+}
+
+// parseStartTime parses an optional RFC 3339 start time
+func parseStartTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	start, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid start_time %q: expected RFC 3339, e.g. 2024-06-01T12:00:00Z", value)
+	}
+	return start.UTC(), nil
 }
 
 // BoundingBox defines simulation area limits
@@ -41,17 +84,262 @@ type ServerConfig struct {
 	WebRoot string `yaml:"web_root" default:"web"`
 }
 
-// OutputConfig contains CoT and other output settings
+// OutputConfig contains CoT and other output settings. Sinks lists output streams with their own
+// format, rate and filter; the adsb, ais, dis and asterix sections each add a sink for their endpoints.
 type OutputConfig struct {
+	Sinks   []SinkConfig  `yaml:"sinks,omitempty"`
 	CoT     CoTConfig     `yaml:"cot"`
+	ADSB    ADSBConfig    `yaml:"adsb,omitempty"`
+	AIS     AISConfig     `yaml:"ais,omitempty"`
+	DIS     DISConfig     `yaml:"dis,omitempty"`
+	ASTERIX ASTERIXConfig `yaml:"asterix,omitempty"`
 	Logging LoggingConfig `yaml:"logging"`
 }
 
-// CoTConfig contains Cursor-on-Target output settings
+// CoTConfig contains Cursor-on-Target output settings. The endpoint is a udp:// (multicast),
+// tcp:// or ssl:// URL; the queue and backoff settings apply to tcp and ssl streams.
 type CoTConfig struct {
-	Enabled    bool   `yaml:"enabled" default:"true"`
-	Endpoint   string `yaml:"endpoint" default:"udp://239.2.3.1:6969"`
-	UpdateRate string `yaml:"update_rate" default:"5s"`
+	Enabled    bool         `yaml:"enabled" default:"true"`
+	Endpoint   string       `yaml:"endpoint" default:"udp://239.2.3.1:6969"`
+	UpdateRate string       `yaml:"update_rate" default:"5s"`
+	TLS        CoTTLSConfig `yaml:"tls,omitempty"`
+	QueueSize  int          `yaml:"queue_size,omitempty"`  // Messages held while disconnected, 0 for the default
+	MaxBackoff string       `yaml:"max_backoff,omitempty"` // Longest reconnect delay, e.g. "30s"
+	Encoding   string       `yaml:"encoding,omitempty"`    // xml (default), or TAK Protocol mesh (udp) or stream (tcp, ssl)
+}
+
+// CoTTLSConfig holds the PEM files for ssl:// endpoints such as a TAK Server
+type CoTTLSConfig struct {
+	CertFile           string `yaml:"cert_file,omitempty"`
+	KeyFile            string `yaml:"key_file,omitempty"`
+	CAFile             string `yaml:"ca_file,omitempty"`
+	ServerName         string `yaml:"server_name,omitempty"`
+	InsecureSkipVerify bool   `yaml:"insecure_skip_verify,omitempty"`
+}
+
+// ParseMaxBackoff returns the longest reconnect delay; zero means the publisher default
+func (c CoTConfig) ParseMaxBackoff() (time.Duration, error) {
+	if c.MaxBackoff == "" {
+		return 0, nil
+	}
+	backoff, err := time.ParseDuration(c.MaxBackoff)
+	if err != nil {
+		return 0, fmt.Errorf("invalid max_backoff %q: %w", c.MaxBackoff, err)
+	}
+	if backoff < 0 {
+		return 0, fmt.Errorf("max_backoff cannot be negative: %s", c.MaxBackoff)
+	}
+	return backoff, nil
+}
+
+// Validate checks the endpoint scheme, encoding and stream settings; an empty endpoint takes the default
+func (c CoTConfig) Validate() error {
+	scheme := "udp"
+	if c.Endpoint != "" {
+		u, err := url.Parse(c.Endpoint)
+		if err != nil {
+			return fmt.Errorf("invalid cot endpoint %q: %w", c.Endpoint, err)
+		}
+		scheme = strings.ToLower(u.Scheme)
+		switch scheme {
+		case "udp", "tcp", "ssl":
+		default:
+			return fmt.Errorf("invalid cot endpoint %q: scheme must be udp, tcp or ssl", c.Endpoint)
+		}
+		if u.Hostname() == "" || u.Port() == "" {
+			return fmt.Errorf("invalid cot endpoint %q: host and port are required", c.Endpoint)
+		}
+	}
+
+	switch strings.ToLower(c.Encoding) {
+	case "", "xml":
+	case "mesh":
+		if scheme != "udp" {
+			return fmt.Errorf("cot encoding mesh needs a udp endpoint, use stream for %s", scheme)
+		}
+	case "stream":
+		if scheme == "udp" {
+			return fmt.Errorf("cot encoding stream needs a tcp or ssl endpoint, use mesh for udp")
+		}
+	default:
+		return fmt.Errorf("invalid cot encoding %q: expected xml, mesh or stream", c.Encoding)
+	}
+
+	if c.QueueSize < 0 {
+		return fmt.Errorf("invalid cot queue_size: %d", c.QueueSize)
+	}
+	if _, err := c.ParseMaxBackoff(); err != nil {
+		return err
+	}
+	if (c.TLS.CertFile == "") != (c.TLS.KeyFile == "") {
+		return fmt.Errorf("cot tls cert_file and key_file must be given together")
+	}
+	return nil
+}
+
+// ADSBConfig contains ADS-B output settings for airborne platforms; each format is off when its
+// endpoint is empty
+type ADSBConfig struct {
+	SBSEndpoint   string `yaml:"sbs_endpoint,omitempty"`   // tcp:// consumer of SBS-1 BaseStation CSV
+	GDL90Endpoint string `yaml:"gdl90_endpoint,omitempty"` // udp:// EFB address, usually broadcast on port 4000
+}
+
+// Validate checks that SBS-1 goes to a tcp:// endpoint and GDL90 to a udp:// endpoint
+func (c ADSBConfig) Validate() error {
+	for _, endpoint := range []struct{ name, value, scheme string }{
+		{"sbs_endpoint", c.SBSEndpoint, "tcp"},
+		{"gdl90_endpoint", c.GDL90Endpoint, "udp"},
+	} {
+		if endpoint.value == "" {
+			continue
+		}
+		u, err := url.Parse(endpoint.value)
+		if err != nil {
+			return fmt.Errorf("invalid adsb %s %q: %w", endpoint.name, endpoint.value, err)
+		}
+		if strings.ToLower(u.Scheme) != endpoint.scheme {
+			return fmt.Errorf("invalid adsb %s %q: scheme must be %s", endpoint.name, endpoint.value, endpoint.scheme)
+		}
+		if u.Hostname() == "" || u.Port() == "" {
+			return fmt.Errorf("invalid adsb %s %q: host and port are required", endpoint.name, endpoint.value)
+		}
+	}
+	return nil
+}
+
+// AISConfig contains AIS output settings for maritime platforms; it is off when the endpoint is empty
+type AISConfig struct {
+	Endpoint string `yaml:"endpoint,omitempty"` // udp:// or tcp:// NMEA consumer, e.g. OpenCPN on port 10110
+}
+
+// Validate checks the endpoint is udp:// or tcp://
+func (c AISConfig) Validate() error {
+	if c.Endpoint == "" {
+		return nil
+	}
+	u, err := url.Parse(c.Endpoint)
+	if err != nil {
+		return fmt.Errorf("invalid ais endpoint %q: %w", c.Endpoint, err)
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "udp", "tcp":
+	default:
+		return fmt.Errorf("invalid ais endpoint %q: scheme must be udp or tcp", c.Endpoint)
+	}
+	if u.Hostname() == "" || u.Port() == "" {
+		return fmt.Errorf("invalid ais endpoint %q: host and port are required", c.Endpoint)
+	}
+	return nil
+}
+
+// DISConfig contains DIS (IEEE 1278.1) Entity State output settings; it is off when the endpoint is
+// empty. Unset identifiers default to 1, the protocol version to 7 and the heartbeat to 5s.
+type DISConfig struct {
+	Endpoint        string `yaml:"endpoint,omitempty"`         // udp:// broadcast address or multicast group, usually port 3000
+	SiteID          int    `yaml:"site_id,omitempty"`          // 0 uses 1; 0 is reserved on the wire
+	ApplicationID   int    `yaml:"application_id,omitempty"`   // 0 uses 1
+	ExerciseID      int    `yaml:"exercise_id,omitempty"`      // 0 uses 1
+	ProtocolVersion int    `yaml:"protocol_version,omitempty"` // 6 or 7
+	Heartbeat       string `yaml:"heartbeat,omitempty"`        // Longest gap between updates of an entity, e.g. "5s"
+}
+
+// ParseHeartbeat returns the heartbeat interval; zero means the publisher default
+func (c DISConfig) ParseHeartbeat() (time.Duration, error) {
+	if c.Heartbeat == "" {
+		return 0, nil
+	}
+	heartbeat, err := time.ParseDuration(c.Heartbeat)
+	if err != nil {
+		return 0, fmt.Errorf("invalid dis heartbeat %q: %w", c.Heartbeat, err)
+	}
+	if heartbeat <= 0 {
+		return 0, fmt.Errorf("dis heartbeat must be positive: %s", c.Heartbeat)
+	}
+	return heartbeat, nil
+}
+
+// Validate checks the endpoint is udp:// and the identifiers fit their PDU fields
+func (c DISConfig) Validate() error {
+	if c.Endpoint != "" {
+		u, err := url.Parse(c.Endpoint)
+		if err != nil {
+			return fmt.Errorf("invalid dis endpoint %q: %w", c.Endpoint, err)
+		}
+		if strings.ToLower(u.Scheme) != "udp" {
+			return fmt.Errorf("invalid dis endpoint %q: scheme must be udp", c.Endpoint)
+		}
+		if u.Hostname() == "" || u.Port() == "" {
+			return fmt.Errorf("invalid dis endpoint %q: host and port are required", c.Endpoint)
+		}
+	}
+
+	for _, id := range []struct {
+		name       string
+		value, max int
+	}{
+		{"site_id", c.SiteID, 0xFFFE},
+		{"application_id", c.ApplicationID, 0xFFFE},
+		{"exercise_id", c.ExerciseID, 0xFF},
+	} {
+		if id.value < 0 || id.value > id.max {
+			return fmt.Errorf("invalid dis %s %d: must be between 0 and %d", id.name, id.value, id.max)
+		}
+	}
+	switch c.ProtocolVersion {
+	case 0, 6, 7:
+	default:
+		return fmt.Errorf("invalid dis protocol_version %d: expected 6 or 7", c.ProtocolVersion)
+	}
+	_, err := c.ParseHeartbeat()
+	return err
+}
+
+// ASTERIXConfig contains ASTERIX CAT062 system track output settings; it is off when the endpoint
+// is empty
+type ASTERIXConfig struct {
+	Endpoint     string `yaml:"endpoint,omitempty"`      // udp:// address of the display or track consumer
+	SAC          int    `yaml:"sac,omitempty"`           // System area code
+	SIC          int    `yaml:"sic,omitempty"`           // System identification code
+	UpdatePeriod string `yaml:"update_period,omitempty"` // Time between track updates, e.g. "4s"
+}
+
+// ParseUpdatePeriod returns the track update period; zero means the publisher default
+func (c ASTERIXConfig) ParseUpdatePeriod() (time.Duration, error) {
+	if c.UpdatePeriod == "" {
+		return 0, nil
+	}
+	period, err := time.ParseDuration(c.UpdatePeriod)
+	if err != nil {
+		return 0, fmt.Errorf("invalid asterix update_period %q: %w", c.UpdatePeriod, err)
+	}
+	if period <= 0 {
+		return 0, fmt.Errorf("asterix update_period must be positive: %s", c.UpdatePeriod)
+	}
+	return period, nil
+}
+
+// Validate checks the endpoint is udp://, SAC and SIC fit in a byte and the update period parses
+func (c ASTERIXConfig) Validate() error {
+	if c.Endpoint != "" {
+		u, err := url.Parse(c.Endpoint)
+		if err != nil {
+			return fmt.Errorf("invalid asterix endpoint %q: %w", c.Endpoint, err)
+		}
+		if strings.ToLower(u.Scheme) != "udp" {
+			return fmt.Errorf("invalid asterix endpoint %q: scheme must be udp", c.Endpoint)
+		}
+		if u.Hostname() == "" || u.Port() == "" {
+			return fmt.Errorf("invalid asterix endpoint %q: host and port are required", c.Endpoint)
+		}
+	}
+	if c.SAC < 0 || c.SAC > 255 {
+		return fmt.Errorf("invalid asterix sac %d: must be between 0 and 255", c.SAC)
+	}
+	if c.SIC < 0 || c.SIC > 255 {
+		return fmt.Errorf("invalid asterix sic %d: must be between 0 and 255", c.SIC)
+	}
+	_, err := c.ParseUpdatePeriod()
+	return err
 }
 
 // LoggingConfig contains logging settings
@@ -112,6 +400,12 @@ type PlatformTypeDefinition struct {
 	// Call sign patterns
 	CallSignPrefix string `yaml:"callsign_prefix,omitempty"`
 	CallSignFormat string `yaml:"callsign_format,omitempty"` // e.g., "{prefix}{id}"
+
+	// Cursor on Target reporting
+	CoTConfig models.CoTConfiguration `yaml:"cot_config,omitempty"`
+
+	// DIS reporting
+	DISConfig models.DISConfiguration `yaml:"dis_config,omitempty"`
 }
 
 // ScenarioConfig defines a simulation scenario with platform instances
@@ -124,14 +418,32 @@ type ScenarioConfig struct {
 
 // PlatformInstance defines a specific platform instance in a scenario
 type PlatformInstance struct {
-	ID          string          `yaml:"id"`
-	TypeID      string          `yaml:"type_id"`            // References PlatformTypeDefinition
-	Name        string          `yaml:"name"`               // Display name/flight number
-	CallSign    string          `yaml:"callsign,omitempty"` // Override callsign
-	StartPos    Position        `yaml:"start_position"`
-	Destination *Position       `yaml:"destination,omitempty"`
-	Route       []Position      `yaml:"route,omitempty"`
-	Behavior    *BehaviorConfig `yaml:"behavior,omitempty"`
+	ID          string           `yaml:"id"`
+	TypeID      string           `yaml:"type_id"`                // References PlatformTypeDefinition
+	Name        string           `yaml:"name"`                   // Display name/flight number
+	CallSign    string           `yaml:"callsign,omitempty"`     // Override callsign
+	Affiliation string           `yaml:"affiliation,omitempty"`  // Override the type's CoT affiliation, e.g. "hostile"
+	ICAOAddress string           `yaml:"icao_address,omitempty"` // ADS-B address as six hex digits, derived from the ID when empty
+	Squawk      string           `yaml:"squawk,omitempty"`       // Four octal digits, derived from the ID when empty
+	MMSI        string           `yaml:"mmsi,omitempty"`         // AIS MMSI as nine digits, derived from the ID when empty
+	AISDark     bool             `yaml:"ais_dark,omitempty"`     // Vessel does not transmit AIS, e.g. a warship running dark
+	StartPos    Position         `yaml:"start_position"`
+	Destination *Position        `yaml:"destination,omitempty"`
+	Route       []Position       `yaml:"route,omitempty"`
+	RouteMode   string           `yaml:"route_mode,omitempty"`   // "once" (default), "loop", "reverse", "hold"
+	RouteSpeeds []float64        `yaml:"route_speeds,omitempty"` // m/s per route leg, 0 = cruise speed
+	Behavior    *BehaviorConfig  `yaml:"behavior,omitempty"`
+	TLE         *TLESource       `yaml:"tle,omitempty"`              // Space platforms only: follow the orbit of an element set
+	Orbit       *OrbitalElements `yaml:"orbital_elements,omitempty"` // Space platforms only: follow a Keplerian orbit
+
+	// Space platforms only: one platform per satellite of a Walker-delta shell
+	Constellation *WalkerConstellation `yaml:"constellation,omitempty"`
+
+	SpawnTime        float64 `yaml:"spawn_time,omitempty"`         // Seconds after scenario start, 0 = present from the start
+	DespawnTime      float64 `yaml:"despawn_time,omitempty"`       // Seconds after scenario start, 0 = never
+	DespawnOnArrival bool    `yaml:"despawn_on_arrival,omitempty"` // Remove once the destination or route end is reached
+
+	elements *models.TLE // Element set chosen when a TLE source was expanded
 }
 
 // Position represents a 3D position
@@ -151,9 +463,9 @@ type BehaviorConfig struct {
 // PatrolBehavior defines patrol pattern behavior
 type PatrolBehavior struct {
 	Pattern   string     `yaml:"pattern"` // "line", "box", "circle"
-	Points    []Position `yaml:"points"`
+	Points    []Position `yaml:"points"`  // box: 2 opposite corners or a polygon; circle: center then a point on the circle
 	Speed     float64    `yaml:"speed,omitempty"`
-	LoopCount int        `yaml:"loop_count,omitempty"` // -1 for infinite
+	LoopCount int        `yaml:"loop_count,omitempty"` // -1 for infinite, 0 for a single pass
 }
 
 // CircuitBehavior defines circuit flight pattern
@@ -259,6 +571,44 @@ func validateConfig(config *Config) error {
 		return fmt.Errorf("invalid time scale: %f", config.Simulation.TimeScale)
 	}
 
+	if config.Simulation.Workers < 0 {
+		return fmt.Errorf("invalid worker count: %d", config.Simulation.Workers)
+	}
+
+	// Validate run length and start time
+	if _, err := config.Simulation.ParseMaxDuration(); err != nil {
+		return err
+	}
+	if _, err := config.Simulation.ParseStartTime(); err != nil {
+		return err
+	}
+
+	if err := config.Output.CoT.Validate(); err != nil {
+		return err
+	}
+	if err := config.Output.ADSB.Validate(); err != nil {
+		return err
+	}
+	if err := config.Output.AIS.Validate(); err != nil {
+		return err
+	}
+	if err := config.Output.DIS.Validate(); err != nil {
+		return err
+	}
+	if err := config.Output.ASTERIX.Validate(); err != nil {
+		return err
+	}
+	if err := config.Output.validateSinks(); err != nil {
+		return err
+	}
+
+	// Validate default scenario reference
+	if config.Simulation.Scenario != "" {
+		if _, exists := config.Platforms.Scenarios[config.Simulation.Scenario]; !exists {
+			return fmt.Errorf("unknown default scenario: %s", config.Simulation.Scenario)
+		}
+	}
+
 	// Validate platform type references in scenarios
 	for scenarioName, scenario := range config.Platforms.Scenarios {
 		for i, instance := range scenario.Instances {
@@ -266,12 +616,126 @@ func validateConfig(config *Config) error {
 				return fmt.Errorf("scenario %s, instance %d: unknown platform type %s",
 					scenarioName, i, instance.TypeID)
 			}
+			if _, err := models.ParseRouteMode(instance.RouteMode); err != nil {
+				return fmt.Errorf("scenario %s, instance %d: %w", scenarioName, i, err)
+			}
+			if instance.Behavior != nil {
+				if err := instance.Behavior.Validate(); err != nil {
+					return fmt.Errorf("scenario %s, instance %d: %w", scenarioName, i, err)
+				}
+			}
+			if err := instance.ValidateTiming(); err != nil {
+				return fmt.Errorf("scenario %s, instance %d: %w", scenarioName, i, err)
+			}
+			typeDef, _ := config.Platforms.GetType(instance.TypeID)
+			if err := instance.validateOrbit(typeDef.Type); err != nil {
+				return fmt.Errorf("scenario %s, instance %d: %w", scenarioName, i, err)
+			}
+			if err := models.ValidateAffiliation(instance.Affiliation); err != nil {
+				return fmt.Errorf("scenario %s, instance %d: %w", scenarioName, i, err)
+			}
+			if err := models.ValidateTransponder(instance.ICAOAddress, instance.Squawk, instance.MMSI); err != nil {
+				return fmt.Errorf("scenario %s, instance %d: %w", scenarioName, i, err)
+			}
 		}
 	}
 
 	return nil
 }
 
+// ValidateTiming checks the instance spawn and despawn settings
+func (p *PlatformInstance) ValidateTiming() error {
+	if p.SpawnTime < 0 {
+		return fmt.Errorf("spawn_time cannot be negative")
+	}
+	if p.DespawnTime < 0 {
+		return fmt.Errorf("despawn_time cannot be negative")
+	}
+	if p.DespawnTime > 0 && p.DespawnTime <= p.SpawnTime {
+		return fmt.Errorf("despawn_time must be after spawn_time")
+	}
+	// Only destinations, routes and patrols have an end to arrive at
+	if p.DespawnOnArrival && p.Destination == nil && len(p.Route) == 0 && (p.Behavior == nil || p.Behavior.Patrol == nil) {
+		return fmt.Errorf("despawn_on_arrival requires a destination, route or patrol behavior")
+	}
+	return nil
+}
+
+// Validate checks that exactly one behavior pattern is defined and that its parameters are usable
+func (b *BehaviorConfig) Validate() error {
+	defined := 0
+	if b.Patrol != nil {
+		defined++
+	}
+	if b.CircuitFlight != nil {
+		defined++
+	}
+	if b.RandomWalk != nil {
+		defined++
+	}
+	if defined != 1 {
+		return fmt.Errorf("behavior must define exactly one of patrol, circuit or random_walk, got %d", defined)
+	}
+
+	switch {
+	case b.Patrol != nil:
+		return b.Patrol.validate()
+	case b.CircuitFlight != nil:
+		if b.CircuitFlight.Radius <= 0 {
+			return fmt.Errorf("circuit radius must be positive: %f", b.CircuitFlight.Radius)
+		}
+		if b.CircuitFlight.Speed < 0 {
+			return fmt.Errorf("circuit speed cannot be negative: %f", b.CircuitFlight.Speed)
+		}
+	default:
+		area := b.RandomWalk.Area
+		if area.North <= area.South || area.East <= area.West {
+			return fmt.Errorf("random walk area is empty: %+v", area)
+		}
+		if b.RandomWalk.MaxDistance < 0 {
+			return fmt.Errorf("random walk max distance cannot be negative: %f", b.RandomWalk.MaxDistance)
+		}
+		if b.RandomWalk.Speed < 0 {
+			return fmt.Errorf("random walk speed cannot be negative: %f", b.RandomWalk.Speed)
+		}
+	}
+	return nil
+}
+
+// validate checks the patrol pattern has enough points
+func (p *PatrolBehavior) validate() error {
+	switch p.Pattern {
+	case "line", "circle":
+		if len(p.Points) < 2 {
+			return fmt.Errorf("%s patrol requires at least 2 points, got %d", p.Pattern, len(p.Points))
+		}
+	case "box":
+		if len(p.Points) < 2 {
+			return fmt.Errorf("box patrol requires 2 corner points or a polygon, got %d points", len(p.Points))
+		}
+	default:
+		return fmt.Errorf("unknown patrol pattern: %s", p.Pattern)
+	}
+
+	if p.LoopCount < -1 {
+		return fmt.Errorf("invalid patrol loop count: %d", p.LoopCount)
+	}
+	if p.Speed < 0 {
+		return fmt.Errorf("patrol speed cannot be negative: %f", p.Speed)
+	}
+	return nil
+}
+
+// ScenarioNames returns the names of all configured scenarios in sorted order
+func (pr *PlatformRegistry) ScenarioNames() []string {
+	names := make([]string, 0, len(pr.Scenarios))
+	for name := range pr.Scenarios {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 // HasType checks if a platform type exists in the registry
 func (pr *PlatformRegistry) HasType(typeID string) bool {
 	if _, exists := pr.AirborneTypes[typeID]; exists {
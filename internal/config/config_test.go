@@ -2,7 +2,9 @@ package config
 
 import (
 	"os"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/rhino11/trafficsim/internal/testutil"
 )
@@ -255,3 +257,333 @@ platforms:
 		t.Error("Expected error for invalid port")
 	}
 }
+
+func TestValidateConfig_DefaultScenario(t *testing.T) {
+	cfg := &Config{
+		Simulation: SimulationConfig{TimeScale: 1.0, Scenario: "missing"},
+		Server:     ServerConfig{Port: 8080},
+		Platforms: PlatformRegistry{
+			Scenarios: map[string]ScenarioConfig{
+				"zulu":  {Name: "Zulu"},
+				"alpha": {Name: "Alpha"},
+			},
+		},
+	}
+
+	if err := validateConfig(cfg); err == nil {
+		t.Error("Expected error for unknown default scenario")
+	}
+
+	cfg.Simulation.Scenario = "zulu"
+	if err := validateConfig(cfg); err != nil {
+		t.Errorf("Expected valid default scenario, got error: %v", err)
+	}
+
+	names := cfg.Platforms.ScenarioNames()
+	if len(names) != 2 || names[0] != "alpha" || names[1] != "zulu" {
+		t.Errorf("Expected sorted scenario names [alpha zulu], got %v", names)
+	}
+}
+
+func TestBehaviorConfigValidate(t *testing.T) {
+	corners := []Position{{Latitude: 36.8, Longitude: -76.3}, {Latitude: 36.9, Longitude: -76.2}}
+
+	tests := []struct {
+		name     string
+		behavior BehaviorConfig
+		wantErr  bool
+	}{
+		{"empty", BehaviorConfig{}, true},
+		{"patrol box", BehaviorConfig{Patrol: &PatrolBehavior{Pattern: "box", Points: corners, LoopCount: -1}}, false},
+		{"patrol unknown pattern", BehaviorConfig{Patrol: &PatrolBehavior{Pattern: "zigzag", Points: corners}}, true},
+		{"patrol single point", BehaviorConfig{Patrol: &PatrolBehavior{Pattern: "line", Points: corners[:1]}}, true},
+		{"patrol bad loop count", BehaviorConfig{Patrol: &PatrolBehavior{Pattern: "line", Points: corners, LoopCount: -2}}, true},
+		{"circuit", BehaviorConfig{CircuitFlight: &CircuitBehavior{Center: corners[0], Radius: 5000}}, false},
+		{"circuit without radius", BehaviorConfig{CircuitFlight: &CircuitBehavior{Center: corners[0]}}, true},
+		{"random walk", BehaviorConfig{RandomWalk: &RandomWalkBehavior{Area: BoundingBox{North: 37, South: 36, East: -76, West: -77}}}, false},
+		{"random walk empty area", BehaviorConfig{RandomWalk: &RandomWalkBehavior{}}, true},
+		{
+			"multiple patterns",
+			BehaviorConfig{
+				Patrol:        &PatrolBehavior{Pattern: "line", Points: corners},
+				CircuitFlight: &CircuitBehavior{Radius: 5000},
+			},
+			true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.behavior.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPlatformInstanceValidateTiming(t *testing.T) {
+	destination := &Position{Latitude: 36.9, Longitude: -76.2}
+	patrol := &BehaviorConfig{Patrol: &PatrolBehavior{Pattern: "line", Points: []Position{{}, {Latitude: 1}}}}
+	circuit := &BehaviorConfig{CircuitFlight: &CircuitBehavior{Radius: 5000}}
+
+	tests := []struct {
+		name     string
+		instance PlatformInstance
+		wantErr  bool
+	}{
+		{"no timing", PlatformInstance{}, false},
+		{"spawn and despawn", PlatformInstance{SpawnTime: 300, DespawnTime: 600}, false},
+		{"negative spawn", PlatformInstance{SpawnTime: -1}, true},
+		{"despawn before spawn", PlatformInstance{SpawnTime: 600, DespawnTime: 300}, true},
+		{"arrival with destination", PlatformInstance{Destination: destination, DespawnOnArrival: true}, false},
+		{"arrival with patrol", PlatformInstance{Behavior: patrol, DespawnOnArrival: true}, false},
+		{"arrival with circuit", PlatformInstance{Behavior: circuit, DespawnOnArrival: true}, true},
+		{"arrival without destination", PlatformInstance{DespawnOnArrival: true}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.instance.ValidateTiming()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateTiming() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSimulationConfigTiming(t *testing.T) {
+	sim := SimulationConfig{MaxDuration: "90m", StartTime: "2024-06-01T14:00:00+02:00"}
+
+	duration, err := sim.ParseMaxDuration()
+	if err != nil || duration != 90*time.Minute {
+		t.Errorf("Expected 90m max duration, got %v (%v)", duration, err)
+	}
+	start, err := sim.ParseStartTime()
+	if err != nil || !start.Equal(time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)) || start.Location() != time.UTC {
+		t.Errorf("Expected start time normalised to UTC, got %v (%v)", start, err)
+	}
+
+	if start, err := (SimulationConfig{}).ParseStartTime(); err != nil || !start.IsZero() {
+		t.Errorf("Expected zero start time when unset, got %v (%v)", start, err)
+	}
+	if _, err := (SimulationConfig{MaxDuration: "-1h"}).ParseMaxDuration(); err == nil {
+		t.Error("Expected error for negative max duration")
+	}
+	if _, err := (SimulationConfig{StartTime: "June 1st"}).ParseStartTime(); err == nil {
+		t.Error("Expected error for a start time that is not RFC 3339")
+	}
+
+	cfg := &Config{
+		Simulation: SimulationConfig{TimeScale: 1, MaxDuration: "forever"},
+		Server:     ServerConfig{Port: 8080},
+	}
+	if err := validateConfig(cfg); err == nil || !strings.Contains(err.Error(), "max_duration") {
+		t.Errorf("Expected max_duration validation error, got %v", err)
+	}
+}
+
+func TestCoTConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		cot     CoTConfig
+		wantErr bool
+	}{
+		{"default endpoint", CoTConfig{}, false},
+		{"udp", CoTConfig{Endpoint: "udp://239.2.3.1:6969"}, false},
+		{"tcp", CoTConfig{Endpoint: "tcp://tak.example.com:8087", QueueSize: 500, MaxBackoff: "1m"}, false},
+		{"ssl with client cert", CoTConfig{Endpoint: "ssl://tak.example.com:8089", TLS: CoTTLSConfig{CertFile: "c.pem", KeyFile: "k.pem"}}, false},
+		{"unknown scheme", CoTConfig{Endpoint: "http://tak.example.com:8080"}, true},
+		{"missing port", CoTConfig{Endpoint: "tcp://tak.example.com"}, true},
+		{"negative queue", CoTConfig{Endpoint: "tcp://tak.example.com:8087", QueueSize: -1}, true},
+		{"bad backoff", CoTConfig{Endpoint: "tcp://tak.example.com:8087", MaxBackoff: "soon"}, true},
+		{"cert without key", CoTConfig{Endpoint: "ssl://tak.example.com:8089", TLS: CoTTLSConfig{CertFile: "c.pem"}}, true},
+		{"mesh on default udp", CoTConfig{Encoding: "mesh"}, false},
+		{"stream on ssl", CoTConfig{Endpoint: "ssl://tak.example.com:8089", Encoding: "stream"}, false},
+		{"stream on udp", CoTConfig{Endpoint: "udp://239.2.3.1:6969", Encoding: "stream"}, true},
+		{"mesh on tcp", CoTConfig{Endpoint: "tcp://tak.example.com:8087", Encoding: "mesh"}, true},
+		{"unknown encoding", CoTConfig{Encoding: "json"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cot.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestADSBConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		adsb    ADSBConfig
+		wantErr bool
+	}{
+		{"disabled", ADSBConfig{}, false},
+		{"both", ADSBConfig{SBSEndpoint: "tcp://127.0.0.1:30003", GDL90Endpoint: "udp://192.168.1.255:4000"}, false},
+		{"sbs over udp", ADSBConfig{SBSEndpoint: "udp://127.0.0.1:30003"}, true},
+		{"gdl90 over tcp", ADSBConfig{GDL90Endpoint: "tcp://127.0.0.1:4000"}, true},
+		{"missing port", ADSBConfig{GDL90Endpoint: "udp://192.168.1.255"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.adsb.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAISConfigValidate(t *testing.T) {
+	for endpoint, wantErr := range map[string]bool{
+		"":                        false,
+		"udp://127.0.0.1:10110":   false,
+		"tcp://opencpn.lan:10110": false,
+		"ssl://opencpn.lan:10110": true,
+		"udp://127.0.0.1":         true,
+	} {
+		if err := (AISConfig{Endpoint: endpoint}).Validate(); (err != nil) != wantErr {
+			t.Errorf("Validate(%q) error = %v, wantErr %v", endpoint, err, wantErr)
+		}
+	}
+}
+
+func TestDISConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  DISConfig
+		wantErr bool
+	}{
+		{"off", DISConfig{}, false},
+		{"broadcast", DISConfig{Endpoint: "udp://192.168.1.255:3000", SiteID: 42, ApplicationID: 7, ExerciseID: 1}, false},
+		{"multicast v6", DISConfig{Endpoint: "udp://239.1.2.3:3000", ProtocolVersion: 6, Heartbeat: "10s"}, false},
+		{"tcp", DISConfig{Endpoint: "tcp://127.0.0.1:3000"}, true},
+		{"no port", DISConfig{Endpoint: "udp://127.0.0.1"}, true},
+		{"site too large", DISConfig{SiteID: 65535}, true},
+		{"negative application", DISConfig{ApplicationID: -1}, true},
+		{"exercise too large", DISConfig{ExerciseID: 256}, true},
+		{"version 5", DISConfig{ProtocolVersion: 5}, true},
+		{"bad heartbeat", DISConfig{Heartbeat: "soon"}, true},
+		{"zero heartbeat", DISConfig{Heartbeat: "0s"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.config.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestASTERIXConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  ASTERIXConfig
+		wantErr bool
+	}{
+		{"off", ASTERIXConfig{}, false},
+		{"configured", ASTERIXConfig{Endpoint: "udp://239.1.1.62:8600", SAC: 25, SIC: 101, UpdatePeriod: "5s"}, false},
+		{"tcp", ASTERIXConfig{Endpoint: "tcp://127.0.0.1:8600"}, true},
+		{"no port", ASTERIXConfig{Endpoint: "udp://127.0.0.1"}, true},
+		{"sac too large", ASTERIXConfig{SAC: 256}, true},
+		{"negative sic", ASTERIXConfig{SIC: -1}, true},
+		{"bad period", ASTERIXConfig{UpdatePeriod: "often"}, true},
+		{"negative period", ASTERIXConfig{UpdatePeriod: "-4s"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.config.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateConfig_Transponder(t *testing.T) {
+	instances := []PlatformInstance{{ID: "UAL1", TypeID: "b737", Name: "UA1", ICAOAddress: "A1B2C3", Squawk: "4521"}}
+	cfg := &Config{
+		Simulation: SimulationConfig{TimeScale: 1.0},
+		Server:     ServerConfig{Port: 8080},
+		Platforms: PlatformRegistry{
+			AirborneTypes: PlatformTypeDefinitions{"b737": {Class: "Boeing 737"}},
+			Scenarios:     map[string]ScenarioConfig{"test": {Name: "Test", Instances: instances}},
+		},
+	}
+	if err := validateConfig(cfg); err != nil {
+		t.Fatalf("Expected valid transponder settings, got %v", err)
+	}
+
+	instances[0].Squawk = "1289"
+	if err := validateConfig(cfg); err == nil {
+		t.Error("Expected a non-octal squawk to be rejected")
+	}
+	instances[0].Squawk = ""
+	instances[0].ICAOAddress = "XYZ"
+	if err := validateConfig(cfg); err == nil {
+		t.Error("Expected an invalid ICAO address to be rejected")
+	}
+	instances[0].ICAOAddress = ""
+	instances[0].MMSI = "12345"
+	if err := validateConfig(cfg); err == nil {
+		t.Error("Expected an invalid MMSI to be rejected")
+	}
+}
+
+func TestSinkConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  SinkConfig
+		wantErr bool
+	}{
+		{"cot stream", SinkConfig{Format: "cot", Endpoint: "ssl://tak.example.com:8089", Rate: "2s", CoT: CoTConfig{Encoding: "stream"}}, false},
+		{"filtered dis", SinkConfig{Format: "dis", Endpoint: "udp://239.1.2.3:3000", Filter: SinkFilter{Types: []string{"airborne"}, Affiliations: []string{"hostile"}}}, false},
+		{"area", SinkConfig{Format: "ais", Endpoint: "tcp://127.0.0.1:10110", Filter: SinkFilter{Area: &BoundingBox{North: 40, South: 35, East: -70, West: -80}}}, false},
+		{"policy", SinkConfig{Format: "cot", Endpoint: "udp://239.2.3.1:6969", Policy: SinkPolicy{PositionThreshold: 50, HeadingThreshold: 15, Heartbeat: "2m"}}, false},
+		{"no endpoint", SinkConfig{Format: "cot"}, true},
+		{"unknown format", SinkConfig{Format: "nmea", Endpoint: "udp://127.0.0.1:10110"}, true},
+		{"sbs over udp", SinkConfig{Format: "sbs", Endpoint: "udp://127.0.0.1:30003"}, true},
+		{"mesh over tcp", SinkConfig{Format: "cot", Endpoint: "tcp://127.0.0.1:8087", CoT: CoTConfig{Encoding: "mesh"}}, true},
+		{"bad dis site", SinkConfig{Format: "dis", Endpoint: "udp://127.0.0.1:3000", DIS: DISConfig{SiteID: 70000}}, true},
+		{"bad rate", SinkConfig{Format: "gdl90", Endpoint: "udp://127.0.0.1:4000", Rate: "0s"}, true},
+		{"unknown type", SinkConfig{Format: "cot", Endpoint: "udp://239.2.3.1:6969", Filter: SinkFilter{Types: []string{"subsurface"}}}, true},
+		{"unknown affiliation", SinkConfig{Format: "cot", Endpoint: "udp://239.2.3.1:6969", Filter: SinkFilter{Affiliations: []string{"ally"}}}, true},
+		{"inverted area", SinkConfig{Format: "cot", Endpoint: "udp://239.2.3.1:6969", Filter: SinkFilter{Area: &BoundingBox{North: 35, South: 40}}}, true},
+		{"negative threshold", SinkConfig{Format: "cot", Endpoint: "udp://239.2.3.1:6969", Policy: SinkPolicy{SpeedThreshold: -1}}, true},
+		{"bad heartbeat", SinkConfig{Format: "cot", Endpoint: "udp://239.2.3.1:6969", Policy: SinkPolicy{PositionThreshold: 50, Heartbeat: "0s"}}, true},
+		{"heartbeat alone", SinkConfig{Format: "cot", Endpoint: "udp://239.2.3.1:6969", Policy: SinkPolicy{Heartbeat: "1m"}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.config.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestOutputConfigSinkConfigs(t *testing.T) {
+	out := OutputConfig{
+		Sinks:   []SinkConfig{{Name: "tak", Format: "cot", Endpoint: "tcp://127.0.0.1:8087"}},
+		ADSB:    ADSBConfig{GDL90Endpoint: "udp://127.0.0.1:4000"},
+		ASTERIX: ASTERIXConfig{Endpoint: "udp://127.0.0.1:8600", SAC: 25, UpdatePeriod: "5s"},
+	}
+	sinks := out.SinkConfigs()
+	if len(sinks) != 3 || sinks[0].Name != "tak" || sinks[1].Format != SinkFormatGDL90 {
+		t.Fatalf("Unexpected sinks %+v", sinks)
+	}
+	if asterix := sinks[2]; asterix.Rate != "5s" || asterix.ASTERIX.SAC != 25 {
+		t.Errorf("Expected the asterix section's period and SAC, got %+v", asterix)
+	}
+	if err := out.validateSinks(); err != nil {
+		t.Errorf("validateSinks() = %v", err)
+	}
+
+	out.Sinks = append(out.Sinks, SinkConfig{Name: "gdl90", Format: "gdl90", Endpoint: "udp://127.0.0.1:4001"})
+	if err := out.validateSinks(); err == nil {
+		t.Error("Expected a sink named like the adsb section's to be a duplicate")
+	}
+}
@@ -0,0 +1,113 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/rhino11/trafficsim/internal/models"
+)
+
+// WalkerConstellation is a Walker-delta shell i:t/p/f of circular orbits: t satellites spread
+// evenly over p planes whose nodes are spaced evenly around the equator, with neighbouring planes
+// phased by f×360/t degrees
+type WalkerConstellation struct {
+	Satellites     int     `yaml:"satellites" json:"satellites"`                               // t, a multiple of planes
+	Planes         int     `yaml:"planes" json:"planes"`                                       // p
+	Phasing        int     `yaml:"phasing" json:"phasing"`                                     // f, 0 to planes-1
+	Inclination    float64 `yaml:"inclination" json:"inclination"`                             // degrees
+	Altitude       float64 `yaml:"altitude" json:"altitude"`                                   // meters above the equator
+	RAANOffset     float64 `yaml:"raan_offset,omitempty" json:"raan_offset,omitempty"`         // Node of the first plane, degrees
+	Epoch          string  `yaml:"epoch,omitempty" json:"epoch,omitempty"`                     // RFC 3339, default scenario start
+	CallSignFormat string  `yaml:"callsign_format,omitempty" json:"callsign_format,omitempty"` // e.g. "STARLINK-{plane}-{slot}"
+}
+
+// Validate checks the pattern and orbit
+func (w *WalkerConstellation) Validate() error {
+	if w.Satellites <= 0 || w.Planes <= 0 {
+		return fmt.Errorf("constellation.satellites and constellation.planes must be positive")
+	}
+	if w.Satellites%w.Planes != 0 {
+		return fmt.Errorf("constellation.satellites (%d) must be a multiple of constellation.planes (%d)", w.Satellites, w.Planes)
+	}
+	if w.Phasing < 0 || w.Phasing >= w.Planes {
+		return fmt.Errorf("constellation.phasing must be between 0 and %d", w.Planes-1)
+	}
+	if w.Altitude <= 0 {
+		return fmt.Errorf("constellation.altitude must be positive")
+	}
+	orbit := OrbitalElements{Inclination: w.Inclination, Epoch: w.Epoch}
+	if err := orbit.Validate(); err != nil {
+		return fmt.Errorf("constellation: %w", err)
+	}
+	return nil
+}
+
+// Orbits returns the orbital elements of every satellite, plane by plane
+func (w *WalkerConstellation) Orbits() []OrbitalElements {
+	perPlane := w.Satellites / w.Planes
+	orbits := make([]OrbitalElements, 0, w.Satellites)
+	for plane := 0; plane < w.Planes; plane++ {
+		for slot := 0; slot < perPlane; slot++ {
+			meanAnomaly := float64(slot)*360/float64(perPlane) + float64(plane*w.Phasing)*360/float64(w.Satellites)
+			orbits = append(orbits, OrbitalElements{
+				SemiMajorAxis:            models.WGS84SemiMajorAxis + w.Altitude,
+				Inclination:              w.Inclination,
+				LongitudeOfAscendingNode: normalizeDegrees(w.RAANOffset + float64(plane)*360/float64(w.Planes)),
+				MeanAnomaly:              normalizeDegrees(meanAnomaly),
+				Epoch:                    w.Epoch,
+			})
+		}
+	}
+	return orbits
+}
+
+// normalizeDegrees wraps an angle into [0, 360)
+func normalizeDegrees(angle float64) float64 {
+	for angle >= 360 {
+		angle -= 360
+	}
+	for angle < 0 {
+		angle += 360
+	}
+	return angle
+}
+
+// expandConstellation replaces an instance with a constellation by one instance per satellite,
+// with the ID <id>-<plane>-<slot> counting both from 1. An instance callsign is used the same way,
+// as <callsign>-<plane>-<slot>. Otherwise callsigns come from the constellation's callsign format,
+// or else the type's, with {plane}, {slot} and {number} (1 to satellites) set, and are the ID when
+// the format is empty or has placeholders a satellite cannot fill.
+func expandConstellation(instance PlatformInstance, callsigns models.CallsignConfiguration) ([]PlatformInstance, error) {
+	walker := instance.Constellation
+	if err := walker.Validate(); err != nil {
+		return nil, err
+	}
+	if walker.CallSignFormat != "" {
+		callsigns.Format = walker.CallSignFormat
+	}
+
+	perPlane := walker.Satellites / walker.Planes
+	expanded := make([]PlatformInstance, 0, walker.Satellites)
+	for i, orbit := range walker.Orbits() {
+		plane, slot := i/perPlane+1, i%perPlane+1
+		satellite := instance
+		satellite.Constellation = nil
+		satellite.Orbit = &orbit
+		satellite.ID = fmt.Sprintf("%s-%d-%d", instance.ID, plane, slot)
+		if instance.CallSign != "" {
+			satellite.CallSign = fmt.Sprintf("%s-%d-%d", instance.CallSign, plane, slot)
+		} else if callsign, ok := callsigns.Generate(nil, map[string]string{
+			"id":     satellite.ID,
+			"plane":  strconv.Itoa(plane),
+			"slot":   strconv.Itoa(slot),
+			"number": strconv.Itoa(i + 1),
+		}); ok {
+			satellite.CallSign = callsign
+		} else {
+			satellite.CallSign = satellite.ID
+		}
+		satellite.Name = satellite.CallSign
+		expanded = append(expanded, satellite)
+	}
+	return expanded, nil
+}
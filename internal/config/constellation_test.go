@@ -0,0 +1,165 @@
+package config
+
+import (
+	"math"
+	"testing"
+
+	"github.com/rhino11/trafficsim/internal/models"
+)
+
+func TestWalkerConstellation_Validate(t *testing.T) {
+	valid := WalkerConstellation{Satellites: 24, Planes: 3, Phasing: 1, Inclination: 56, Altitude: 23222e3}
+	if err := valid.Validate(); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		modify func(*WalkerConstellation)
+	}{
+		{"no satellites", func(w *WalkerConstellation) { w.Satellites = 0 }},
+		{"no planes", func(w *WalkerConstellation) { w.Planes = 0 }},
+		{"uneven planes", func(w *WalkerConstellation) { w.Satellites = 25 }},
+		{"phasing", func(w *WalkerConstellation) { w.Phasing = 3 }},
+		{"altitude", func(w *WalkerConstellation) { w.Altitude = 0 }},
+		{"inclination", func(w *WalkerConstellation) { w.Inclination = 200 }},
+		{"epoch", func(w *WalkerConstellation) { w.Epoch = "tomorrow" }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			walker := valid
+			tt.modify(&walker)
+			if err := walker.Validate(); err == nil {
+				t.Error("Expected the constellation to be rejected")
+			}
+		})
+	}
+}
+
+func TestWalkerConstellation_Orbits(t *testing.T) {
+	// Galileo: 56:24/3/1
+	walker := WalkerConstellation{Satellites: 24, Planes: 3, Phasing: 1, Inclination: 56, Altitude: 23222e3, RAANOffset: 10}
+	orbits := walker.Orbits()
+	if len(orbits) != 24 {
+		t.Fatalf("Expected 24 orbits, got %d", len(orbits))
+	}
+
+	tests := []struct {
+		index             int
+		raan, meanAnomaly float64
+	}{
+		{0, 10, 0},
+		{1, 10, 45},
+		{8, 130, 15}, // Second plane, phased by 360/24
+		{17, 250, 75},
+	}
+	for _, tt := range tests {
+		orbit := orbits[tt.index]
+		if math.Abs(orbit.LongitudeOfAscendingNode-tt.raan) > 1e-9 || math.Abs(orbit.MeanAnomaly-tt.meanAnomaly) > 1e-9 {
+			t.Errorf("Orbit %d: node %v, mean anomaly %v, want %v and %v",
+				tt.index, orbit.LongitudeOfAscendingNode, orbit.MeanAnomaly, tt.raan, tt.meanAnomaly)
+		}
+		if orbit.SemiMajorAxis != models.WGS84SemiMajorAxis+23222e3 || orbit.Inclination != 56 {
+			t.Errorf("Orbit %d: unexpected size or inclination %+v", tt.index, orbit)
+		}
+	}
+}
+
+func TestPlatformFactory_Constellation(t *testing.T) {
+	registry := createTestRegistry()
+	satellite := registry.SpaceTypes["satellite"]
+	satellite.CallSignPrefix = "SAT"
+	satellite.CallSignFormat = "{prefix}-{number:03d}"
+	registry.SpaceTypes["satellite"] = satellite
+	registry.Scenarios["shell"] = ScenarioConfig{
+		Name: "shell",
+		Instances: []PlatformInstance{
+			{ID: "SHELL", TypeID: "satellite", Constellation: &WalkerConstellation{
+				Satellites: 12, Planes: 4, Phasing: 2, Inclination: 60, Altitude: 1200e3,
+			}},
+			{ID: "POLAR", TypeID: "satellite", Constellation: &WalkerConstellation{
+				Satellites: 4, Planes: 2, Inclination: 90, Altitude: 800e3, CallSignFormat: "POLAR-{plane}-{slot}",
+			}},
+		},
+	}
+	factory := NewPlatformFactory(registry)
+
+	platforms, err := factory.CreateScenario("shell")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(platforms) != 16 {
+		t.Fatalf("Expected 16 satellites, got %d", len(platforms))
+	}
+
+	// The type's callsign format numbers the satellites in order
+	first := platforms[0].(*models.UniversalPlatform)
+	if first.ID != "SHELL-1-1" || first.CallSign != "SAT-001" || first.Config.Name != "SAT-001" {
+		t.Errorf("Unexpected first satellite %s %s %s", first.ID, first.CallSign, first.Config.Name)
+	}
+	if last := platforms[11].(*models.UniversalPlatform); last.ID != "SHELL-4-3" || last.CallSign != "SAT-012" {
+		t.Errorf("Unexpected last satellite %s %s", last.ID, last.CallSign)
+	}
+
+	// The constellation's own format takes precedence
+	polar := platforms[15].(*models.UniversalPlatform)
+	if polar.ID != "POLAR-2-2" || polar.CallSign != "POLAR-2-2" {
+		t.Errorf("Unexpected polar satellite %s %s", polar.ID, polar.CallSign)
+	}
+	orbit, ok := polar.Orbit.(*models.KeplerOrbit)
+	if !ok {
+		t.Fatalf("Expected a Keplerian orbit, got %T", polar.Orbit)
+	}
+	if elements := orbit.Elements(); elements.RAAN != 180 || elements.MeanAnomaly != 180 || elements.Inclination != 90 {
+		t.Errorf("Unexpected polar elements %+v", elements)
+	}
+
+	// An instance callsign numbers the satellites like their IDs, and formats a satellite cannot
+	// fill fall back to the ID
+	registry.Scenarios["named"] = ScenarioConfig{Instances: []PlatformInstance{
+		{ID: "NAMED", TypeID: "satellite", CallSign: "BIRD", Constellation: &WalkerConstellation{
+			Satellites: 2, Planes: 1, Altitude: 800e3,
+		}},
+		{ID: "AIRLINE", TypeID: "satellite", Constellation: &WalkerConstellation{
+			Satellites: 2, Planes: 1, Altitude: 800e3, CallSignFormat: "{airline}{slot}",
+		}},
+	}}
+	named, err := factory.CreateScenario("named")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, want := range []string{"BIRD-1-1", "BIRD-1-2", "AIRLINE-1-1", "AIRLINE-1-2"} {
+		if callsign := named[i].(*models.UniversalPlatform).CallSign; callsign != want {
+			t.Errorf("Satellite %d callsign = %s, want %s", i, callsign, want)
+		}
+	}
+
+	// A constellation is created per satellite, never as a single platform
+	if _, err := factory.CreatePlatform(registry.Scenarios["shell"].Instances[0]); err == nil {
+		t.Error("Expected a single platform for a constellation to be rejected")
+	}
+
+	registry.Scenarios["aircraft"] = ScenarioConfig{Instances: []PlatformInstance{
+		{ID: "JETS", TypeID: "f16_fighter", Constellation: &WalkerConstellation{Satellites: 2, Planes: 1, Altitude: 1000}},
+	}}
+	if _, err := factory.CreateScenario("aircraft"); err == nil {
+		t.Error("Expected a constellation of aircraft to be rejected")
+	}
+}
+
+func TestScenarioLoader_Constellation(t *testing.T) {
+	scenario, err := NewScenarioLoader("../../data/platforms").Load("../../data/configs/starlink_shell.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(scenario.Entries) != 1584 {
+		t.Fatalf("Expected 1584 satellites, got %d", len(scenario.Entries))
+	}
+	entry := scenario.Entries[23]
+	if entry.Instance.ID != "STARLINK-2-2" || entry.Instance.CallSign != "STARLINK-2-2" || entry.Domain != PlatformTypeSpace {
+		t.Errorf("Unexpected entry %+v", entry.Instance)
+	}
+	if orbit := entry.Instance.Orbit; orbit == nil || orbit.LongitudeOfAscendingNode != 5 {
+		t.Errorf("Expected the second plane 5° east of the first, got %+v", orbit)
+	}
+}
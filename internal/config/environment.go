@@ -0,0 +1,121 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+)
+
+// EnvironmentConfig describes the wind and water current a scenario's platforms move through
+type EnvironmentConfig struct {
+	Wind    *FlowConfig `yaml:"wind,omitempty" json:"wind,omitempty"`       // Direction the wind blows from
+	Current *FlowConfig `yaml:"current,omitempty" json:"current,omitempty"` // Direction the current sets toward
+}
+
+// FlowConfig is a wind or current field: uniform, gridded, or in altitude layers of either,
+// optionally varying over time
+type FlowConfig struct {
+	Speed     float64        `yaml:"speed,omitempty" json:"speed,omitempty"`         // m/s
+	Direction float64        `yaml:"direction,omitempty" json:"direction,omitempty"` // degrees
+	Grid      *FlowGrid      `yaml:"grid,omitempty" json:"grid,omitempty"`
+	Layers    []FlowLayer    `yaml:"layers,omitempty" json:"layers,omitempty"` // Interpolated by altitude
+	Variation *FlowVariation `yaml:"variation,omitempty" json:"variation,omitempty"`
+}
+
+// FlowLayer is the field at one altitude
+type FlowLayer struct {
+	Altitude  float64   `yaml:"altitude" json:"altitude"` // meters
+	Speed     float64   `yaml:"speed,omitempty" json:"speed,omitempty"`
+	Direction float64   `yaml:"direction,omitempty" json:"direction,omitempty"`
+	Grid      *FlowGrid `yaml:"grid,omitempty" json:"grid,omitempty"`
+}
+
+// FlowGrid samples a field at evenly spaced points across an area, interpolated between them and
+// held at the edge values outside it. Rows run from north to south and columns from west to east.
+type FlowGrid struct {
+	Area       BoundingBox `yaml:"area" json:"area"`
+	Speeds     [][]float64 `yaml:"speeds" json:"speeds"`         // m/s
+	Directions [][]float64 `yaml:"directions" json:"directions"` // degrees
+}
+
+// FlowVariation swings the field's speed and direction sinusoidally over time
+type FlowVariation struct {
+	Period    float64 `yaml:"period" json:"period"`                           // seconds
+	Speed     float64 `yaml:"speed,omitempty" json:"speed,omitempty"`         // m/s either side of the field speed
+	Direction float64 `yaml:"direction,omitempty" json:"direction,omitempty"` // degrees either side of the field direction
+}
+
+// Validate checks the wind and current fields
+func (e *EnvironmentConfig) Validate() error {
+	if e.Wind != nil {
+		if err := e.Wind.Validate(); err != nil {
+			return fmt.Errorf("environment.wind: %w", err)
+		}
+	}
+	if e.Current != nil {
+		if err := e.Current.Validate(); err != nil {
+			return fmt.Errorf("environment.current: %w", err)
+		}
+	}
+	return nil
+}
+
+// Validate checks the field's layers, grids and variation
+func (f *FlowConfig) Validate() error {
+	if len(f.Layers) > 0 {
+		if f.Speed != 0 || f.Grid != nil {
+			return fmt.Errorf("speed and grid cannot be combined with layers")
+		}
+		if !sort.SliceIsSorted(f.Layers, func(i, j int) bool { return f.Layers[i].Altitude < f.Layers[j].Altitude }) {
+			return fmt.Errorf("layers must be in order of altitude")
+		}
+		for i := range f.Layers {
+			layer := FlowConfig{Speed: f.Layers[i].Speed, Grid: f.Layers[i].Grid}
+			if err := layer.Validate(); err != nil {
+				return fmt.Errorf("layer %d: %w", i, err)
+			}
+			if i > 0 && f.Layers[i].Altitude == f.Layers[i-1].Altitude {
+				return fmt.Errorf("layer %d: duplicate altitude %v", i, f.Layers[i].Altitude)
+			}
+		}
+	}
+	if f.Speed < 0 {
+		return fmt.Errorf("speed cannot be negative")
+	}
+	if f.Grid != nil {
+		if f.Speed != 0 {
+			return fmt.Errorf("speed and grid cannot both be set")
+		}
+		if err := f.Grid.Validate(); err != nil {
+			return err
+		}
+	}
+	if f.Variation != nil && f.Variation.Period <= 0 {
+		return fmt.Errorf("variation.period must be positive")
+	}
+	return nil
+}
+
+// Validate checks that the grid covers an area with matching speed and direction samples
+func (g *FlowGrid) Validate() error {
+	if g.Area.North < g.Area.South || g.Area.East < g.Area.West {
+		return fmt.Errorf("grid.area north and east must not be below south and west")
+	}
+	if len(g.Speeds) == 0 || len(g.Speeds[0]) == 0 {
+		return fmt.Errorf("grid.speeds must have at least one row and column")
+	}
+	if len(g.Directions) != len(g.Speeds) {
+		return fmt.Errorf("grid.directions has %d rows, grid.speeds has %d", len(g.Directions), len(g.Speeds))
+	}
+	columns := len(g.Speeds[0])
+	for row := range g.Speeds {
+		if len(g.Speeds[row]) != columns || len(g.Directions[row]) != columns {
+			return fmt.Errorf("grid row %d must have %d speeds and directions", row, columns)
+		}
+		for _, speed := range g.Speeds[row] {
+			if speed < 0 {
+				return fmt.Errorf("grid row %d: speed cannot be negative", row)
+			}
+		}
+	}
+	return nil
+}
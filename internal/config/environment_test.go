@@ -0,0 +1,51 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEnvironmentConfig_Validate(t *testing.T) {
+	grid := func() *FlowGrid {
+		return &FlowGrid{
+			Area:       BoundingBox{North: 37, South: 36, East: -75, West: -76},
+			Speeds:     [][]float64{{1, 2}, {3, 4}},
+			Directions: [][]float64{{90, 90}, {180, 180}},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		flow    FlowConfig
+		wantErr string
+	}{
+		{"uniform", FlowConfig{Speed: 10, Direction: 270}, ""},
+		{"grid", FlowConfig{Grid: grid()}, ""},
+		{"layers", FlowConfig{Layers: []FlowLayer{{Altitude: 0, Speed: 5}, {Altitude: 10000, Grid: grid()}}}, ""},
+		{"variation", FlowConfig{Speed: 10, Variation: &FlowVariation{Period: 600, Speed: 2}}, ""},
+		{"negative speed", FlowConfig{Speed: -1}, "negative"},
+		{"speed and grid", FlowConfig{Speed: 1, Grid: grid()}, "both"},
+		{"speed and layers", FlowConfig{Speed: 1, Layers: []FlowLayer{{Altitude: 0}}}, "layers"},
+		{"unordered layers", FlowConfig{Layers: []FlowLayer{{Altitude: 1000}, {Altitude: 0}}}, "order"},
+		{"duplicate layers", FlowConfig{Layers: []FlowLayer{{Altitude: 0}, {Altitude: 0}}}, "duplicate"},
+		{"variation period", FlowConfig{Speed: 10, Variation: &FlowVariation{Speed: 2}}, "period"},
+		{"grid rows", FlowConfig{Grid: &FlowGrid{Speeds: [][]float64{{1}}, Directions: [][]float64{{1}, {2}}}}, "rows"},
+		{"ragged grid", FlowConfig{Grid: &FlowGrid{Speeds: [][]float64{{1, 2}, {3}}, Directions: [][]float64{{1, 2}, {3}}}}, "row 1"},
+		{"empty grid", FlowConfig{Grid: &FlowGrid{}}, "at least one"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			environment := EnvironmentConfig{Wind: &tt.flow}
+			err := environment.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Unexpected error %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate() error = %v, want one mentioning %q", err, tt.wantErr)
+			}
+		})
+	}
+}
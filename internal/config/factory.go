@@ -3,7 +3,7 @@ package config
 import (
 	"fmt"
 	"math"
-	"strings"
+	"math/rand"
 	"time"
 
 	"github.com/rhino11/trafficsim/internal/models"
@@ -21,12 +21,21 @@ const (
 // PlatformFactory creates platform instances from configuration data
 type PlatformFactory struct {
 	registry *PlatformRegistry
+	rng      *rand.Rand // Source for generated call signs
 }
 
-// NewPlatformFactory creates a new platform factory
+// NewPlatformFactory creates a new platform factory with a time-seeded random source
 func NewPlatformFactory(registry *PlatformRegistry) *PlatformFactory {
 	return &PlatformFactory{
 		registry: registry,
+		rng:      rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// SetRand sets the random source used for generated call signs, e.g. a seeded engine source
+func (f *PlatformFactory) SetRand(rng *rand.Rand) {
+	if rng != nil {
+		f.rng = rng
 	}
 }
 
@@ -38,31 +47,47 @@ func (f *PlatformFactory) CreatePlatform(instance PlatformInstance) (models.Plat
 		return nil, fmt.Errorf("failed to get platform type %s: %w", instance.TypeID, err)
 	}
 
-	// Convert config position to models position
-	startPos := models.Position{
-		Latitude:  instance.StartPos.Latitude,
-		Longitude: instance.StartPos.Longitude,
-		Altitude:  instance.StartPos.Altitude,
+	// Generate call sign if not provided
+	if instance.CallSign == "" {
+		instance.CallSign = f.generateCallSign(typeDef, instance.ID)
 	}
 
-	// Generate call sign if not provided
+	// Convert PlatformTypeDefinition to models.PlatformTypeDefinition
+	modelTypeDef := f.convertToModelTypeDefinition(typeDef)
+
+	return f.CreatePlatformFromDefinition(instance, typeDef.Type, modelTypeDef)
+}
+
+// CreatePlatformFromDefinition creates a universal platform from a full models type definition,
+// as loaded from the distributed files in data/platforms/
+func (f *PlatformFactory) CreatePlatformFromDefinition(instance PlatformInstance, domain string, modelTypeDef *models.PlatformTypeDefinition) (models.Platform, error) {
+	if modelTypeDef == nil {
+		return nil, fmt.Errorf("no type definition for platform %s", instance.ID)
+	}
+
+	// Convert config position to models position
+	startPos := toModelPosition(instance.StartPos)
+
+	// Fall back to the instance ID when no call sign is provided
 	callSign := instance.CallSign
 	if callSign == "" {
-		callSign = f.generateCallSign(typeDef, instance.ID)
+		callSign = instance.ID
 	}
 
 	// Determine platform type from configuration
-	platformType := f.determinePlatformType(typeDef.Type)
-
-	// Convert PlatformTypeDefinition to models.PlatformTypeDefinition
-	modelTypeDef := f.convertToModelTypeDefinition(typeDef)
+	platformType := f.determinePlatformType(domain)
 
 	// Create platform configuration
 	platformConfig := &models.PlatformConfiguration{
 		ID:            instance.ID,
-		Type:          typeDef.Type,
+		Type:          domain,
 		Name:          instance.Name,
 		StartPosition: startPos,
+		Affiliation:   instance.Affiliation,
+		ICAOAddress:   instance.ICAOAddress,
+		Squawk:        instance.Squawk,
+		MMSI:          instance.MMSI,
+		AISDark:       instance.AISDark,
 		Mission: models.MissionConfiguration{
 			Type:       "standard",
 			Parameters: make(map[string]interface{}),
@@ -88,20 +113,81 @@ func (f *PlatformFactory) CreatePlatform(instance PlatformInstance) (models.Plat
 		MissionTime:   0,
 	}
 
+	switch {
+	case instance.TLE != nil:
+		if err := attachTLEOrbit(platform, instance); err != nil {
+			return nil, fmt.Errorf("platform %s: %w", instance.ID, err)
+		}
+	case instance.Orbit != nil:
+		if err := attachKeplerOrbit(platform, instance); err != nil {
+			return nil, fmt.Errorf("platform %s: %w", instance.ID, err)
+		}
+	case instance.Constellation != nil:
+		return nil, fmt.Errorf("platform %s: a constellation is one platform per satellite; create it with CreateScenario", instance.ID)
+	}
+
 	return platform, nil
 }
 
 // CreateScenario creates all platforms for a given scenario - only loads specified platforms
 func (f *PlatformFactory) CreateScenario(scenarioName string) ([]models.Platform, error) {
+	instances, err := f.ScenarioInstances(scenarioName)
+	if err != nil {
+		return nil, err
+	}
+	return f.CreateInstances(instances)
+}
+
+// ScenarioInstances returns the platform instances of a scenario, with every TLE source and
+// constellation expanded to one instance per satellite
+func (f *PlatformFactory) ScenarioInstances(scenarioName string) ([]PlatformInstance, error) {
 	scenario, exists := f.registry.Scenarios[scenarioName]
 	if !exists {
 		return nil, fmt.Errorf("scenario not found: %s", scenarioName)
 	}
+	instances, err := expandTLEInstances(scenario.Instances)
+	if err != nil {
+		return nil, fmt.Errorf("scenario %s: %w", scenarioName, err)
+	}
+	instances, err = f.expandConstellations(instances)
+	if err != nil {
+		return nil, fmt.Errorf("scenario %s: %w", scenarioName, err)
+	}
+	return instances, nil
+}
 
+// expandConstellations replaces every instance with a constellation by its satellites, named with
+// the callsign format of the instance's type
+func (f *PlatformFactory) expandConstellations(instances []PlatformInstance) ([]PlatformInstance, error) {
+	expanded := make([]PlatformInstance, 0, len(instances))
+	for _, instance := range instances {
+		if instance.Constellation == nil {
+			expanded = append(expanded, instance)
+			continue
+		}
+		typeDef, err := f.registry.GetType(instance.TypeID)
+		if err != nil {
+			return nil, fmt.Errorf("instance %s: %w", instance.ID, err)
+		}
+		if err := instance.validateOrbit(typeDef.Type); err != nil {
+			return nil, fmt.Errorf("instance %s: %w", instance.ID, err)
+		}
+		callsigns := models.CallsignConfiguration{Prefix: typeDef.CallSignPrefix, Format: typeDef.CallSignFormat}
+		satellites, err := expandConstellation(instance, callsigns)
+		if err != nil {
+			return nil, fmt.Errorf("instance %s: %w", instance.ID, err)
+		}
+		expanded = append(expanded, satellites...)
+	}
+	return expanded, nil
+}
+
+// CreateInstances creates a platform for each instance, with its route or destination
+func (f *PlatformFactory) CreateInstances(instances []PlatformInstance) ([]models.Platform, error) {
 	var platforms []models.Platform
 
 	// Only create platforms that are explicitly defined in the scenario
-	for _, instance := range scenario.Instances {
+	for _, instance := range instances {
 		// Verify the platform type exists in configuration
 		if !f.registry.HasType(instance.TypeID) {
 			return nil, fmt.Errorf("platform type %s not found in configuration for instance %s",
@@ -113,16 +199,8 @@ func (f *PlatformFactory) CreateScenario(scenarioName string) ([]models.Platform
 			return nil, fmt.Errorf("failed to create platform %s: %w", instance.ID, err)
 		}
 
-		// Set destination if specified
-		if instance.Destination != nil {
-			dest := models.Position{
-				Latitude:  instance.Destination.Latitude,
-				Longitude: instance.Destination.Longitude,
-				Altitude:  instance.Destination.Altitude,
-			}
-			if err := platform.SetDestination(dest); err != nil {
-				return nil, fmt.Errorf("failed to set destination for %s: %w", instance.ID, err)
-			}
+		if err := f.configureNavigation(platform, instance); err != nil {
+			return nil, err
 		}
 
 		platforms = append(platforms, platform)
@@ -131,6 +209,52 @@ func (f *PlatformFactory) CreateScenario(scenarioName string) ([]models.Platform
 	return platforms, nil
 }
 
+// configureNavigation sets the instance route if specified, otherwise a single destination
+func (f *PlatformFactory) configureNavigation(platform models.Platform, instance PlatformInstance) error {
+	if len(instance.Route) > 0 {
+		if err := f.applyRoute(platform, instance); err != nil {
+			return fmt.Errorf("failed to set route for %s: %w", instance.ID, err)
+		}
+	} else if instance.Destination != nil {
+		if err := platform.SetDestination(toModelPosition(*instance.Destination)); err != nil {
+			return fmt.Errorf("failed to set destination for %s: %w", instance.ID, err)
+		}
+	}
+	return nil
+}
+
+// applyRoute assigns the instance route to the platform; a destination becomes the final waypoint
+func (f *PlatformFactory) applyRoute(platform models.Platform, instance PlatformInstance) error {
+	universalPlatform, ok := platform.(*models.UniversalPlatform)
+	if !ok {
+		return fmt.Errorf("platform %s does not support route following", instance.ID)
+	}
+
+	mode, err := models.ParseRouteMode(instance.RouteMode)
+	if err != nil {
+		return err
+	}
+
+	waypoints := make([]models.Position, 0, len(instance.Route)+1)
+	for _, waypoint := range instance.Route {
+		waypoints = append(waypoints, toModelPosition(waypoint))
+	}
+	if instance.Destination != nil {
+		waypoints = append(waypoints, toModelPosition(*instance.Destination))
+	}
+
+	return universalPlatform.SetRoute(waypoints, mode, instance.RouteSpeeds)
+}
+
+// toModelPosition converts a config position to a models position
+func toModelPosition(pos Position) models.Position {
+	return models.Position{
+		Latitude:  pos.Latitude,
+		Longitude: pos.Longitude,
+		Altitude:  pos.Altitude,
+	}
+}
+
 // GetAvailablePlatformTypes returns only the platform types that are actually configured
 func (f *PlatformFactory) GetAvailablePlatformTypes() map[string][]string {
 	available := make(map[string][]string)
@@ -190,13 +314,15 @@ func (f *PlatformFactory) ValidateScenario(scenarioName string) error {
 
 // generateCallSign generates a call sign based on the platform type definition
 func (f *PlatformFactory) generateCallSign(typeDef *PlatformTypeDefinition, instanceID string) string {
-	if typeDef.CallSignPrefix != "" {
-		if typeDef.CallSignFormat != "" {
-			// Use custom format
-			callSign := strings.ReplaceAll(typeDef.CallSignFormat, "{prefix}", typeDef.CallSignPrefix)
-			callSign = strings.ReplaceAll(callSign, "{id}", instanceID)
+	if typeDef.CallSignFormat != "" {
+		// Use custom format, drawing any {number} from the factory's random source
+		callsignConf := models.CallsignConfiguration{Prefix: typeDef.CallSignPrefix, Format: typeDef.CallSignFormat}
+		if callSign, ok := callsignConf.Generate(f.rng, map[string]string{"id": instanceID}); ok {
 			return callSign
 		}
+	}
+
+	if typeDef.CallSignPrefix != "" {
 		// Default format: prefix + last 3 chars of ID
 		suffix := instanceID
 		if len(instanceID) > 3 {
@@ -227,6 +353,13 @@ func (f *PlatformFactory) determinePlatformType(typeStr string) models.PlatformT
 
 // convertToModelTypeDefinition converts config type definition to models type definition
 func (f *PlatformFactory) convertToModelTypeDefinition(configDef *PlatformTypeDefinition) *models.PlatformTypeDefinition {
+	// Orbits are circular at the maximum altitude unless the type gives its apsides
+	orbitalAltitude, eccentricity := configDef.MaxAltitude, 0.0
+	if configDef.Apogee > 0 && configDef.Perigee > 0 {
+		semiMajorAxis, e := models.OrbitFromApsides(configDef.Apogee, configDef.Perigee)
+		orbitalAltitude, eccentricity = semiMajorAxis-models.WGS84SemiMajorAxis, e
+	}
+
 	return &models.PlatformTypeDefinition{
 		Class:    configDef.Class,
 		Category: configDef.Category,
@@ -243,9 +376,9 @@ func (f *PlatformFactory) convertToModelTypeDefinition(configDef *PlatformTypeDe
 			// Orbital characteristics
 			OrbitalVelocity: configDef.MaxSpeed, // Use max speed as orbital velocity for space platforms
 			OrbitalPeriod:   configDef.OrbitalPeriod,
-			OrbitalAltitude: configDef.MaxAltitude, // Use max altitude as orbital altitude
+			OrbitalAltitude: orbitalAltitude, // Mean altitude: semi-major axis above the equator
 			Inclination:     configDef.Inclination,
-			Eccentricity:    0.0, // Assume circular orbits for simplicity
+			Eccentricity:    eccentricity,
 		},
 		Physical: models.PhysicalCharacteristics{
 			Length:       configDef.Length,
@@ -262,6 +395,8 @@ func (f *PlatformFactory) convertToModelTypeDefinition(configDef *PlatformTypeDe
 			Prefix: configDef.CallSignPrefix,
 			Format: configDef.CallSignFormat,
 		},
+		CoTConf: configDef.CoTConfig,
+		DISConf: configDef.DISConfig,
 	}
 }
 
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"math/rand"
 	"testing"
 
 	"github.com/rhino11/trafficsim/internal/models"
@@ -188,6 +189,56 @@ func TestPlatformFactory_CreateScenario(t *testing.T) {
 	}
 }
 
+func TestPlatformFactory_CreateScenarioWithRoute(t *testing.T) {
+	registry := createTestRegistry()
+	registry.Scenarios["patrol"] = ScenarioConfig{
+		Name: "Patrol",
+		Instances: []PlatformInstance{
+			{
+				ID:       "test-carrier-1",
+				TypeID:   "aircraft_carrier",
+				StartPos: Position{Latitude: 35.0, Longitude: -120.0},
+				Route: []Position{
+					{Latitude: 35.1, Longitude: -120.0},
+					{Latitude: 35.1, Longitude: -120.1},
+				},
+				Destination: &Position{Latitude: 35.0, Longitude: -120.1},
+				RouteMode:   "reverse",
+				RouteSpeeds: []float64{5, 8, 10},
+			},
+		},
+	}
+
+	factory := NewPlatformFactory(registry)
+	platforms, err := factory.CreateScenario("patrol")
+	if err != nil {
+		t.Fatalf("Failed to create scenario: %v", err)
+	}
+
+	platform, ok := platforms[0].(*models.UniversalPlatform)
+	if !ok {
+		t.Fatalf("Expected UniversalPlatform, got %T", platforms[0])
+	}
+
+	if len(platform.Route) != 3 {
+		t.Fatalf("Expected destination appended as final waypoint, got %d waypoints", len(platform.Route))
+	}
+	if platform.RoutePlan == nil || platform.RoutePlan.Mode != models.RouteModeReverse {
+		t.Fatal("Expected reverse route plan")
+	}
+	if platform.Destination == nil || platform.Destination.Latitude != 35.1 {
+		t.Error("Expected destination to be the first waypoint")
+	}
+	if platform.CurrentLegSpeed() != 5 {
+		t.Errorf("Expected first leg speed 5, got %f", platform.CurrentLegSpeed())
+	}
+
+	registry.Scenarios["patrol"].Instances[0].RouteMode = "zigzag"
+	if _, err := factory.CreateScenario("patrol"); err == nil {
+		t.Error("Expected error for unknown route mode")
+	}
+}
+
 func TestPlatformFactory_GetAvailablePlatformTypes(t *testing.T) {
 	registry := &PlatformRegistry{
 		AirborneTypes: map[string]PlatformTypeDefinition{
@@ -331,6 +382,37 @@ func TestPlatformFactory_generateCallSign(t *testing.T) {
 	}
 }
 
+func TestPlatformFactory_generateCallSignSeeded(t *testing.T) {
+	typeDef := PlatformTypeDefinition{CallSignPrefix: "UAL", CallSignFormat: "{prefix}{number:04d}"}
+
+	generate := func(seed int64) []string {
+		factory := NewPlatformFactory(&PlatformRegistry{})
+		factory.SetRand(rand.New(rand.NewSource(seed)))
+		var callSigns []string
+		for _, id := range []string{"a", "b", "c"} {
+			callSigns = append(callSigns, factory.generateCallSign(&typeDef, id))
+		}
+		return callSigns
+	}
+
+	first, second := generate(42), generate(42)
+	for i := range first {
+		if first[i] != second[i] {
+			t.Errorf("Expected the same call signs for the same seed, got %v and %v", first, second)
+			break
+		}
+		if len(first[i]) != 7 || first[i][:3] != "UAL" {
+			t.Errorf("Expected UAL and four digits, got %q", first[i])
+		}
+	}
+
+	// Unresolvable placeholders fall back to the prefix format
+	typeDef.CallSignFormat = "{prefix}{mission}"
+	if callSign := generate(42)[0]; callSign != "UALa" {
+		t.Errorf("Expected fallback call sign UALa, got %q", callSign)
+	}
+}
+
 func TestPlatformFactory_determinePlatformType(t *testing.T) {
 	factory := &PlatformFactory{}
 
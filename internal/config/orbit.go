@@ -0,0 +1,117 @@
+package config
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/rhino11/trafficsim/internal/models"
+)
+
+// OrbitalElements are classical orbital elements for space routes and platforms. Zero size, shape
+// and inclination fall back to the platform type's orbital altitude or period, eccentricity and
+// inclination.
+type OrbitalElements struct {
+	SemiMajorAxis            float64 `yaml:"semi_major_axis" json:"semi_major_axis"` // meters
+	Eccentricity             float64 `yaml:"eccentricity" json:"eccentricity"`
+	Inclination              float64 `yaml:"inclination" json:"inclination"`                                 // degrees
+	LongitudeOfAscendingNode float64 `yaml:"longitude_of_ascending_node" json:"longitude_of_ascending_node"` // degrees
+	ArgumentOfPerigee        float64 `yaml:"argument_of_perigee" json:"argument_of_perigee"`                 // degrees
+	MeanAnomaly              float64 `yaml:"mean_anomaly" json:"mean_anomaly"`                               // degrees
+	Epoch                    string  `yaml:"epoch,omitempty" json:"epoch,omitempty"`                         // RFC 3339, default scenario start
+}
+
+// Validate checks the element ranges and epoch
+func (o *OrbitalElements) Validate() error {
+	if o.SemiMajorAxis < 0 {
+		return fmt.Errorf("orbital_elements.semi_major_axis cannot be negative")
+	}
+	if o.Eccentricity < 0 || o.Eccentricity >= 1 {
+		return fmt.Errorf("orbital_elements.eccentricity must be at least 0 and below 1")
+	}
+	if o.Inclination < 0 || o.Inclination > 180 {
+		return fmt.Errorf("orbital_elements.inclination must be between 0 and 180 degrees")
+	}
+	if _, err := o.parseEpoch(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// parseEpoch returns the epoch; the zero time when unset
+func (o *OrbitalElements) parseEpoch() (time.Time, error) {
+	if o.Epoch == "" {
+		return time.Time{}, nil
+	}
+	epoch, err := time.Parse(time.RFC3339, o.Epoch)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("orbital_elements.epoch must be RFC 3339, e.g. 2025-06-04T06:00:00Z: %w", err)
+	}
+	return epoch.UTC(), nil
+}
+
+// Keplerian returns the elements with any unset size, shape and inclination taken from the
+// platform type's orbit
+func (o *OrbitalElements) Keplerian(performance models.PerformanceCharacteristics) (models.KeplerianElements, error) {
+	if err := o.Validate(); err != nil {
+		return models.KeplerianElements{}, err
+	}
+	elements, sized := performance.OrbitElements(o.LongitudeOfAscendingNode, o.ArgumentOfPerigee, o.MeanAnomaly)
+	if o.SemiMajorAxis > 0 {
+		elements.SemiMajorAxis = o.SemiMajorAxis
+	} else if !sized {
+		return models.KeplerianElements{}, fmt.Errorf("orbital_elements.semi_major_axis is required without an orbital altitude or period")
+	}
+	if o.Eccentricity > 0 {
+		elements.Eccentricity = o.Eccentricity
+	}
+	if o.Inclination > 0 {
+		elements.Inclination = o.Inclination
+	}
+	elements.Epoch, _ = o.parseEpoch()
+	return elements, nil
+}
+
+// validateOrbit checks the instance's TLE source, orbital elements or constellation against its
+// domain
+func (p *PlatformInstance) validateOrbit(domain string) error {
+	sources := 0
+	for _, set := range []bool{p.TLE != nil, p.Orbit != nil, p.Constellation != nil} {
+		if set {
+			sources++
+		}
+	}
+	if sources == 0 {
+		return nil
+	}
+	if sources > 1 {
+		return fmt.Errorf("only one of tle, orbital_elements and constellation can be set")
+	}
+	if domain != PlatformTypeSpace {
+		return fmt.Errorf("orbits are only supported for space platforms, not %s", domain)
+	}
+	switch {
+	case p.TLE != nil:
+		return p.TLE.Validate()
+	case p.Constellation != nil:
+		return p.Constellation.Validate()
+	}
+	return p.Orbit.Validate()
+}
+
+// attachKeplerOrbit makes a space platform follow the instance's orbital elements. Elements without
+// an epoch hold from the time the simulation first places the platform, normally the scenario start.
+func attachKeplerOrbit(platform *models.UniversalPlatform, instance PlatformInstance) error {
+	if platform.PlatformType != models.PlatformTypeSpace {
+		return fmt.Errorf("orbits are only supported for space platforms, %s is %s", instance.ID, platform.PlatformType)
+	}
+	elements, err := instance.Orbit.Keplerian(platform.TypeDef.Performance)
+	if err != nil {
+		return err
+	}
+	orbit, err := models.NewKeplerOrbit(elements)
+	if err != nil {
+		return err
+	}
+	platform.Orbit = orbit
+	return nil
+}
@@ -0,0 +1,118 @@
+package config
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rhino11/trafficsim/internal/models"
+)
+
+func TestOrbitalElements_Validate(t *testing.T) {
+	tests := []struct {
+		name     string
+		elements OrbitalElements
+		wantErr  bool
+	}{
+		{"defaults", OrbitalElements{}, false},
+		{"with epoch", OrbitalElements{SemiMajorAxis: 7e6, Epoch: "2025-06-04T06:00:00Z"}, false},
+		{"negative semi-major axis", OrbitalElements{SemiMajorAxis: -1}, true},
+		{"parabolic", OrbitalElements{Eccentricity: 1}, true},
+		{"inclination", OrbitalElements{Inclination: 190}, true},
+		{"epoch", OrbitalElements{Epoch: "4 June 2025"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.elements.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestOrbitalElements_Keplerian(t *testing.T) {
+	performance := models.PerformanceCharacteristics{OrbitalAltitude: 550e3, Inclination: 53}
+
+	// Unset size and inclination come from the platform type
+	elements, err := (&OrbitalElements{LongitudeOfAscendingNode: 45, MeanAnomaly: 90}).Keplerian(performance)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if elements.SemiMajorAxis != models.WGS84SemiMajorAxis+550e3 || elements.Inclination != 53 ||
+		elements.RAAN != 45 || elements.MeanAnomaly != 90 || !elements.Epoch.IsZero() {
+		t.Errorf("Unexpected elements %+v", elements)
+	}
+
+	elements, err = (&OrbitalElements{SemiMajorAxis: 26560e3, Inclination: 55, Epoch: "2025-06-04T06:00:00Z"}).Keplerian(performance)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if elements.SemiMajorAxis != 26560e3 || elements.Inclination != 55 ||
+		!elements.Epoch.Equal(time.Date(2025, 6, 4, 6, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected the YAML elements to override the type, got %+v", elements)
+	}
+
+	if _, err := (&OrbitalElements{}).Keplerian(models.PerformanceCharacteristics{}); err == nil {
+		t.Error("Expected elements without a size to need one from the platform type")
+	}
+}
+
+func TestPlatformFactory_KeplerOrbit(t *testing.T) {
+	registry := createTestRegistry()
+	registry.SpaceTypes["molniya"] = PlatformTypeDefinition{
+		Class:       "Molniya",
+		Type:        "space",
+		MaxSpeed:    10000,
+		MaxAltitude: 39900e3,
+		Apogee:      39900e3,
+		Perigee:     500e3,
+		Inclination: 63.4,
+	}
+	factory := NewPlatformFactory(registry)
+
+	platform, err := factory.CreatePlatform(PlatformInstance{
+		ID:     "MOLNIYA-1",
+		TypeID: "molniya",
+		Orbit:  &OrbitalElements{ArgumentOfPerigee: 270},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	satellite := platform.(*models.UniversalPlatform)
+	orbit, ok := satellite.Orbit.(*models.KeplerOrbit)
+	if !ok {
+		t.Fatalf("Expected a Keplerian orbit, got %T", satellite.Orbit)
+	}
+
+	// The type's apsides give the orbit's shape
+	elements := orbit.Elements()
+	if math.Abs(elements.SemiMajorAxis*(1-elements.Eccentricity)-models.WGS84SemiMajorAxis-500e3) > 1 ||
+		elements.Inclination != 63.4 || elements.ArgPerigee != 270 {
+		t.Errorf("Unexpected elements %+v", elements)
+	}
+
+	_, err = factory.CreatePlatform(PlatformInstance{ID: "SHIP", TypeID: "f16_fighter", Orbit: &OrbitalElements{SemiMajorAxis: 7e6}})
+	if err == nil || !strings.Contains(err.Error(), "space") {
+		t.Errorf("Expected orbital elements on an aircraft to be rejected, got %v", err)
+	}
+
+	instance := PlatformInstance{ID: "SAT", TypeID: "satellite", Orbit: &OrbitalElements{SemiMajorAxis: 6000e3}}
+	if _, err := factory.CreatePlatform(instance); err == nil {
+		t.Error("Expected an orbit below the surface to be rejected")
+	}
+}
+
+func TestPlatformInstance_ValidateOrbit(t *testing.T) {
+	instance := PlatformInstance{ID: "SAT", TLE: &TLESource{File: testTLEFile}, Orbit: &OrbitalElements{}}
+	if err := instance.validateOrbit(PlatformTypeSpace); err == nil {
+		t.Error("Expected a TLE and orbital elements together to be rejected")
+	}
+	instance.TLE = nil
+	if err := instance.validateOrbit(PlatformTypeSpace); err != nil {
+		t.Errorf("Unexpected error %v", err)
+	}
+	if err := instance.validateOrbit(PlatformTypeMaritime); err == nil {
+		t.Error("Expected orbital elements on a ship to be rejected")
+	}
+}
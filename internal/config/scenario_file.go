@@ -0,0 +1,650 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/rhino11/trafficsim/internal/models"
+)
+
+// DefaultPlatformsDir is the directory scenario source_file paths are resolved against
+const DefaultPlatformsDir = "data/platforms"
+
+// ScenarioFile is a self-contained scenario in the data/configs format
+type ScenarioFile struct {
+	Metadata       ScenarioMetadata      `yaml:"metadata" json:"metadata"`
+	ScenarioConfig ScenarioSettings      `yaml:"scenario_config,omitempty" json:"scenario_config,omitempty"`
+	Platforms      []ScenarioPlatform    `yaml:"platforms" json:"platforms"`
+	Routes         map[string]NamedRoute `yaml:"routes,omitempty" json:"routes,omitempty"`
+}
+
+// ScenarioMetadata describes a scenario file
+type ScenarioMetadata struct {
+	Name             string  `yaml:"name" json:"name"`
+	Description      string  `yaml:"description,omitempty" json:"description,omitempty"`
+	Version          string  `yaml:"version,omitempty" json:"version,omitempty"`
+	Duration         float64 `yaml:"duration,omitempty" json:"duration,omitempty"`                   // seconds
+	TimeAcceleration float64 `yaml:"time_acceleration,omitempty" json:"time_acceleration,omitempty"` // simulation seconds per wall second
+	StartTime        string  `yaml:"start_time,omitempty" json:"start_time,omitempty"`               // RFC 3339
+}
+
+// ParseStartTime returns the simulated date and time at t=0; the zero time when unset
+func (m ScenarioMetadata) ParseStartTime() (time.Time, error) {
+	return parseStartTime(m.StartTime)
+}
+
+// ScenarioSettings holds environment settings shared by all platforms in a scenario
+type ScenarioSettings struct {
+	Weather     *WeatherConfig     `yaml:"weather,omitempty" json:"weather,omitempty"`
+	Environment *EnvironmentConfig `yaml:"environment,omitempty" json:"environment,omitempty"` // Wind and current fields; weather wind when unset
+}
+
+// WeatherConfig describes the scenario weather
+type WeatherConfig struct {
+	Conditions    string  `yaml:"conditions,omitempty" json:"conditions,omitempty"`
+	WindSpeed     float64 `yaml:"wind_speed,omitempty" json:"wind_speed,omitempty"`         // m/s
+	WindDirection float64 `yaml:"wind_direction,omitempty" json:"wind_direction,omitempty"` // degrees the wind blows from
+	Visibility    float64 `yaml:"visibility,omitempty" json:"visibility,omitempty"`         // meters
+	Precipitation string  `yaml:"precipitation,omitempty" json:"precipitation,omitempty"`
+}
+
+// ScenarioPlatform is a platform entry in a scenario file
+type ScenarioPlatform struct {
+	ID               string                 `yaml:"id" json:"id"`
+	Type             string                 `yaml:"type" json:"type"`                                   // Platform type ID within the source file
+	SourceFile       string                 `yaml:"source_file,omitempty" json:"source_file,omitempty"` // Relative to data/platforms/
+	Name             string                 `yaml:"name" json:"name"`
+	CallSign         string                 `yaml:"callsign,omitempty" json:"callsign,omitempty"`
+	Affiliation      string                 `yaml:"affiliation,omitempty" json:"affiliation,omitempty"`   // Overrides the type's cot_config affiliation
+	ICAOAddress      string                 `yaml:"icao_address,omitempty" json:"icao_address,omitempty"` // ADS-B address, six hex digits
+	Squawk           string                 `yaml:"squawk,omitempty" json:"squawk,omitempty"`             // Four octal digits
+	MMSI             string                 `yaml:"mmsi,omitempty" json:"mmsi,omitempty"`                 // AIS MMSI, nine digits
+	AISDark          bool                   `yaml:"ais_dark,omitempty" json:"ais_dark,omitempty"`         // No AIS transmissions
+	Class            string                 `yaml:"class,omitempty" json:"class,omitempty"`               // Set by the scenario builder
+	Domain           string                 `yaml:"domain,omitempty" json:"domain,omitempty"`             // airborne, maritime, land, space
+	StartPosition    Position               `yaml:"start_position" json:"start_position"`
+	RouteID          string                 `yaml:"route_id,omitempty" json:"route_id,omitempty"`
+	SpawnTime        float64                `yaml:"spawn_time,omitempty" json:"spawn_time,omitempty"`     // seconds after scenario start
+	DespawnTime      float64                `yaml:"despawn_time,omitempty" json:"despawn_time,omitempty"` // seconds after scenario start, 0 = never
+	DespawnOnArrival bool                   `yaml:"despawn_on_arrival,omitempty" json:"despawn_on_arrival,omitempty"`
+	Mission          map[string]interface{} `yaml:"mission,omitempty" json:"mission,omitempty"`
+	TLE              *TLESource             `yaml:"tle,omitempty" json:"tle,omitempty"`                           // File relative to data/, e.g. tle/stations.txt
+	OrbitalElements  *OrbitalElements       `yaml:"orbital_elements,omitempty" json:"orbital_elements,omitempty"` // Overrides the route's
+	Constellation    *WalkerConstellation   `yaml:"constellation,omitempty" json:"constellation,omitempty"`       // One platform per satellite
+}
+
+// NamedRoute is a reusable route definition referenced by route_id
+type NamedRoute struct {
+	Name            string                 `yaml:"name" json:"name"`
+	Type            string                 `yaml:"type,omitempty" json:"type,omitempty"`
+	Pattern         string                 `yaml:"pattern,omitempty" json:"pattern,omitempty"`
+	Mode            string                 `yaml:"mode,omitempty" json:"mode,omitempty"` // Route mode for waypoint routes, see RouteMode
+	Waypoints       []RouteWaypoint        `yaml:"waypoints,omitempty" json:"waypoints,omitempty"`
+	Center          *Position              `yaml:"center,omitempty" json:"center,omitempty"`
+	Station         *Position              `yaml:"station,omitempty" json:"station,omitempty"`
+	Boundaries      *BoundingBox           `yaml:"boundaries,omitempty" json:"boundaries,omitempty"`
+	OrbitalElements *OrbitalElements       `yaml:"orbital_elements,omitempty" json:"orbital_elements,omitempty"`
+	Parameters      map[string]interface{} `yaml:"parameters,omitempty" json:"parameters,omitempty"`
+}
+
+// RouteWaypoint is a route position with an optional leg speed
+type RouteWaypoint struct {
+	Latitude  float64 `yaml:"latitude" json:"latitude"`
+	Longitude float64 `yaml:"longitude" json:"longitude"`
+	Altitude  float64 `yaml:"altitude" json:"altitude"`
+	Speed     float64 `yaml:"speed,omitempty" json:"speed,omitempty"` // m/s toward this waypoint, 0 = cruise speed
+}
+
+// Scenario is a scenario file with every platform and route reference resolved
+type Scenario struct {
+	Metadata    ScenarioMetadata
+	Weather     *WeatherConfig
+	Environment *EnvironmentConfig
+	Entries     []ScenarioEntry
+}
+
+// ScenarioEntry is an engine-ready platform definition from a scenario file
+type ScenarioEntry struct {
+	Instance PlatformInstance // Route, route mode and behavior resolved from route_id
+	Domain   string           // airborne, maritime, land, space
+	TypeDef  *models.PlatformTypeDefinition
+}
+
+// ScenarioLoader reads scenario files and resolves their platform and route references
+type ScenarioLoader struct {
+	platformsDir string
+	sourceCache  map[string]map[string]models.PlatformTypeDefinition
+}
+
+// NewScenarioLoader creates a loader resolving source files against platformsDir
+func NewScenarioLoader(platformsDir string) *ScenarioLoader {
+	if platformsDir == "" {
+		platformsDir = DefaultPlatformsDir
+	}
+	return &ScenarioLoader{
+		platformsDir: platformsDir,
+		sourceCache:  make(map[string]map[string]models.PlatformTypeDefinition),
+	}
+}
+
+// PlatformsDirFor returns the platforms directory beside a scenario file's directory
+// (data/configs/x.yaml -> data/platforms), falling back to DefaultPlatformsDir
+func PlatformsDirFor(scenarioPath string) string {
+	platformsDir := filepath.Join(filepath.Dir(filepath.Dir(scenarioPath)), "platforms")
+	if info, err := os.Stat(platformsDir); err == nil && info.IsDir() {
+		return platformsDir
+	}
+	return DefaultPlatformsDir
+}
+
+// ParseScenarioFile parses scenario file content
+func ParseScenarioFile(data []byte) (*ScenarioFile, error) {
+	var file ScenarioFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse scenario file: %w", err)
+	}
+	return &file, nil
+}
+
+// ReadScenarioFile reads and parses a scenario file from disk
+func ReadScenarioFile(path string) (*ScenarioFile, error) {
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read scenario file: %w", err)
+	}
+	return ParseScenarioFile(data)
+}
+
+// Load reads, validates and resolves a scenario file
+func (l *ScenarioLoader) Load(path string) (*Scenario, error) {
+	file, err := ReadScenarioFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return l.Resolve(file)
+}
+
+// Validate checks the scenario file structure, returning every problem found
+func (sf *ScenarioFile) Validate() error {
+	var errs []error
+
+	if sf.Metadata.Name == "" {
+		errs = append(errs, fmt.Errorf("metadata.name is required"))
+	}
+	if sf.Metadata.Duration <= 0 {
+		errs = append(errs, fmt.Errorf("metadata.duration must be positive"))
+	}
+	if sf.Metadata.TimeAcceleration < 0 {
+		errs = append(errs, fmt.Errorf("metadata.time_acceleration cannot be negative"))
+	}
+	if _, err := sf.Metadata.ParseStartTime(); err != nil {
+		errs = append(errs, fmt.Errorf("metadata.%w", err))
+	}
+
+	if sf.ScenarioConfig.Environment != nil {
+		if err := sf.ScenarioConfig.Environment.Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("scenario_config.%w", err))
+		}
+	}
+
+	platformIDs := make(map[string]bool)
+	for i, platform := range sf.Platforms {
+		if platform.ID == "" {
+			errs = append(errs, fmt.Errorf("platform %d: id is required", i))
+		} else {
+			if platformIDs[platform.ID] {
+				errs = append(errs, fmt.Errorf("platform %d: duplicate id '%s'", i, platform.ID))
+			}
+			platformIDs[platform.ID] = true
+		}
+
+		if platform.Type == "" {
+			errs = append(errs, fmt.Errorf("platform %d (%s): type is required", i, platform.ID))
+		}
+
+		if platform.StartPosition.Latitude < -90 || platform.StartPosition.Latitude > 90 {
+			errs = append(errs, fmt.Errorf("platform %d (%s): latitude must be between -90 and 90", i, platform.ID))
+		}
+		if platform.StartPosition.Longitude < -180 || platform.StartPosition.Longitude > 180 {
+			errs = append(errs, fmt.Errorf("platform %d (%s): longitude must be between -180 and 180", i, platform.ID))
+		}
+		if err := models.ValidateAffiliation(platform.Affiliation); err != nil {
+			errs = append(errs, fmt.Errorf("platform %d (%s): %w", i, platform.ID, err))
+		}
+		if err := models.ValidateTransponder(platform.ICAOAddress, platform.Squawk, platform.MMSI); err != nil {
+			errs = append(errs, fmt.Errorf("platform %d (%s): %w", i, platform.ID, err))
+		}
+
+		timing := PlatformInstance{SpawnTime: platform.SpawnTime, DespawnTime: platform.DespawnTime}
+		if err := timing.ValidateTiming(); err != nil {
+			errs = append(errs, fmt.Errorf("platform %d (%s): %w", i, platform.ID, err))
+		}
+
+		if platform.RouteID != "" {
+			if _, exists := sf.Routes[platform.RouteID]; !exists {
+				errs = append(errs, fmt.Errorf("platform %d (%s): unknown route_id '%s'", i, platform.ID, platform.RouteID))
+			}
+		}
+		if platform.TLE != nil {
+			if err := platform.TLE.Validate(); err != nil {
+				errs = append(errs, fmt.Errorf("platform %d (%s): %w", i, platform.ID, err))
+			}
+		}
+		if platform.OrbitalElements != nil {
+			if err := platform.OrbitalElements.Validate(); err != nil {
+				errs = append(errs, fmt.Errorf("platform %d (%s): %w", i, platform.ID, err))
+			}
+		}
+		if platform.Constellation != nil {
+			if err := platform.Constellation.Validate(); err != nil {
+				errs = append(errs, fmt.Errorf("platform %d (%s): %w", i, platform.ID, err))
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Resolve validates a scenario file and resolves its source files and routes
+func (l *ScenarioLoader) Resolve(file *ScenarioFile) (*Scenario, error) {
+	if err := file.Validate(); err != nil {
+		return nil, err
+	}
+
+	scenario := &Scenario{
+		Metadata:    file.Metadata,
+		Weather:     file.ScenarioConfig.Weather,
+		Environment: file.ScenarioConfig.Environment,
+		Entries:     make([]ScenarioEntry, 0, len(file.Platforms)),
+	}
+
+	var errs []error
+	for _, platform := range file.Platforms {
+		entry, err := l.resolvePlatform(file, platform)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("platform %s: %w", platform.ID, err))
+			continue
+		}
+
+		// One entry per satellite in the element set file or constellation
+		var instances []PlatformInstance
+		switch {
+		case entry.Instance.TLE != nil:
+			instances, err = expandTLEInstances([]PlatformInstance{entry.Instance})
+		case entry.Instance.Constellation != nil:
+			instances, err = expandConstellation(entry.Instance, entry.TypeDef.CallsignConf)
+		default:
+			scenario.Entries = append(scenario.Entries, *entry)
+			continue
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("platform %s: %w", platform.ID, err))
+			continue
+		}
+		for _, instance := range instances {
+			satellite := *entry
+			satellite.Instance = instance
+			scenario.Entries = append(scenario.Entries, satellite)
+		}
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return scenario, nil
+}
+
+// resolvePlatform builds the engine-ready entry for one scenario platform
+func (l *ScenarioLoader) resolvePlatform(file *ScenarioFile, platform ScenarioPlatform) (*ScenarioEntry, error) {
+	sourceFile := platform.SourceFile
+	if sourceFile == "" {
+		found, err := l.findSourceFile(platform.Type)
+		if err != nil {
+			return nil, err
+		}
+		sourceFile = found
+	}
+
+	typeDef, err := l.loadPlatformType(sourceFile, platform.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	domain := strings.SplitN(filepath.ToSlash(sourceFile), "/", 2)[0]
+	switch domain {
+	case PlatformTypeAirborne, PlatformTypeMaritime, PlatformTypeLand, PlatformTypeSpace:
+	default:
+		return nil, fmt.Errorf("cannot determine domain from source file %s", sourceFile)
+	}
+
+	tle, err := l.resolveTLESource(platform.TLE)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := &ScenarioEntry{
+		Instance: PlatformInstance{
+			ID:            platform.ID,
+			TypeID:        platform.Type,
+			Name:          platform.Name,
+			CallSign:      platform.CallSign,
+			Affiliation:   platform.Affiliation,
+			ICAOAddress:   platform.ICAOAddress,
+			Squawk:        platform.Squawk,
+			MMSI:          platform.MMSI,
+			AISDark:       platform.AISDark,
+			StartPos:      platform.StartPosition,
+			TLE:           tle,
+			Constellation: platform.Constellation,
+
+			SpawnTime:        platform.SpawnTime,
+			DespawnTime:      platform.DespawnTime,
+			DespawnOnArrival: platform.DespawnOnArrival,
+		},
+		Domain:  domain,
+		TypeDef: typeDef,
+	}
+
+	if platform.RouteID != "" {
+		route := file.Routes[platform.RouteID]
+		if err := route.apply(entry); err != nil {
+			return nil, fmt.Errorf("route %s: %w", platform.RouteID, err)
+		}
+	}
+	if platform.OrbitalElements != nil {
+		entry.Instance.Orbit = platform.OrbitalElements
+	}
+	if err := entry.Instance.ValidateTiming(); err != nil {
+		return nil, err
+	}
+	if err := entry.Instance.validateOrbit(domain); err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+// resolveTLESource returns a TLE source with its file resolved against the data directory holding
+// the platforms directory, as source files are resolved against data/platforms. Files outside the
+// data directory are rejected, as scenarios may come from API requests.
+func (l *ScenarioLoader) resolveTLESource(source *TLESource) (*TLESource, error) {
+	if source == nil {
+		return nil, nil
+	}
+	resolved := *source
+	if resolved.File != "" {
+		dataDir := filepath.Dir(l.platformsDir)
+		cleanPath := filepath.Clean(filepath.FromSlash(resolved.File))
+		if filepath.IsAbs(cleanPath) || strings.HasPrefix(cleanPath, "..") {
+			return nil, fmt.Errorf("tle.file %s must be relative to %s", source.File, dataDir)
+		}
+		resolved.File = filepath.Join(dataDir, cleanPath)
+	}
+	return &resolved, nil
+}
+
+// loadPlatformType reads a platform type definition from a file under the platforms directory
+func (l *ScenarioLoader) loadPlatformType(sourceFile, typeID string) (*models.PlatformTypeDefinition, error) {
+	types, err := l.readSourceFile(sourceFile)
+	if err != nil {
+		return nil, err
+	}
+
+	typeDef, exists := types[typeID]
+	if !exists {
+		return nil, fmt.Errorf("platform type %s not defined in %s", typeID, sourceFile)
+	}
+	return &typeDef, nil
+}
+
+// readSourceFile parses the platform_types of a platform definition file, caching the result
+func (l *ScenarioLoader) readSourceFile(sourceFile string) (map[string]models.PlatformTypeDefinition, error) {
+	cleanPath := filepath.Clean(filepath.FromSlash(sourceFile))
+	if filepath.IsAbs(cleanPath) || strings.HasPrefix(cleanPath, "..") {
+		return nil, fmt.Errorf("source file %s must be relative to %s", sourceFile, l.platformsDir)
+	}
+
+	if types, cached := l.sourceCache[cleanPath]; cached {
+		return types, nil
+	}
+
+	data, err := os.ReadFile(filepath.Join(l.platformsDir, cleanPath))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read source file: %w", err)
+	}
+
+	var definition struct {
+		PlatformTypes map[string]models.PlatformTypeDefinition `yaml:"platform_types"`
+	}
+	if err := yaml.Unmarshal(data, &definition); err != nil {
+		return nil, fmt.Errorf("failed to parse source file %s: %w", sourceFile, err)
+	}
+
+	l.sourceCache[cleanPath] = definition.PlatformTypes
+	return definition.PlatformTypes, nil
+}
+
+// findSourceFile searches the platforms directory for the file defining a platform type
+func (l *ScenarioLoader) findSourceFile(typeID string) (string, error) {
+	var found string
+	err := filepath.WalkDir(l.platformsDir, func(path string, entry os.DirEntry, err error) error {
+		if err != nil || entry.IsDir() || found != "" {
+			return err
+		}
+		if ext := filepath.Ext(path); ext != ".yaml" && ext != ".yml" {
+			return nil
+		}
+
+		relative, err := filepath.Rel(l.platformsDir, path)
+		if err != nil {
+			return err
+		}
+		types, err := l.readSourceFile(filepath.ToSlash(relative))
+		if err != nil {
+			return nil // Skip unreadable definitions while searching
+		}
+		if _, exists := types[typeID]; exists {
+			found = filepath.ToSlash(relative)
+		}
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to search %s: %w", l.platformsDir, err)
+	}
+	if found == "" {
+		return "", fmt.Errorf("no source file defines platform type %s", typeID)
+	}
+	return found, nil
+}
+
+// CreatePlatforms creates a platform for every scenario entry
+func (s *Scenario) CreatePlatforms() ([]models.Platform, error) {
+	factory := NewPlatformFactory(&PlatformRegistry{})
+
+	platforms := make([]models.Platform, 0, len(s.Entries))
+	for _, entry := range s.Entries {
+		platform, err := factory.CreatePlatformFromDefinition(entry.Instance, entry.Domain, entry.TypeDef)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create platform %s: %w", entry.Instance.ID, err)
+		}
+		if err := factory.configureNavigation(platform, entry.Instance); err != nil {
+			return nil, err
+		}
+		platforms = append(platforms, platform)
+	}
+	return platforms, nil
+}
+
+// Instances returns the resolved platform instances of the scenario
+func (s *Scenario) Instances() []PlatformInstance {
+	instances := make([]PlatformInstance, 0, len(s.Entries))
+	for _, entry := range s.Entries {
+		instances = append(instances, entry.Instance)
+	}
+	return instances
+}
+
+// apply converts the named route into a route or behavior on the scenario entry
+func (r NamedRoute) apply(entry *ScenarioEntry) error {
+	instance := &entry.Instance
+	speed := r.param("patrol_speed")
+
+	switch {
+	case len(r.Waypoints) > 0:
+		if _, err := models.ParseRouteMode(r.Mode); err != nil {
+			return err
+		}
+		for _, waypoint := range r.Waypoints {
+			instance.Route = append(instance.Route, Position{
+				Latitude:  waypoint.Latitude,
+				Longitude: waypoint.Longitude,
+				Altitude:  waypoint.Altitude,
+			})
+			instance.RouteSpeeds = append(instance.RouteSpeeds, waypoint.Speed)
+		}
+		instance.RouteMode = r.Mode
+	case r.Station != nil:
+		instance.Route = []Position{*r.Station}
+		instance.RouteMode = string(models.RouteModeHold)
+		instance.RouteSpeeds = []float64{speed}
+	case r.OrbitalElements != nil:
+		instance.Orbit = r.OrbitalElements
+	case r.Boundaries != nil:
+		if r.Pattern == "random_walk" {
+			instance.Behavior = &BehaviorConfig{RandomWalk: &RandomWalkBehavior{
+				Area:        *r.Boundaries,
+				MaxDistance: r.param("waypoint_spacing"),
+				Speed:       speed,
+			}}
+		} else {
+			// Box and sweep patterns patrol the boundary corners
+			altitude := r.param("altitude")
+			instance.Behavior = &BehaviorConfig{Patrol: &PatrolBehavior{
+				Pattern: "box",
+				Points: []Position{
+					{Latitude: r.Boundaries.South, Longitude: r.Boundaries.West, Altitude: altitude},
+					{Latitude: r.Boundaries.North, Longitude: r.Boundaries.East, Altitude: altitude},
+				},
+				Speed:     speed,
+				LoopCount: -1,
+			}}
+		}
+	case r.Center != nil:
+		return r.applyCenteredPattern(instance)
+	default:
+		return fmt.Errorf("route has no waypoints, station, boundaries, center or orbital elements")
+	}
+
+	if instance.Behavior != nil {
+		return instance.Behavior.Validate()
+	}
+	return nil
+}
+
+// applyCenteredPattern converts patterns flown around a center point
+func (r NamedRoute) applyCenteredPattern(instance *PlatformInstance) error {
+	center := *r.Center
+
+	switch r.Pattern {
+	case "racetrack":
+		halfLeg := r.param("leg_length") / 2
+		if halfLeg <= 0 {
+			return fmt.Errorf("racetrack pattern requires a positive leg_length")
+		}
+		heading := r.param("pattern_heading")
+		instance.Behavior = &BehaviorConfig{Patrol: &PatrolBehavior{
+			Pattern: "line",
+			Points: []Position{
+				offsetPosition(center, heading+180, halfLeg),
+				offsetPosition(center, heading, halfLeg),
+			},
+			Speed:     r.param("patrol_speed"),
+			LoopCount: -1,
+		}}
+	case "expanding_square":
+		waypoints, err := expandingSquare(center, r.param("pattern_size"), r.param("expansion_rate"))
+		if err != nil {
+			return err
+		}
+		if altitude := r.param("search_altitude"); altitude > 0 {
+			for i := range waypoints {
+				waypoints[i].Altitude = altitude
+			}
+		}
+		instance.Route = waypoints
+		instance.RouteMode = string(models.RouteModeHold)
+		speeds := make([]float64, len(waypoints))
+		for i := range speeds {
+			speeds[i] = r.param("search_speed")
+		}
+		instance.RouteSpeeds = speeds
+	default:
+		// Combat air patrols and other orbits circle the center
+		radius := r.param("patrol_radius")
+		if radius <= 0 {
+			return fmt.Errorf("%s pattern requires a positive patrol_radius", r.Pattern)
+		}
+		instance.Behavior = &BehaviorConfig{CircuitFlight: &CircuitBehavior{
+			Center: center,
+			Radius: radius,
+			Speed:  r.param("patrol_speed"),
+		}}
+	}
+
+	if instance.Behavior != nil {
+		return instance.Behavior.Validate()
+	}
+	return nil
+}
+
+// param returns a numeric route parameter, or 0 when it is missing or not a number
+func (r NamedRoute) param(name string) float64 {
+	switch value := r.Parameters[name].(type) {
+	case int:
+		return float64(value)
+	case float64:
+		return value
+	default:
+		return 0
+	}
+}
+
+// expandingSquare generates search legs growing by spacing until they cover a square of the given size
+func expandingSquare(center Position, size, spacing float64) ([]Position, error) {
+	if size <= 0 {
+		return nil, fmt.Errorf("expanding_square pattern requires a positive pattern_size")
+	}
+	if spacing <= 0 {
+		spacing = size / 10
+	}
+
+	waypoints := []Position{center}
+	current := center
+	for leg := 0; float64(leg/2+1)*spacing <= size; leg++ {
+		current = offsetPosition(current, float64(leg%4)*90, float64(leg/2+1)*spacing)
+		waypoints = append(waypoints, current)
+	}
+	return waypoints, nil
+}
+
+// offsetPosition moves a position by distance meters along a bearing (flat-earth approximation)
+func offsetPosition(pos Position, bearing, distance float64) Position {
+	const earthRadius = 6371000.0
+
+	bearingRad := bearing * math.Pi / 180.0
+	deltaLat := distance * math.Cos(bearingRad) / earthRadius * 180.0 / math.Pi
+	deltaLon := distance * math.Sin(bearingRad) / earthRadius * 180.0 / math.Pi / math.Cos(pos.Latitude*math.Pi/180.0)
+
+	return Position{
+		Latitude:  pos.Latitude + deltaLat,
+		Longitude: pos.Longitude + deltaLon,
+		Altitude:  pos.Altitude,
+	}
+}
@@ -0,0 +1,179 @@
+package config
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testPlatformsDir = "../../data/platforms"
+
+func TestScenarioLoader_LoadDataConfigs(t *testing.T) {
+	paths, err := filepath.Glob("../../data/configs/*.yaml")
+	if err != nil || len(paths) == 0 {
+		t.Fatalf("No scenario files found: %v", err)
+	}
+
+	for _, path := range paths {
+		t.Run(filepath.Base(path), func(t *testing.T) {
+			file, err := ReadScenarioFile(path)
+			if err != nil {
+				t.Fatalf("ReadScenarioFile failed: %v", err)
+			}
+
+			scenario, err := NewScenarioLoader(testPlatformsDir).Load(path)
+			if err != nil {
+				t.Fatalf("Load failed: %v", err)
+			}
+			// Constellations expand to one entry per satellite
+			want := 0
+			for _, platform := range file.Platforms {
+				if platform.Constellation != nil {
+					want += platform.Constellation.Satellites
+				} else {
+					want++
+				}
+			}
+			if len(scenario.Entries) != want {
+				t.Errorf("Expected %d entries, got %d", want, len(scenario.Entries))
+			}
+
+			platforms, err := scenario.CreatePlatforms()
+			if err != nil {
+				t.Fatalf("CreatePlatforms failed: %v", err)
+			}
+			if len(platforms) != want {
+				t.Errorf("Expected %d platforms, got %d", want, len(platforms))
+			}
+		})
+	}
+}
+
+func TestScenarioLoader_MilitaryExercise(t *testing.T) {
+	scenario, err := NewScenarioLoader(testPlatformsDir).Load("../../data/configs/military_exercise.yaml")
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	if scenario.Metadata.Duration != 28800 || scenario.Metadata.TimeAcceleration != 2.0 {
+		t.Errorf("Unexpected metadata: %+v", scenario.Metadata)
+	}
+	if scenario.Weather == nil || scenario.Weather.WindSpeed != 7.7 || scenario.Weather.WindDirection != 45 {
+		t.Errorf("Unexpected weather: %+v", scenario.Weather)
+	}
+
+	entries := make(map[string]ScenarioEntry)
+	for _, entry := range scenario.Entries {
+		entries[entry.Instance.ID] = entry
+	}
+
+	viper := entries["VIPER02"]
+	if viper.Domain != PlatformTypeAirborne || viper.Instance.SpawnTime != 300 {
+		t.Errorf("Expected airborne VIPER02 spawning at 300s, got %s at %.0fs", viper.Domain, viper.Instance.SpawnTime)
+	}
+	if viper.Instance.Behavior == nil || viper.Instance.Behavior.CircuitFlight == nil {
+		t.Fatal("Expected combat air patrol to become a circuit behavior")
+	}
+	if circuit := viper.Instance.Behavior.CircuitFlight; circuit.Radius != 92600 || circuit.Speed != 400 {
+		t.Errorf("Unexpected circuit: %+v", circuit)
+	}
+
+	steel := entries["STEEL01"]
+	if len(steel.Instance.Route) != 3 || steel.Instance.RouteSpeeds[1] != 10 {
+		t.Errorf("Expected 3 waypoints with a 10 m/s second leg, got %d waypoints, speeds %v",
+			len(steel.Instance.Route), steel.Instance.RouteSpeeds)
+	}
+
+	for _, entry := range scenario.Entries {
+		if entry.Domain != PlatformTypeMaritime {
+			continue
+		}
+		if entry.Instance.RouteMode != "hold" || len(entry.Instance.Route) != 1 {
+			t.Errorf("Expected station keeping to hold at one position, got %s with %d waypoints",
+				entry.Instance.RouteMode, len(entry.Instance.Route))
+		}
+	}
+}
+
+func TestScenarioLoader_Errors(t *testing.T) {
+	valid := func() *ScenarioFile {
+		return &ScenarioFile{
+			Metadata: ScenarioMetadata{Name: "Test", Duration: 3600},
+			Platforms: []ScenarioPlatform{{
+				ID:            "VIPER01",
+				Type:          "f16_fighting_falcon",
+				SourceFile:    "airborne/military/f16_fighting_falcon.yaml",
+				StartPosition: Position{Latitude: 35.2, Longitude: -115.0, Altitude: 915},
+			}},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(*ScenarioFile)
+		wantErr string
+	}{
+		{"valid", func(*ScenarioFile) {}, ""},
+		{"missing name", func(f *ScenarioFile) { f.Metadata.Name = "" }, "metadata.name is required"},
+		{"unknown route", func(f *ScenarioFile) { f.Platforms[0].RouteID = "missing" }, "unknown route_id 'missing'"},
+		{"duplicate id", func(f *ScenarioFile) { f.Platforms = append(f.Platforms, f.Platforms[0]) }, "duplicate id"},
+		{"missing source file", func(f *ScenarioFile) { f.Platforms[0].SourceFile = "airborne/none.yaml" }, "failed to read source file"},
+		{"escaping source file", func(f *ScenarioFile) { f.Platforms[0].SourceFile = "../configs/x.yaml" }, "must be relative"},
+		{"escaping TLE file", func(f *ScenarioFile) { f.Platforms[0].TLE = &TLESource{File: "../../../../etc/passwd"} }, "must be relative"},
+		{"absolute TLE file", func(f *ScenarioFile) { f.Platforms[0].TLE = &TLESource{File: "/etc/passwd"} }, "must be relative"},
+		{"type not in source file", func(f *ScenarioFile) { f.Platforms[0].Type = "b52" }, "not defined in"},
+		{"unknown type without source file", func(f *ScenarioFile) {
+			f.Platforms[0].SourceFile = ""
+			f.Platforms[0].Type = "flying_saucer"
+		}, "no source file defines"},
+		{"despawn before spawn", func(f *ScenarioFile) {
+			f.Platforms[0].SpawnTime = 600
+			f.Platforms[0].DespawnTime = 300
+		}, "despawn_time must be after spawn_time"},
+		{"despawn on arrival without route", func(f *ScenarioFile) { f.Platforms[0].DespawnOnArrival = true }, "requires a destination"},
+		{"empty route", func(f *ScenarioFile) {
+			f.Routes = map[string]NamedRoute{"empty": {Name: "Empty"}}
+			f.Platforms[0].RouteID = "empty"
+		}, "route has no waypoints"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := valid()
+			tt.modify(file)
+
+			_, err := NewScenarioLoader(testPlatformsDir).Resolve(file)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Resolve failed: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestScenarioLoader_FindSourceFile(t *testing.T) {
+	file := &ScenarioFile{
+		Metadata: ScenarioMetadata{Name: "Builder", Duration: 60},
+		Platforms: []ScenarioPlatform{{
+			ID:            "TRUCK01",
+			Type:          "pickup_truck",
+			StartPosition: Position{Latitude: 29.7, Longitude: -95.3},
+		}},
+	}
+
+	scenario, err := NewScenarioLoader(testPlatformsDir).Resolve(file)
+	if err != nil {
+		t.Fatalf("Resolve failed: %v", err)
+	}
+	if scenario.Entries[0].Domain != PlatformTypeLand {
+		t.Errorf("Expected land domain, got %s", scenario.Entries[0].Domain)
+	}
+	if scenario.Entries[0].TypeDef.Class == "" {
+		t.Error("Expected type definition to be loaded")
+	}
+}
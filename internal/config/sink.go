@@ -0,0 +1,234 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rhino11/trafficsim/internal/models"
+)
+
+// Output sink formats
+const (
+	SinkFormatCoT     = "cot"
+	SinkFormatSBS     = "sbs"
+	SinkFormatGDL90   = "gdl90"
+	SinkFormatAIS     = "ais"
+	SinkFormatDIS     = "dis"
+	SinkFormatASTERIX = "asterix"
+)
+
+// SinkConfig is one output stream: a format sent to an endpoint at its own rate, for the platforms
+// that pass its filter. Format specific settings come from the matching block, whose endpoint is
+// ignored in favour of the sink's.
+type SinkConfig struct {
+	Name     string     `yaml:"name,omitempty"`   // Unique label for status and logs, defaults to format and endpoint
+	Format   string     `yaml:"format"`           // cot, sbs, gdl90, ais, dis or asterix
+	Endpoint string     `yaml:"endpoint"`         // udp://, tcp:// or ssl:// as the format allows
+	Rate     string     `yaml:"rate,omitempty"`   // Time between updates, e.g. "1s"; asterix defaults to 4s, others to 1s
+	Filter   SinkFilter `yaml:"filter,omitempty"` // Platforms to report, all when empty
+	Policy   SinkPolicy `yaml:"policy,omitempty"` // When a platform is worth reporting, every update when empty
+
+	CoT     CoTConfig     `yaml:"cot,omitempty"`     // TLS, queue, backoff and encoding of cot sinks
+	DIS     DISConfig     `yaml:"dis,omitempty"`     // Identifiers, version and heartbeat of dis sinks
+	ASTERIX ASTERIXConfig `yaml:"asterix,omitempty"` // SAC and SIC of asterix sinks
+}
+
+// SinkFilter selects the platforms a sink reports; empty lists match everything
+type SinkFilter struct {
+	Types        []string     `yaml:"types,omitempty"`        // airborne, maritime, land or space
+	Affiliations []string     `yaml:"affiliations,omitempty"` // friend, hostile, neutral, unknown, ...
+	Area         *BoundingBox `yaml:"area,omitempty"`         // Only platforms inside the box
+}
+
+// SinkPolicy limits reports to platforms whose receivers would otherwise be misled. A platform is
+// reported when dead reckoning from its last report is off by more than the position threshold, when
+// its heading or speed has changed by more than their thresholds, or when the heartbeat has passed
+// since its last report. Zero thresholds are not checked, and without any the policy is off.
+type SinkPolicy struct {
+	PositionThreshold float64 `yaml:"position_threshold,omitempty"` // meters of dead reckoning error
+	HeadingThreshold  float64 `yaml:"heading_threshold,omitempty"`  // degrees
+	SpeedThreshold    float64 `yaml:"speed_threshold,omitempty"`    // m/s
+	Heartbeat         string  `yaml:"heartbeat,omitempty"`          // Longest time between reports, defaults to 1m
+}
+
+// Enabled reports whether any threshold is set
+func (p SinkPolicy) Enabled() bool {
+	return p.PositionThreshold > 0 || p.HeadingThreshold > 0 || p.SpeedThreshold > 0
+}
+
+// ParseHeartbeat returns the longest time between reports of a platform; zero means the default
+func (p SinkPolicy) ParseHeartbeat() (time.Duration, error) {
+	if p.Heartbeat == "" {
+		return 0, nil
+	}
+	heartbeat, err := time.ParseDuration(p.Heartbeat)
+	if err != nil {
+		return 0, fmt.Errorf("invalid policy heartbeat %q: %w", p.Heartbeat, err)
+	}
+	if heartbeat <= 0 {
+		return 0, fmt.Errorf("policy heartbeat must be positive: %s", p.Heartbeat)
+	}
+	return heartbeat, nil
+}
+
+// Validate checks the thresholds are not negative and the heartbeat parses
+func (p SinkPolicy) Validate() error {
+	for _, threshold := range []struct {
+		name  string
+		value float64
+	}{
+		{"position_threshold", p.PositionThreshold},
+		{"heading_threshold", p.HeadingThreshold},
+		{"speed_threshold", p.SpeedThreshold},
+	} {
+		if threshold.value < 0 {
+			return fmt.Errorf("policy %s cannot be negative: %g", threshold.name, threshold.value)
+		}
+	}
+	if p.Heartbeat != "" && !p.Enabled() {
+		return fmt.Errorf("policy heartbeat needs a position, heading or speed threshold")
+	}
+	_, err := p.ParseHeartbeat()
+	return err
+}
+
+// Label returns the sink name, or its format and endpoint when it has none
+func (s SinkConfig) Label() string {
+	if s.Name != "" {
+		return s.Name
+	}
+	return s.Format + " " + s.Endpoint
+}
+
+// ParseRate returns the time between updates; zero means the format default
+func (s SinkConfig) ParseRate() (time.Duration, error) {
+	if s.Rate == "" {
+		return 0, nil
+	}
+	rate, err := time.ParseDuration(s.Rate)
+	if err != nil {
+		return 0, fmt.Errorf("invalid rate %q: %w", s.Rate, err)
+	}
+	if rate <= 0 {
+		return 0, fmt.Errorf("rate must be positive: %s", s.Rate)
+	}
+	return rate, nil
+}
+
+// Validate checks the format, that the endpoint suits it, the rate, the policy and the filter
+func (s SinkConfig) Validate() error {
+	if err := s.validate(); err != nil {
+		return fmt.Errorf("sink %s: %w", s.Label(), err)
+	}
+	return nil
+}
+
+func (s SinkConfig) validate() error {
+	if s.Endpoint == "" {
+		return fmt.Errorf("endpoint is required")
+	}
+
+	var err error
+	switch strings.ToLower(s.Format) {
+	case SinkFormatCoT:
+		cot := s.CoT
+		cot.Endpoint = s.Endpoint
+		err = cot.Validate()
+	case SinkFormatSBS:
+		err = ADSBConfig{SBSEndpoint: s.Endpoint}.Validate()
+	case SinkFormatGDL90:
+		err = ADSBConfig{GDL90Endpoint: s.Endpoint}.Validate()
+	case SinkFormatAIS:
+		err = AISConfig{Endpoint: s.Endpoint}.Validate()
+	case SinkFormatDIS:
+		dis := s.DIS
+		dis.Endpoint = s.Endpoint
+		err = dis.Validate()
+	case SinkFormatASTERIX:
+		asterix := s.ASTERIX
+		asterix.Endpoint = s.Endpoint
+		err = asterix.Validate()
+	default:
+		return fmt.Errorf("unknown format %q: expected cot, sbs, gdl90, ais, dis or asterix", s.Format)
+	}
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.ParseRate(); err != nil {
+		return err
+	}
+	if err := s.Policy.Validate(); err != nil {
+		return err
+	}
+	return s.Filter.Validate()
+}
+
+// Validate checks the platform types and affiliations are known and the area is not inverted
+func (f SinkFilter) Validate() error {
+	for _, platformType := range f.Types {
+		switch models.PlatformType(strings.ToLower(platformType)) {
+		case models.PlatformTypeAirborne, models.PlatformTypeMaritime, models.PlatformTypeLand, models.PlatformTypeSpace:
+		default:
+			return fmt.Errorf("unknown platform type %q in filter", platformType)
+		}
+	}
+	for _, affiliation := range f.Affiliations {
+		if affiliation == "" {
+			return fmt.Errorf("empty affiliation in filter")
+		}
+		if err := models.ValidateAffiliation(affiliation); err != nil {
+			return fmt.Errorf("filter: %w", err)
+		}
+	}
+	if f.Area != nil && (f.Area.North < f.Area.South || f.Area.East < f.Area.West) {
+		return fmt.Errorf("filter area must have north >= south and east >= west")
+	}
+	return nil
+}
+
+// SinkConfigs returns the configured sinks followed by one for each endpoint set in the adsb, ais,
+// dis and asterix sections. The cot section only supplies settings; CoT sinks are listed in sinks
+// or added from the command line.
+func (c OutputConfig) SinkConfigs() []SinkConfig {
+	sinks := append([]SinkConfig(nil), c.Sinks...)
+	if c.ADSB.SBSEndpoint != "" {
+		sinks = append(sinks, SinkConfig{Name: SinkFormatSBS, Format: SinkFormatSBS, Endpoint: c.ADSB.SBSEndpoint})
+	}
+	if c.ADSB.GDL90Endpoint != "" {
+		sinks = append(sinks, SinkConfig{Name: SinkFormatGDL90, Format: SinkFormatGDL90, Endpoint: c.ADSB.GDL90Endpoint})
+	}
+	if c.AIS.Endpoint != "" {
+		sinks = append(sinks, SinkConfig{Name: SinkFormatAIS, Format: SinkFormatAIS, Endpoint: c.AIS.Endpoint})
+	}
+	if c.DIS.Endpoint != "" {
+		sinks = append(sinks, SinkConfig{Name: SinkFormatDIS, Format: SinkFormatDIS, Endpoint: c.DIS.Endpoint, DIS: c.DIS})
+	}
+	if c.ASTERIX.Endpoint != "" {
+		sinks = append(sinks, SinkConfig{
+			Name:     SinkFormatASTERIX,
+			Format:   SinkFormatASTERIX,
+			Endpoint: c.ASTERIX.Endpoint,
+			Rate:     c.ASTERIX.UpdatePeriod,
+			ASTERIX:  c.ASTERIX,
+		})
+	}
+	return sinks
+}
+
+// validateSinks checks every sink, including those from the per-format sections, and that names are unique
+func (c OutputConfig) validateSinks() error {
+	seen := make(map[string]bool)
+	for _, sink := range c.SinkConfigs() {
+		if err := sink.Validate(); err != nil {
+			return err
+		}
+		label := sink.Label()
+		if seen[label] {
+			return fmt.Errorf("duplicate sink %s", label)
+		}
+		seen[label] = true
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/rhino11/trafficsim/internal/models"
+)
+
+// TLESource places space platforms on the orbits of two-line element sets read from a file in the
+// CelesTrak format, such as one downloaded to data/tle/
+type TLESource struct {
+	File      string `yaml:"file" json:"file"`                               // Relative to the working directory
+	Satellite string `yaml:"satellite,omitempty" json:"satellite,omitempty"` // Name or catalog number, empty for every set in the file
+}
+
+// Validate checks that the source names a file
+func (s *TLESource) Validate() error {
+	if strings.TrimSpace(s.File) == "" {
+		return fmt.Errorf("tle.file is required")
+	}
+	return nil
+}
+
+// Select reads the file and returns the element sets the source picks
+func (s *TLESource) Select() ([]models.TLE, error) {
+	sets, err := models.ReadTLEFile(s.File)
+	if err != nil {
+		return nil, err
+	}
+	return s.filter(sets)
+}
+
+// filter returns the element sets matching the satellite name or catalog number
+func (s *TLESource) filter(sets []models.TLE) ([]models.TLE, error) {
+	if len(sets) == 0 {
+		return nil, fmt.Errorf("TLE file %s has no element sets", s.File)
+	}
+	if s.Satellite == "" {
+		return sets, nil
+	}
+
+	catalog, isNumber := strconv.Atoi(s.Satellite)
+	for _, tle := range sets {
+		if (isNumber == nil && tle.CatalogNumber == catalog) || strings.EqualFold(tle.Name, s.Satellite) {
+			return []models.TLE{tle}, nil
+		}
+	}
+	return nil, fmt.Errorf("satellite %s not found in TLE file %s", s.Satellite, s.File)
+}
+
+// expandTLEInstances replaces every instance with a TLE source by one instance per element set it
+// selects. A source picking a single satellite keeps the instance ID; otherwise each instance is
+// named after its satellite and identified by the catalog number.
+func expandTLEInstances(instances []PlatformInstance) ([]PlatformInstance, error) {
+	expanded := make([]PlatformInstance, 0, len(instances))
+	files := make(map[string][]models.TLE)
+	for _, instance := range instances {
+		if instance.TLE == nil {
+			expanded = append(expanded, instance)
+			continue
+		}
+		if err := instance.TLE.Validate(); err != nil {
+			return nil, fmt.Errorf("instance %s: %w", instance.ID, err)
+		}
+
+		sets, cached := files[instance.TLE.File]
+		if !cached {
+			var err error
+			if sets, err = models.ReadTLEFile(instance.TLE.File); err != nil {
+				return nil, fmt.Errorf("instance %s: %w", instance.ID, err)
+			}
+			files[instance.TLE.File] = sets
+		}
+		selected, err := instance.TLE.filter(sets)
+		if err != nil {
+			return nil, fmt.Errorf("instance %s: %w", instance.ID, err)
+		}
+
+		for i := range selected {
+			satellite := instance
+			satellite.elements = &selected[i]
+			if instance.TLE.Satellite == "" {
+				satellite.ID = fmt.Sprintf("%s-%d", instance.ID, selected[i].CatalogNumber)
+				satellite.Name = selected[i].Name
+				satellite.CallSign = ""
+			}
+			if satellite.Name == "" {
+				satellite.Name = selected[i].Name
+			}
+			expanded = append(expanded, satellite)
+		}
+	}
+	return expanded, nil
+}
+
+// orbitElements returns the element set an instance follows: the one chosen when the instance was
+// expanded, or the single set its source selects
+func (p *PlatformInstance) orbitElements() (models.TLE, error) {
+	if p.elements != nil {
+		return *p.elements, nil
+	}
+	sets, err := p.TLE.Select()
+	if err != nil {
+		return models.TLE{}, err
+	}
+	if len(sets) != 1 {
+		return models.TLE{}, fmt.Errorf("TLE file %s has %d element sets; choose one with tle.satellite", p.TLE.File, len(sets))
+	}
+	return sets[0], nil
+}
+
+// attachTLEOrbit makes a space platform follow the instance's element set with SGP4, placing it at
+// its position at the element set epoch until the simulation clock moves it
+func attachTLEOrbit(platform *models.UniversalPlatform, instance PlatformInstance) error {
+	if platform.PlatformType != models.PlatformTypeSpace {
+		return fmt.Errorf("orbits are only supported for space platforms, %s is %s", instance.ID, platform.PlatformType)
+	}
+	tle, err := instance.orbitElements()
+	if err != nil {
+		return err
+	}
+	propagator, err := models.NewSGP4(tle)
+	if err != nil {
+		return err
+	}
+	platform.Orbit = propagator
+	if err := platform.UpdateOrbit(tle.Epoch); err != nil {
+		return err
+	}
+	platform.Config.StartPosition = platform.State.Position
+	return nil
+}
@@ -0,0 +1,170 @@
+package config
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rhino11/trafficsim/internal/models"
+)
+
+const testTLEFile = "../../data/tle/sample.txt"
+
+func TestTLESource_Select(t *testing.T) {
+	tests := []struct {
+		name      string
+		satellite string
+		want      []int
+	}{
+		{"every set", "", []int{25544, 5, 8195, 28626}},
+		{"catalog number", "8195", []int{8195}},
+		{"name", "iss (zarya)", []int{25544}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := TLESource{File: testTLEFile, Satellite: tt.satellite}
+			sets, err := source.Select()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(sets) != len(tt.want) {
+				t.Fatalf("Selected %d element sets, want %d", len(sets), len(tt.want))
+			}
+			for i, catalog := range tt.want {
+				if sets[i].CatalogNumber != catalog {
+					t.Errorf("Element set %d is %d, want %d", i, sets[i].CatalogNumber, catalog)
+				}
+			}
+		})
+	}
+
+	if _, err := (&TLESource{File: testTLEFile, Satellite: "HUBBLE"}).Select(); err == nil {
+		t.Error("Expected an unknown satellite to be rejected")
+	}
+	if _, err := (&TLESource{File: "missing.txt"}).Select(); err == nil {
+		t.Error("Expected a missing file to be rejected")
+	}
+	if err := (&TLESource{}).Validate(); err == nil {
+		t.Error("Expected a source without a file to be rejected")
+	}
+}
+
+func TestPlatformFactory_TLEScenario(t *testing.T) {
+	registry := createTestRegistry()
+	registry.Scenarios["orbits"] = ScenarioConfig{
+		Name: "orbits",
+		Instances: []PlatformInstance{
+			{ID: "SAT", TypeID: "satellite", TLE: &TLESource{File: testTLEFile}, SpawnTime: 60},
+			{ID: "ISS", TypeID: "satellite", Name: "Station", TLE: &TLESource{File: testTLEFile, Satellite: "25544"}},
+		},
+	}
+	factory := NewPlatformFactory(registry)
+
+	instances, err := factory.ScenarioInstances("orbits")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(instances) != 5 {
+		t.Fatalf("Expected four satellites and the ISS, got %d instances", len(instances))
+	}
+	if instances[0].ID != "SAT-25544" || instances[0].Name != "ISS (ZARYA)" || instances[0].SpawnTime != 60 {
+		t.Errorf("Unexpected expanded instance %+v", instances[0])
+	}
+	if instances[4].ID != "ISS" || instances[4].Name != "Station" {
+		t.Errorf("Expected a single satellite to keep its instance, got %+v", instances[4])
+	}
+
+	platforms, err := factory.CreateScenario("orbits")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(platforms) != 5 {
+		t.Fatalf("Expected 5 platforms, got %d", len(platforms))
+	}
+	for _, platform := range platforms {
+		satellite := platform.(*models.UniversalPlatform)
+		if !satellite.IsOrbiting() {
+			t.Fatalf("Platform %s does not follow its orbit", satellite.ID)
+		}
+		if satellite.Config.StartPosition != satellite.State.Position || satellite.State.Position.Altitude < 100e3 {
+			t.Errorf("Platform %s should start on its orbit, at %+v", satellite.ID, satellite.State.Position)
+		}
+	}
+
+	// The station starts at its position at the element set epoch, 415 km up
+	iss := platforms[4].(*models.UniversalPlatform)
+	if math.Abs(iss.State.Position.Altitude-415e3) > 15e3 || iss.State.Speed < 7e3 {
+		t.Errorf("Unexpected ISS state %+v", iss.State)
+	}
+}
+
+func TestPlatformFactory_TLERequiresSpace(t *testing.T) {
+	factory := NewPlatformFactory(createTestRegistry())
+	instance := PlatformInstance{ID: "SHIP", TypeID: "f16_fighter", TLE: &TLESource{File: testTLEFile, Satellite: "25544"}}
+	if _, err := factory.CreatePlatform(instance); err == nil || !strings.Contains(err.Error(), "space") {
+		t.Errorf("Expected a TLE on an aircraft to be rejected, got %v", err)
+	}
+
+	instance = PlatformInstance{ID: "SAT", TypeID: "satellite", TLE: &TLESource{File: testTLEFile}}
+	if _, err := factory.CreatePlatform(instance); err == nil {
+		t.Error("Expected a file of several element sets to need a satellite")
+	}
+}
+
+func TestScenarioLoader_TLE(t *testing.T) {
+	// Scenario files resolve the element set file against the data directory
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "tle"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	sets, err := os.ReadFile(testTLEFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "tle", "sample.txt"), sets, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	platformsDir := filepath.Join(dir, "platforms")
+	if err := os.MkdirAll(filepath.Join(platformsDir, "space"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	definition := "platform_types:\n  satellite:\n    class: Satellite\n    category: satellite\n"
+	if err := os.WriteFile(filepath.Join(platformsDir, "space", "satellite.yaml"), []byte(definition), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := ParseScenarioFile([]byte(`
+metadata:
+  name: Orbits
+  duration: 3600
+platforms:
+  - id: DEEP
+    type: satellite
+    tle:
+      file: tle/sample.txt
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	scenario, err := NewScenarioLoader(platformsDir).Resolve(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(scenario.Entries) != 4 || scenario.Entries[2].Instance.ID != "DEEP-8195" {
+		t.Fatalf("Expected one entry per element set, got %+v", scenario.Instances())
+	}
+	platforms, err := scenario.CreatePlatforms()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(platforms) != 4 || !platforms[3].(*models.UniversalPlatform).IsOrbiting() {
+		t.Errorf("Expected 4 orbiting platforms, got %d", len(platforms))
+	}
+
+	file.Platforms[0].TLE.File = ""
+	if _, err := NewScenarioLoader(platformsDir).Resolve(file); err == nil {
+		t.Error("Expected a TLE source without a file to be rejected")
+	}
+}
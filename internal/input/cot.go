@@ -0,0 +1,377 @@
+// Package input feeds tracks from outside sources into the simulation
+package input
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/xml"
+	"fmt"
+	"log"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rhino11/trafficsim/internal/models"
+	"github.com/rhino11/trafficsim/internal/output"
+	"github.com/rhino11/trafficsim/internal/sim"
+)
+
+// SourceCoT is the ExternalInfo source of tracks ingested from CoT
+const SourceCoT = "cot"
+
+// ownUIDPrefix marks events published by trafficsim itself, which are ignored so a listener on the
+// same multicast group as the publisher does not echo simulated platforms back in
+const ownUIDPrefix = "TRAFFICSIM-"
+
+// Listener defaults
+const (
+	defaultSweepInterval = time.Second
+	defaultMinBackoff    = 500 * time.Millisecond
+	defaultMaxBackoff    = 30 * time.Second
+	maxDatagramSize      = 65535
+)
+
+// TrackSink receives external tracks, normally a *sim.Engine
+type TrackSink interface {
+	UpdateExternalTrack(track sim.ExternalTrack) (models.Platform, error)
+	ExpireExternalTracks(now time.Time) int
+}
+
+// ListenerOptions configures a CoT listener
+type ListenerOptions struct {
+	TLS           *tls.Config   // Client TLS configuration for ssl:// feeds
+	SweepInterval time.Duration // How often stale tracks are removed
+	MaxBackoff    time.Duration // Longest delay between reconnects to a tcp:// or ssl:// feed
+}
+
+// ListenerStats counts the events a listener has handled
+type ListenerStats struct {
+	Received uint64 `json:"received"` // Events applied to the simulation
+	Ignored  uint64 `json:"ignored"`  // Own echoes, non-atom events and events already stale
+	Rejected uint64 `json:"rejected"` // Messages that could not be parsed or applied
+	Expired  uint64 `json:"expired"`  // Tracks removed on stale
+}
+
+// CoTListener subscribes to a CoT feed and keeps external platforms in the simulation up to date.
+// udp:// endpoints are joined as multicast groups (or bound as unicast ports) and accept CoT XML or
+// TAK Protocol mesh datagrams; tcp:// and ssl:// endpoints are connected to as a client and read as
+// a stream of CoT XML events, reconnecting with backoff.
+type CoTListener struct {
+	endpoint output.Endpoint
+	sink     TrackSink
+	opts     ListenerOptions
+	clock    models.Clock // Feed clock used to age out tracks, wall time by default
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu      sync.Mutex
+	stats   ListenerStats
+	udpConn *net.UDPConn
+	started bool
+}
+
+// NewCoTListener creates a listener for a udp://, tcp:// or ssl:// endpoint
+func NewCoTListener(endpoint string, sink TrackSink, opts ListenerOptions) (*CoTListener, error) {
+	ep, err := output.ParseEndpoint(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	if opts.SweepInterval <= 0 {
+		opts.SweepInterval = defaultSweepInterval
+	}
+	if opts.MaxBackoff <= 0 {
+		opts.MaxBackoff = defaultMaxBackoff
+	}
+	if ep.Scheme == output.SchemeSSL && opts.TLS == nil {
+		opts.TLS = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &CoTListener{
+		endpoint: ep,
+		sink:     sink,
+		opts:     opts,
+		clock:    models.WallClock{},
+		ctx:      ctx,
+		cancel:   cancel,
+	}, nil
+}
+
+// SetClock sets the clock stale times are compared against
+func (l *CoTListener) SetClock(clock models.Clock) {
+	if clock != nil {
+		l.clock = clock
+	}
+}
+
+// Start begins receiving. UDP sockets are opened before it returns; streams connect in the background.
+func (l *CoTListener) Start() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.started {
+		return fmt.Errorf("listener for %s already started", l.endpoint)
+	}
+
+	if l.endpoint.Scheme == output.SchemeUDP {
+		conn, err := listenUDP(l.endpoint)
+		if err != nil {
+			return err
+		}
+		l.udpConn = conn
+		l.wg.Add(1)
+		go l.readDatagrams(conn)
+	} else {
+		l.wg.Add(1)
+		go l.readStreams()
+	}
+
+	l.wg.Add(1)
+	go l.sweep()
+	l.started = true
+	return nil
+}
+
+// Close stops the listener; external platforms already added age out as usual
+func (l *CoTListener) Close() error {
+	l.cancel()
+	l.mu.Lock()
+	if l.udpConn != nil {
+		l.udpConn.Close()
+	}
+	l.mu.Unlock()
+	l.wg.Wait()
+	return nil
+}
+
+// Stats returns the event counters
+func (l *CoTListener) Stats() ListenerStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.stats
+}
+
+// Addr returns the local address of a UDP listener, or nil for streams
+func (l *CoTListener) Addr() net.Addr {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.udpConn == nil {
+		return nil
+	}
+	return l.udpConn.LocalAddr()
+}
+
+// listenUDP joins a multicast group, or binds a unicast address
+func listenUDP(ep output.Endpoint) (*net.UDPConn, error) {
+	addr, err := net.ResolveUDPAddr("udp", ep.Address())
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %w", ep, err)
+	}
+	var conn *net.UDPConn
+	if addr.IP.IsMulticast() {
+		conn, err = net.ListenMulticastUDP("udp", nil, addr)
+	} else {
+		conn, err = net.ListenUDP("udp", addr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", ep, err)
+	}
+	return conn, nil
+}
+
+// readDatagrams handles one CoT message per datagram until the socket is closed
+func (l *CoTListener) readDatagrams(conn *net.UDPConn) {
+	defer l.wg.Done()
+	buf := make([]byte, maxDatagramSize)
+	for {
+		n, _, err := conn.ReadFromUDP(buf)
+		if err != nil {
+			if l.ctx.Err() == nil {
+				log.Printf("CoT listener on %s stopped: %v", l.endpoint, err)
+			}
+			return
+		}
+		event, err := ParseCoTMessage(buf[:n])
+		if err != nil {
+			l.count(func(s *ListenerStats) { s.Rejected++ })
+			continue
+		}
+		l.HandleEvent(event)
+	}
+}
+
+// readStreams connects to a stream feed and reads events, reconnecting with backoff until closed
+func (l *CoTListener) readStreams() {
+	defer l.wg.Done()
+	backoff := defaultMinBackoff
+	for {
+		conn, err := l.dial()
+		if err == nil {
+			backoff = defaultMinBackoff
+			stop := context.AfterFunc(l.ctx, func() { conn.Close() }) // Unblocks the read on Close
+			err = l.readStream(conn)
+			stop()
+			conn.Close()
+		}
+		if l.ctx.Err() != nil {
+			return
+		}
+		log.Printf("CoT feed %s unavailable: %v", l.endpoint, err)
+
+		select {
+		case <-l.ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		if backoff *= 2; backoff > l.opts.MaxBackoff {
+			backoff = l.opts.MaxBackoff
+		}
+	}
+}
+
+// dial connects to the stream feed
+func (l *CoTListener) dial() (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: 10 * time.Second}
+	if l.opts.TLS != nil {
+		tlsDialer := &tls.Dialer{NetDialer: dialer, Config: l.opts.TLS}
+		return tlsDialer.DialContext(l.ctx, "tcp", l.endpoint.Address())
+	}
+	return dialer.DialContext(l.ctx, "tcp", l.endpoint.Address())
+}
+
+// readStream decodes consecutive <event> elements until the connection fails
+func (l *CoTListener) readStream(conn net.Conn) error {
+	decoder := xml.NewDecoder(conn)
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok || start.Name.Local != "event" {
+			continue
+		}
+
+		var event output.CoTEvent
+		if err := decoder.DecodeElement(&event, &start); err != nil {
+			return fmt.Errorf("malformed CoT event: %w", err)
+		}
+		l.HandleEvent(event)
+	}
+}
+
+// sweep removes stale tracks on an interval
+func (l *CoTListener) sweep() {
+	defer l.wg.Done()
+	ticker := time.NewTicker(l.opts.SweepInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-l.ctx.Done():
+			return
+		case <-ticker.C:
+			if expired := l.sink.ExpireExternalTracks(l.clock.Now()); expired > 0 {
+				l.count(func(s *ListenerStats) { s.Expired += uint64(expired) })
+			}
+		}
+	}
+}
+
+// HandleEvent applies one CoT event to the sink, ignoring trafficsim's own events, non-atom
+// events (e.g. chat or markers) and events that are already stale
+func (l *CoTListener) HandleEvent(event output.CoTEvent) {
+	if strings.HasPrefix(event.UID, ownUIDPrefix) {
+		l.count(func(s *ListenerStats) { s.Ignored++ })
+		return
+	}
+
+	track, err := TrackFromEvent(event)
+	if err != nil {
+		l.count(func(s *ListenerStats) { s.Ignored++ })
+		return
+	}
+	if !track.Stale.After(l.clock.Now()) {
+		l.count(func(s *ListenerStats) { s.Ignored++ })
+		return
+	}
+
+	if _, err := l.sink.UpdateExternalTrack(track); err != nil {
+		log.Printf("Rejected CoT track %s: %v", track.ID, err)
+		l.count(func(s *ListenerStats) { s.Rejected++ })
+		return
+	}
+	l.count(func(s *ListenerStats) { s.Received++ })
+}
+
+// count updates the stats under the lock
+func (l *CoTListener) count(update func(*ListenerStats)) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	update(&l.stats)
+}
+
+// ParseCoTMessage decodes a CoT XML event or a TAK Protocol mesh datagram
+func ParseCoTMessage(data []byte) (output.CoTEvent, error) {
+	if payload, err := output.ParseTakMesh(data); err == nil {
+		return output.UnmarshalTakMessage(payload)
+	}
+
+	var event output.CoTEvent
+	if err := xml.Unmarshal(data, &event); err != nil {
+		return output.CoTEvent{}, fmt.Errorf("invalid CoT message: %w", err)
+	}
+	return event, nil
+}
+
+// TrackFromEvent converts a CoT atom event into an external track. The platform type follows the
+// battle dimension of the CoT type; the callsign falls back to the UID.
+func TrackFromEvent(event output.CoTEvent) (sim.ExternalTrack, error) {
+	if event.UID == "" {
+		return sim.ExternalTrack{}, fmt.Errorf("CoT event has no uid")
+	}
+	if !strings.HasPrefix(event.Type, "a-") {
+		return sim.ExternalTrack{}, fmt.Errorf("CoT event %s of type %q is not a track", event.UID, event.Type)
+	}
+
+	reported, err := parseCoTTime(event.Time)
+	if err != nil {
+		return sim.ExternalTrack{}, err
+	}
+	stale, err := parseCoTTime(event.Stale)
+	if err != nil {
+		return sim.ExternalTrack{}, err
+	}
+
+	callsign := event.Detail.Contact.Callsign
+	if callsign == "" {
+		callsign = event.UID
+	}
+
+	return sim.ExternalTrack{
+		ID:       event.UID,
+		Callsign: callsign,
+		Type:     models.PlatformTypeForCoT(event.Type),
+		CoTType:  event.Type,
+		Source:   SourceCoT,
+		Position: models.Position{
+			Latitude:  event.Point.Lat,
+			Longitude: event.Point.Lon,
+			Altitude:  event.Point.Hae,
+		},
+		Speed:   event.Detail.Track.Speed,
+		Heading: event.Detail.Track.Course,
+		Time:    reported,
+		Stale:   stale,
+	}, nil
+}
+
+// parseCoTTime parses a CoT timestamp; senders vary in their fractional seconds
+func parseCoTTime(value string) (time.Time, error) {
+	t, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid CoT time %q", value)
+	}
+	return t.UTC(), nil
+}
@@ -0,0 +1,269 @@
+package input
+
+import (
+	"fmt"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rhino11/trafficsim/internal/models"
+	"github.com/rhino11/trafficsim/internal/output"
+	"github.com/rhino11/trafficsim/internal/sim"
+)
+
+// atakEvent is a position report as sent by ATAK, with detail elements trafficsim does not model
+const atakEvent = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<event version="2.0" uid="ANDROID-589520ccfcd20f01" type="a-f-G-U-C" how="h-e"
+       time="%[1]s" start="%[1]s" stale="%[2]s">
+  <point lat="36.8485" lon="-76.2951" hae="12.5" ce="9.9" le="9999999.0"/>
+  <detail>
+    <takv os="30" version="4.8.1" device="SAMSUNG SM-G781U" platform="ATAK-CIV"/>
+    <contact endpoint="*:-1:stcp" callsign="WOLF-1"/>
+    <__group role="Team Lead" name="Cyan"/>
+    <track course="271.5" speed="1.4"/>
+    <status battery="88"/>
+  </detail>
+</event>`
+
+// testClock is a settable models.Clock
+type testClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *testClock) Set(now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = now
+}
+
+func cotTime(t time.Time) string {
+	return t.UTC().Format("2006-01-02T15:04:05.000Z")
+}
+
+// freeUDPEndpoint returns a udp:// endpoint on a port that was free a moment ago
+func freeUDPEndpoint(t *testing.T) string {
+	t.Helper()
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatalf("Failed to reserve a UDP port: %v", err)
+	}
+	defer conn.Close()
+	return "udp://" + conn.LocalAddr().String()
+}
+
+// waitFor polls cond until it holds or the test times out
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestTrackFromEvent(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	event, err := ParseCoTMessage([]byte(fmt.Sprintf(atakEvent, cotTime(now), cotTime(now.Add(2*time.Minute)))))
+	if err != nil {
+		t.Fatalf("ParseCoTMessage failed: %v", err)
+	}
+
+	track, err := TrackFromEvent(event)
+	if err != nil {
+		t.Fatalf("TrackFromEvent failed: %v", err)
+	}
+	want := sim.ExternalTrack{
+		ID:       "ANDROID-589520ccfcd20f01",
+		Callsign: "WOLF-1",
+		Type:     models.PlatformTypeLand,
+		CoTType:  "a-f-G-U-C",
+		Source:   SourceCoT,
+		Position: models.Position{Latitude: 36.8485, Longitude: -76.2951, Altitude: 12.5},
+		Speed:    1.4,
+		Heading:  271.5,
+		Time:     now,
+		Stale:    now.Add(2 * time.Minute),
+	}
+	if track != want {
+		t.Errorf("TrackFromEvent = %+v\nwant %+v", track, want)
+	}
+
+	// Callsign falls back to the UID, and fractional seconds are optional
+	event.Detail.Contact.Callsign = ""
+	event.Time = "2024-06-01T12:00:00Z"
+	if track, err := TrackFromEvent(event); err != nil || track.Callsign != event.UID {
+		t.Errorf("Expected UID callsign, got %q, %v", track.Callsign, err)
+	}
+
+	invalid := []output.CoTEvent{
+		{UID: "CHAT-1", Type: "b-t-f", Time: event.Time, Stale: event.Stale}, // Not a track
+		{Type: "a-f-G", Time: event.Time, Stale: event.Stale},                // No uid
+		{UID: "X", Type: "a-f-G", Time: "yesterday", Stale: event.Stale},     // Bad time
+	}
+	for _, event := range invalid {
+		if _, err := TrackFromEvent(event); err == nil {
+			t.Errorf("Expected TrackFromEvent(%+v) to fail", event)
+		}
+	}
+}
+
+func TestParseCoTMessage_TakMesh(t *testing.T) {
+	generator := output.NewCoTGenerator()
+	if err := generator.SetEncoding(output.EncodingMesh); err != nil {
+		t.Fatal(err)
+	}
+	message, err := generator.GenerateMessage(output.PlatformState{ID: "F16", Callsign: "VIPER01", CoTType: "a-f-A-M-F", Latitude: 39})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	event, err := ParseCoTMessage(message)
+	if err != nil {
+		t.Fatalf("ParseCoTMessage failed on a mesh datagram: %v", err)
+	}
+	if event.UID != "TRAFFICSIM-F16" || event.Point.Lat != 39 {
+		t.Errorf("Unexpected event %+v", event)
+	}
+	if _, err := ParseCoTMessage([]byte("not cot")); err == nil {
+		t.Error("Expected an error for garbage")
+	}
+}
+
+func TestCoTListener_UDP(t *testing.T) {
+	engine := sim.NewEngine(nil)
+	endpoint := freeUDPEndpoint(t)
+	listener, err := NewCoTListener(endpoint, engine, ListenerOptions{})
+	if err != nil {
+		t.Fatalf("NewCoTListener failed: %v", err)
+	}
+	if err := listener.Start(); err != nil {
+		t.Skipf("Cannot listen on %s: %v", endpoint, err)
+	}
+	defer listener.Close()
+
+	conn, err := net.Dial("udp", listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	now := time.Now()
+	live := fmt.Sprintf(atakEvent, cotTime(now), cotTime(now.Add(time.Minute)))
+	stale := fmt.Sprintf(atakEvent, cotTime(now.Add(-time.Hour)), cotTime(now.Add(-time.Minute)))
+	echo, err := output.NewCoTGenerator().GenerateCoTMessage(output.PlatformState{ID: "UA123", CoTType: "a-n-A-C-F"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, message := range [][]byte{echo, []byte(stale), []byte("garbage"), []byte(live)} {
+		if _, err := conn.Write(message); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	waitFor(t, "external platform", func() bool { return engine.GetExternalPlatformCount() == 1 })
+	platform, err := engine.GetPlatform("ANDROID-589520ccfcd20f01")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if platform.GetCallSign() != "WOLF-1" || platform.GetState().Position.Latitude != 36.8485 {
+		t.Errorf("Unexpected platform %s at %+v", platform.GetCallSign(), platform.GetState().Position)
+	}
+	waitFor(t, "stats", func() bool { return listener.Stats().Rejected == 1 })
+	if stats := listener.Stats(); stats.Received != 1 || stats.Ignored != 2 {
+		t.Errorf("Expected 1 received, 2 ignored and 1 rejected, got %+v", stats)
+	}
+}
+
+func TestCoTListener_TCPStream(t *testing.T) {
+	server, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to start feed server: %v", err)
+	}
+	defer server.Close()
+
+	engine := sim.NewEngine(nil)
+	listener, err := NewCoTListener("tcp://"+server.Addr().String(), engine, ListenerOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := listener.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	conn, err := server.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	// A TAK Server stream: consecutive events, each with its own XML declaration
+	now := time.Now()
+	fmt.Fprintf(conn, atakEvent, cotTime(now), cotTime(now.Add(time.Minute)))
+	generator := output.NewCoTGenerator()
+	message, err := generator.GenerateCoTMessage(output.PlatformState{ID: "SHIP", Callsign: "VESSEL", CoTType: "a-n-S-X"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := conn.Write([]byte(`<event version="2.0" uid="AIS-366999999" type="a-n-S-X-M" how="m-g" time="` + cotTime(now) +
+		`" start="` + cotTime(now) + `" stale="` + cotTime(now.Add(time.Minute)) + `"><point lat="37" lon="-76" hae="0" ce="10" le="10"/></event>`)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := conn.Write(message); err != nil { // Own echo, ignored
+		t.Fatal(err)
+	}
+
+	waitFor(t, "two external platforms", func() bool { return engine.GetExternalPlatformCount() == 2 })
+	ship, err := engine.GetPlatform("AIS-366999999")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ship.GetType() != models.PlatformTypeMaritime {
+		t.Errorf("Expected a maritime platform for a-n-S-X-M, got %s", ship.GetType())
+	}
+	waitFor(t, "echo ignored", func() bool { return listener.Stats().Ignored == 1 })
+}
+
+func TestCoTListener_AgesOutOnStale(t *testing.T) {
+	engine := sim.NewEngine(nil)
+	listener, err := NewCoTListener("tcp://127.0.0.1:1", engine, ListenerOptions{SweepInterval: 5 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	clock := &testClock{now: time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)}
+	listener.SetClock(clock)
+	if err := listener.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	now := clock.Now()
+	event, err := ParseCoTMessage([]byte(fmt.Sprintf(atakEvent, cotTime(now), cotTime(now.Add(30*time.Second)))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	listener.HandleEvent(event)
+	if engine.GetExternalPlatformCount() != 1 {
+		t.Fatalf("Expected the track to be added")
+	}
+
+	// Still fresh after several sweeps
+	time.Sleep(20 * time.Millisecond)
+	if engine.GetExternalPlatformCount() != 1 {
+		t.Fatal("Track removed before its stale time")
+	}
+
+	clock.Set(now.Add(31 * time.Second))
+	waitFor(t, "stale track removal", func() bool { return engine.GetExternalPlatformCount() == 0 })
+	waitFor(t, "expired count", func() bool { return listener.Stats().Expired == 1 })
+}
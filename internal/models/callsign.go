@@ -0,0 +1,93 @@
+package models
+
+import (
+	"fmt"
+	"math/rand"
+	"regexp"
+	"strconv"
+)
+
+// callsignPlaceholder matches {name} and {name:format} placeholders, e.g. {number:04d}
+var callsignPlaceholder = regexp.MustCompile(`\{(\w+)(?::(\w+))?\}`)
+
+// Default range for {number} when the configuration has no number_range
+const (
+	defaultCallsignNumberMin = 1
+	defaultCallsignNumberMax = 9999
+)
+
+// Generate expands the callsign format. {prefix} comes from the configuration, vars supply values such
+// as {id}, and {number}/{flight_number} and {name}/{airline}/{company}/{module} are drawn from rng using
+// number_range, names and modules. A ":04d" style suffix formats numeric values. It returns false when
+// the format is empty or a placeholder cannot be resolved, so callers can fall back to another callsign.
+func (c CallsignConfiguration) Generate(rng *rand.Rand, vars map[string]string) (string, bool) {
+	if c.Format == "" {
+		return "", false
+	}
+
+	// Each placeholder is drawn once, so repeated placeholders expand to the same value
+	drawn := make(map[string]string)
+	resolved := true
+	callsign := callsignPlaceholder.ReplaceAllStringFunc(c.Format, func(match string) string {
+		parts := callsignPlaceholder.FindStringSubmatch(match)
+		name, spec := parts[1], parts[2]
+
+		value, ok := drawn[name]
+		if !ok {
+			value, ok = c.resolvePlaceholder(name, rng, vars)
+			if !ok {
+				resolved = false
+				return match
+			}
+			drawn[name] = value
+		}
+		return formatCallsignValue(value, spec)
+	})
+	return callsign, resolved
+}
+
+// resolvePlaceholder returns the value of one callsign placeholder
+func (c CallsignConfiguration) resolvePlaceholder(name string, rng *rand.Rand, vars map[string]string) (string, bool) {
+	if value, ok := vars[name]; ok {
+		return value, true
+	}
+
+	switch name {
+	case "prefix":
+		return c.Prefix, true
+	case "number", "flight_number":
+		if rng == nil {
+			return "", false
+		}
+		low, high := defaultCallsignNumberMin, defaultCallsignNumberMax
+		if c.NumberRange[1] > 0 && c.NumberRange[1] >= c.NumberRange[0] {
+			low, high = c.NumberRange[0], c.NumberRange[1]
+		}
+		return strconv.Itoa(low + rng.Intn(high-low+1)), true
+	case "name", "airline", "company":
+		return pickCallsignValue(c.Names, rng)
+	case "module":
+		return pickCallsignValue(c.Modules, rng)
+	}
+	return "", false
+}
+
+// pickCallsignValue returns a random entry of values
+func pickCallsignValue(values []string, rng *rand.Rand) (string, bool) {
+	if len(values) == 0 || rng == nil {
+		return "", false
+	}
+	return values[rng.Intn(len(values))], true
+}
+
+// formatCallsignValue applies a printf verb such as "04d" to numeric values
+func formatCallsignValue(value, spec string) string {
+	if spec == "" {
+		return value
+	}
+	number, err := strconv.Atoi(value)
+	if err != nil {
+		return value
+	}
+	return fmt.Sprintf("%"+spec, number)
+}
@@ -0,0 +1,52 @@
+package models
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestCallsignConfigurationGenerate(t *testing.T) {
+	tests := []struct {
+		name   string
+		conf   CallsignConfiguration
+		vars   map[string]string
+		want   string // Exact call sign, when not drawn from the random source
+		wantOK bool
+	}{
+		{"prefix and id", CallsignConfiguration{Prefix: "NAVY", Format: "{prefix}{id}"}, map[string]string{"id": "51"}, "NAVY51", true},
+		{"padded variable", CallsignConfiguration{Format: "GPS-{number:02d}"}, map[string]string{"number": "7"}, "GPS-07", true},
+		{"fixed number range", CallsignConfiguration{Format: "TRUCK{number:04d}", NumberRange: [2]int{42, 42}}, nil, "TRUCK0042", true},
+		{"single name", CallsignConfiguration{Format: "{airline}{flight_number}", Names: []string{"KLM"}, NumberRange: [2]int{12, 12}}, nil, "KLM12", true},
+		{"unknown placeholder", CallsignConfiguration{Format: "DRAGON-{mission}"}, nil, "", false},
+		{"no names", CallsignConfiguration{Format: "{name}"}, nil, "", false},
+		{"empty format", CallsignConfiguration{Prefix: "UAL"}, nil, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := tt.conf.Generate(rand.New(rand.NewSource(1)), tt.vars)
+			if ok != tt.wantOK || (tt.wantOK && got != tt.want) {
+				t.Errorf("Generate() = %q, %v; want %q, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestCallsignConfigurationGenerate_Seeded(t *testing.T) {
+	conf := CallsignConfiguration{Format: "{airline}{number}", Names: []string{"AFR", "BAW", "DLH"}, NumberRange: [2]int{100, 999}}
+
+	first, _ := conf.Generate(rand.New(rand.NewSource(99)), nil)
+	second, _ := conf.Generate(rand.New(rand.NewSource(99)), nil)
+	if first != second {
+		t.Errorf("Expected the same call sign for the same seed, got %q and %q", first, second)
+	}
+	if len(first) != 6 {
+		t.Errorf("Expected a three letter airline and three digit number, got %q", first)
+	}
+
+	// Repeated placeholders expand to one drawn value
+	conf.Format = "{number}-{number}"
+	if callsign, _ := conf.Generate(rand.New(rand.NewSource(5)), nil); callsign[:3] != callsign[4:] {
+		t.Errorf("Expected repeated placeholders to match, got %q", callsign)
+	}
+}
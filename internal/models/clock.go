@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// Clock reports the current date and time. The simulation engine implements it with simulation time,
+// anchored at the scenario start time, so timestamps follow accelerated and paused runs.
+type Clock interface {
+	Now() time.Time
+}
+
+// WallClock is a Clock that reads the system time
+type WallClock struct{}
+
+// Now returns the current system time in UTC
+func (WallClock) Now() time.Time {
+	return time.Now().UTC()
+}
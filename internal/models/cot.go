@@ -0,0 +1,91 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CoTConfiguration describes how a platform type is reported as Cursor on Target
+type CoTConfiguration struct {
+	Type            string `yaml:"type,omitempty" json:"type,omitempty"`               // CoT type, e.g. "a-f-A-M-F-Q"
+	Icon            string `yaml:"icon,omitempty" json:"icon,omitempty"`               // MIL-STD-2525 symbol code
+	Affiliation     string `yaml:"affiliation,omitempty" json:"affiliation,omitempty"` // friend, hostile, neutral, unknown, ...
+	Dimension       string `yaml:"dimension,omitempty" json:"dimension,omitempty"`     // air, ground, sea, space, ...
+	BattleDimension string `yaml:"battle_dimension,omitempty" json:"battle_dimension,omitempty"`
+	CategoryCode    string `yaml:"category_code,omitempty" json:"category_code,omitempty"`
+}
+
+// affiliationCodes maps affiliation names to the CoT atom affiliation letter
+var affiliationCodes = map[string]string{
+	"pending":        "p",
+	"unknown":        "u",
+	"assumed_friend": "a",
+	"friend":         "f",
+	"neutral":        "n",
+	"suspect":        "s",
+	"hostile":        "h",
+	"joker":          "j",
+	"faker":          "k",
+	"none":           "o",
+}
+
+// AffiliationCode returns the CoT affiliation letter for an affiliation name such as "friend" or "hostile"
+func AffiliationCode(affiliation string) (string, bool) {
+	code, ok := affiliationCodes[strings.ToLower(affiliation)]
+	return code, ok
+}
+
+// ValidateAffiliation checks that an optional affiliation name is known
+func ValidateAffiliation(affiliation string) error {
+	if affiliation == "" {
+		return nil
+	}
+	if _, ok := AffiliationCode(affiliation); !ok {
+		return fmt.Errorf("unknown affiliation %q", affiliation)
+	}
+	return nil
+}
+
+// WithAffiliation returns a CoT atom type with its affiliation letter replaced, e.g. "a-f-A-M-F" to
+// "a-h-A-M-F" for "hostile". Types that are not atoms, and unknown affiliations, are returned unchanged.
+func WithAffiliation(cotType, affiliation string) string {
+	code, ok := AffiliationCode(affiliation)
+	parts := strings.Split(cotType, "-")
+	if !ok || len(parts) < 3 || parts[0] != "a" {
+		return cotType
+	}
+	parts[1] = code
+	return strings.Join(parts, "-")
+}
+
+// AffiliationOf returns the affiliation name encoded in a CoT atom type, or "unknown"
+func AffiliationOf(cotType string) string {
+	parts := strings.Split(cotType, "-")
+	if len(parts) >= 3 && parts[0] == "a" {
+		for name, code := range affiliationCodes {
+			if code == parts[1] {
+				return name
+			}
+		}
+	}
+	return "unknown"
+}
+
+// Validate checks the CoT type shape and that the affiliation, when given, matches the type
+func (c CoTConfiguration) Validate() error {
+	if err := ValidateAffiliation(c.Affiliation); err != nil {
+		return err
+	}
+	if c.Type == "" {
+		return nil
+	}
+
+	parts := strings.Split(c.Type, "-")
+	if len(parts) < 3 || parts[0] != "a" || len(parts[1]) != 1 {
+		return fmt.Errorf("CoT type %q must be an atom such as a-f-A-M-F", c.Type)
+	}
+	if c.Affiliation != "" && WithAffiliation(c.Type, c.Affiliation) != c.Type {
+		return fmt.Errorf("CoT type %q does not match affiliation %q", c.Type, c.Affiliation)
+	}
+	return nil
+}
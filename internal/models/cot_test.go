@@ -0,0 +1,49 @@
+package models
+
+import "testing"
+
+func TestWithAffiliation(t *testing.T) {
+	tests := []struct {
+		cotType, affiliation, want string
+	}{
+		{"a-f-A-M-F", "hostile", "a-h-A-M-F"},
+		{"a-n-G-U-C-V", "Friend", "a-f-G-U-C-V"},
+		{"a-f-A-M-F", "enemy", "a-f-A-M-F"}, // Unknown affiliation
+		{"b-m-p-w", "hostile", "b-m-p-w"},   // Not an atom
+		{"a-f", "hostile", "a-f"},           // Too short
+	}
+	for _, tt := range tests {
+		if got := WithAffiliation(tt.cotType, tt.affiliation); got != tt.want {
+			t.Errorf("WithAffiliation(%q, %q) = %q, want %q", tt.cotType, tt.affiliation, got, tt.want)
+		}
+	}
+
+	if AffiliationOf("a-h-S-U-S") != "hostile" || AffiliationOf("b-m-p-w") != "unknown" {
+		t.Error("Unexpected affiliation read from CoT type")
+	}
+}
+
+func TestCoTConfigurationValidate(t *testing.T) {
+	valid := []CoTConfiguration{
+		{},
+		{Type: "a-f-A-M-U", Affiliation: "friend"},
+		{Type: "a-n-P-U-S"},
+		{Affiliation: "neutral"},
+	}
+	for _, conf := range valid {
+		if err := conf.Validate(); err != nil {
+			t.Errorf("Expected %+v to be valid, got %v", conf, err)
+		}
+	}
+
+	invalid := []CoTConfiguration{
+		{Type: "a-f-A-M-U", Affiliation: "neutral"},
+		{Type: "fighter"},
+		{Affiliation: "enemy"},
+	}
+	for _, conf := range invalid {
+		if err := conf.Validate(); err == nil {
+			t.Errorf("Expected %+v to be invalid", conf)
+		}
+	}
+}
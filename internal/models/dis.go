@@ -0,0 +1,68 @@
+package models
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// DISConfiguration describes how a platform type is reported over DIS (IEEE 1278.1)
+type DISConfiguration struct {
+	EntityType string `yaml:"entity_type,omitempty" json:"entity_type,omitempty"` // kind.domain.country.category.subcategory.specific.extra, e.g. "1.2.225.1.3.3.0"
+}
+
+// DISEntityType is the seven field entity type record from the SISO-REF-010 enumerations
+type DISEntityType struct {
+	Kind        uint8
+	Domain      uint8
+	Country     uint16
+	Category    uint8
+	Subcategory uint8
+	Specific    uint8
+	Extra       uint8
+}
+
+// String formats the entity type in the dotted form used by SISO-REF-010
+func (t DISEntityType) String() string {
+	return fmt.Sprintf("%d.%d.%d.%d.%d.%d.%d", t.Kind, t.Domain, t.Country, t.Category, t.Subcategory, t.Specific, t.Extra)
+}
+
+// ParseDISEntityType parses a dotted entity type such as "1.2.225.1.3.3.0". Trailing fields may be
+// left off and default to zero.
+func ParseDISEntityType(entityType string) (DISEntityType, error) {
+	parts := strings.Split(entityType, ".")
+	if entityType == "" || len(parts) > 7 {
+		return DISEntityType{}, fmt.Errorf("DIS entity type %q must be up to seven dotted numbers", entityType)
+	}
+
+	var fields [7]uint64
+	for i, part := range parts {
+		bits := 8
+		if i == 2 {
+			bits = 16
+		}
+		value, err := strconv.ParseUint(part, 10, bits)
+		if err != nil {
+			return DISEntityType{}, fmt.Errorf("DIS entity type %q: field %d must be a number below %d", entityType, i+1, 1<<bits)
+		}
+		fields[i] = value
+	}
+	return DISEntityType{
+		Kind:        uint8(fields[0]),
+		Domain:      uint8(fields[1]),
+		Country:     uint16(fields[2]),
+		Category:    uint8(fields[3]),
+		Subcategory: uint8(fields[4]),
+		Specific:    uint8(fields[5]),
+		Extra:       uint8(fields[6]),
+	}, nil
+}
+
+// Validate checks the entity type, when given, is well formed
+func (c DISConfiguration) Validate() error {
+	if c.EntityType == "" {
+		return nil
+	}
+	_, err := ParseDISEntityType(c.EntityType)
+	return err
+}
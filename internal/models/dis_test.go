@@ -0,0 +1,25 @@
+package models
+
+import "testing"
+
+func TestParseDISEntityType(t *testing.T) {
+	entityType, err := ParseDISEntityType("1.2.225.1.3.3")
+	if err != nil {
+		t.Fatalf("ParseDISEntityType failed: %v", err)
+	}
+	if want := (DISEntityType{Kind: 1, Domain: 2, Country: 225, Category: 1, Subcategory: 3, Specific: 3}); entityType != want {
+		t.Errorf("ParseDISEntityType = %+v, want %+v", entityType, want)
+	}
+	if entityType.String() != "1.2.225.1.3.3.0" {
+		t.Errorf("String() = %q", entityType.String())
+	}
+
+	if err := (DISConfiguration{}).Validate(); err != nil {
+		t.Errorf("Expected an empty dis_config to be valid: %v", err)
+	}
+	for _, invalid := range []string{"1.2.225.1.3.3.0.0", "1.256", "1.2.65536", "1..2", "a-f-A"} {
+		if err := (DISConfiguration{EntityType: invalid}).Validate(); err == nil {
+			t.Errorf("Expected entity type %q to be rejected", invalid)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package models
+
+import (
+	"math"
+	"strings"
+	"time"
+)
+
+// ExternalInfo marks a platform reported by an outside feed, such as live CoT tracks. The
+// simulation shows external platforms but never moves, steers or resets them.
+type ExternalInfo struct {
+	Source string    `json:"source"` // Feed the track came from, e.g. "cot"
+	UID    string    `json:"uid"`    // Track identifier in the feed
+	Stale  time.Time `json:"stale"`  // When the track ages out without a newer report
+}
+
+// NewExternalPlatform creates a read-only platform for a track from an outside feed. The CoT type,
+// when given, is kept so the platform is reported with the same symbol it arrived with.
+func NewExternalPlatform(id, callSign string, platformType PlatformType, cotType string, info ExternalInfo) *UniversalPlatform {
+	typeDef := &PlatformTypeDefinition{
+		Class:    "External Track",
+		Category: "external",
+		CoTConf:  CoTConfiguration{Type: cotType, Affiliation: AffiliationOf(cotType)},
+	}
+	config := &PlatformConfiguration{
+		ID:   id,
+		Type: string(platformType),
+		Name: callSign,
+	}
+
+	platform := createBasePlatform(id, platformType, typeDef, config, Position{}, callSign, 0)
+	platform.External = &info
+	return platform
+}
+
+// IsExternal reports whether the platform is a read-only track from an outside feed
+func (up *UniversalPlatform) IsExternal() bool {
+	return up.External != nil
+}
+
+// IsExternalPlatform reports whether any platform is a read-only track from an outside feed
+func IsExternalPlatform(platform Platform) bool {
+	universalPlatform, ok := platform.(*UniversalPlatform)
+	return ok && universalPlatform.IsExternal()
+}
+
+// SetExternalState moves an external platform to a reported position, speed (m/s) and heading
+// (degrees true), as of the report time
+func (up *UniversalPlatform) SetExternalState(position Position, speed, heading float64, reported time.Time) {
+	headingRad := heading * math.Pi / 180
+	up.State.Position = position
+	up.State.Speed = speed
+	up.State.Heading = heading
+	up.State.Velocity = Velocity{
+		North: speed * math.Cos(headingRad),
+		East:  speed * math.Sin(headingRad),
+	}
+	up.State.Physics.Position = position
+	up.State.LastUpdated = reported
+	up.lastPosition = position
+}
+
+// PlatformTypeForCoT returns the platform type for the battle dimension of a CoT atom type,
+// e.g. airborne for "a-f-A-M-F". Unknown dimensions are treated as land.
+func PlatformTypeForCoT(cotType string) PlatformType {
+	parts := strings.Split(cotType, "-")
+	if len(parts) >= 3 && parts[0] == "a" {
+		switch parts[2] {
+		case "A":
+			return PlatformTypeAirborne
+		case "S", "U":
+			return PlatformTypeMaritime
+		case "P":
+			return PlatformTypeSpace
+		}
+	}
+	return PlatformTypeLand
+}
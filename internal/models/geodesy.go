@@ -0,0 +1,77 @@
+package models
+
+import "math"
+
+// WGS84 ellipsoid
+const (
+	WGS84SemiMajorAxis = 6378137.0         // meters
+	WGS84Flattening    = 1 / 298.257223563 // unitless
+	wgs84E2            = WGS84Flattening * (2 - WGS84Flattening)
+	wgs84SemiMinorAxis = WGS84SemiMajorAxis * (1 - WGS84Flattening)
+)
+
+// ECEF is an Earth-centered, Earth-fixed vector in meters (or m/s, m/s² for rates)
+type ECEF struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+	Z float64 `json:"z"`
+}
+
+// GeodeticToECEF converts a WGS84 latitude, longitude and height to ECEF coordinates
+func GeodeticToECEF(pos Position) ECEF {
+	lat := pos.Latitude * math.Pi / 180
+	lon := pos.Longitude * math.Pi / 180
+	sinLat, cosLat := math.Sincos(lat)
+	sinLon, cosLon := math.Sincos(lon)
+
+	n := WGS84SemiMajorAxis / math.Sqrt(1-wgs84E2*sinLat*sinLat)
+	return ECEF{
+		X: (n + pos.Altitude) * cosLat * cosLon,
+		Y: (n + pos.Altitude) * cosLat * sinLon,
+		Z: (n*(1-wgs84E2) + pos.Altitude) * sinLat,
+	}
+}
+
+// ECEFToGeodetic converts ECEF coordinates to a WGS84 latitude, longitude and height. Bowring's
+// estimate is refined twice, which is accurate to well under a millimeter up to geostationary heights.
+func ECEFToGeodetic(ecef ECEF) Position {
+	p := math.Hypot(ecef.X, ecef.Y)
+	lon := math.Atan2(ecef.Y, ecef.X)
+	if p < 1e-9 {
+		// On the polar axis
+		return Position{Latitude: math.Copysign(90, ecef.Z), Altitude: math.Abs(ecef.Z) - wgs84SemiMinorAxis}
+	}
+
+	ep2 := wgs84E2 / (1 - wgs84E2)
+	theta := math.Atan2(ecef.Z*WGS84SemiMajorAxis, p*wgs84SemiMinorAxis)
+	sinTheta, cosTheta := math.Sincos(theta)
+	lat := math.Atan2(ecef.Z+ep2*wgs84SemiMinorAxis*sinTheta*sinTheta*sinTheta,
+		p-wgs84E2*WGS84SemiMajorAxis*cosTheta*cosTheta*cosTheta)
+
+	var alt float64
+	for i := 0; i < 3; i++ {
+		sinLat, cosLat := math.Sincos(lat)
+		n := WGS84SemiMajorAxis / math.Sqrt(1-wgs84E2*sinLat*sinLat)
+		if math.Abs(cosLat) > 1e-10 {
+			alt = p/cosLat - n
+		} else {
+			alt = math.Abs(ecef.Z)/math.Abs(sinLat) - n*(1-wgs84E2)
+		}
+		if i < 2 {
+			lat = math.Atan2(ecef.Z, p*(1-wgs84E2*n/(n+alt)))
+		}
+	}
+	return Position{Latitude: lat * 180 / math.Pi, Longitude: lon * 180 / math.Pi, Altitude: alt}
+}
+
+// ENUToECEF rotates a local east, north, up vector at a latitude and longitude (degrees) into
+// ECEF axes, e.g. to express a platform's velocity geocentrically
+func ENUToECEF(latitude, longitude, east, north, up float64) ECEF {
+	sinLat, cosLat := math.Sincos(latitude * math.Pi / 180)
+	sinLon, cosLon := math.Sincos(longitude * math.Pi / 180)
+	return ECEF{
+		X: -sinLon*east - sinLat*cosLon*north + cosLat*cosLon*up,
+		Y: cosLon*east - sinLat*sinLon*north + cosLat*sinLon*up,
+		Z: cosLat*north + sinLat*up,
+	}
+}
@@ -0,0 +1,49 @@
+package models
+
+import (
+	"math"
+	"testing"
+)
+
+func TestGeodeticToECEF(t *testing.T) {
+	tests := []struct {
+		name string
+		pos  Position
+		want ECEF
+	}{
+		{"equator prime meridian", Position{}, ECEF{X: WGS84SemiMajorAxis}},
+		{"equator 90E at 1000m", Position{Longitude: 90, Altitude: 1000}, ECEF{Y: WGS84SemiMajorAxis + 1000}},
+		{"north pole", Position{Latitude: 90}, ECEF{Z: 6356752.314245}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := GeodeticToECEF(tt.pos)
+			if math.Abs(got.X-tt.want.X) > 1e-3 || math.Abs(got.Y-tt.want.Y) > 1e-3 || math.Abs(got.Z-tt.want.Z) > 1e-3 {
+				t.Errorf("GeodeticToECEF(%+v) = %+v, want %+v", tt.pos, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestECEFToGeodetic_RoundTrip(t *testing.T) {
+	for _, pos := range []Position{
+		{Latitude: 38.8977, Longitude: -77.0365, Altitude: 18},
+		{Latitude: -33.8688, Longitude: 151.2093, Altitude: 11000},
+		{Latitude: 51.6, Longitude: -179.9, Altitude: 408000},
+		{Latitude: -90, Longitude: 0, Altitude: 2800},
+		{Latitude: 0.05, Longitude: 75, Altitude: 35786000},
+	} {
+		got := ECEFToGeodetic(GeodeticToECEF(pos))
+		if math.Abs(got.Latitude-pos.Latitude) > 1e-9 || math.Abs(got.Longitude-pos.Longitude) > 1e-9 || math.Abs(got.Altitude-pos.Altitude) > 1e-3 {
+			t.Errorf("Round trip of %+v gave %+v", pos, got)
+		}
+	}
+}
+
+func TestENUToECEF(t *testing.T) {
+	// At lat 0, lon 0 east is +Y, north is +Z and up is +X
+	got := ENUToECEF(0, 0, 1, 2, 3)
+	if math.Abs(got.X-3) > 1e-12 || math.Abs(got.Y-1) > 1e-12 || math.Abs(got.Z-2) > 1e-12 {
+		t.Errorf("ENUToECEF = %+v, want {3 1 2}", got)
+	}
+}
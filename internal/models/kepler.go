@@ -0,0 +1,171 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// Earth's gravity for Keplerian orbits: WGS84 gravitational parameter and the EGM96 J2 term
+const (
+	EarthMu = 3.986004418e14 // m³/s²
+	EarthJ2 = 1.08262668e-3
+)
+
+// KeplerianElements are the classical elements of an orbit at an epoch
+type KeplerianElements struct {
+	SemiMajorAxis float64   // meters
+	Eccentricity  float64   // 0 = circular
+	Inclination   float64   // degrees
+	RAAN          float64   // Right ascension of the ascending node, degrees
+	ArgPerigee    float64   // Argument of perigee, degrees
+	MeanAnomaly   float64   // degrees
+	Epoch         time.Time // Zero: the elements hold when the platform first takes its orbit
+}
+
+// OrbitFromApsides returns the semi-major axis and eccentricity of an orbit from its apogee and
+// perigee altitudes in meters
+func OrbitFromApsides(apogee, perigee float64) (semiMajorAxis, eccentricity float64) {
+	ra := WGS84SemiMajorAxis + apogee
+	rp := WGS84SemiMajorAxis + perigee
+	return (ra + rp) / 2, (ra - rp) / (ra + rp)
+}
+
+// OrbitElements returns elements for the orbit a platform type flies, sized by its orbital altitude
+// or else its orbital period and oriented by the given angles in degrees. It reports false when the
+// type describes no orbit.
+func (pc PerformanceCharacteristics) OrbitElements(raan, argPerigee, meanAnomaly float64) (KeplerianElements, bool) {
+	elements := KeplerianElements{
+		Eccentricity: pc.Eccentricity,
+		Inclination:  pc.Inclination,
+		RAAN:         raan,
+		ArgPerigee:   argPerigee,
+		MeanAnomaly:  meanAnomaly,
+	}
+	switch {
+	case pc.OrbitalAltitude > 0:
+		elements.SemiMajorAxis = WGS84SemiMajorAxis + pc.OrbitalAltitude
+	case pc.OrbitalPeriod > 0:
+		meanMotion := 2 * math.Pi / pc.OrbitalPeriod
+		elements.SemiMajorAxis = math.Cbrt(EarthMu / (meanMotion * meanMotion))
+	default:
+		return elements, false
+	}
+	return elements, true
+}
+
+// KeplerOrbit propagates two-body Keplerian elements with the secular J2 drift of the node,
+// perigee and mean anomaly, so orbits precess and ground tracks walk west as they do in reality
+type KeplerOrbit struct {
+	elements KeplerianElements
+
+	// Mean motion and secular rates, rad/s
+	meanMotion, raanRate, argPerigeeRate float64
+
+	mu    sync.RWMutex
+	epoch time.Time
+}
+
+// NewKeplerOrbit checks the elements and computes their secular rates
+func NewKeplerOrbit(elements KeplerianElements) (*KeplerOrbit, error) {
+	a, e := elements.SemiMajorAxis, elements.Eccentricity
+	if e < 0 || e >= 1 {
+		return nil, fmt.Errorf("eccentricity must be at least 0 and below 1, got %v", e)
+	}
+	if perigee := a * (1 - e); perigee <= WGS84SemiMajorAxis {
+		return nil, fmt.Errorf("orbit with semi-major axis %.0f m and eccentricity %v passes below the surface", a, e)
+	}
+
+	n := math.Sqrt(EarthMu / (a * a * a))
+	p := a * (1 - e*e)
+	cosI := math.Cos(elements.Inclination * math.Pi / 180)
+	k := 0.75 * n * EarthJ2 * (WGS84SemiMajorAxis / p) * (WGS84SemiMajorAxis / p)
+	return &KeplerOrbit{
+		elements:       elements,
+		meanMotion:     n + k*math.Sqrt(1-e*e)*(3*cosI*cosI-1),
+		raanRate:       -2 * k * cosI,
+		argPerigeeRate: k * (5*cosI*cosI - 1),
+		epoch:          elements.Epoch,
+	}, nil
+}
+
+// Elements returns the elements, with the epoch they were anchored to
+func (k *KeplerOrbit) Elements() KeplerianElements {
+	elements := k.elements
+	elements.Epoch = k.Epoch()
+	return elements
+}
+
+// Period returns the time between passes of the ascending node
+func (k *KeplerOrbit) Period() time.Duration {
+	return time.Duration(2 * math.Pi / (k.meanMotion + k.argPerigeeRate) * float64(time.Second))
+}
+
+// Epoch returns the time the elements hold at, zero until anchored
+func (k *KeplerOrbit) Epoch() time.Time {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return k.epoch
+}
+
+// Anchor sets the epoch of elements given without one; later calls have no effect
+func (k *KeplerOrbit) Anchor(t time.Time) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if k.epoch.IsZero() {
+		k.epoch = t
+	}
+}
+
+// Propagate returns the geodetic position and Earth-fixed velocity at t, anchoring the elements at
+// t if they have no epoch yet
+func (k *KeplerOrbit) Propagate(t time.Time) (Position, Velocity, error) {
+	k.Anchor(t)
+	dt := t.Sub(k.Epoch()).Seconds()
+
+	const deg2rad = math.Pi / 180
+	e := k.elements.Eccentricity
+	a := k.elements.SemiMajorAxis
+	raan := k.elements.RAAN*deg2rad + k.raanRate*dt
+	argp := k.elements.ArgPerigee*deg2rad + k.argPerigeeRate*dt
+	meanAnomaly := math.Mod(k.elements.MeanAnomaly*deg2rad+k.meanMotion*dt, 2*math.Pi)
+
+	// Kepler's equation by Newton's method
+	eccentricAnomaly := meanAnomaly
+	if e > 0.8 {
+		eccentricAnomaly = math.Pi
+	}
+	for i := 0; i < 20; i++ {
+		step := (eccentricAnomaly - e*math.Sin(eccentricAnomaly) - meanAnomaly) / (1 - e*math.Cos(eccentricAnomaly))
+		eccentricAnomaly -= step
+		if math.Abs(step) < 1e-12 {
+			break
+		}
+	}
+
+	// Position and velocity in the orbital plane, x toward perigee
+	sinE, cosE := math.Sincos(eccentricAnomaly)
+	root := math.Sqrt(1 - e*e)
+	x := a * (cosE - e)
+	y := a * root * sinE
+	rate := k.meanMotion / (1 - e*cosE) // dE/dt
+	vx := -a * sinE * rate
+	vy := a * root * cosE * rate
+
+	// Rotate by the argument of perigee, inclination and node into the inertial frame of date
+	sinW, cosW := math.Sincos(argp)
+	sinI, cosI := math.Sincos(k.elements.Inclination * deg2rad)
+	sinO, cosO := math.Sincos(raan)
+	toInertial := func(px, py float64) [3]float64 {
+		xw := cosW*px - sinW*py
+		yw := sinW*px + cosW*py
+		return [3]float64{
+			(cosO*xw - sinO*cosI*yw) / 1000,
+			(sinO*xw + cosO*cosI*yw) / 1000,
+			sinI * yw / 1000,
+		}
+	}
+	position, velocity := TEMEToGeodetic(toInertial(x, y), toInertial(vx, vy), t)
+	return position, velocity, nil
+}
@@ -0,0 +1,168 @@
+package models
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestKeplerOrbit_Circular(t *testing.T) {
+	orbit, err := NewKeplerOrbit(KeplerianElements{SemiMajorAxis: WGS84SemiMajorAxis + 500e3, Inclination: 51.6})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Two-body period is 5677 s; J2 shortens the nodal period by a few seconds
+	if period := orbit.Period().Seconds(); math.Abs(period-5677) > 15 {
+		t.Errorf("Period = %.0f s, want about 5677 s", period)
+	}
+
+	start := time.Date(2025, 6, 4, 6, 0, 0, 0, time.UTC)
+	maxLatitude := 0.0
+	for minute := 0; minute <= 100; minute++ {
+		position, velocity, err := orbit.Propagate(start.Add(time.Duration(minute) * time.Minute))
+		if err != nil {
+			t.Fatal(err)
+		}
+		// The geodetic altitude of a circular orbit only rises with the ellipsoid's flattening
+		if position.Altitude < 499e3 || position.Altitude > 522e3 {
+			t.Fatalf("Minute %d: altitude %.0f m off the orbit", minute, position.Altitude)
+		}
+		if position.Longitude < -180 || position.Longitude > 180 {
+			t.Fatalf("Minute %d: longitude %v out of range", minute, position.Longitude)
+		}
+		if speed := math.Sqrt(velocity.North*velocity.North + velocity.East*velocity.East); speed < 6.8e3 || speed > 7.8e3 {
+			t.Fatalf("Minute %d: ground-relative speed %.0f m/s", minute, speed)
+		}
+		maxLatitude = math.Max(maxLatitude, math.Abs(position.Latitude))
+	}
+	if maxLatitude < 50 || maxLatitude > 52 {
+		t.Errorf("Ground track reaches %.1f°, want about the 51.6° inclination", maxLatitude)
+	}
+}
+
+func TestKeplerOrbit_Anchor(t *testing.T) {
+	orbit, err := NewKeplerOrbit(KeplerianElements{SemiMajorAxis: WGS84SemiMajorAxis + 550e3, Inclination: 53, MeanAnomaly: 90})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !orbit.Epoch().IsZero() {
+		t.Fatal("Elements without an epoch should not be anchored yet")
+	}
+
+	start := time.Date(2025, 6, 4, 6, 0, 0, 0, time.UTC)
+	first, _, err := orbit.Propagate(start)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !orbit.Epoch().Equal(start) {
+		t.Errorf("Epoch = %v, want the first propagation time %v", orbit.Epoch(), start)
+	}
+
+	// A quarter past the node the satellite is at its northernmost point
+	if math.Abs(first.Latitude-53) > 0.5 {
+		t.Errorf("Latitude at a mean anomaly of 90° is %.2f, want about 53", first.Latitude)
+	}
+
+	// Later propagation keeps the anchor, so the same time gives the same position
+	if _, _, err := orbit.Propagate(start.Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	again, _, _ := orbit.Propagate(start)
+	if again != first {
+		t.Errorf("Position moved after re-anchoring: %+v, want %+v", again, first)
+	}
+}
+
+func TestKeplerOrbit_SunSynchronous(t *testing.T) {
+	// At 700 km and 98.19° the node follows the mean sun, 0.9856° a day eastward
+	orbit, err := NewKeplerOrbit(KeplerianElements{SemiMajorAxis: WGS84SemiMajorAxis + 700e3, Inclination: 98.19})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rate := orbit.raanRate * 86400 * 180 / math.Pi; math.Abs(rate-0.9856) > 0.01 {
+		t.Errorf("Node drift = %.4f°/day, want 0.9856", rate)
+	}
+
+	// Polar orbits do not precess
+	polar, _ := NewKeplerOrbit(KeplerianElements{SemiMajorAxis: WGS84SemiMajorAxis + 700e3, Inclination: 90})
+	if math.Abs(polar.raanRate) > 1e-15 {
+		t.Errorf("Polar node drift = %v rad/s, want 0", polar.raanRate)
+	}
+}
+
+func TestKeplerOrbit_Eccentric(t *testing.T) {
+	// Molniya orbit: 12 h period, perigee 500 km, apogee near 39,900 km
+	semiMajorAxis, eccentricity := OrbitFromApsides(39900e3, 500e3)
+	orbit, err := NewKeplerOrbit(KeplerianElements{
+		SemiMajorAxis: semiMajorAxis,
+		Eccentricity:  eccentricity,
+		Inclination:   63.4,
+		ArgPerigee:    270,
+		Epoch:         time.Date(2025, 6, 4, 0, 0, 0, 0, time.UTC),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if period := orbit.Period().Hours(); math.Abs(period-12) > 0.05 {
+		t.Errorf("Period = %.2f h, want about 12", period)
+	}
+
+	lowest, highest := math.Inf(1), 0.0
+	for minute := 0; minute < 720; minute += 2 {
+		position, _, err := orbit.Propagate(orbit.Epoch().Add(time.Duration(minute) * time.Minute))
+		if err != nil {
+			t.Fatal(err)
+		}
+		lowest = math.Min(lowest, position.Altitude)
+		highest = math.Max(highest, position.Altitude)
+	}
+	if math.Abs(lowest-500e3) > 50e3 || math.Abs(highest-39900e3) > 50e3 {
+		t.Errorf("Altitude ranges %.0f to %.0f km, want 500 to 39,900 km", lowest/1000, highest/1000)
+	}
+}
+
+func TestNewKeplerOrbit_Invalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		elements KeplerianElements
+	}{
+		{"hyperbolic", KeplerianElements{SemiMajorAxis: 8000e3, Eccentricity: 1}},
+		{"negative eccentricity", KeplerianElements{SemiMajorAxis: 8000e3, Eccentricity: -0.1}},
+		{"underground", KeplerianElements{SemiMajorAxis: 6000e3}},
+		{"perigee below the surface", KeplerianElements{SemiMajorAxis: 8000e3, Eccentricity: 0.5}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewKeplerOrbit(tt.elements); err == nil {
+				t.Error("Expected the orbit to be rejected")
+			}
+		})
+	}
+}
+
+func TestSpacePlatform_UpdateFollowsApsides(t *testing.T) {
+	satellite := NewStarlinkSatellite("STARLINK-1234", "1234", Position{Longitude: 10})
+	satellite.State.LastUpdated = time.Date(2025, 6, 4, 6, 0, 0, 0, time.UTC)
+	if err := satellite.Update(0); err != nil {
+		t.Fatal(err)
+	}
+	start := satellite.GetState().Position
+	if math.Abs(start.Latitude) > 1e-6 || math.Abs(start.Longitude-10) > 1e-6 {
+		t.Fatalf("Expected the orbit to start over the platform's position, got %+v", start)
+	}
+
+	// A quarter of an orbit later the satellite is at the inclination's latitude, further east
+	for i := 0; i < 24; i++ {
+		if err := satellite.Update(time.Minute); err != nil {
+			t.Fatal(err)
+		}
+	}
+	state := satellite.GetState()
+	if math.Abs(state.Position.Latitude-53) > 1 || state.Position.Longitude < 40 {
+		t.Errorf("Unexpected position %+v after a quarter orbit", state.Position)
+	}
+	if math.Abs(state.Position.Altitude-550e3) > 25e3 {
+		t.Errorf("Altitude = %.0f m, want about 550 km", state.Position.Altitude)
+	}
+}
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"math"
+	"time"
+)
+
+// OrbitPropagator gives a space platform's position and Earth-fixed velocity at any time, such as
+// an SGP4 propagator for a two-line element set or a KeplerOrbit for classical elements
+type OrbitPropagator interface {
+	Propagate(t time.Time) (Position, Velocity, error)
+}
+
+// UpdateOrbit moves a platform with an orbit propagator to its position and velocity at t. Speed
+// and heading describe the motion over the ground, as for every other platform.
+func (up *UniversalPlatform) UpdateOrbit(t time.Time) error {
+	position, velocity, err := up.Orbit.Propagate(t)
+	if err != nil {
+		return err
+	}
+	up.State.Position = position
+	up.State.Velocity = velocity
+	up.State.Speed = math.Hypot(velocity.North, velocity.East)
+	up.State.Heading = math.Mod(math.Atan2(velocity.East, velocity.North)*180/math.Pi+360, 360)
+	up.State.Physics.Position = position
+	up.State.LastUpdated = t
+	up.lastPosition = position
+	return nil
+}
+
+// IsOrbiting reports whether the platform's motion comes from an orbit propagator
+func (up *UniversalPlatform) IsOrbiting() bool {
+	return up.Orbit != nil
+}
@@ -195,6 +195,8 @@ type PlatformTypeDefinition struct {
 	Operational  OperationalCharacteristics `yaml:"operational"`
 	Sensors      SensorCharacteristics      `yaml:"sensors"`
 	CallsignConf CallsignConfiguration      `yaml:"callsign_config"`
+	CoTConf      CoTConfiguration           `yaml:"cot_config"`
+	DISConf      DISConfiguration           `yaml:"dis_config"`
 }
 
 // SensorCharacteristics defines sensor capabilities
@@ -221,6 +223,11 @@ type PlatformConfiguration struct {
 	Name          string               `yaml:"name"`
 	StartPosition Position             `yaml:"start_position"`
 	Mission       MissionConfiguration `yaml:"mission"`
+	Affiliation   string               `yaml:"affiliation,omitempty"`  // Overrides the type's CoT affiliation
+	ICAOAddress   string               `yaml:"icao_address,omitempty"` // Overrides the derived ADS-B address
+	Squawk        string               `yaml:"squawk,omitempty"`       // Overrides the derived squawk
+	MMSI          string               `yaml:"mmsi,omitempty"`         // Overrides the derived AIS MMSI
+	AISDark       bool                 `yaml:"ais_dark,omitempty"`     // Vessel does not transmit AIS
 }
 
 // UniversalPlatform implements the Platform interface using configuration data
@@ -235,6 +242,13 @@ type UniversalPlatform struct {
 	// Navigation
 	Destination *Position  `json:"destination,omitempty"`
 	Route       []Position `json:"route,omitempty"`
+	RoutePlan   *RoutePlan `json:"route_plan,omitempty"`
+
+	// Set for read-only tracks from an outside feed
+	External *ExternalInfo `json:"external,omitempty"`
+
+	// Set for space platforms that follow an orbit, e.g. from a TLE
+	Orbit OrbitPropagator `json:"-"`
 
 	// Runtime state
 	FuelRemaining float64       `json:"fuel_remaining"`
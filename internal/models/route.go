@@ -0,0 +1,86 @@
+package models
+
+import "fmt"
+
+// RouteMode defines what a platform does after reaching the last waypoint of its route
+type RouteMode string
+
+const (
+	RouteModeOnce    RouteMode = "once"    // Stop at the final waypoint
+	RouteModeLoop    RouteMode = "loop"    // Continue from the first waypoint again
+	RouteModeReverse RouteMode = "reverse" // Retrace the route back to the first waypoint
+	RouteModeHold    RouteMode = "hold"    // Keep station at the final waypoint
+)
+
+// ParseRouteMode converts a configuration string to a RouteMode (empty means once)
+func ParseRouteMode(mode string) (RouteMode, error) {
+	switch RouteMode(mode) {
+	case "", RouteModeOnce:
+		return RouteModeOnce, nil
+	case RouteModeLoop, RouteModeReverse, RouteModeHold:
+		return RouteMode(mode), nil
+	default:
+		return "", fmt.Errorf("unknown route mode: %s", mode)
+	}
+}
+
+// RoutePlan tracks how a platform is progressing along its Route
+type RoutePlan struct {
+	Mode          RouteMode `json:"mode"`
+	Speeds        []float64 `json:"speeds,omitempty"` // m/s per leg toward Route[i], 0 uses cruise speed
+	CurrentIndex  int       `json:"current_index"`    // Index of the waypoint being steered toward
+	Direction     int       `json:"direction"`        // 1 forward, -1 while retracing in reverse mode
+	LapsCompleted int       `json:"laps_completed"`
+	MaxLaps       int       `json:"max_laps,omitempty"` // Loop/reverse laps before holding, 0 = unlimited
+	Completed     bool      `json:"completed"`
+}
+
+// SetRoute assigns waypoints and starts steering toward the first one
+func (up *UniversalPlatform) SetRoute(waypoints []Position, mode RouteMode, speeds []float64) error {
+	if len(waypoints) == 0 {
+		return fmt.Errorf("route for platform %s has no waypoints", up.ID)
+	}
+
+	if _, err := ParseRouteMode(string(mode)); err != nil {
+		return err
+	}
+	if mode == "" {
+		mode = RouteModeOnce
+	}
+
+	up.Route = append([]Position(nil), waypoints...)
+	up.RoutePlan = &RoutePlan{
+		Mode:      mode,
+		Speeds:    append([]float64(nil), speeds...),
+		Direction: 1,
+	}
+
+	return up.SetDestination(up.Route[0])
+}
+
+// ResetRoute restarts the route from its first waypoint
+func (up *UniversalPlatform) ResetRoute() {
+	if up.RoutePlan == nil || len(up.Route) == 0 {
+		return
+	}
+
+	up.RoutePlan.CurrentIndex = 0
+	up.RoutePlan.Direction = 1
+	up.RoutePlan.LapsCompleted = 0
+	up.RoutePlan.Completed = false
+	waypoint := up.Route[0]
+	up.Destination = &waypoint
+}
+
+// CurrentLegSpeed returns the target speed for the current leg, or 0 when unset
+func (up *UniversalPlatform) CurrentLegSpeed() float64 {
+	if up.RoutePlan == nil || up.RoutePlan.Completed {
+		return 0
+	}
+
+	index := up.RoutePlan.CurrentIndex
+	if index < 0 || index >= len(up.RoutePlan.Speeds) {
+		return 0
+	}
+	return up.RoutePlan.Speeds[index]
+}
@@ -0,0 +1,463 @@
+package models
+
+import "math"
+
+// Lunar and solar constants of SDP4
+const (
+	sdp4ZNS   = 1.19459e-5 // Solar mean motion, rad/min
+	sdp4ZES   = 0.01675    // Solar eccentricity
+	sdp4ZNL   = 1.5835218e-4
+	sdp4ZEL   = 0.05490
+	sdp4RPTim = 4.37526908801129966e-3 // Earth rotation, rad/min
+)
+
+// deepSpaceTerms are the lunar and solar coefficients shared by the deep space initialization
+type deepSpaceTerms struct {
+	sinim, cosim, emsq                   float64
+	s1, s2, s3, s4, s5                   float64
+	ss1, ss2, ss3, ss4, ss5              float64
+	sz1, sz3, sz11, sz13, sz21, sz23     float64
+	sz31, sz33                           float64
+	z1, z3, z11, z13, z21, z23, z31, z33 float64
+}
+
+// deepSpaceCommon computes the lunar and solar perturbation coefficients at tc minutes from the
+// epoch and stores the periodic ones, as Vallado's dscom
+func (s *SGP4) deepSpaceCommon(tc, ep, inclp, nodep, argpp, np float64) deepSpaceTerms {
+	const (
+		c1ss   = 2.9864797e-6
+		c1l    = 4.7968065e-7
+		zsinis = 0.39785416
+		zcosis = 0.91744867
+		zcosgs = 0.1945905
+		zsings = -0.98088458
+	)
+
+	var d deepSpaceTerms
+	nm := np
+	em := ep
+	snodm, cnodm := math.Sin(nodep), math.Cos(nodep)
+	sinomm, cosomm := math.Sin(argpp), math.Cos(argpp)
+	d.sinim, d.cosim = math.Sin(inclp), math.Cos(inclp)
+	d.emsq = em * em
+	betasq := 1 - d.emsq
+	rtemsq := math.Sqrt(betasq)
+
+	// Lunar orbit at the epoch
+	day := s.epochJD - 2433281.5 + 18261.5 + tc/minutesPerDay
+	xnodce := math.Mod(4.5236020-9.2422029e-4*day, twoPi)
+	stem, ctem := math.Sin(xnodce), math.Cos(xnodce)
+	zcosil := 0.91375164 - 0.03568096*ctem
+	zsinil := math.Sqrt(1 - zcosil*zcosil)
+	zsinhl := 0.089683511 * stem / zsinil
+	zcoshl := math.Sqrt(1 - zsinhl*zsinhl)
+	gam := 5.8351514 + 0.0019443680*day
+	zx := 0.39785416 * stem / zsinil
+	zy := zcoshl*ctem + 0.91744867*zsinhl*stem
+	zx = math.Atan2(zx, zy)
+	zx = gam + zx - xnodce
+	zcosgl, zsingl := math.Cos(zx), math.Sin(zx)
+
+	// Solar terms on the first pass, lunar on the second
+	zcosg, zsing := zcosgs, zsings
+	zcosi, zsini := zcosis, zsinis
+	zcosh, zsinh := cnodm, snodm
+	cc := c1ss
+	xnoi := 1 / nm
+	var s6, s7, ss6, ss7, z2, z12, z22, z32, sz2, sz12, sz22, sz32 float64
+	for lsflg := 1; lsflg <= 2; lsflg++ {
+		a1 := zcosg*zcosh + zsing*zcosi*zsinh
+		a3 := -zsing*zcosh + zcosg*zcosi*zsinh
+		a7 := -zcosg*zsinh + zsing*zcosi*zcosh
+		a8 := zsing * zsini
+		a9 := zsing*zsinh + zcosg*zcosi*zcosh
+		a10 := zcosg * zsini
+		a2 := d.cosim*a7 + d.sinim*a8
+		a4 := d.cosim*a9 + d.sinim*a10
+		a5 := -d.sinim*a7 + d.cosim*a8
+		a6 := -d.sinim*a9 + d.cosim*a10
+
+		x1 := a1*cosomm + a2*sinomm
+		x2 := a3*cosomm + a4*sinomm
+		x3 := -a1*sinomm + a2*cosomm
+		x4 := -a3*sinomm + a4*cosomm
+		x5 := a5 * sinomm
+		x6 := a6 * sinomm
+		x7 := a5 * cosomm
+		x8 := a6 * cosomm
+
+		d.z31 = 12*x1*x1 - 3*x3*x3
+		z32 = 24*x1*x2 - 6*x3*x4
+		d.z33 = 12*x2*x2 - 3*x4*x4
+		d.z1 = 3*(a1*a1+a2*a2) + d.z31*d.emsq
+		z2 = 6*(a1*a3+a2*a4) + z32*d.emsq
+		d.z3 = 3*(a3*a3+a4*a4) + d.z33*d.emsq
+		d.z11 = -6*a1*a5 + d.emsq*(-24*x1*x7-6*x3*x5)
+		z12 = -6*(a1*a6+a3*a5) + d.emsq*(-24*(x2*x7+x1*x8)-6*(x3*x6+x4*x5))
+		d.z13 = -6*a3*a6 + d.emsq*(-24*x2*x8-6*x4*x6)
+		d.z21 = 6*a2*a5 + d.emsq*(24*x1*x5-6*x3*x7)
+		z22 = 6*(a4*a5+a2*a6) + d.emsq*(24*(x2*x5+x1*x6)-6*(x4*x7+x3*x8))
+		d.z23 = 6*a4*a6 + d.emsq*(24*x2*x6-6*x4*x8)
+		d.z1 = d.z1 + d.z1 + betasq*d.z31
+		z2 = z2 + z2 + betasq*z32
+		d.z3 = d.z3 + d.z3 + betasq*d.z33
+		d.s3 = cc * xnoi
+		d.s2 = -0.5 * d.s3 / rtemsq
+		d.s4 = d.s3 * rtemsq
+		d.s1 = -15 * em * d.s4
+		d.s5 = x1*x3 + x2*x4
+		s6 = x2*x3 + x1*x4
+		s7 = x2*x4 - x1*x3
+
+		if lsflg == 1 {
+			d.ss1, d.ss2, d.ss3, d.ss4, d.ss5, ss6, ss7 = d.s1, d.s2, d.s3, d.s4, d.s5, s6, s7
+			d.sz1, sz2, d.sz3 = d.z1, z2, d.z3
+			d.sz11, sz12, d.sz13 = d.z11, z12, d.z13
+			d.sz21, sz22, d.sz23 = d.z21, z22, d.z23
+			d.sz31, sz32, d.sz33 = d.z31, z32, d.z33
+			zcosg, zsing = zcosgl, zsingl
+			zcosi, zsini = zcosil, zsinil
+			zcosh = zcoshl*cnodm + zsinhl*snodm
+			zsinh = snodm*zcoshl - cnodm*zsinhl
+			cc = c1l
+		}
+	}
+
+	s.zmol = math.Mod(4.7199672+0.22997150*day-gam, twoPi)
+	s.zmos = math.Mod(6.2565837+0.017201977*day, twoPi)
+
+	// Solar periodic coefficients
+	s.se2 = 2 * d.ss1 * ss6
+	s.se3 = 2 * d.ss1 * ss7
+	s.si2 = 2 * d.ss2 * sz12
+	s.si3 = 2 * d.ss2 * (d.sz13 - d.sz11)
+	s.sl2 = -2 * d.ss3 * sz2
+	s.sl3 = -2 * d.ss3 * (d.sz3 - d.sz1)
+	s.sl4 = -2 * d.ss3 * (-21 - 9*d.emsq) * sdp4ZES
+	s.sgh2 = 2 * d.ss4 * sz32
+	s.sgh3 = 2 * d.ss4 * (d.sz33 - d.sz31)
+	s.sgh4 = -18 * d.ss4 * sdp4ZES
+	s.sh2 = -2 * d.ss2 * sz22
+	s.sh3 = -2 * d.ss2 * (d.sz23 - d.sz21)
+
+	// Lunar periodic coefficients
+	s.ee2 = 2 * d.s1 * s6
+	s.e3 = 2 * d.s1 * s7
+	s.xi2 = 2 * d.s2 * z12
+	s.xi3 = 2 * d.s2 * (d.z13 - d.z11)
+	s.xl2 = -2 * d.s3 * z2
+	s.xl3 = -2 * d.s3 * (d.z3 - d.z1)
+	s.xl4 = -2 * d.s3 * (-21 - 9*d.emsq) * sdp4ZEL
+	s.xgh2 = 2 * d.s4 * z32
+	s.xgh3 = 2 * d.s4 * (d.z33 - d.z31)
+	s.xgh4 = -18 * d.s4 * sdp4ZEL
+	s.xh2 = -2 * d.s2 * z22
+	s.xh3 = -2 * d.s2 * (d.z23 - d.z21)
+	return d
+}
+
+// deepSpaceInit computes the secular lunar and solar rates and the resonance coefficients of 12 hour
+// and geosynchronous orbits, as Vallado's dsinit at the epoch
+func (s *SGP4) deepSpaceInit(d deepSpaceTerms, eccsq, xpidot float64) {
+	const (
+		q22    = 1.7891679e-6
+		q31    = 2.1460748e-6
+		q33    = 2.2123015e-7
+		root22 = 1.7891679e-6
+		root44 = 7.3636953e-9
+		root54 = 2.1765803e-9
+		root32 = 3.7393792e-7
+		root52 = 1.1428639e-7
+		x2o3   = 2.0 / 3.0
+	)
+
+	nm := s.noUnkozai
+	em := s.ecco
+	inclm := s.inclo
+	switch {
+	case nm < 0.0052359877 && nm > 0.0034906585:
+		s.irez = 1 // Geosynchronous
+	case nm >= 8.26e-3 && nm <= 9.24e-3 && em >= 0.5:
+		s.irez = 2 // Half day, e.g. Molniya
+	}
+
+	// Solar secular terms
+	ses := d.ss1 * sdp4ZNS * d.ss5
+	sis := d.ss2 * sdp4ZNS * (d.sz11 + d.sz13)
+	sls := -sdp4ZNS * d.ss3 * (d.sz1 + d.sz3 - 14 - 6*d.emsq)
+	sghs := d.ss4 * sdp4ZNS * (d.sz31 + d.sz33 - 6)
+	shs := -sdp4ZNS * d.ss2 * (d.sz21 + d.sz23)
+	if inclm < 5.2359877e-2 || inclm > math.Pi-5.2359877e-2 {
+		shs = 0
+	}
+	if d.sinim != 0 {
+		shs /= d.sinim
+	}
+	sgs := sghs - d.cosim*shs
+
+	// Lunar secular terms
+	s.dedt = ses + d.s1*sdp4ZNL*d.s5
+	s.didt = sis + d.s2*sdp4ZNL*(d.z11+d.z13)
+	s.dmdt = sls - sdp4ZNL*d.s3*(d.z1+d.z3-14-6*d.emsq)
+	sghl := d.s4 * sdp4ZNL * (d.z31 + d.z33 - 6)
+	shll := -sdp4ZNL * d.s2 * (d.z21 + d.z23)
+	if inclm < 5.2359877e-2 || inclm > math.Pi-5.2359877e-2 {
+		shll = 0
+	}
+	s.domdt = sgs + sghl
+	s.dnodt = shs
+	if d.sinim != 0 {
+		s.domdt -= d.cosim / d.sinim * shll
+		s.dnodt += shll / d.sinim
+	}
+
+	if s.irez == 0 {
+		return
+	}
+	theta := math.Mod(s.gsto, twoPi)
+	aonv := math.Pow(nm/sgp4XKE, x2o3)
+
+	if s.irez == 2 {
+		// Geopotential resonance of 12 hour orbits
+		cosisq := d.cosim * d.cosim
+		emsq := eccsq
+		eoc := em * emsq
+		g201 := -0.306 - (em-0.64)*0.440
+		var g211, g310, g322, g410, g422, g520, g521, g532, g533 float64
+		if em <= 0.65 {
+			g211 = 3.616 - 13.2470*em + 16.2900*emsq
+			g310 = -19.302 + 117.3900*em - 228.4190*emsq + 156.5910*eoc
+			g322 = -18.9068 + 109.7927*em - 214.6334*emsq + 146.5816*eoc
+			g410 = -41.122 + 242.6940*em - 471.0940*emsq + 313.9530*eoc
+			g422 = -146.407 + 841.8800*em - 1629.014*emsq + 1083.4350*eoc
+			g520 = -532.114 + 3017.977*em - 5740.032*emsq + 3708.2760*eoc
+		} else {
+			g211 = -72.099 + 331.819*em - 508.738*emsq + 266.724*eoc
+			g310 = -346.844 + 1582.851*em - 2415.925*emsq + 1246.113*eoc
+			g322 = -342.585 + 1554.908*em - 2366.899*emsq + 1215.972*eoc
+			g410 = -1052.797 + 4758.686*em - 7193.992*emsq + 3651.957*eoc
+			g422 = -3581.690 + 16178.110*em - 24462.770*emsq + 12422.520*eoc
+			if em > 0.715 {
+				g520 = -5149.66 + 29936.92*em - 54087.36*emsq + 31324.56*eoc
+			} else {
+				g520 = 1464.74 - 4664.75*em + 3763.64*emsq
+			}
+		}
+		if em < 0.7 {
+			g533 = -919.22770 + 4988.6100*em - 9064.7700*emsq + 5542.21*eoc
+			g521 = -822.71072 + 4568.6173*em - 8491.4146*emsq + 5337.524*eoc
+			g532 = -853.66600 + 4690.2500*em - 8624.7700*emsq + 5341.4*eoc
+		} else {
+			g533 = -37995.780 + 161616.52*em - 229838.20*emsq + 109377.94*eoc
+			g521 = -51752.104 + 218913.95*em - 309468.16*emsq + 146349.42*eoc
+			g532 = -40023.880 + 170470.89*em - 242699.48*emsq + 115605.82*eoc
+		}
+
+		sini2 := d.sinim * d.sinim
+		cosim := d.cosim
+		sinim := d.sinim
+		f220 := 0.75 * (1 + 2*cosim + cosisq)
+		f221 := 1.5 * sini2
+		f321 := 1.875 * sinim * (1 - 2*cosim - 3*cosisq)
+		f322 := -1.875 * sinim * (1 + 2*cosim - 3*cosisq)
+		f441 := 35 * sini2 * f220
+		f442 := 39.3750 * sini2 * sini2
+		f522 := 9.84375 * sinim * (sini2*(1-2*cosim-5*cosisq) + 0.33333333*(-2+4*cosim+6*cosisq))
+		f523 := sinim * (4.92187512*sini2*(-2-4*cosim+10*cosisq) + 6.56250012*(1+2*cosim-3*cosisq))
+		f542 := 29.53125 * sinim * (2 - 8*cosim + cosisq*(-12+8*cosim+10*cosisq))
+		f543 := 29.53125 * sinim * (-2 - 8*cosim + cosisq*(12+8*cosim-10*cosisq))
+		xno2 := nm * nm
+		ainv2 := aonv * aonv
+		temp1 := 3 * xno2 * ainv2
+		temp := temp1 * root22
+		s.d2201 = temp * f220 * g201
+		s.d2211 = temp * f221 * g211
+		temp1 *= aonv
+		temp = temp1 * root32
+		s.d3210 = temp * f321 * g310
+		s.d3222 = temp * f322 * g322
+		temp1 *= aonv
+		temp = 2 * temp1 * root44
+		s.d4410 = temp * f441 * g410
+		s.d4422 = temp * f442 * g422
+		temp1 *= aonv
+		temp = temp1 * root52
+		s.d5220 = temp * f522 * g520
+		s.d5232 = temp * f523 * g532
+		temp = 2 * temp1 * root54
+		s.d5421 = temp * f542 * g521
+		s.d5433 = temp * f543 * g533
+		s.xlamo = math.Mod(s.mo+s.nodeo+s.nodeo-theta-theta, twoPi)
+		s.xfact = s.mdot + s.dmdt + 2*(s.nodedot+s.dnodt-sdp4RPTim) - s.noUnkozai
+		return
+	}
+
+	// Synchronous resonance
+	emsq := d.emsq
+	cosim := d.cosim
+	g200 := 1 + emsq*(-2.5+0.8125*emsq)
+	g310 := 1 + 2*emsq
+	g300 := 1 + emsq*(-6+6.60937*emsq)
+	f220 := 0.75 * (1 + cosim) * (1 + cosim)
+	f311 := 0.9375*d.sinim*d.sinim*(1+3*cosim) - 0.75*(1+cosim)
+	f330 := 1 + cosim
+	f330 = 1.875 * f330 * f330 * f330
+	s.del1 = 3 * nm * nm * aonv * aonv
+	s.del2 = 2 * s.del1 * f220 * g200 * q22
+	s.del3 = 3 * s.del1 * f330 * g300 * q33 * aonv
+	s.del1 = s.del1 * f311 * g310 * q31 * aonv
+	s.xlamo = math.Mod(s.mo+s.nodeo+s.argpo-theta, twoPi)
+	s.xfact = s.mdot + xpidot - sdp4RPTim + s.dmdt + s.domdt + s.dnodt - s.noUnkozai
+}
+
+// deepSpaceSecular applies the secular lunar and solar effects and integrates the resonance terms
+// t minutes from the epoch, as Vallado's dspace. The integrator always starts at the epoch, so the
+// propagator keeps no state between calls.
+func (s *SGP4) deepSpaceSecular(t, em, argpm, inclm, mm, nodem float64) (emOut, argpmOut, inclmOut, mmOut, nodemOut, nm float64) {
+	const (
+		fasx2 = 0.13130908
+		fasx4 = 2.8843198
+		fasx6 = 0.37448087
+		g22   = 5.7686396
+		g32   = 0.95240898
+		g44   = 1.8014998
+		g52   = 1.0508330
+		g54   = 4.4108898
+		stepp = 720.0
+		stepn = -720.0
+		step2 = 259200.0
+	)
+
+	theta := math.Mod(s.gsto+t*sdp4RPTim, twoPi)
+	em += s.dedt * t
+	inclm += s.didt * t
+	argpm += s.domdt * t
+	nodem += s.dnodt * t
+	mm += s.dmdt * t
+	nm = s.noUnkozai
+	if s.irez == 0 {
+		return em, argpm, inclm, mm, nodem, nm
+	}
+
+	atime := 0.0
+	xni := s.noUnkozai
+	xli := s.xlamo
+	delt := stepn
+	if t > 0 {
+		delt = stepp
+	}
+
+	var xndt, xldot, xnddt, ft float64
+	for {
+		if s.irez != 2 {
+			xndt = s.del1*math.Sin(xli-fasx2) + s.del2*math.Sin(2*(xli-fasx4)) + s.del3*math.Sin(3*(xli-fasx6))
+			xldot = xni + s.xfact
+			xnddt = s.del1*math.Cos(xli-fasx2) + 2*s.del2*math.Cos(2*(xli-fasx4)) + 3*s.del3*math.Cos(3*(xli-fasx6))
+			xnddt *= xldot
+		} else {
+			xomi := s.argpo + s.argpdot*atime
+			x2omi := xomi + xomi
+			x2li := xli + xli
+			xndt = s.d2201*math.Sin(x2omi+xli-g22) + s.d2211*math.Sin(xli-g22) +
+				s.d3210*math.Sin(xomi+xli-g32) + s.d3222*math.Sin(-xomi+xli-g32) +
+				s.d4410*math.Sin(x2omi+x2li-g44) + s.d4422*math.Sin(x2li-g44) +
+				s.d5220*math.Sin(xomi+xli-g52) + s.d5232*math.Sin(-xomi+xli-g52) +
+				s.d5421*math.Sin(xomi+x2li-g54) + s.d5433*math.Sin(-xomi+x2li-g54)
+			xldot = xni + s.xfact
+			xnddt = s.d2201*math.Cos(x2omi+xli-g22) + s.d2211*math.Cos(xli-g22) +
+				s.d3210*math.Cos(xomi+xli-g32) + s.d3222*math.Cos(-xomi+xli-g32) +
+				s.d5220*math.Cos(xomi+xli-g52) + s.d5232*math.Cos(-xomi+xli-g52) +
+				2*(s.d4410*math.Cos(x2omi+x2li-g44)+s.d4422*math.Cos(x2li-g44)+
+					s.d5421*math.Cos(xomi+x2li-g54)+s.d5433*math.Cos(-xomi+x2li-g54))
+			xnddt *= xldot
+		}
+
+		if math.Abs(t-atime) < stepp {
+			ft = t - atime
+			break
+		}
+		xli += xldot*delt + xndt*step2
+		xni += xndt*delt + xnddt*step2
+		atime += delt
+	}
+
+	nm = xni + xndt*ft + xnddt*ft*ft*0.5
+	xl := xli + xldot*ft + xndt*ft*ft*0.5
+	if s.irez != 1 {
+		mm = xl - 2*nodem + 2*theta
+	} else {
+		mm = xl - nodem - argpm + theta
+	}
+	return em, argpm, inclm, mm, nodem, nm
+}
+
+// deepSpacePeriodics adds the lunar and solar periodic terms t minutes from the epoch, as Vallado's
+// dpper, using the Lyddane modification near zero inclination
+func (s *SGP4) deepSpacePeriodics(t, ep, inclp, nodep, argpp, mp float64) (epOut, inclpOut, nodepOut, argppOut, mpOut float64) {
+	// Solar terms
+	zm := s.zmos + sdp4ZNS*t
+	zf := zm + 2*sdp4ZES*math.Sin(zm)
+	sinzf := math.Sin(zf)
+	f2 := 0.5*sinzf*sinzf - 0.25
+	f3 := -0.5 * sinzf * math.Cos(zf)
+	ses := s.se2*f2 + s.se3*f3
+	sis := s.si2*f2 + s.si3*f3
+	sls := s.sl2*f2 + s.sl3*f3 + s.sl4*sinzf
+	sghs := s.sgh2*f2 + s.sgh3*f3 + s.sgh4*sinzf
+	shs := s.sh2*f2 + s.sh3*f3
+
+	// Lunar terms
+	zm = s.zmol + sdp4ZNL*t
+	zf = zm + 2*sdp4ZEL*math.Sin(zm)
+	sinzf = math.Sin(zf)
+	f2 = 0.5*sinzf*sinzf - 0.25
+	f3 = -0.5 * sinzf * math.Cos(zf)
+	sel := s.ee2*f2 + s.e3*f3
+	sil := s.xi2*f2 + s.xi3*f3
+	sll := s.xl2*f2 + s.xl3*f3 + s.xl4*sinzf
+	sghl := s.xgh2*f2 + s.xgh3*f3 + s.xgh4*sinzf
+	shll := s.xh2*f2 + s.xh3*f3
+
+	pe := ses + sel - s.peo
+	pinc := sis + sil - s.pinco
+	pl := sls + sll - s.plo
+	pgh := sghs + sghl - s.pgho
+	ph := shs + shll - s.pho
+
+	inclp += pinc
+	ep += pe
+	sinip, cosip := math.Sin(inclp), math.Cos(inclp)
+	if inclp >= 0.2 {
+		ph /= sinip
+		pgh -= cosip * ph
+		argpp += pgh
+		nodep += ph
+		mp += pl
+		return ep, inclp, nodep, argpp, mp
+	}
+
+	// Lyddane modification for low inclinations
+	sinop, cosop := math.Sin(nodep), math.Cos(nodep)
+	alfdp := sinip * sinop
+	betdp := sinip * cosop
+	dalf := ph*cosop + pinc*cosip*sinop
+	dbet := -ph*sinop + pinc*cosip*cosop
+	alfdp += dalf
+	betdp += dbet
+	nodep = math.Mod(nodep, twoPi)
+	xls := mp + argpp + cosip*nodep
+	dls := pl + pgh - pinc*nodep*sinip
+	xls += dls
+	xnoh := nodep
+	nodep = math.Atan2(alfdp, betdp)
+	if math.Abs(xnoh-nodep) > math.Pi {
+		if nodep < xnoh {
+			nodep += twoPi
+		} else {
+			nodep -= twoPi
+		}
+	}
+	mp += pl
+	argpp = xls - mp - cosip*nodep
+	return ep, inclp, nodep, argpp, mp
+}
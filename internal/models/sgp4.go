@@ -0,0 +1,442 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+// SGP4 gravity model: WGS-72, which the element sets are fitted with
+const (
+	sgp4Mu            = 398600.8 // km³/s²
+	sgp4EarthRadiusKm = 6378.135 // km
+	sgp4J2            = 0.001082616
+	sgp4J3            = -0.00000253881
+	sgp4J4            = -0.00000165597
+	sgp4J3OverJ2      = sgp4J3 / sgp4J2
+	twoPi             = 2 * math.Pi
+	minutesPerDay     = 1440.0
+	deepSpacePeriod   = 225.0 // minutes; longer periods need the lunar-solar SDP4 terms
+)
+
+// sgp4XKE is sqrt(GM) in earth radii^1.5 per minute
+var sgp4XKE = 60 / math.Sqrt(sgp4EarthRadiusKm*sgp4EarthRadiusKm*sgp4EarthRadiusKm/sgp4Mu)
+
+// Errors from propagating an element set too far or into a decayed orbit
+var (
+	ErrSGP4Eccentricity = errors.New("sgp4: mean eccentricity out of range")
+	ErrSGP4MeanMotion   = errors.New("sgp4: mean motion is not positive")
+	ErrSGP4Perturbation = errors.New("sgp4: perturbed eccentricity out of range")
+	ErrSGP4SemiLatus    = errors.New("sgp4: semi-latus rectum is negative")
+	ErrSGP4Decayed      = errors.New("sgp4: satellite has decayed")
+)
+
+// SGP4 propagates a two-line element set with the SGP4 model, switching to SDP4's lunar, solar and
+// resonance terms for periods of 225 minutes or more. It follows Vallado's revision of Spacetrack
+// Report #3 ("Revisiting Spacetrack Report #3", AIAA 2006-6753) in its improved operations mode.
+// An SGP4 is immutable once created, so it can be shared between goroutines.
+type SGP4 struct {
+	tle       TLE
+	epochJD   float64 // Julian date of the epoch
+	deepSpace bool
+	isimp     bool // Perigee below 220 km: drop the higher order drag terms
+
+	// Mean elements, radians and radians per minute
+	ecco, inclo, nodeo, argpo, mo, noUnkozai, bstar float64
+
+	// Near earth constants
+	aycof, con41, cc1, cc4, cc5, d2, d3, d4, delmo, eta, argpdot, omgcof, sinmao,
+	t2cof, t3cof, t4cof, t5cof, x1mth2, x7thm1, mdot, nodedot, xlcof, xmcof, nodecf float64
+
+	// Deep space constants
+	irez                                                                 int
+	d2201, d2211, d3210, d3222, d4410, d4422, d5220, d5232, d5421, d5433 float64
+	dedt, del1, del2, del3, didt, dmdt, dnodt, domdt                     float64
+	e3, ee2, peo, pgho, pho, pinco, plo, se2, se3, sgh2, sgh3, sgh4      float64
+	sh2, sh3, si2, si3, sl2, sl3, sl4, gsto, xfact                       float64
+	xgh2, xgh3, xgh4, xh2, xh3, xi2, xi3, xl2, xl3, xl4, xlamo           float64
+	zmol, zmos                                                           float64
+}
+
+// NewSGP4 initializes the propagator for an element set
+func NewSGP4(tle TLE) (*SGP4, error) {
+	const deg2rad = math.Pi / 180
+	s := &SGP4{
+		tle:   tle,
+		bstar: tle.BStar,
+		ecco:  tle.Eccentricity,
+		inclo: tle.Inclination * deg2rad,
+		nodeo: tle.RAAN * deg2rad,
+		argpo: tle.ArgPerigee * deg2rad,
+		mo:    tle.MeanAnomaly * deg2rad,
+	}
+	s.epochJD = julianDateOfYear(tle.epochYear) + tle.epochDay - 1
+	noKozai := tle.MeanMotion / (minutesPerDay / twoPi) // rad/min
+	if err := s.init(noKozai); err != nil {
+		return nil, fmt.Errorf("TLE %d: %w", tle.CatalogNumber, err)
+	}
+	return s, nil
+}
+
+// TLE returns the element set the propagator was created from
+func (s *SGP4) TLE() TLE {
+	return s.tle
+}
+
+// Propagate returns the geodetic position and Earth-fixed velocity at t
+func (s *SGP4) Propagate(t time.Time) (Position, Velocity, error) {
+	r, v, err := s.PropagateTEME(s.MinutesSinceEpoch(t))
+	if err != nil {
+		return Position{}, Velocity{}, err
+	}
+	pos, vel := TEMEToGeodetic(r, v, t)
+	return pos, vel, nil
+}
+
+// MinutesSinceEpoch returns the time from the element set epoch to t in minutes
+func (s *SGP4) MinutesSinceEpoch(t time.Time) float64 {
+	return (julianDate(t) - s.epochJD) * minutesPerDay
+}
+
+// julianDateOfYear returns the Julian date of January 1st 00:00 UTC of a year from 1901 to 2099
+func julianDateOfYear(year int) float64 {
+	return float64(367*year-(7*year)/4+31) + 1721013.5
+}
+
+// julianDate returns the Julian date of t, taking UTC as UT1
+func julianDate(t time.Time) float64 {
+	t = t.UTC()
+	start := time.Date(t.Year(), time.January, 1, 0, 0, 0, 0, time.UTC)
+	return julianDateOfYear(t.Year()) + t.Sub(start).Hours()/24
+}
+
+// greenwichSiderealTime returns the Greenwich mean sidereal angle in radians at a UT1 Julian date,
+// using the IAU-82 model
+func greenwichSiderealTime(jdut1 float64) float64 {
+	tut1 := (jdut1 - 2451545.0) / 36525.0
+	seconds := -6.2e-6*tut1*tut1*tut1 + 0.093104*tut1*tut1 +
+		(876600.0*3600+8640184.812866)*tut1 + 67310.54841
+	gmst := math.Mod(seconds*math.Pi/180/240, twoPi)
+	if gmst < 0 {
+		gmst += twoPi
+	}
+	return gmst
+}
+
+// init computes the constants of the propagator, as Vallado's sgp4init
+func (s *SGP4) init(noKozai float64) error {
+	const x2o3 = 2.0 / 3.0
+	ss := 78.0/sgp4EarthRadiusKm + 1
+	qzms2t := math.Pow((120.0-78.0)/sgp4EarthRadiusKm, 4)
+
+	// Recover the original mean motion and semi-major axis from the Kozai mean motion
+	eccsq := s.ecco * s.ecco
+	omeosq := 1 - eccsq
+	rteosq := math.Sqrt(omeosq)
+	cosio := math.Cos(s.inclo)
+	cosio2 := cosio * cosio
+	ak := math.Pow(sgp4XKE/noKozai, x2o3)
+	d1 := 0.75 * sgp4J2 * (3*cosio2 - 1) / (rteosq * omeosq)
+	del := d1 / (ak * ak)
+	adel := ak * (1 - del*del - del*(1.0/3.0+134*del*del/81))
+	del = d1 / (adel * adel)
+	s.noUnkozai = noKozai / (1 + del)
+
+	ao := math.Pow(sgp4XKE/s.noUnkozai, x2o3)
+	sinio := math.Sin(s.inclo)
+	po := ao * omeosq
+	con42 := 1 - 5*cosio2
+	s.con41 = -con42 - cosio2 - cosio2
+	posq := po * po
+	rp := ao * (1 - s.ecco)
+	s.gsto = greenwichSiderealTime(s.epochJD)
+
+	if s.ecco < 0 || s.ecco >= 1 {
+		return ErrSGP4Eccentricity
+	}
+	if s.noUnkozai <= 0 {
+		return ErrSGP4MeanMotion
+	}
+
+	s.isimp = rp < 220/sgp4EarthRadiusKm+1
+	sfour := ss
+	qzms24 := qzms2t
+	perigee := (rp - 1) * sgp4EarthRadiusKm
+
+	// Lower the atmosphere's reference height for perigees below 156 km
+	if perigee < 156 {
+		sfour = perigee - 78
+		if perigee < 98 {
+			sfour = 20
+		}
+		qzms24 = math.Pow((120-sfour)/sgp4EarthRadiusKm, 4)
+		sfour = sfour/sgp4EarthRadiusKm + 1
+	}
+	pinvsq := 1 / posq
+
+	tsi := 1 / (ao - sfour)
+	s.eta = ao * s.ecco * tsi
+	etasq := s.eta * s.eta
+	eeta := s.ecco * s.eta
+	psisq := math.Abs(1 - etasq)
+	coef := qzms24 * math.Pow(tsi, 4)
+	coef1 := coef / math.Pow(psisq, 3.5)
+	cc2 := coef1 * s.noUnkozai * (ao*(1+1.5*etasq+eeta*(4+etasq)) +
+		0.375*sgp4J2*tsi/psisq*s.con41*(8+3*etasq*(8+etasq)))
+	s.cc1 = s.bstar * cc2
+	cc3 := 0.0
+	if s.ecco > 1e-4 {
+		cc3 = -2 * coef * tsi * sgp4J3OverJ2 * s.noUnkozai * sinio / s.ecco
+	}
+	s.x1mth2 = 1 - cosio2
+	s.cc4 = 2 * s.noUnkozai * coef1 * ao * omeosq *
+		(s.eta*(2+0.5*etasq) + s.ecco*(0.5+2*etasq) -
+			sgp4J2*tsi/(ao*psisq)*(-3*s.con41*(1-2*eeta+etasq*(1.5-0.5*eeta))+
+				0.75*s.x1mth2*(2*etasq-eeta*(1+etasq))*math.Cos(2*s.argpo)))
+	s.cc5 = 2 * coef1 * ao * omeosq * (1 + 2.75*(etasq+eeta) + eeta*etasq)
+
+	cosio4 := cosio2 * cosio2
+	temp1 := 1.5 * sgp4J2 * pinvsq * s.noUnkozai
+	temp2 := 0.5 * temp1 * sgp4J2 * pinvsq
+	temp3 := -0.46875 * sgp4J4 * pinvsq * pinvsq * s.noUnkozai
+	s.mdot = s.noUnkozai + 0.5*temp1*rteosq*s.con41 + 0.0625*temp2*rteosq*(13-78*cosio2+137*cosio4)
+	s.argpdot = -0.5*temp1*con42 + 0.0625*temp2*(7-114*cosio2+395*cosio4) +
+		temp3*(3-36*cosio2+49*cosio4)
+	xhdot1 := -temp1 * cosio
+	s.nodedot = xhdot1 + (0.5*temp2*(4-19*cosio2)+2*temp3*(3-7*cosio2))*cosio
+	xpidot := s.argpdot + s.nodedot
+	s.omgcof = s.bstar * cc3 * math.Cos(s.argpo)
+	if s.ecco > 1e-4 {
+		s.xmcof = -x2o3 * coef * s.bstar / eeta
+	}
+	s.nodecf = 3.5 * omeosq * xhdot1 * s.cc1
+	s.t2cof = 1.5 * s.cc1
+	s.xlcof = longPeriodCoefficient(sinio, cosio)
+	s.aycof = -0.5 * sgp4J3OverJ2 * sinio
+	delmotemp := 1 + s.eta*math.Cos(s.mo)
+	s.delmo = delmotemp * delmotemp * delmotemp
+	s.sinmao = math.Sin(s.mo)
+	s.x7thm1 = 7*cosio2 - 1
+
+	if twoPi/s.noUnkozai >= deepSpacePeriod {
+		s.deepSpace = true
+		s.isimp = true
+		dc := s.deepSpaceCommon(0, s.ecco, s.inclo, s.nodeo, s.argpo, s.noUnkozai)
+		s.deepSpaceInit(dc, eccsq, xpidot)
+	}
+
+	if !s.isimp {
+		cc1sq := s.cc1 * s.cc1
+		s.d2 = 4 * ao * tsi * cc1sq
+		temp := s.d2 * tsi * s.cc1 / 3
+		s.d3 = (17*ao + sfour) * temp
+		s.d4 = 0.5 * temp * ao * tsi * (221*ao + 31*sfour) * s.cc1
+		s.t3cof = s.d2 + 2*cc1sq
+		s.t4cof = 0.25 * (3*s.d3 + s.cc1*(12*s.d2+10*cc1sq))
+		s.t5cof = 0.2 * (3*s.d4 + 12*s.cc1*s.d3 + 6*s.d2*s.d2 + 15*cc1sq*(2*s.d2+cc1sq))
+	}
+
+	// Propagating to the epoch catches element sets that are already decayed
+	_, _, err := s.PropagateTEME(0)
+	return err
+}
+
+// longPeriodCoefficient returns the long period periodic coefficient for an inclination, guarding the
+// division for retrograde equatorial orbits
+func longPeriodCoefficient(sinio, cosio float64) float64 {
+	if math.Abs(cosio+1) > 1.5e-12 {
+		return -0.25 * sgp4J3OverJ2 * sinio * (3 + 5*cosio) / (1 + cosio)
+	}
+	return -0.25 * sgp4J3OverJ2 * sinio * (3 + 5*cosio) / 1.5e-12
+}
+
+// PropagateTEME returns the position in km and velocity in km/s in the True Equator Mean Equinox
+// frame, tsince minutes after the element set epoch
+func (s *SGP4) PropagateTEME(tsince float64) (r, v [3]float64, err error) {
+	const x2o3 = 2.0 / 3.0
+	vkmpersec := sgp4EarthRadiusKm * sgp4XKE / 60
+	t := tsince
+
+	// Secular gravity and atmospheric drag
+	xmdf := s.mo + s.mdot*t
+	argpdf := s.argpo + s.argpdot*t
+	nodedf := s.nodeo + s.nodedot*t
+	argpm := argpdf
+	mm := xmdf
+	t2 := t * t
+	nodem := nodedf + s.nodecf*t2
+	tempa := 1 - s.cc1*t
+	tempe := s.bstar * s.cc4 * t
+	templ := s.t2cof * t2
+
+	if !s.isimp {
+		delomg := s.omgcof * t
+		delmtemp := 1 + s.eta*math.Cos(xmdf)
+		delm := s.xmcof * (delmtemp*delmtemp*delmtemp - s.delmo)
+		temp := delomg + delm
+		mm = xmdf + temp
+		argpm = argpdf - temp
+		t3 := t2 * t
+		t4 := t3 * t
+		tempa = tempa - s.d2*t2 - s.d3*t3 - s.d4*t4
+		tempe += s.bstar * s.cc5 * (math.Sin(mm) - s.sinmao)
+		templ += s.t3cof*t3 + t4*(s.t4cof+t*s.t5cof)
+	}
+
+	nm := s.noUnkozai
+	em := s.ecco
+	inclm := s.inclo
+	if s.deepSpace {
+		em, argpm, inclm, mm, nodem, nm = s.deepSpaceSecular(t, em, argpm, inclm, mm, nodem)
+	}
+	if nm <= 0 {
+		return r, v, ErrSGP4MeanMotion
+	}
+
+	am := math.Pow(sgp4XKE/nm, x2o3) * tempa * tempa
+	nm = sgp4XKE / math.Pow(am, 1.5)
+	em -= tempe
+	if em >= 1 || em < -0.001 {
+		return r, v, ErrSGP4Eccentricity
+	}
+	if em < 1e-6 {
+		em = 1e-6
+	}
+	mm += s.noUnkozai * templ
+	xlm := mm + argpm + nodem
+
+	nodem = math.Mod(nodem, twoPi)
+	argpm = math.Mod(argpm, twoPi)
+	xlm = math.Mod(xlm, twoPi)
+	mm = math.Mod(xlm-argpm-nodem, twoPi)
+
+	// Lunar-solar periodics
+	ep, xincp, argpp, nodep, mp := em, inclm, argpm, nodem, mm
+	sinip, cosip := math.Sin(inclm), math.Cos(inclm)
+	aycof, xlcof := s.aycof, s.xlcof
+	con41, x1mth2, x7thm1 := s.con41, s.x1mth2, s.x7thm1
+	if s.deepSpace {
+		ep, xincp, nodep, argpp, mp = s.deepSpacePeriodics(t, ep, xincp, nodep, argpp, mp)
+		if xincp < 0 {
+			xincp = -xincp
+			nodep += math.Pi
+			argpp -= math.Pi
+		}
+		if ep < 0 || ep > 1 {
+			return r, v, ErrSGP4Perturbation
+		}
+
+		sinip, cosip = math.Sin(xincp), math.Cos(xincp)
+		aycof = -0.5 * sgp4J3OverJ2 * sinip
+		xlcof = longPeriodCoefficient(sinip, cosip)
+		cosisq := cosip * cosip
+		con41 = 3*cosisq - 1
+		x1mth2 = 1 - cosisq
+		x7thm1 = 7*cosisq - 1
+	}
+
+	// Long period periodics
+	axnl := ep * math.Cos(argpp)
+	temp := 1 / (am * (1 - ep*ep))
+	aynl := ep*math.Sin(argpp) + temp*aycof
+	xl := mp + argpp + nodep + temp*xlcof*axnl
+
+	// Kepler's equation
+	u := math.Mod(xl-nodep, twoPi)
+	eo1 := u
+	tem5 := 9999.9
+	var sineo1, coseo1 float64
+	for ktr := 1; math.Abs(tem5) >= 1e-12 && ktr <= 10; ktr++ {
+		sineo1, coseo1 = math.Sin(eo1), math.Cos(eo1)
+		tem5 = 1 - coseo1*axnl - sineo1*aynl
+		tem5 = (u - aynl*coseo1 + axnl*sineo1 - eo1) / tem5
+		if math.Abs(tem5) >= 0.95 {
+			tem5 = math.Copysign(0.95, tem5)
+		}
+		eo1 += tem5
+	}
+
+	// Short period periodics
+	ecose := axnl*coseo1 + aynl*sineo1
+	esine := axnl*sineo1 - aynl*coseo1
+	el2 := axnl*axnl + aynl*aynl
+	pl := am * (1 - el2)
+	if pl < 0 {
+		return r, v, ErrSGP4SemiLatus
+	}
+	rl := am * (1 - ecose)
+	rdotl := math.Sqrt(am) * esine / rl
+	rvdotl := math.Sqrt(pl) / rl
+	betal := math.Sqrt(1 - el2)
+	temp = esine / (1 + betal)
+	sinu := am / rl * (sineo1 - aynl - axnl*temp)
+	cosu := am / rl * (coseo1 - axnl + aynl*temp)
+	su := math.Atan2(sinu, cosu)
+	sin2u := (cosu + cosu) * sinu
+	cos2u := 1 - 2*sinu*sinu
+	temp = 1 / pl
+	temp1 := 0.5 * sgp4J2 * temp
+	temp2 := temp1 * temp
+
+	mrt := rl*(1-1.5*temp2*betal*con41) + 0.5*temp1*x1mth2*cos2u
+	su -= 0.25 * temp2 * x7thm1 * sin2u
+	xnode := nodep + 1.5*temp2*cosip*sin2u
+	xinc := xincp + 1.5*temp2*cosip*sinip*cos2u
+	mvt := rdotl - nm*temp1*x1mth2*sin2u/sgp4XKE
+	rvdot := rvdotl + nm*temp1*(x1mth2*cos2u+1.5*con41)/sgp4XKE
+
+	// Orientation vectors
+	sinsu, cossu := math.Sin(su), math.Cos(su)
+	snod, cnod := math.Sin(xnode), math.Cos(xnode)
+	sini, cosi := math.Sin(xinc), math.Cos(xinc)
+	xmx := -snod * cosi
+	xmy := cnod * cosi
+	ux := xmx*sinsu + cnod*cossu
+	uy := xmy*sinsu + snod*cossu
+	uz := sini * sinsu
+	vx := xmx*cossu - cnod*sinsu
+	vy := xmy*cossu - snod*sinsu
+	vz := sini * cossu
+
+	r = [3]float64{mrt * ux * sgp4EarthRadiusKm, mrt * uy * sgp4EarthRadiusKm, mrt * uz * sgp4EarthRadiusKm}
+	v = [3]float64{(mvt*ux + rvdot*vx) * vkmpersec, (mvt*uy + rvdot*vy) * vkmpersec, (mvt*uz + rvdot*vz) * vkmpersec}
+	if mrt < 1 {
+		return r, v, ErrSGP4Decayed
+	}
+	return r, v, nil
+}
+
+// TEMEToGeodetic converts a TEME position (km) and velocity (km/s) at time t to a geodetic position
+// and a velocity over the rotating Earth in east, north and up components. Polar motion is ignored
+// and UTC is taken as UT1, which costs some tens of meters.
+func TEMEToGeodetic(r, v [3]float64, t time.Time) (Position, Velocity) {
+	const earthRotation = 7.292115146706979e-5 // rad/s
+	gmst := greenwichSiderealTime(julianDate(t))
+	sinG, cosG := math.Sincos(gmst)
+
+	// Rotate into the pseudo Earth-fixed frame and remove the Earth's rotation from the velocity
+	ecef := ECEF{
+		X: (cosG*r[0] + sinG*r[1]) * 1000,
+		Y: (-sinG*r[0] + cosG*r[1]) * 1000,
+		Z: r[2] * 1000,
+	}
+	vx := (cosG*v[0]+sinG*v[1])*1000 + earthRotation*ecef.Y
+	vy := (-sinG*v[0]+cosG*v[1])*1000 - earthRotation*ecef.X
+	vz := v[2] * 1000
+
+	pos := ECEFToGeodetic(ecef)
+	return pos, ecefToENU(pos.Latitude, pos.Longitude, vx, vy, vz)
+}
+
+// ecefToENU rotates an ECEF vector into local east, north and up components at a latitude and
+// longitude in degrees, the inverse of ENUToECEF
+func ecefToENU(latitude, longitude, x, y, z float64) Velocity {
+	sinLat, cosLat := math.Sincos(latitude * math.Pi / 180)
+	sinLon, cosLon := math.Sincos(longitude * math.Pi / 180)
+	return Velocity{
+		East:  -sinLon*x + cosLon*y,
+		North: -sinLat*cosLon*x - sinLat*sinLon*y + cosLat*z,
+		Up:    cosLat*cosLon*x + cosLat*sinLon*y + sinLat*z,
+	}
+}
@@ -0,0 +1,210 @@
+package models
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+// Verification cases from the SGP4-VER.TLE set published with Vallado's "Revisiting Spacetrack
+// Report #3", with the TEME results of its improved mode (km, km/s)
+func TestSGP4_VerificationVectors(t *testing.T) {
+	tests := []struct {
+		name         string
+		line1, line2 string
+		tsince       float64 // minutes
+		r, v         [3]float64
+	}{
+		{
+			name:   "00005 near earth at epoch",
+			line1:  "1 00005U 58002B   00179.78495062  .00000023  00000-0  28098-4 0  4753",
+			line2:  "2 00005  34.2682 348.7242 1859667 331.7664  19.3264 10.82419157413667",
+			tsince: 0,
+			r:      [3]float64{7022.46529266, -1400.08296755, 0.03995155},
+			v:      [3]float64{1.893841015, 6.405893759, 4.534807250},
+		},
+		{
+			name:   "00005 near earth after 6 hours",
+			line1:  "1 00005U 58002B   00179.78495062  .00000023  00000-0  28098-4 0  4753",
+			line2:  "2 00005  34.2682 348.7242 1859667 331.7664  19.3264 10.82419157413667",
+			tsince: 360,
+			r:      [3]float64{-7154.03120202, -3783.17682504, -3536.19412294},
+			v:      [3]float64{4.741887409, -4.151817765, -2.093935425},
+		},
+		{
+			name:   "00005 near earth after 12 hours",
+			line1:  "1 00005U 58002B   00179.78495062  .00000023  00000-0  28098-4 0  4753",
+			line2:  "2 00005  34.2682 348.7242 1859667 331.7664  19.3264 10.82419157413667",
+			tsince: 720,
+			r:      [3]float64{-7134.59340119, 6531.68641334, 3260.27186483},
+			v:      [3]float64{-4.113793027, -2.911922039, -2.557327851},
+		},
+		{
+			name:   "00005 near earth after 18 hours",
+			line1:  "1 00005U 58002B   00179.78495062  .00000023  00000-0  28098-4 0  4753",
+			line2:  "2 00005  34.2682 348.7242 1859667 331.7664  19.3264 10.82419157413667",
+			tsince: 1080,
+			r:      [3]float64{5568.53901181, 4492.06992591, 3863.87641983},
+			v:      [3]float64{-4.209106476, 5.159719888, 2.744852980},
+		},
+		{
+			name:   "08195 Molniya 12 hour resonance",
+			line1:  "1 08195U 75081A   06176.33215444  .00000099  00000-0  11873-3 0   813",
+			line2:  "2 08195  64.1586 279.0717 6877146 264.7651  20.2257  2.00491383225656",
+			tsince: 0,
+			r:      [3]float64{2349.89483350, -14785.93811562, 0.02119378},
+			v:      [3]float64{2.721488096, -3.256811655, 4.498416672},
+		},
+		{
+			name:   "09880 Molniya 12 hour resonance",
+			line1:  "1 09880U 77021A   06176.56157475  .00000421  00000-0  10000-3 0  9814",
+			line2:  "2 09880  64.5968 349.3786 7069051 270.0229  16.3320  2.00813614112380",
+			tsince: 0,
+			r:      [3]float64{13020.06750784, -2449.07193500, 1.15896030},
+			v:      [3]float64{4.247363935, 1.597178501, 4.956708611},
+		},
+		{
+			name:   "28626 geosynchronous at epoch",
+			line1:  "1 28626U 05008A   06176.46683397 -.00000205  00000-0  10000-3 0  2190",
+			line2:  "2 28626   0.0019 286.9433 0000335  13.7918  55.6504  1.00270176  4891",
+			tsince: 0,
+			r:      [3]float64{42080.71852213, -2646.86387436, 0.81851294},
+			v:      [3]float64{0.193105177, 3.068688251, 0.000438449},
+		},
+		{
+			name:   "28626 geosynchronous after 2 hours",
+			line1:  "1 28626U 05008A   06176.46683397 -.00000205  00000-0  10000-3 0  2190",
+			line2:  "2 28626   0.0019 286.9433 0000335  13.7918  55.6504  1.00270176  4891",
+			tsince: 120,
+			r:      [3]float64{37740.00085593, 18802.76872802, 3.45512584},
+			v:      [3]float64{-1.371035206, 2.752105932, 0.000336883},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tle, err := ParseTLE(tt.line1, tt.line2)
+			if err != nil {
+				t.Fatal(err)
+			}
+			sgp4, err := NewSGP4(tle)
+			if err != nil {
+				t.Fatal(err)
+			}
+			r, v, err := sgp4.PropagateTEME(tt.tsince)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i := range r {
+				if math.Abs(r[i]-tt.r[i]) > 1e-3 || math.Abs(v[i]-tt.v[i]) > 1e-6 {
+					t.Fatalf("PropagateTEME(%v) = %v %v, want %v %v", tt.tsince, r, v, tt.r, tt.v)
+				}
+			}
+		})
+	}
+}
+
+func TestSGP4_Propagate(t *testing.T) {
+	tle, err := ParseTLE(
+		"1 25544U 98067A   19343.69339541  .00001764  00000-0  38792-4 0  9991",
+		"2 25544  51.6439 211.2001 0007417  17.6667  85.6398 15.50103472202482",
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sgp4, err := NewSGP4(tle)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := sgp4.MinutesSinceEpoch(tle.Epoch.Add(90 * time.Minute)); math.Abs(got-90) > 1e-6 {
+		t.Errorf("MinutesSinceEpoch = %v, want 90", got)
+	}
+
+	for minutes := 0; minutes <= 1440; minutes += 30 {
+		at := tle.Epoch.Add(time.Duration(minutes) * time.Minute)
+		pos, vel, err := sgp4.Propagate(at)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if pos.Altitude < 390e3 || pos.Altitude > 440e3 {
+			t.Errorf("Altitude after %d minutes = %.0f m, want the station's orbit", minutes, pos.Altitude)
+		}
+		if math.Abs(pos.Latitude) > 52 {
+			t.Errorf("Latitude after %d minutes = %.2f, beyond the inclination", minutes, pos.Latitude)
+		}
+
+		// Earth-fixed speed is the inertial 7.66 km/s less the rotation of the ground below
+		speed := math.Sqrt(vel.North*vel.North + vel.East*vel.East + vel.Up*vel.Up)
+		if speed < 7.1e3 || speed > 7.7e3 {
+			t.Errorf("Speed after %d minutes = %.0f m/s", minutes, speed)
+		}
+
+		// The velocity agrees with the change of position over a second
+		next, _, _ := sgp4.Propagate(at.Add(time.Second))
+		moved := GeodeticToECEF(next)
+		start := GeodeticToECEF(pos)
+		step := ENUToECEF(pos.Latitude, pos.Longitude, vel.East, vel.North, vel.Up)
+		dx := moved.X - start.X - step.X
+		dy := moved.Y - start.Y - step.Y
+		dz := moved.Z - start.Z - step.Z
+		if miss := math.Sqrt(dx*dx + dy*dy + dz*dz); miss > 5 {
+			t.Errorf("Position after one second misses the velocity by %.1f m", miss)
+		}
+	}
+}
+
+func TestSGP4_Geosynchronous(t *testing.T) {
+	// Synchronous resonance and the Lyddane periodics near zero inclination keep the satellite over
+	// the same longitude
+	tle, err := ParseTLE(
+		"1 28626U 05008A   06176.46683397 -.00000205  00000-0  10000-3 0  2190",
+		"2 28626   0.0019 286.9433 0000335  13.7918  55.6504  1.00270176  4891",
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sgp4, err := NewSGP4(tle)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, _, err := sgp4.Propagate(tle.Epoch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for hours := 0; hours <= 72; hours += 6 {
+		pos, vel, err := sgp4.Propagate(tle.Epoch.Add(time.Duration(hours) * time.Hour))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if math.Abs(pos.Altitude-35786e3) > 20e3 || math.Abs(pos.Latitude) > 0.1 {
+			t.Errorf("Position after %d hours = %+v, want geostationary", hours, pos)
+		}
+		if drift := math.Abs(pos.Longitude - first.Longitude); drift > 0.5 {
+			t.Errorf("Longitude drifted %.2f degrees after %d hours", drift, hours)
+		}
+		if speed := math.Hypot(vel.North, vel.East); speed > 5 {
+			t.Errorf("Earth-fixed speed after %d hours = %.1f m/s, want nearly stationary", hours, speed)
+		}
+	}
+}
+
+func TestSGP4_Decayed(t *testing.T) {
+	// A very low orbit with heavy drag reenters within days
+	tle, err := ParseTLE(
+		"1 25544U 98067A   19343.69339541  .00001764  00000-0  38792-1 0  9998",
+		"2 25544  51.6439 211.2001 0007417  17.6667  85.6398 16.40103472202482",
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sgp4, err := NewSGP4(tle)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := sgp4.PropagateTEME(60 * 24 * 30); err == nil {
+		t.Error("Expected propagation a month past reentry to fail")
+	} else if !errors.Is(err, ErrSGP4Decayed) && !errors.Is(err, ErrSGP4Eccentricity) && !errors.Is(err, ErrSGP4Perturbation) &&
+		!errors.Is(err, ErrSGP4SemiLatus) && !errors.Is(err, ErrSGP4MeanMotion) {
+		t.Errorf("Unexpected error %v", err)
+	}
+}
@@ -69,11 +69,33 @@ func (s *SpacePlatform) SetPhysicsState(physics PhysicsState) {
 	s.UniversalPlatform.SetPhysicsState(physics)
 }
 
-// Update implements simplified orbital mechanics
+// OrbitElements returns Keplerian elements for the platform's apogee, perigee and inclination,
+// ascending northward over its current longitude at t
+func (s *SpacePlatform) OrbitElements(t time.Time) KeplerianElements {
+	semiMajorAxis, eccentricity := OrbitFromApsides(s.Apogee, s.Perigee)
+	raan := s.State.Position.Longitude + greenwichSiderealTime(julianDate(t))*180/math.Pi
+	return KeplerianElements{
+		SemiMajorAxis: semiMajorAxis,
+		Eccentricity:  eccentricity,
+		Inclination:   s.Inclination,
+		RAAN:          math.Mod(raan, 360),
+		Epoch:         t,
+	}
+}
+
+// Update propagates the platform's orbit, taking a Keplerian orbit from its apsides on the first
+// update when it has none
 func (s *SpacePlatform) Update(deltaTime time.Duration) error {
-	// Use enhanced orbital mechanics from UniversalPlatform base
-	// but can override for specialized space platform behavior
+	if s.Orbit == nil && s.Apogee > 0 && s.Perigee > 0 {
+		if orbit, err := NewKeplerOrbit(s.OrbitElements(s.State.LastUpdated)); err == nil {
+			s.Orbit = orbit
+		}
+	}
+	if s.Orbit != nil {
+		return s.UpdateOrbit(s.State.LastUpdated.Add(deltaTime))
+	}
 
+	// Without an orbit, fall back to a circular equatorial approximation
 	// Simplified orbital mechanics - circular orbit approximation
 	dt := deltaTime.Seconds()
 
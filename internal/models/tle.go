@@ -0,0 +1,263 @@
+package models
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TLE is a NORAD two-line element set: the mean orbital elements of a satellite at an epoch, as
+// published by CelesTrak and Space-Track
+type TLE struct {
+	Name           string    // Line 0 of the three-line format, empty for bare two-line sets
+	CatalogNumber  int       // NORAD catalog number
+	Classification byte      // U, C or S
+	Designator     string    // International designator, e.g. "98067A"
+	Epoch          time.Time // UTC
+	MeanMotionDot  float64   // First derivative of mean motion divided by two, rev/day²
+	MeanMotionDDot float64   // Second derivative of mean motion divided by six, rev/day³
+	BStar          float64   // Drag term, 1/earth radii
+	ElementSet     int
+	Inclination    float64 // degrees
+	RAAN           float64 // Right ascension of the ascending node, degrees
+	Eccentricity   float64
+	ArgPerigee     float64 // Argument of perigee, degrees
+	MeanAnomaly    float64 // degrees
+	MeanMotion     float64 // revolutions per day
+	RevNumber      int     // Revolutions at epoch
+
+	epochYear int     // Four digit year of the epoch
+	epochDay  float64 // Day of the year and fraction, 1.0 being January 1st 00:00 UTC
+}
+
+// ParseTLE parses the two lines of an element set, checking their layout and checksums
+func ParseTLE(line1, line2 string) (TLE, error) {
+	line1 = strings.TrimRight(line1, " \r\n")
+	line2 = strings.TrimRight(line2, " \r\n")
+	if len(line1) < 69 || line1[0] != '1' {
+		return TLE{}, fmt.Errorf("TLE line 1 must be 69 characters starting with 1, got %d characters", len(line1))
+	}
+	if len(line2) < 69 || line2[0] != '2' {
+		return TLE{}, fmt.Errorf("TLE line 2 must be 69 characters starting with 2, got %d characters", len(line2))
+	}
+	for _, line := range []string{line1, line2} {
+		if err := verifyTLEChecksum(line); err != nil {
+			return TLE{}, err
+		}
+	}
+
+	p := tleParser{}
+	tle := TLE{
+		CatalogNumber:  p.catalog(line1[2:7]),
+		Classification: line1[7],
+		Designator:     strings.TrimSpace(line1[9:17]),
+		MeanMotionDot:  p.float(line1[33:43], "mean motion derivative"),
+		MeanMotionDDot: p.exponent(line1[44:52], "mean motion second derivative"),
+		BStar:          p.exponent(line1[53:61], "BSTAR"),
+		ElementSet:     p.int(line1[64:68], "element set number"),
+		Inclination:    p.float(line2[8:16], "inclination"),
+		RAAN:           p.float(line2[17:25], "right ascension of the ascending node"),
+		Eccentricity:   p.float("0."+strings.TrimSpace(line2[26:33]), "eccentricity"),
+		ArgPerigee:     p.float(line2[34:42], "argument of perigee"),
+		MeanAnomaly:    p.float(line2[43:51], "mean anomaly"),
+		MeanMotion:     p.float(line2[52:63], "mean motion"),
+		RevNumber:      p.int(line2[63:68], "revolution number"),
+	}
+	year := p.int(line1[18:20], "epoch year")
+	tle.epochDay = p.float(line1[20:32], "epoch day")
+	if catalog2 := p.catalog(line2[2:7]); p.err == nil && catalog2 != tle.CatalogNumber {
+		return TLE{}, fmt.Errorf("TLE lines are for different satellites: %d and %d", tle.CatalogNumber, catalog2)
+	}
+	if p.err != nil {
+		return TLE{}, p.err
+	}
+
+	// Two digit years run from 1957, the year of the first satellite
+	if year < 57 {
+		year += 2000
+	} else {
+		year += 1900
+	}
+	tle.epochYear = year
+	tle.Epoch = time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC).
+		Add(time.Duration((tle.epochDay - 1) * 86400 * float64(time.Second)))
+
+	if tle.MeanMotion <= 0 {
+		return TLE{}, fmt.Errorf("TLE %d: mean motion must be positive", tle.CatalogNumber)
+	}
+	return tle, nil
+}
+
+// ParseTLEs reads element sets in the CelesTrak text format: either bare pairs of lines, or each
+// pair preceded by a name line. Blank lines are skipped.
+func ParseTLEs(r io.Reader) ([]TLE, error) {
+	var (
+		sets  []TLE
+		name  string
+		line1 string
+		row   int
+		row1  int // Row of line1
+	)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		row++
+		line := strings.TrimRight(scanner.Text(), " \r")
+		switch {
+		case strings.TrimSpace(line) == "":
+			continue
+		case strings.HasPrefix(line, "1 ") && line1 == "":
+			line1, row1 = line, row
+		case strings.HasPrefix(line, "2 ") && line1 != "":
+			tle, err := ParseTLE(line1, line)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", row, err)
+			}
+			tle.Name = name
+			sets = append(sets, tle)
+			name, line1 = "", ""
+		case line1 != "":
+			return nil, fmt.Errorf("line %d: expected line 2 of the element set started on line %d", row, row1)
+		default:
+			name = strings.TrimSpace(strings.TrimPrefix(line, "0 "))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if line1 != "" {
+		return nil, fmt.Errorf("line %d: element set is missing line 2", row1)
+	}
+	return sets, nil
+}
+
+// ReadTLEFile reads every element set in a CelesTrak format file
+func ReadTLEFile(path string) ([]TLE, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open TLE file: %w", err)
+	}
+	defer file.Close()
+
+	sets, err := ParseTLEs(file)
+	if err != nil {
+		return nil, fmt.Errorf("TLE file %s: %w", path, err)
+	}
+	return sets, nil
+}
+
+// verifyTLEChecksum checks the modulo 10 checksum in column 69: the sum of the digits of the line,
+// with minus signs counting as one
+func verifyTLEChecksum(line string) error {
+	sum := 0
+	for _, c := range line[:68] {
+		switch {
+		case c >= '0' && c <= '9':
+			sum += int(c - '0')
+		case c == '-':
+			sum++
+		}
+	}
+	if want := int(line[68] - '0'); sum%10 != want {
+		return fmt.Errorf("TLE checksum mismatch on line %c: computed %d, line has %c", line[0], sum%10, line[68])
+	}
+	return nil
+}
+
+// tleParser converts TLE fields, keeping the first error
+type tleParser struct {
+	err error
+}
+
+// fail records an invalid field. Errors name the field but not its text, which may come from a
+// file the caller cannot otherwise read.
+func (p *tleParser) fail(field string, err error) {
+	if p.err != nil {
+		return
+	}
+	var numErr *strconv.NumError
+	if errors.As(err, &numErr) {
+		err = numErr.Err
+	}
+	p.err = fmt.Errorf("invalid TLE %s: %w", field, err)
+}
+
+func (p *tleParser) float(field, name string) float64 {
+	value, err := strconv.ParseFloat(strings.TrimSpace(field), 64)
+	if err != nil {
+		p.fail(name, err)
+	}
+	return value
+}
+
+func (p *tleParser) int(field, name string) int {
+	trimmed := strings.TrimSpace(field)
+	if trimmed == "" {
+		return 0
+	}
+	value, err := strconv.Atoi(trimmed)
+	if err != nil {
+		p.fail(name, err)
+	}
+	return value
+}
+
+// exponent parses the packed notation of BSTAR and the second derivative, where " 12345-4"
+// stands for 0.12345e-4
+func (p *tleParser) exponent(field, name string) float64 {
+	trimmed := strings.TrimSpace(field)
+	if trimmed == "" {
+		return 0
+	}
+	split := strings.LastIndexAny(trimmed, "+-")
+	if split <= 0 {
+		p.fail(name, fmt.Errorf("missing exponent"))
+		return 0
+	}
+	mantissa := trimmed[:split]
+	sign := 1.0
+	switch mantissa[0] {
+	case '-':
+		sign, mantissa = -1, mantissa[1:]
+	case '+':
+		mantissa = mantissa[1:]
+	}
+	m, err := strconv.ParseFloat("0."+mantissa, 64)
+	if err != nil {
+		p.fail(name, err)
+		return 0
+	}
+	e, err := strconv.Atoi(trimmed[split:])
+	if err != nil {
+		p.fail(name, err)
+		return 0
+	}
+	return sign * m * math.Pow10(e)
+}
+
+// catalog parses a catalog number, including the Alpha-5 form where a leading letter stands for
+// 10 to 33 (skipping I and O) so numbers above 99999 fit in five columns
+func (p *tleParser) catalog(field string) int {
+	trimmed := strings.TrimSpace(field)
+	if trimmed != "" && trimmed[0] >= 'A' && trimmed[0] <= 'Z' {
+		letter := trimmed[0]
+		if letter == 'I' || letter == 'O' {
+			p.fail("catalog number", fmt.Errorf("letters I and O are not used in Alpha-5 numbers"))
+			return 0
+		}
+		value := int(letter-'A') + 10
+		if letter > 'I' {
+			value--
+		}
+		if letter > 'O' {
+			value--
+		}
+		return value*10000 + p.int(trimmed[1:], "catalog number")
+	}
+	return p.int(trimmed, "catalog number")
+}
@@ -0,0 +1,131 @@
+package models
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	issLine1 = "1 25544U 98067A   19343.69339541  .00001764  00000-0  38792-4 0  9991"
+	issLine2 = "2 25544  51.6439 211.2001 0007417  17.6667  85.6398 15.50103472202482"
+)
+
+func TestParseTLE(t *testing.T) {
+	tle, err := ParseTLE(issLine1, issLine2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tle.CatalogNumber != 25544 || tle.Classification != 'U' || tle.Designator != "98067A" {
+		t.Errorf("Unexpected identification %+v", tle)
+	}
+	wantEpoch := time.Date(2019, 12, 9, 16, 38, 29, 363_000_000, time.UTC)
+	if d := tle.Epoch.Sub(wantEpoch); d < -time.Millisecond || d > time.Millisecond {
+		t.Errorf("Epoch = %v, want %v", tle.Epoch, wantEpoch)
+	}
+	if math.Abs(tle.BStar-0.38792e-4) > 1e-12 {
+		t.Errorf("BStar = %v", tle.BStar)
+	}
+	if tle.Inclination != 51.6439 || tle.RAAN != 211.2001 || tle.Eccentricity != 0.0007417 ||
+		tle.ArgPerigee != 17.6667 || tle.MeanAnomaly != 85.6398 || tle.MeanMotion != 15.50103472 {
+		t.Errorf("Unexpected elements %+v", tle)
+	}
+	if tle.RevNumber != 20248 || tle.ElementSet != 999 {
+		t.Errorf("RevNumber = %d, ElementSet = %d", tle.RevNumber, tle.ElementSet)
+	}
+}
+
+func TestParseTLE_Invalid(t *testing.T) {
+	tests := []struct {
+		name         string
+		line1, line2 string
+	}{
+		{"short line", issLine1[:60], issLine2},
+		{"swapped lines", issLine2, issLine1},
+		{"checksum", issLine1[:68] + "0", issLine2},
+		{"different satellites", issLine1, "2 25545" + issLine2[7:68] + "3"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseTLE(tt.line1, tt.line2); err == nil {
+				t.Error("Expected an error")
+			}
+		})
+	}
+}
+
+func TestTLEParser_Fields(t *testing.T) {
+	p := tleParser{}
+	if got := p.exponent(" 12345-4", "test"); math.Abs(got-0.12345e-4) > 1e-15 {
+		t.Errorf("exponent = %v", got)
+	}
+	if got := p.exponent("-11606-4", "test"); math.Abs(got+0.11606e-4) > 1e-15 {
+		t.Errorf("negative exponent = %v", got)
+	}
+	if got := p.exponent(" 00000+0", "test"); got != 0 {
+		t.Errorf("zero exponent = %v", got)
+	}
+	if got := p.catalog("A0001"); got != 100001 {
+		t.Errorf("Alpha-5 A0001 = %d, want 100001", got)
+	}
+	if got := p.catalog("J2345"); got != 182345 {
+		t.Errorf("Alpha-5 J2345 = %d, want 182345", got)
+	}
+	if got := p.catalog("Z9999"); got != 339999 {
+		t.Errorf("Alpha-5 Z9999 = %d, want 339999", got)
+	}
+	if p.err != nil {
+		t.Fatal(p.err)
+	}
+	p.catalog("I0001")
+	if p.err == nil {
+		t.Error("Expected I to be rejected in Alpha-5 numbers")
+	}
+}
+
+func TestParseTLEs(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		{"two line", issLine1 + "\n" + issLine2 + "\n", []string{""}},
+		{"three line", "ISS (ZARYA)\n" + issLine1 + "\n" + issLine2 + "\n", []string{"ISS (ZARYA)"}},
+		{"numbered name", "0 ISS (ZARYA)\r\n" + issLine1 + "\r\n" + issLine2 + "\r\n", []string{"ISS (ZARYA)"}},
+		{"several", "ISS\n" + issLine1 + "\n" + issLine2 + "\n\nAGAIN\n" + issLine1 + "\n" + issLine2, []string{"ISS", "AGAIN"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sets, err := ParseTLEs(strings.NewReader(tt.input))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(sets) != len(tt.want) {
+				t.Fatalf("Got %d element sets, want %d", len(sets), len(tt.want))
+			}
+			for i, name := range tt.want {
+				if sets[i].Name != name {
+					t.Errorf("Name %d = %q, want %q", i, sets[i].Name, name)
+				}
+			}
+		})
+	}
+
+	if _, err := ParseTLEs(strings.NewReader("ISS\n" + issLine1 + "\n")); err == nil {
+		t.Error("Expected an element set without line 2 to be rejected")
+	}
+
+	// Errors give line numbers rather than the text, which may come from a file the caller cannot read
+	for _, input := range []string{
+		"root:x:0:0\n1 this-line-is-a-secret\n",
+		"1 this-line-is-a-secret\nroot:x:0:0\n",
+		"1 this-line-is-a-secret\n2 this-line-is-a-secret\n",
+		"1 25544U 98067A   this-line-is-a-secret     .00002182  00000-0 -11606-4 0  2927\n" + issLine2,
+	} {
+		_, err := ParseTLEs(strings.NewReader(input))
+		if err == nil || strings.Contains(err.Error(), "secret") || strings.Contains(err.Error(), "root") {
+			t.Errorf("Expected an error without the file contents for %q, got %v", input, err)
+		}
+	}
+}
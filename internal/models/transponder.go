@@ -0,0 +1,145 @@
+package models
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strconv"
+	"strings"
+)
+
+// Squawk codes with a fixed meaning, never handed out as discrete codes
+var reservedSquawks = map[uint32]bool{
+	0o0000: true, 0o1200: true, 0o2000: true, 0o7000: true,
+	0o7500: true, 0o7600: true, 0o7700: true, 0o7777: true,
+}
+
+// ParseICAOAddress parses a 24-bit ICAO aircraft address written as six hex digits, e.g. "A1B2C3"
+func ParseICAOAddress(address string) (uint32, error) {
+	if len(address) != 6 {
+		return 0, fmt.Errorf("ICAO address %q must be six hex digits", address)
+	}
+	value, err := strconv.ParseUint(address, 16, 32)
+	if err != nil {
+		return 0, fmt.Errorf("ICAO address %q must be six hex digits", address)
+	}
+	if value == 0 || value == 0xFFFFFF {
+		return 0, fmt.Errorf("ICAO address %q is reserved", address)
+	}
+	return uint32(value), nil
+}
+
+// ValidateSquawk checks that an optional squawk is four octal digits, e.g. "4521"
+func ValidateSquawk(squawk string) error {
+	if squawk == "" {
+		return nil
+	}
+	if len(squawk) != 4 || strings.Trim(squawk, "01234567") != "" {
+		return fmt.Errorf("squawk %q must be four octal digits", squawk)
+	}
+	return nil
+}
+
+// ParseMMSI parses a nine digit AIS Maritime Mobile Service Identity, e.g. "366123456"
+func ParseMMSI(mmsi string) (uint32, error) {
+	if len(mmsi) != 9 || strings.Trim(mmsi, "0123456789") != "" {
+		return 0, fmt.Errorf("MMSI %q must be nine digits", mmsi)
+	}
+	value, _ := strconv.ParseUint(mmsi, 10, 32)
+	return uint32(value), nil
+}
+
+// ValidateTransponder checks optional icao_address, squawk and mmsi overrides
+func ValidateTransponder(icaoAddress, squawk, mmsi string) error {
+	if icaoAddress != "" {
+		if _, err := ParseICAOAddress(icaoAddress); err != nil {
+			return err
+		}
+	}
+	if mmsi != "" {
+		if _, err := ParseMMSI(mmsi); err != nil {
+			return err
+		}
+	}
+	return ValidateSquawk(squawk)
+}
+
+// ICAOAddressOf returns the platform's configured ICAO address, or one derived from its ID so the
+// same platform keeps the same address across runs
+func ICAOAddressOf(platform Platform) uint32 {
+	if config := platformConfiguration(platform); config != nil && config.ICAOAddress != "" {
+		if address, err := ParseICAOAddress(config.ICAOAddress); err == nil {
+			return address
+		}
+	}
+
+	hash := hashID(platform.GetID())
+	address := (hash ^ hash>>24) & 0xFFFFFF
+	if address == 0 || address == 0xFFFFFF {
+		address = 0x000001
+	}
+	return address
+}
+
+// SquawkOf returns the platform's configured squawk, or a discrete code derived from its ID that
+// avoids the VFR, emergency and other reserved codes
+func SquawkOf(platform Platform) string {
+	if config := platformConfiguration(platform); config != nil && config.Squawk != "" {
+		return config.Squawk
+	}
+
+	// Discrete codes 0100-6777, skipping reserved ones
+	code := 0o0100 + hashID(platform.GetID())%(0o7000-0o0100)
+	for reservedSquawks[code] {
+		code++
+	}
+	return fmt.Sprintf("%04o", code)
+}
+
+// MMSIOf returns the vessel's configured MMSI, or a ship station MMSI derived from its ID, with a
+// maritime identification digit between 201 and 775
+func MMSIOf(platform Platform) uint32 {
+	if config := platformConfiguration(platform); config != nil && config.MMSI != "" {
+		if mmsi, err := ParseMMSI(config.MMSI); err == nil {
+			return mmsi
+		}
+	}
+
+	hash := hashID(platform.GetID())
+	mid := 201 + hash%575
+	return mid*1000000 + (hash/575)%1000000
+}
+
+// IsAISDark reports whether a vessel has been configured not to transmit AIS
+func IsAISDark(platform Platform) bool {
+	config := platformConfiguration(platform)
+	return config != nil && config.AISDark
+}
+
+// TransponderCallsign formats a callsign for ADS-B: at most eight upper case letters and digits
+func TransponderCallsign(callsign string) string {
+	var builder strings.Builder
+	for _, r := range strings.ToUpper(callsign) {
+		if (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			builder.WriteRune(r)
+			if builder.Len() == 8 {
+				break
+			}
+		}
+	}
+	return builder.String()
+}
+
+// platformConfiguration returns the scenario configuration of a UniversalPlatform
+func platformConfiguration(platform Platform) *PlatformConfiguration {
+	if universalPlatform, ok := platform.(*UniversalPlatform); ok {
+		return universalPlatform.Config
+	}
+	return nil
+}
+
+// hashID hashes a platform ID with FNV-1a
+func hashID(id string) uint32 {
+	hash := fnv.New32a()
+	hash.Write([]byte(id))
+	return hash.Sum32()
+}
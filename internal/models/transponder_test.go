@@ -0,0 +1,74 @@
+package models
+
+import "testing"
+
+func TestParseICAOAddressAndSquawk(t *testing.T) {
+	if address, err := ParseICAOAddress("a1b2C3"); err != nil || address != 0xA1B2C3 {
+		t.Errorf("ParseICAOAddress(a1b2C3) = %06X, %v", address, err)
+	}
+	for _, invalid := range []string{"", "A1B2C", "A1B2C3D", "G1B2C3", "000000", "FFFFFF"} {
+		if _, err := ParseICAOAddress(invalid); err == nil {
+			t.Errorf("Expected ParseICAOAddress(%q) to fail", invalid)
+		}
+	}
+
+	for _, valid := range []string{"", "1200", "4521", "7700"} {
+		if err := ValidateSquawk(valid); err != nil {
+			t.Errorf("ValidateSquawk(%q) failed: %v", valid, err)
+		}
+	}
+	for _, invalid := range []string{"120", "12000", "1280", "A200"} {
+		if err := ValidateSquawk(invalid); err == nil {
+			t.Errorf("Expected ValidateSquawk(%q) to fail", invalid)
+		}
+	}
+}
+
+func TestTransponderDerivedFromPlatform(t *testing.T) {
+	platform := NewBoeing737_800Universal("UAL123", "UA123", Position{Latitude: 40.6, Longitude: -73.8, Altitude: 10000})
+	other := NewBoeing737_800Universal("UAL124", "UA124", Position{})
+
+	// Derived values are stable, distinct and never reserved
+	address := ICAOAddressOf(platform)
+	if address == 0 || address > 0xFFFFFF || address != ICAOAddressOf(platform) || address == ICAOAddressOf(other) {
+		t.Errorf("Unexpected derived ICAO address %06X", address)
+	}
+	squawk := SquawkOf(platform)
+	if err := ValidateSquawk(squawk); err != nil || squawk[0] == '7' || squawk == "1200" || squawk != SquawkOf(platform) {
+		t.Errorf("Unexpected derived squawk %q", squawk)
+	}
+
+	// Scenario overrides win
+	platform.Config.ICAOAddress = "A1B2C3"
+	platform.Config.Squawk = "7700"
+	if ICAOAddressOf(platform) != 0xA1B2C3 || SquawkOf(platform) != "7700" {
+		t.Errorf("Expected configured transponder, got %06X %s", ICAOAddressOf(platform), SquawkOf(platform))
+	}
+
+	if got := TransponderCallsign("ua-123 heavy"); got != "UA123HEA" {
+		t.Errorf("TransponderCallsign = %q, want UA123HEA", got)
+	}
+}
+
+func TestMMSIOf(t *testing.T) {
+	ship := NewContainerShipUniversal("MAERSK1", "Maersk Alabama", Position{})
+	mmsi := MMSIOf(ship)
+	if mid := mmsi / 1000000; mid < 201 || mid > 775 || mmsi != MMSIOf(ship) {
+		t.Errorf("Unexpected derived MMSI %09d", mmsi)
+	}
+
+	ship.Config.MMSI = "366123456"
+	if MMSIOf(ship) != 366123456 {
+		t.Errorf("Expected configured MMSI, got %d", MMSIOf(ship))
+	}
+	if IsAISDark(ship) {
+		t.Error("Expected vessels to transmit AIS unless configured dark")
+	}
+
+	if err := ValidateTransponder("", "", "36612345"); err == nil {
+		t.Error("Expected an eight digit MMSI to be rejected")
+	}
+	if err := ValidateTransponder("", "", "36612345X"); err == nil {
+		t.Error("Expected a non-numeric MMSI to be rejected")
+	}
+}
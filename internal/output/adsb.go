@@ -0,0 +1,133 @@
+package output
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/rhino11/trafficsim/internal/models"
+)
+
+// ADS-B output formats
+const (
+	ADSBFormatSBS   = "sbs"   // SBS-1 BaseStation CSV, as served on port 30003, over tcp://
+	ADSBFormatGDL90 = "gdl90" // GDL90 traffic reports over udp://, usually to an EFB on port 4000
+)
+
+// Unit conversions for ADS-B fields
+const (
+	feetPerMeter      = 3.28084
+	knotsPerMeterSec  = 1.943844
+	fpmPerMeterSecond = 196.850394
+)
+
+// GDL90 emitter categories
+const (
+	EmitterNoInfo          = 0
+	EmitterLight           = 1 // < 15500 lb
+	EmitterSmall           = 2 // 15500 to 75000 lb
+	EmitterLarge           = 3 // 75000 to 300000 lb
+	EmitterHeavy           = 5 // > 300000 lb
+	EmitterHighPerformance = 6 // > 5g and 400 kt
+	EmitterRotorcraft      = 7
+)
+
+// ADSBTarget is an airborne platform as reported by its transponder
+type ADSBTarget struct {
+	ICAO         uint32  // 24-bit aircraft address
+	Callsign     string  // Up to eight characters
+	Squawk       string  // Four octal digits
+	Latitude     float64 // degrees
+	Longitude    float64 // degrees
+	Altitude     float64 // feet
+	GroundSpeed  float64 // knots
+	Track        float64 // degrees true
+	VerticalRate float64 // feet per minute
+	OnGround     bool
+	Emitter      byte // GDL90 emitter category
+}
+
+// ADSBPublisher sends ADS-B reports for airborne platforms
+type ADSBPublisher interface {
+	PublishTarget(target ADSBTarget) error
+	SetClock(clock models.Clock)
+	Close() error
+}
+
+// PlatformToADSBTarget converts an airborne platform to an ADS-B target; other platforms have no
+// transponder and return false
+func PlatformToADSBTarget(platform models.Platform) (ADSBTarget, bool) {
+	if platform.GetType() != models.PlatformTypeAirborne {
+		return ADSBTarget{}, false
+	}
+	state := platform.GetState()
+
+	return ADSBTarget{
+		ICAO:         models.ICAOAddressOf(platform),
+		Callsign:     models.TransponderCallsign(platform.GetCallSign()),
+		Squawk:       models.SquawkOf(platform),
+		Latitude:     state.Position.Latitude,
+		Longitude:    state.Position.Longitude,
+		Altitude:     state.Position.Altitude * feetPerMeter,
+		GroundSpeed:  state.Speed * knotsPerMeterSec,
+		Track:        math.Mod(state.Heading+360, 360),
+		VerticalRate: state.Velocity.Up * fpmPerMeterSecond,
+		OnGround:     state.Position.Altitude <= 0,
+		Emitter:      emitterCategory(platform),
+	}, true
+}
+
+// emitterCategory picks the GDL90 emitter category from the platform class and mass
+func emitterCategory(platform models.Platform) byte {
+	class := strings.ToLower(platform.GetClass())
+	if strings.Contains(class, "helicopter") || strings.Contains(class, "rotor") {
+		return EmitterRotorcraft
+	}
+	if up, ok := platform.(*models.UniversalPlatform); ok && up.TypeDef != nil {
+		category := strings.ToLower(up.TypeDef.Category)
+		if strings.Contains(category, "helicopter") || strings.Contains(category, "rotor") {
+			return EmitterRotorcraft
+		}
+		if strings.Contains(category, "military") && platform.GetMaxSpeed()*knotsPerMeterSec > 400 {
+			return EmitterHighPerformance
+		}
+	}
+
+	pounds := platform.GetMass() * 2.20462
+	switch {
+	case pounds <= 0:
+		return EmitterNoInfo
+	case pounds < 15500:
+		return EmitterLight
+	case pounds < 75000:
+		return EmitterSmall
+	case pounds < 300000:
+		return EmitterLarge
+	default:
+		return EmitterHeavy
+	}
+}
+
+// NewADSBPublisher creates an SBS-1 publisher for a tcp:// endpoint or a GDL90 publisher for a
+// udp:// endpoint
+func NewADSBPublisher(format, endpoint string, opts StreamOptions) (ADSBPublisher, error) {
+	ep, err := ParseEndpoint(endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	switch strings.ToLower(format) {
+	case ADSBFormatSBS:
+		if ep.Scheme != SchemeTCP {
+			return nil, fmt.Errorf("SBS-1 output needs a tcp:// endpoint, got %s", ep)
+		}
+		return NewSBSPublisher(ep.Address(), opts), nil
+	case ADSBFormatGDL90:
+		if ep.Scheme != SchemeUDP {
+			return nil, fmt.Errorf("GDL90 output needs a udp:// endpoint, got %s", ep)
+		}
+		return NewGDL90Publisher(ep.Address())
+	default:
+		return nil, fmt.Errorf("unknown ADS-B format %q (want %s or %s)", format, ADSBFormatSBS, ADSBFormatGDL90)
+	}
+}
@@ -0,0 +1,201 @@
+package output
+
+import (
+	"bufio"
+	"bytes"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rhino11/trafficsim/internal/models"
+)
+
+// testTarget is the traffic report example from the GDL90 ICD
+var testTarget = ADSBTarget{
+	ICAO:         0xAB4549,
+	Callsign:     "N825V",
+	Squawk:       "4521",
+	Latitude:     float64(0x1FEF15) * 180 / (1 << 23),
+	Longitude:    float64(0xA88978-(1<<24)) * 180 / (1 << 23),
+	Altitude:     5000,
+	GroundSpeed:  123,
+	Track:        45,
+	VerticalRate: 64,
+	Emitter:      EmitterLight,
+}
+
+func TestPlatformToADSBTarget(t *testing.T) {
+	aircraft := models.NewBoeing737_800Universal("UAL123", "UA123", models.Position{Latitude: 40.6, Longitude: -73.8, Altitude: 10000})
+	aircraft.State.Speed = 200
+	aircraft.State.Heading = -90
+	aircraft.State.Velocity.Up = 5
+	aircraft.Config.Squawk = "7600"
+
+	target, ok := PlatformToADSBTarget(aircraft)
+	if !ok {
+		t.Fatal("Expected an airborne platform to have a transponder")
+	}
+	if target.ICAO != models.ICAOAddressOf(aircraft) || target.Callsign != "UA123" || target.Squawk != "7600" {
+		t.Errorf("Unexpected identity %06X %q %q", target.ICAO, target.Callsign, target.Squawk)
+	}
+	if int(target.Altitude) != 32808 || int(target.GroundSpeed) != 388 || target.Track != 270 || int(target.VerticalRate) != 984 {
+		t.Errorf("Unexpected kinematics %+v", target)
+	}
+	if target.OnGround || target.Emitter != EmitterLarge {
+		t.Errorf("Expected a large airborne emitter, got %+v", target)
+	}
+
+	ship := models.NewArleighBurkeDestroyerUniversal("DDG51", "Arleigh Burke", models.Position{})
+	if _, ok := PlatformToADSBTarget(ship); ok {
+		t.Error("Expected ships to have no ADS-B transponder")
+	}
+}
+
+func TestFormatSBS(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 123e6, time.UTC)
+	want := "" +
+		"MSG,1,1,1,AB4549,1,2024/06/01,12:00:00.123,2024/06/01,12:00:00.123,N825V,,,,,,,,0,0,0,0\r\n" +
+		"MSG,3,1,1,AB4549,1,2024/06/01,12:00:00.123,2024/06/01,12:00:00.123,,5000,,,44.90707,-122.99486,,,0,0,0,0\r\n" +
+		"MSG,4,1,1,AB4549,1,2024/06/01,12:00:00.123,2024/06/01,12:00:00.123,,,123,45,,,64,,0,0,0,0\r\n" +
+		"MSG,6,1,1,AB4549,1,2024/06/01,12:00:00.123,2024/06/01,12:00:00.123,,5000,,,,,,4521,0,0,0,0\r\n"
+	if got := string(FormatSBS(testTarget, now)); got != want {
+		t.Errorf("FormatSBS =\n%s\nwant\n%s", got, want)
+	}
+
+	emergency := testTarget
+	emergency.Squawk = "7700"
+	emergency.OnGround = true
+	line := strings.Split(string(FormatSBS(emergency, now)), "\r\n")[3]
+	if !strings.HasSuffix(line, ",7700,0,-1,0,-1") {
+		t.Errorf("Expected emergency and on-ground flags, got %s", line)
+	}
+}
+
+func TestGDL90_ICDExamples(t *testing.T) {
+	// Heartbeat example from the ICD, including its CRC
+	heartbeat := []byte{0x00, 0x81, 0x41, 0xDB, 0xD0, 0x08, 0x02}
+	want := []byte{0x7E, 0x00, 0x81, 0x41, 0xDB, 0xD0, 0x08, 0x02, 0xB3, 0x8B, 0x7E}
+	if got := FrameGDL90(heartbeat); !bytes.Equal(got, want) {
+		t.Errorf("FrameGDL90 = % X, want % X", got, want)
+	}
+
+	traffic := []byte{
+		0x14, 0x00, 0xAB, 0x45, 0x49, 0x1F, 0xEF, 0x15, 0xA8, 0x89, 0x78, 0x0F, 0x09, 0xA9,
+		0x07, 0xB0, 0x01, 0x20, 0x01, 0x4E, 0x38, 0x32, 0x35, 0x56, 0x20, 0x20, 0x20, 0x00,
+	}
+	if got := EncodeGDL90Traffic(testTarget); !bytes.Equal(got, traffic) {
+		t.Errorf("EncodeGDL90Traffic =\n% X\nwant\n% X", got, traffic)
+	}
+}
+
+func TestGDL90_FramingRoundTrip(t *testing.T) {
+	// Messages containing the flag and escape bytes are escaped on the wire
+	message := []byte{0x14, 0x7E, 0x7D, 0x00, 0x7E}
+	frame := FrameGDL90(message)
+	if bytes.Count(frame, []byte{0x7E}) != 2 {
+		t.Errorf("Expected only the delimiting flags in % X", frame)
+	}
+	parsed, err := ParseGDL90(frame)
+	if err != nil || !bytes.Equal(parsed, message) {
+		t.Errorf("ParseGDL90 = % X, %v", parsed, err)
+	}
+
+	frame[2] ^= 0x01
+	if _, err := ParseGDL90(frame); err == nil {
+		t.Error("Expected a CRC error for a corrupted frame")
+	}
+
+	heartbeat := EncodeGDL90Heartbeat(time.Date(2024, 6, 1, 23, 59, 59, 0, time.UTC))
+	seconds := 86399 // Needs timestamp bit 16
+	if heartbeat[2] != 0x81 || heartbeat[3] != byte(seconds) || heartbeat[4] != byte(seconds>>8) {
+		t.Errorf("Unexpected heartbeat % X", heartbeat)
+	}
+}
+
+func TestSBSPublisher_TCP(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer listener.Close()
+
+	publisher, err := NewADSBPublisher("sbs", "tcp://"+listener.Addr().String(), StreamOptions{})
+	if err != nil {
+		t.Fatalf("NewADSBPublisher failed: %v", err)
+	}
+	defer publisher.Close()
+	if err := publisher.PublishTarget(testTarget); err != nil {
+		t.Fatal(err)
+	}
+
+	conn, err := listener.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	reader := bufio.NewReader(conn)
+	for _, prefix := range []string{"MSG,1,", "MSG,3,", "MSG,4,", "MSG,6,"} {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("Failed to read SBS line: %v", err)
+		}
+		if !strings.HasPrefix(line, prefix) || !strings.HasSuffix(line, "\r\n") || strings.Count(line, ",") != 21 {
+			t.Errorf("Unexpected SBS line %q", line)
+		}
+	}
+}
+
+func TestGDL90Publisher_UDP(t *testing.T) {
+	receiver, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer receiver.Close()
+
+	publisher, err := NewADSBPublisher("GDL90", "udp://"+receiver.LocalAddr().String(), StreamOptions{})
+	if err != nil {
+		t.Fatalf("NewADSBPublisher failed: %v", err)
+	}
+	defer publisher.Close()
+	if err := publisher.PublishTarget(testTarget); err != nil {
+		t.Fatal(err)
+	}
+
+	// The heartbeat goes out on start, the traffic report after it
+	receiver.SetReadDeadline(time.Now().Add(5 * time.Second))
+	buf := make([]byte, 1500)
+	var ids []byte
+	for len(ids) < 2 {
+		n, err := receiver.Read(buf)
+		if err != nil {
+			t.Fatalf("Failed to read GDL90 datagram: %v", err)
+		}
+		message, err := ParseGDL90(buf[:n])
+		if err != nil {
+			t.Fatalf("Invalid GDL90 frame % X: %v", buf[:n], err)
+		}
+		ids = append(ids, message[0])
+		if message[0] == gdl90TrafficID && !bytes.Equal(message, EncodeGDL90Traffic(testTarget)) {
+			t.Errorf("Unexpected traffic report % X", message)
+		}
+	}
+	if !bytes.Contains(ids, []byte{gdl90HeartbeatID}) || !bytes.Contains(ids, []byte{gdl90TrafficID}) {
+		t.Errorf("Expected a heartbeat and a traffic report, got IDs % X", ids)
+	}
+}
+
+func TestNewADSBPublisher_Errors(t *testing.T) {
+	for _, tt := range []struct{ format, endpoint string }{
+		{"sbs", "udp://127.0.0.1:30003"},
+		{"gdl90", "tcp://127.0.0.1:4000"},
+		{"asterix", "udp://127.0.0.1:4000"},
+		{"sbs", "127.0.0.1:30003"},
+	} {
+		if publisher, err := NewADSBPublisher(tt.format, tt.endpoint, StreamOptions{}); err == nil {
+			publisher.Close()
+			t.Errorf("Expected NewADSBPublisher(%q, %q) to fail", tt.format, tt.endpoint)
+		}
+	}
+}
@@ -0,0 +1,452 @@
+package output
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rhino11/trafficsim/internal/models"
+)
+
+// AIS message types
+const (
+	aisPositionReportA = 1  // Class A scheduled position report
+	aisStaticVoyage    = 5  // Class A static and voyage related data
+	aisPositionReportB = 18 // Class B standard position report
+	aisStaticDataB     = 24 // Class B static data report, in parts A and B
+)
+
+// AIS navigation status and ship type codes
+const (
+	AISStatusUnderWay = 0 // Under way using engine
+	AISStatusAtAnchor = 1
+
+	AISShipTypeFishing   = 30
+	AISShipTypeMilitary  = 35
+	AISShipTypePleasure  = 37
+	AISShipTypeTug       = 52
+	AISShipTypePassenger = 60
+	AISShipTypeCargo     = 70
+	AISShipTypeTanker    = 80
+	AISShipTypeOther     = 90
+)
+
+// AIS encoding limits
+const (
+	aisMaxFragmentChars   = 60              // Payload characters per sentence, within the NMEA 82 character limit
+	aisClassBMaxLength    = 20.0            // meters; smaller vessels carry Class B transponders
+	aisStaticInterval     = 6 * time.Minute // How often static data is repeated, as for a Class A station
+	aisStoppedSpeed       = 0.1             // m/s below which a vessel is reported at anchor
+	aisNotAvailableROT    = -128
+	aisNotAvailableETAHr  = 24
+	aisNotAvailableETAMin = 60
+)
+
+// AISVessel is a maritime platform as reported by its AIS transponder
+type AISVessel struct {
+	MMSI        uint32
+	Name        string // Up to 20 characters
+	Callsign    string // Up to 7 characters
+	ShipType    byte
+	ClassB      bool
+	NavStatus   byte
+	Latitude    float64 // degrees
+	Longitude   float64 // degrees
+	SOG         float64 // Speed over ground, knots
+	COG         float64 // Course over ground, degrees true
+	Heading     float64 // degrees true
+	ToBow       int     // meters from the position reference to the bow
+	ToStern     int
+	ToPort      int
+	ToStarboard int
+	Draught     float64 // meters
+	Destination string  // Up to 20 characters
+}
+
+// PlatformToAISVessel converts a maritime platform to an AIS vessel. Other platforms, and vessels
+// configured with ais_dark, do not transmit and return false.
+func PlatformToAISVessel(platform models.Platform) (AISVessel, bool) {
+	if platform.GetType() != models.PlatformTypeMaritime || models.IsAISDark(platform) {
+		return AISVessel{}, false
+	}
+	state := platform.GetState()
+
+	length, width := platform.GetLength(), platform.GetWidth()
+	var draught float64
+	shipType := byte(AISShipTypeOther)
+	if up, ok := platform.(*models.UniversalPlatform); ok && up.TypeDef != nil {
+		draught = up.TypeDef.Physical.Draft
+		shipType = aisShipType(up.TypeDef.Category + " " + up.TypeDef.Class)
+	}
+
+	navStatus := byte(AISStatusUnderWay)
+	if state.Speed < aisStoppedSpeed {
+		navStatus = AISStatusAtAnchor
+	}
+	toBow := int(math.Round(length / 2))
+	toPort := int(math.Round(width / 2))
+
+	return AISVessel{
+		MMSI:        models.MMSIOf(platform),
+		Name:        platform.GetName(),
+		Callsign:    platform.GetCallSign(),
+		ShipType:    shipType,
+		ClassB:      length > 0 && length < aisClassBMaxLength,
+		NavStatus:   navStatus,
+		Latitude:    state.Position.Latitude,
+		Longitude:   state.Position.Longitude,
+		SOG:         state.Speed * knotsPerMeterSec,
+		COG:         math.Mod(state.Heading+360, 360),
+		Heading:     math.Mod(state.Heading+360, 360),
+		ToBow:       toBow,
+		ToStern:     int(math.Round(length)) - toBow,
+		ToPort:      toPort,
+		ToStarboard: int(math.Round(width)) - toPort,
+		Draught:     draught,
+	}, true
+}
+
+// aisShipType picks the AIS ship type code from a platform's category and class
+func aisShipType(description string) byte {
+	description = strings.ToLower(description)
+	for _, match := range []struct {
+		keywords []string
+		code     byte
+	}{
+		{[]string{"destroyer", "frigate", "cruiser", "submarine", "carrier", "warship", "military", "naval"}, AISShipTypeMilitary},
+		{[]string{"tanker"}, AISShipTypeTanker},
+		{[]string{"cargo", "container", "bulk"}, AISShipTypeCargo},
+		{[]string{"passenger", "cruise", "ferry"}, AISShipTypePassenger},
+		{[]string{"fishing", "trawler"}, AISShipTypeFishing},
+		{[]string{"tug"}, AISShipTypeTug},
+		{[]string{"yacht", "sail", "pleasure"}, AISShipTypePleasure},
+	} {
+		for _, keyword := range match.keywords {
+			if strings.Contains(description, keyword) {
+				return match.code
+			}
+		}
+	}
+	return AISShipTypeOther
+}
+
+// aisBits accumulates an AIS message one bit per byte before armoring
+type aisBits []byte
+
+// put appends the low width bits of value, most significant first
+func (b *aisBits) put(value uint64, width int) {
+	for i := width - 1; i >= 0; i-- {
+		*b = append(*b, byte(value>>uint(i)&1))
+	}
+}
+
+// putSigned appends a two's complement value
+func (b *aisBits) putSigned(value int64, width int) {
+	b.put(uint64(value), width)
+}
+
+// putText appends text as chars six-bit characters, upper cased and padded with '@'
+func (b *aisBits) putText(text string, chars int) {
+	text = strings.ToUpper(text)
+	for i := 0; i < chars; i++ {
+		var c byte = '@'
+		if i < len(text) {
+			c = text[i]
+		}
+		switch {
+		case c >= 64 && c <= 95:
+			c -= 64
+		case c >= 32 && c <= 63:
+		default:
+			c = ' '
+		}
+		b.put(uint64(c), 6)
+	}
+}
+
+// putPosition appends longitude and latitude in 1/10000 minute
+func (b *aisBits) putPosition(latitude, longitude float64) {
+	b.putSigned(int64(math.Round(longitude*600000)), 28)
+	b.putSigned(int64(math.Round(latitude*600000)), 27)
+}
+
+// aisSOG encodes speed over ground in 0.1 knot steps, 102.2 knots and above as 1022
+func aisSOG(knots float64) uint64 {
+	return uint64(math.Max(0, math.Min(1022, math.Round(knots*10))))
+}
+
+// aisCOG encodes course over ground in 0.1 degree steps
+func aisCOG(degrees float64) uint64 {
+	return uint64(math.Round(math.Mod(degrees+360, 360)*10)) % 3600
+}
+
+// aisHeading encodes true heading in whole degrees
+func aisHeading(degrees float64) uint64 {
+	return uint64(math.Round(math.Mod(degrees+360, 360))) % 360
+}
+
+// aisDimensions appends the bow, stern, port and starboard distances, clamped to their field widths
+func (b *aisBits) putDimensions(v AISVessel) {
+	clamp := func(value, max int) uint64 { return uint64(math.Max(0, math.Min(float64(max), float64(value)))) }
+	b.put(clamp(v.ToBow, 511), 9)
+	b.put(clamp(v.ToStern, 511), 9)
+	b.put(clamp(v.ToPort, 63), 6)
+	b.put(clamp(v.ToStarboard, 63), 6)
+}
+
+// EncodeAISPosition encodes a type 1 report for Class A vessels, or type 18 for Class B, with the
+// UTC second of now as the time stamp
+func EncodeAISPosition(v AISVessel, now time.Time) []byte {
+	var b aisBits
+	if v.ClassB {
+		b.put(aisPositionReportB, 6)
+		b.put(0, 2) // Repeat indicator
+		b.put(uint64(v.MMSI), 30)
+		b.put(0, 8) // Regional reserved
+		b.put(aisSOG(v.SOG), 10)
+		b.put(1, 1) // High position accuracy
+		b.putPosition(v.Latitude, v.Longitude)
+		b.put(aisCOG(v.COG), 12)
+		b.put(aisHeading(v.Heading), 9)
+		b.put(uint64(now.UTC().Second()), 6)
+		b.put(0, 2)                      // Regional reserved
+		b.put(0b1011100, 7)              // Carrier sense unit, no display, DSC, whole band, message 22, autonomous, no RAIM
+		b.put(0b1100000000000000110, 20) // Carrier sense communication state
+		return b
+	}
+
+	b.put(aisPositionReportA, 6)
+	b.put(0, 2)
+	b.put(uint64(v.MMSI), 30)
+	b.put(uint64(v.NavStatus), 4)
+	b.putSigned(aisNotAvailableROT, 8)
+	b.put(aisSOG(v.SOG), 10)
+	b.put(1, 1)
+	b.putPosition(v.Latitude, v.Longitude)
+	b.put(aisCOG(v.COG), 12)
+	b.put(aisHeading(v.Heading), 9)
+	b.put(uint64(now.UTC().Second()), 6)
+	b.put(0, 2)  // No special manoeuvre
+	b.put(0, 3)  // Spare
+	b.put(0, 1)  // No RAIM
+	b.put(0, 19) // SOTDMA communication state
+	return b
+}
+
+// EncodeAISStatic encodes static data: one type 5 message for Class A vessels, or type 24 parts A
+// and B for Class B
+func EncodeAISStatic(v AISVessel) [][]byte {
+	if v.ClassB {
+		var partA, partB aisBits
+		partA.put(aisStaticDataB, 6)
+		partA.put(0, 2)
+		partA.put(uint64(v.MMSI), 30)
+		partA.put(0, 2) // Part A
+		partA.putText(v.Name, 20)
+		partA.put(0, 8) // Spare
+
+		partB.put(aisStaticDataB, 6)
+		partB.put(0, 2)
+		partB.put(uint64(v.MMSI), 30)
+		partB.put(1, 2) // Part B
+		partB.put(uint64(v.ShipType), 8)
+		partB.putText("", 7) // Vendor ID, unit model and serial number
+		partB.putText(v.Callsign, 7)
+		partB.putDimensions(v)
+		partB.put(1, 4) // GPS
+		partB.put(0, 2) // Spare
+		return [][]byte{partA, partB}
+	}
+
+	var b aisBits
+	b.put(aisStaticVoyage, 6)
+	b.put(0, 2)
+	b.put(uint64(v.MMSI), 30)
+	b.put(0, 2)  // ITU-R M.1371-1
+	b.put(0, 30) // IMO number not available
+	b.putText(v.Callsign, 7)
+	b.putText(v.Name, 20)
+	b.put(uint64(v.ShipType), 8)
+	b.putDimensions(v)
+	b.put(1, 4) // GPS
+	b.put(0, 4) // ETA month, day, hour and minute not available
+	b.put(0, 5)
+	b.put(aisNotAvailableETAHr, 5)
+	b.put(aisNotAvailableETAMin, 6)
+	b.put(uint64(math.Max(0, math.Min(255, math.Round(v.Draught*10)))), 8)
+	b.putText(v.Destination, 20)
+	b.put(0, 1) // Data terminal ready
+	b.put(0, 1) // Spare
+	return [][]byte{b}
+}
+
+// ArmorAIS packs message bits into the AIVDM six-bit payload and returns it with the number of
+// fill bits added to the last character
+func ArmorAIS(bits []byte) (string, int) {
+	fill := (6 - len(bits)%6) % 6
+	padded := append(append([]byte{}, bits...), make([]byte, fill)...)
+
+	var payload strings.Builder
+	for i := 0; i < len(padded); i += 6 {
+		var value byte
+		for _, bit := range padded[i : i+6] {
+			value = value<<1 | bit
+		}
+		value += 48
+		if value > 87 {
+			value += 8
+		}
+		payload.WriteByte(value)
+	}
+	return payload.String(), fill
+}
+
+// DearmorAIS unpacks an AIVDM payload into message bits, dropping the fill bits
+func DearmorAIS(payload string, fill int) ([]byte, error) {
+	bits := make([]byte, 0, len(payload)*6)
+	for i := 0; i < len(payload); i++ {
+		c := payload[i]
+		if c < 48 || c > 119 || (c > 87 && c < 96) {
+			return nil, fmt.Errorf("invalid AIS payload character %q", c)
+		}
+		value := c - 48
+		if value > 40 {
+			value -= 8
+		}
+		for bit := 5; bit >= 0; bit-- {
+			bits = append(bits, value>>uint(bit)&1)
+		}
+	}
+	if fill < 0 || fill > 5 || fill > len(bits) {
+		return nil, fmt.Errorf("invalid AIS fill bits %d", fill)
+	}
+	return bits[:len(bits)-fill], nil
+}
+
+// AISSentences splits an armored payload into !AIVDM sentences of at most 60 payload characters.
+// Multipart messages carry sequenceID (0-9); the fill bits go on the last fragment only.
+func AISSentences(payload string, fill int, sequenceID int, channel string) []string {
+	count := (len(payload) + aisMaxFragmentChars - 1) / aisMaxFragmentChars
+	if count == 0 {
+		count = 1
+	}
+	sequence := ""
+	if count > 1 {
+		sequence = fmt.Sprint(sequenceID % 10)
+	}
+
+	sentences := make([]string, 0, count)
+	for i := 0; i < count; i++ {
+		end := min((i+1)*aisMaxFragmentChars, len(payload))
+		fragmentFill := 0
+		if i == count-1 {
+			fragmentFill = fill
+		}
+		body := fmt.Sprintf("AIVDM,%d,%d,%s,%s,%s,%d", count, i+1, sequence, channel, payload[i*aisMaxFragmentChars:end], fragmentFill)
+		sentences = append(sentences, fmt.Sprintf("!%s*%02X", body, NMEAChecksum(body)))
+	}
+	return sentences
+}
+
+// NMEAChecksum XORs the characters between the leading '!' or '$' and the '*'
+func NMEAChecksum(body string) byte {
+	var checksum byte
+	for i := 0; i < len(body); i++ {
+		checksum ^= body[i]
+	}
+	return checksum
+}
+
+// AISPublisher sends AIVDM sentences for vessels to a UDP address, or streams them to a TCP
+// consumer. Static data is sent when a vessel is first seen and every six minutes after.
+type AISPublisher struct {
+	udp    *net.UDPConn
+	stream *StreamPublisher
+
+	mu         sync.Mutex
+	clock      models.Clock
+	sequenceID int
+	lastStatic map[uint32]time.Time
+}
+
+// NewAISPublisher creates a publisher for a udp:// or tcp:// endpoint, e.g. an OpenCPN network
+// connection on port 10110
+func NewAISPublisher(endpoint string, opts StreamOptions) (*AISPublisher, error) {
+	ep, err := ParseEndpoint(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	p := &AISPublisher{clock: models.WallClock{}, lastStatic: make(map[uint32]time.Time)}
+
+	switch ep.Scheme {
+	case SchemeUDP:
+		addr, err := net.ResolveUDPAddr("udp", ep.Address())
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve AIS address: %w", err)
+		}
+		if p.udp, err = net.DialUDP("udp", nil, addr); err != nil {
+			return nil, fmt.Errorf("failed to create UDP connection: %w", err)
+		}
+	case SchemeTCP:
+		opts.TLS = nil
+		p.stream = NewStreamPublisher(ep.Address(), opts)
+	default:
+		return nil, fmt.Errorf("AIS output needs a udp:// or tcp:// endpoint, got %s", ep)
+	}
+	return p, nil
+}
+
+// SetClock sets the clock used for time stamps and the static data interval
+func (p *AISPublisher) SetClock(clock models.Clock) {
+	if clock == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.clock = clock
+}
+
+// PublishVessel sends a position report, followed by static data when it is due
+func (p *AISPublisher) PublishVessel(v AISVessel) error {
+	p.mu.Lock()
+	now := p.clock.Now()
+	messages := [][]byte{EncodeAISPosition(v, now)}
+	if last, sent := p.lastStatic[v.MMSI]; !sent || now.Sub(last) >= aisStaticInterval || now.Before(last) {
+		messages = append(messages, EncodeAISStatic(v)...)
+		p.lastStatic[v.MMSI] = now
+	}
+
+	channel := "A"
+	if v.ClassB {
+		channel = "B"
+	}
+	var sentences []string
+	for _, message := range messages {
+		payload, fill := ArmorAIS(message)
+		if len(payload) > aisMaxFragmentChars {
+			p.sequenceID = (p.sequenceID + 1) % 10
+		}
+		sentences = append(sentences, AISSentences(payload, fill, p.sequenceID, channel)...)
+	}
+	p.mu.Unlock()
+
+	data := []byte(strings.Join(sentences, "\r\n") + "\r\n")
+	if p.stream != nil {
+		return p.stream.Send(data)
+	}
+	if _, err := p.udp.Write(data); err != nil {
+		return fmt.Errorf("failed to send AIS sentences: %w", err)
+	}
+	return nil
+}
+
+// Close closes the socket or stream
+func (p *AISPublisher) Close() error {
+	if p.stream != nil {
+		return p.stream.Close()
+	}
+	return p.udp.Close()
+}
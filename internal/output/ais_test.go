@@ -0,0 +1,280 @@
+package output
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rhino11/trafficsim/internal/models"
+)
+
+var testVessel = AISVessel{
+	MMSI:        366123456,
+	Name:        "EVER GIVEN",
+	Callsign:    "H3RC",
+	ShipType:    AISShipTypeCargo,
+	NavStatus:   AISStatusUnderWay,
+	Latitude:    36.8485,
+	Longitude:   -76.2951,
+	SOG:         12.3,
+	COG:         271.5,
+	Heading:     270,
+	ToBow:       200,
+	ToStern:     200,
+	ToPort:      30,
+	ToStarboard: 29,
+	Draught:     16,
+}
+
+// aisField reads an unsigned field from message bits
+func aisField(bits []byte, start, width int) uint64 {
+	var value uint64
+	for _, bit := range bits[start : start+width] {
+		value = value<<1 | uint64(bit)
+	}
+	return value
+}
+
+// aisSignedField reads a two's complement field
+func aisSignedField(bits []byte, start, width int) int64 {
+	value := int64(aisField(bits, start, width))
+	if value&(1<<(width-1)) != 0 {
+		value -= 1 << width
+	}
+	return value
+}
+
+// aisTextField reads six-bit text, trimming '@' padding and trailing spaces
+func aisTextField(bits []byte, start, chars int) string {
+	var text strings.Builder
+	for i := 0; i < chars; i++ {
+		c := byte(aisField(bits, start+6*i, 6))
+		if c < 32 {
+			c += 64
+		}
+		text.WriteByte(c)
+	}
+	return strings.TrimRight(text.String(), "@ ")
+}
+
+// parseAIVDM checks the sentence checksums and reassembles the message bits
+func parseAIVDM(t *testing.T, sentences []string) []byte {
+	t.Helper()
+	var payload string
+	fill := 0
+	for i, sentence := range sentences {
+		star := strings.LastIndex(sentence, "*")
+		if !strings.HasPrefix(sentence, "!AIVDM,") || star < 0 {
+			t.Fatalf("Malformed sentence %q", sentence)
+		}
+		if want := fmt.Sprintf("%02X", NMEAChecksum(sentence[1:star])); sentence[star+1:] != want {
+			t.Errorf("Checksum of %q is %s, want %s", sentence, sentence[star+1:], want)
+		}
+		if len(sentence) > 82 {
+			t.Errorf("Sentence longer than 82 characters: %q", sentence)
+		}
+		fields := strings.Split(sentence[:star], ",")
+		if fields[1] != strconv.Itoa(len(sentences)) || fields[2] != strconv.Itoa(i+1) {
+			t.Errorf("Sentence %q is not fragment %d of %d", sentence, i+1, len(sentences))
+		}
+		payload += fields[5]
+		fill, _ = strconv.Atoi(fields[6])
+	}
+	bits, err := DearmorAIS(payload, fill)
+	if err != nil {
+		t.Fatalf("DearmorAIS failed: %v", err)
+	}
+	return bits
+}
+
+func TestAIS_ReferenceSentence(t *testing.T) {
+	// Type 1 report from the gpsd AIVDM documentation
+	sentence := "!AIVDM,1,1,,A,13HOI:0P0000VOHLCnHQKwvL05Ip,0*23"
+	bits := parseAIVDM(t, []string{sentence})
+	if len(bits) != 168 || aisField(bits, 0, 6) != 1 || aisField(bits, 8, 30) != 227006760 {
+		t.Errorf("Unexpected reference message: %d bits, type %d, MMSI %d", len(bits), aisField(bits, 0, 6), aisField(bits, 8, 30))
+	}
+	if payload, fill := ArmorAIS(bits); payload != "13HOI:0P0000VOHLCnHQKwvL05Ip" || fill != 0 {
+		t.Errorf("ArmorAIS = %q, %d", payload, fill)
+	}
+
+	if _, err := DearmorAIS("13HO!", 0); err == nil {
+		t.Error("Expected an error for a character outside the armoring alphabet")
+	}
+}
+
+func TestEncodeAISPosition_ClassA(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 42, 0, time.UTC)
+	payload, fill := ArmorAIS(EncodeAISPosition(testVessel, now))
+	sentences := AISSentences(payload, fill, 0, "A")
+	if len(sentences) != 1 || !strings.HasPrefix(sentences[0], "!AIVDM,1,1,,A,") {
+		t.Fatalf("Expected one single-part sentence, got %q", sentences)
+	}
+
+	bits := parseAIVDM(t, sentences)
+	if len(bits) != 168 {
+		t.Fatalf("Expected 168 bits, got %d", len(bits))
+	}
+	checks := []struct {
+		name      string
+		got, want int64
+	}{
+		{"type", int64(aisField(bits, 0, 6)), 1},
+		{"mmsi", int64(aisField(bits, 8, 30)), 366123456},
+		{"status", int64(aisField(bits, 38, 4)), AISStatusUnderWay},
+		{"rot", aisSignedField(bits, 42, 8), -128},
+		{"sog", int64(aisField(bits, 50, 10)), 123},
+		{"lon", aisSignedField(bits, 61, 28), -45777060},
+		{"lat", aisSignedField(bits, 89, 27), 22109100},
+		{"cog", int64(aisField(bits, 116, 12)), 2715},
+		{"heading", int64(aisField(bits, 128, 9)), 270},
+		{"second", int64(aisField(bits, 137, 6)), 42},
+	}
+	for _, check := range checks {
+		if check.got != check.want {
+			t.Errorf("%s = %d, want %d", check.name, check.got, check.want)
+		}
+	}
+}
+
+func TestEncodeAISPosition_ClassB(t *testing.T) {
+	vessel := testVessel
+	vessel.ClassB = true
+	bits := EncodeAISPosition(vessel, time.Date(2024, 6, 1, 12, 0, 7, 0, time.UTC))
+	if len(bits) != 168 || aisField(bits, 0, 6) != 18 || aisField(bits, 8, 30) != 366123456 {
+		t.Fatalf("Unexpected type 18 header")
+	}
+	if aisField(bits, 46, 10) != 123 || aisSignedField(bits, 57, 28) != -45777060 || aisSignedField(bits, 85, 27) != 22109100 {
+		t.Error("Unexpected type 18 kinematics")
+	}
+	if aisField(bits, 133, 6) != 7 || aisField(bits, 141, 1) != 1 {
+		t.Error("Expected the time stamp and carrier sense flag")
+	}
+}
+
+func TestEncodeAISStatic(t *testing.T) {
+	messages := EncodeAISStatic(testVessel)
+	if len(messages) != 1 || len(messages[0]) != 424 {
+		t.Fatalf("Expected one 424 bit type 5 message")
+	}
+
+	// 424 bits armor to 71 characters with 2 fill bits, split over two sentences
+	payload, fill := ArmorAIS(messages[0])
+	if len(payload) != 71 || fill != 2 {
+		t.Fatalf("ArmorAIS gave %d characters and %d fill bits", len(payload), fill)
+	}
+	sentences := AISSentences(payload, fill, 3, "A")
+	if len(sentences) != 2 || !strings.HasPrefix(sentences[0], "!AIVDM,2,1,3,A,") || !strings.HasPrefix(sentences[1], "!AIVDM,2,2,3,A,") {
+		t.Fatalf("Unexpected fragments %q", sentences)
+	}
+	if !strings.Contains(sentences[0], ",0*") || !strings.Contains(sentences[1], ",2*") {
+		t.Errorf("Expected fill bits on the last fragment only: %q", sentences)
+	}
+
+	bits := parseAIVDM(t, sentences)
+	if aisField(bits, 0, 6) != 5 || aisField(bits, 8, 30) != 366123456 {
+		t.Errorf("Unexpected type 5 header")
+	}
+	if callsign := aisTextField(bits, 70, 7); callsign != "H3RC" {
+		t.Errorf("callsign = %q", callsign)
+	}
+	if name := aisTextField(bits, 112, 20); name != "EVER GIVEN" {
+		t.Errorf("name = %q", name)
+	}
+	if aisField(bits, 232, 8) != AISShipTypeCargo {
+		t.Errorf("ship type = %d", aisField(bits, 232, 8))
+	}
+	if aisField(bits, 240, 9) != 200 || aisField(bits, 249, 9) != 200 || aisField(bits, 258, 6) != 30 || aisField(bits, 264, 6) != 29 {
+		t.Error("Unexpected dimensions")
+	}
+	if aisField(bits, 294, 8) != 160 {
+		t.Errorf("draught = %d, want 160", aisField(bits, 294, 8))
+	}
+
+	// Class B vessels send type 24 parts A and B instead
+	classB := testVessel
+	classB.ClassB = true
+	parts := EncodeAISStatic(classB)
+	if len(parts) != 2 || len(parts[0]) != 168 || len(parts[1]) != 168 {
+		t.Fatalf("Expected two 168 bit type 24 parts")
+	}
+	if aisField(parts[0], 0, 6) != 24 || aisField(parts[0], 38, 2) != 0 || aisTextField(parts[0], 40, 20) != "EVER GIVEN" {
+		t.Error("Unexpected type 24 part A")
+	}
+	if aisField(parts[1], 38, 2) != 1 || aisField(parts[1], 40, 8) != AISShipTypeCargo || aisTextField(parts[1], 90, 7) != "H3RC" {
+		t.Error("Unexpected type 24 part B")
+	}
+}
+
+func TestPlatformToAISVessel(t *testing.T) {
+	ship := models.NewArleighBurkeDestroyerUniversal("DDG51", "Arleigh Burke", models.Position{Latitude: 36.8, Longitude: -76.3})
+	ship.State.Speed = 10
+
+	vessel, ok := PlatformToAISVessel(ship)
+	if !ok {
+		t.Fatal("Expected a destroyer to transmit AIS by default")
+	}
+	if vessel.MMSI != models.MMSIOf(ship) || vessel.ShipType != AISShipTypeMilitary || vessel.ClassB {
+		t.Errorf("Unexpected identity %+v", vessel)
+	}
+	if vessel.ToBow+vessel.ToStern != int(ship.GetLength()+0.5) || vessel.Draught != 6.3 || vessel.NavStatus != AISStatusUnderWay {
+		t.Errorf("Unexpected dimensions or status %+v", vessel)
+	}
+
+	ship.Config.AISDark = true
+	if _, ok := PlatformToAISVessel(ship); ok {
+		t.Error("Expected an ais_dark vessel not to transmit")
+	}
+
+	aircraft := models.NewBoeing737_800Universal("UAL123", "UA123", models.Position{})
+	if _, ok := PlatformToAISVessel(aircraft); ok {
+		t.Error("Expected aircraft not to transmit AIS")
+	}
+}
+
+func TestAISPublisher_UDP(t *testing.T) {
+	receiver, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer receiver.Close()
+
+	publisher, err := NewAISPublisher("udp://"+receiver.LocalAddr().String(), StreamOptions{})
+	if err != nil {
+		t.Fatalf("NewAISPublisher failed: %v", err)
+	}
+	defer publisher.Close()
+	clock := fixedClock(time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC))
+	publisher.SetClock(&clock)
+
+	receive := func() []string {
+		receiver.SetReadDeadline(time.Now().Add(5 * time.Second))
+		buf := make([]byte, 1500)
+		n, err := receiver.Read(buf)
+		if err != nil {
+			t.Fatalf("Failed to read AIS datagram: %v", err)
+		}
+		return strings.Split(strings.TrimSuffix(string(buf[:n]), "\r\n"), "\r\n")
+	}
+
+	// First report carries static data, the next only the position until six minutes pass
+	for _, tt := range []struct {
+		advance   time.Duration
+		sentences int
+	}{{0, 3}, {10 * time.Second, 1}, {6 * time.Minute, 3}} {
+		clock = fixedClock(time.Time(clock).Add(tt.advance))
+		if err := publisher.PublishVessel(testVessel); err != nil {
+			t.Fatal(err)
+		}
+		if sentences := receive(); len(sentences) != tt.sentences {
+			t.Errorf("After %v expected %d sentences, got %q", tt.advance, tt.sentences, sentences)
+		}
+	}
+
+	if _, err := NewAISPublisher("ssl://127.0.0.1:10110", StreamOptions{}); err == nil {
+		t.Error("Expected ssl:// to be rejected for AIS")
+	}
+}
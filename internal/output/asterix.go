@@ -0,0 +1,412 @@
+package output
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+	"log"
+	"math"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rhino11/trafficsim/internal/models"
+)
+
+// ASTERIX categories, as defined by EUROCONTROL
+const (
+	ASTERIXCategory034 = 34 // Monoradar service messages
+	ASTERIXCategory062 = 62 // SDPS system track data
+)
+
+// ASTERIX encoding limits and defaults
+const (
+	ASTERIXDefaultUpdatePeriod = 4 * time.Second
+
+	asterixMaxBlock         = 1400 // Bytes per data block, one block per datagram
+	asterixTimeUnits        = 128  // Time of day LSB is 1/128 s
+	asterixPositionLSB      = 180.0 / (1 << 25)
+	asterixVelocityLSB      = 0.25 // m/s
+	asterixAltitudeLSB      = 6.25 // feet
+	asterixNorthMarker      = 1    // I034/000 message type
+	asterixMaxTrackNumber   = 0xFFFF
+	asterixIdentityUnknown  = 1      // I062/245 STI: callsign not downlinked from the target
+	asterixTrackStatusMRH   = 0x20   // Geometric altitude more reliable than barometric
+	asterixTrackStatusGNSS  = 1 << 2 // SRC field: altitude from GNSS
+	asterixTrackStatusFX    = 0x01
+	asterixTrackStatusTSE   = 0x40 // Last message for the track
+	asterixTrackStatusTSB   = 0x20 // First message for the track
+	asterixFlightLevelUnits = 4    // I062/135 LSB is 1/4 FL
+)
+
+// ASTERIXOptions identifies the data source and how often tracks are sent
+type ASTERIXOptions struct {
+	SAC          uint8         // System area code
+	SIC          uint8         // System identification code
+	UpdatePeriod time.Duration // Time between track updates and north markers, 0 for 4s
+}
+
+// asterixTrack is the publisher's record of a track it has sent
+type asterixTrack struct {
+	number uint16
+	last   PlatformState
+}
+
+// ASTERIXPublisher sends CAT062 system tracks over UDP, with a CAT034 north marker ahead of each
+// update so displays can see the service is alive. Updates should be published once per update period. Each platform ID keeps the same track
+// number for as long as it is reported; tracks that disappear are ended with a final update.
+type ASTERIXPublisher struct {
+	conn *net.UDPConn
+	opts ASTERIXOptions
+
+	mu     sync.Mutex // Guards the fields below
+	clock  models.Clock
+	tracks map[string]*asterixTrack
+	inUse  map[uint16]string
+}
+
+// NewASTERIXPublisher creates a publisher for a udp:// endpoint
+func NewASTERIXPublisher(endpoint string, opts ASTERIXOptions) (*ASTERIXPublisher, error) {
+	ep, err := ParseEndpoint(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	if ep.Scheme != SchemeUDP {
+		return nil, fmt.Errorf("ASTERIX output needs a udp:// endpoint, got %s", ep)
+	}
+	addr, err := net.ResolveUDPAddr("udp", ep.Address())
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve ASTERIX address: %w", err)
+	}
+	conn, err := net.DialUDP("udp", nil, addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create UDP connection: %w", err)
+	}
+
+	if opts.UpdatePeriod <= 0 {
+		opts.UpdatePeriod = ASTERIXDefaultUpdatePeriod
+	}
+	return &ASTERIXPublisher{
+		conn:   conn,
+		opts:   opts,
+		clock:  models.WallClock{},
+		tracks: make(map[string]*asterixTrack),
+		inUse:  make(map[uint16]string),
+	}, nil
+}
+
+// SetClock sets the clock used for times of day
+func (p *ASTERIXPublisher) SetClock(clock models.Clock) {
+	if clock == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.clock = clock
+}
+
+// TrackNumber returns the track number assigned to a platform, if it is being reported
+func (p *ASTERIXPublisher) TrackNumber(platformID string) (uint16, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	track, ok := p.tracks[platformID]
+	if !ok {
+		return 0, false
+	}
+	return track.number, true
+}
+
+// PublishTracks sends a north marker and one update of the track picture. States of space
+// platforms are skipped, and tracks that were in the previous picture but not this one are ended.
+func (p *ASTERIXPublisher) PublishTracks(states []PlatformState) error {
+	return p.publish(states, true)
+}
+
+// UpdateTracks sends a north marker and updates for the given tracks only, leaving other tracks
+// running until EndTrack
+func (p *ASTERIXPublisher) UpdateTracks(states []PlatformState) error {
+	return p.publish(states, false)
+}
+
+// publish sends a north marker and updates for states, ending tracks missing from them when endMissing is set
+func (p *ASTERIXPublisher) publish(states []PlatformState, endMissing bool) error {
+	p.mu.Lock()
+	now := p.clock.Now()
+	records := make([][]byte, 0, len(states))
+	seen := make(map[string]bool, len(states))
+	for _, state := range states {
+		if cotDimension(state.CoTType) == "P" || seen[state.ID] {
+			continue
+		}
+		seen[state.ID] = true
+
+		track, known := p.tracks[state.ID]
+		if !known {
+			track = &asterixTrack{number: p.assignTrackNumber(state.ID)}
+			p.tracks[state.ID] = track
+		}
+		track.last = state
+		records = append(records, EncodeCAT062(p.opts, CAT062Track{State: state, Number: track.number, First: !known}, now))
+	}
+	if endMissing {
+		for id, track := range p.tracks {
+			if !seen[id] {
+				records = append(records, EncodeCAT062(p.opts, CAT062Track{State: track.last, Number: track.number, Last: true}, now))
+				p.releaseTrack(id)
+			}
+		}
+	}
+	p.mu.Unlock()
+
+	if err := p.send(EncodeCAT034NorthMarker(p.opts, now)); err != nil {
+		return err
+	}
+	for _, block := range asterixBlocks(ASTERIXCategory062, records) {
+		if err := p.send(block); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// EndTrack sends a final update for a platform's track and frees its number. Platforms without a
+// track are ignored.
+func (p *ASTERIXPublisher) EndTrack(platformID string) error {
+	p.mu.Lock()
+	track, ok := p.tracks[platformID]
+	if !ok {
+		p.mu.Unlock()
+		return nil
+	}
+	record := EncodeCAT062(p.opts, CAT062Track{State: track.last, Number: track.number, Last: true}, p.clock.Now())
+	p.releaseTrack(platformID)
+	p.mu.Unlock()
+
+	return p.send(asterixBlocks(ASTERIXCategory062, [][]byte{record})[0])
+}
+
+// Close ends every track and closes the socket
+func (p *ASTERIXPublisher) Close() error {
+	p.mu.Lock()
+	now := p.clock.Now()
+	var records [][]byte
+	for id, track := range p.tracks {
+		records = append(records, EncodeCAT062(p.opts, CAT062Track{State: track.last, Number: track.number, Last: true}, now))
+		p.releaseTrack(id)
+	}
+	p.mu.Unlock()
+
+	for _, block := range asterixBlocks(ASTERIXCategory062, records) {
+		if err := p.send(block); err != nil {
+			log.Printf("%v", err)
+		}
+	}
+	return p.conn.Close()
+}
+
+// assignTrackNumber derives a track number from the platform ID, so a scenario gets the same numbers
+// on every run, moving to the next free number on a collision. Callers hold mu.
+func (p *ASTERIXPublisher) assignTrackNumber(id string) uint16 {
+	hash := fnv.New32a()
+	hash.Write([]byte(id))
+	number := uint16(hash.Sum32()%asterixMaxTrackNumber) + 1
+	for len(p.inUse) < asterixMaxTrackNumber {
+		if _, taken := p.inUse[number]; !taken {
+			break
+		}
+		number = number%asterixMaxTrackNumber + 1
+	}
+	p.inUse[number] = id
+	return number
+}
+
+// releaseTrack forgets a track and frees its number. Callers hold mu.
+func (p *ASTERIXPublisher) releaseTrack(id string) {
+	if track, ok := p.tracks[id]; ok {
+		delete(p.inUse, track.number)
+		delete(p.tracks, id)
+	}
+}
+
+// send writes one data block as a datagram
+func (p *ASTERIXPublisher) send(block []byte) error {
+	if _, err := p.conn.Write(block); err != nil {
+		return fmt.Errorf("failed to send ASTERIX block: %w", err)
+	}
+	return nil
+}
+
+// CAT062Track is one system track report
+type CAT062Track struct {
+	State  PlatformState
+	Number uint16
+	First  bool // Track start (TSB)
+	Last   bool // Track end (TSE)
+}
+
+// EncodeCAT062 encodes a CAT062 record: data source, time of track, WGS-84 position, velocity, track
+// number and status, and for aircraft the Mode 3/A code, callsign, altitudes and rate of climb
+func EncodeCAT062(opts ASTERIXOptions, track CAT062Track, now time.Time) []byte {
+	state := track.State
+	air := cotDimension(state.CoTType) == "A"
+	fspec := make([]byte, 3)
+	var items []byte
+	item := func(frn int, data ...byte) {
+		fspec[(frn-1)/7] |= 0x80 >> ((frn - 1) % 7)
+		items = append(items, data...)
+	}
+
+	// Items in UAP order
+	item(1, opts.SAC, opts.SIC)                                  // I062/010 data source identifier
+	item(4, asterixTimeOfDay(now)...)                            // I062/070 time of track information
+	item(5, asterixPosition(state.Latitude, state.Longitude)...) // I062/105 position in WGS-84
+	course := state.Course * math.Pi / 180
+	velocity := asterixInt16(state.Speed * math.Sin(course) / asterixVelocityLSB)
+	velocity = append(velocity, asterixInt16(state.Speed*math.Cos(course)/asterixVelocityLSB)...)
+	item(7, velocity...) // I062/185 velocity east and north
+	if air && state.Squawk != "" {
+		item(9, asterixMode3A(state.Squawk)...) // I062/060 Mode 3/A code
+	}
+	if air && state.Callsign != "" {
+		item(10, asterixIdentification(state.Callsign)...) // I062/245 target identification
+	}
+	item(12, byte(track.Number>>8), byte(track.Number)) // I062/040 track number
+	item(13, asterixTrackStatus(track)...)              // I062/080 track status
+	if air {
+		altitudeFeet := state.Altitude * feetPerMeter
+		item(18, asterixInt16(altitudeFeet/asterixAltitudeLSB)...) // I062/130 geometric altitude
+		// I062/135 barometric altitude in the standard atmosphere, a 15-bit field with the QNH bit clear
+		barometric := asterixInt16(math.Max(-15, math.Min(1500, altitudeFeet/100)) * asterixFlightLevelUnits)
+		item(19, barometric[0]&0x7F, barometric[1])
+		item(20, asterixInt16(state.VerticalRate*fpmPerMeterSecond/asterixAltitudeLSB)...) // I062/220 rate of climb
+	}
+
+	return append(asterixFSPEC(fspec), items...)
+}
+
+// EncodeCAT034NorthMarker encodes a CAT034 data block holding a north marker message, with the
+// update period as the antenna rotation period
+func EncodeCAT034NorthMarker(opts ASTERIXOptions, now time.Time) []byte {
+	period := opts.UpdatePeriod
+	if period <= 0 {
+		period = ASTERIXDefaultUpdatePeriod
+	}
+	rotation := uint16(math.Min(period.Seconds()*asterixTimeUnits, math.MaxUint16))
+
+	record := []byte{0xE8} // I034/010, /000, /030 and /041
+	record = append(record, opts.SAC, opts.SIC, asterixNorthMarker)
+	record = append(record, asterixTimeOfDay(now)...)
+	record = append(record, byte(rotation>>8), byte(rotation))
+	return asterixBlocks(ASTERIXCategory034, [][]byte{record})[0]
+}
+
+// asterixBlocks packs records into data blocks of at most asterixMaxBlock bytes
+func asterixBlocks(category byte, records [][]byte) [][]byte {
+	var blocks [][]byte
+	var block []byte
+	flush := func() {
+		if len(block) > 3 {
+			binary.BigEndian.PutUint16(block[1:], uint16(len(block)))
+			blocks = append(blocks, block)
+		}
+		block = nil
+	}
+	for _, record := range records {
+		if block != nil && len(block)+len(record) > asterixMaxBlock {
+			flush()
+		}
+		if block == nil {
+			block = []byte{category, 0, 0}
+		}
+		block = append(block, record...)
+	}
+	flush()
+	return blocks
+}
+
+// asterixFSPEC trims trailing empty FSPEC octets and sets the extension bits
+func asterixFSPEC(fspec []byte) []byte {
+	for len(fspec) > 1 && fspec[len(fspec)-1] == 0 {
+		fspec = fspec[:len(fspec)-1]
+	}
+	for i := 0; i < len(fspec)-1; i++ {
+		fspec[i] |= 0x01
+	}
+	return fspec
+}
+
+// asterixTimeOfDay encodes UTC time since midnight in 1/128 s
+func asterixTimeOfDay(now time.Time) []byte {
+	now = now.UTC()
+	sinceMidnight := now.Sub(time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC))
+	units := uint32(sinceMidnight * asterixTimeUnits / time.Second)
+	return []byte{byte(units >> 16), byte(units >> 8), byte(units)}
+}
+
+// asterixPosition encodes I062/105, latitude and longitude in 180/2^25 degrees
+func asterixPosition(latitude, longitude float64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint32(b, uint32(int32(math.Round(latitude/asterixPositionLSB))))
+	binary.BigEndian.PutUint32(b[4:], uint32(int32(math.Round(longitude/asterixPositionLSB))))
+	return b
+}
+
+// asterixInt16 encodes a value as a saturated two's complement 16-bit field
+func asterixInt16(value float64) []byte {
+	v := int16(math.Max(math.MinInt16, math.Min(math.MaxInt16, math.Round(value))))
+	return []byte{byte(uint16(v) >> 8), byte(v)}
+}
+
+// asterixMode3A encodes I062/060 from four octal digits, leaving the V, G and CH bits clear
+func asterixMode3A(squawk string) []byte {
+	var code uint16
+	for _, digit := range squawk {
+		code = code<<3 | uint16(digit-'0')&0x7
+	}
+	return []byte{byte(code >> 8), byte(code)}
+}
+
+// asterixIdentification encodes I062/245: the STI and eight characters in the ICAO six-bit alphabet
+func asterixIdentification(callsign string) []byte {
+	callsign = models.TransponderCallsign(callsign)
+	var bits uint64
+	for i := 0; i < 8; i++ {
+		c := byte(' ')
+		if i < len(callsign) {
+			c = callsign[i]
+		}
+		bits = bits<<6 | uint64(c&0x3F)
+	}
+	b := []byte{asterixIdentityUnknown << 6, 0, 0, 0, 0, 0, 0}
+	for i := 0; i < 6; i++ {
+		b[1+i] = byte(bits >> (40 - 8*i))
+	}
+	return b
+}
+
+// asterixTrackStatus encodes I062/080 with the first extension, which carries the track start and
+// end flags
+func asterixTrackStatus(track CAT062Track) []byte {
+	status := []byte{asterixTrackStatusGNSS | asterixTrackStatusFX, 0}
+	if cotDimension(track.State.CoTType) == "A" {
+		// Geometric altitude from GNSS is the more reliable height for simulated aircraft
+		status[0] |= asterixTrackStatusMRH
+	}
+	if track.Last {
+		status[1] |= asterixTrackStatusTSE
+	}
+	if track.First {
+		status[1] |= asterixTrackStatusTSB
+	}
+	return status
+}
+
+// cotDimension returns the battle dimension letter of a CoT atom type, e.g. "A" for a-f-A-M-F
+func cotDimension(cotType string) string {
+	parts := strings.Split(cotType, "-")
+	if len(parts) < 3 || parts[0] != "a" {
+		return ""
+	}
+	return parts[2]
+}
@@ -0,0 +1,212 @@
+package output
+
+import (
+	"encoding/binary"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/rhino11/trafficsim/internal/models"
+)
+
+// cat062ItemSizes are the fixed lengths of the CAT062 items the encoder writes, by FRN; 0 marks the
+// variable length track status
+var cat062ItemSizes = map[int]int{1: 2, 4: 3, 5: 8, 7: 4, 9: 2, 10: 7, 12: 2, 13: 0, 18: 2, 19: 2, 20: 2}
+
+// parseCAT062Block splits a CAT062 data block into records of items keyed by FRN
+func parseCAT062Block(t *testing.T, block []byte) []map[int][]byte {
+	t.Helper()
+	if len(block) < 3 || block[0] != ASTERIXCategory062 || int(binary.BigEndian.Uint16(block[1:])) != len(block) {
+		t.Fatalf("Malformed CAT062 block % X", block)
+	}
+	var records []map[int][]byte
+	for data := block[3:]; len(data) > 0; {
+		var frns []int
+		for octet := 0; ; octet++ {
+			for bit := 0; bit < 7; bit++ {
+				if data[octet]&(0x80>>bit) != 0 {
+					frns = append(frns, octet*7+bit+1)
+				}
+			}
+			if data[octet]&0x01 == 0 {
+				data = data[octet+1:]
+				break
+			}
+		}
+
+		record := make(map[int][]byte)
+		for _, frn := range frns {
+			size, ok := cat062ItemSizes[frn]
+			if !ok {
+				t.Fatalf("Unexpected FRN %d", frn)
+			}
+			if size == 0 {
+				for size = 1; data[size-1]&0x01 != 0; size++ {
+				}
+			}
+			record[frn], data = data[:size], data[size:]
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestEncodeCAT062(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 500000000, time.UTC)
+	state := PlatformState{
+		ID:           "UAL123",
+		Callsign:     "UA123",
+		Latitude:     45,
+		Longitude:    -90,
+		Altitude:     10000 / feetPerMeter, // 10000 ft
+		Speed:        100,
+		Course:       90,
+		VerticalRate: 1000 / fpmPerMeterSecond, // 1000 ft/min
+		Squawk:       "4521",
+		CoTType:      "a-n-A-C-F",
+	}
+	opts := ASTERIXOptions{SAC: 25, SIC: 101}
+	block := asterixBlocks(ASTERIXCategory062, [][]byte{EncodeCAT062(opts, CAT062Track{State: state, Number: 1234, First: true}, now)})[0]
+
+	records := parseCAT062Block(t, block)
+	if len(records) != 1 {
+		t.Fatalf("Expected one record, got %d", len(records))
+	}
+	record := records[0]
+	int16At := func(b []byte, offset int) int16 { return int16(binary.BigEndian.Uint16(b[offset:])) }
+
+	if record[1][0] != 25 || record[1][1] != 101 {
+		t.Errorf("SAC/SIC = % X", record[1])
+	}
+	// 12:00:00.5 is 43200.5 s past midnight
+	if tod := uint32(record[4][0])<<16 | uint32(record[4][1])<<8 | uint32(record[4][2]); tod != 43200*128+64 {
+		t.Errorf("time of track = %d", tod)
+	}
+	if lat, lon := int32(binary.BigEndian.Uint32(record[5])), int32(binary.BigEndian.Uint32(record[5][4:])); lat != 1<<23 || lon != -(1<<24) {
+		t.Errorf("position = %d, %d", lat, lon)
+	}
+	if vx, vy := int16At(record[7], 0), int16At(record[7], 2); vx != 400 || vy != 0 {
+		t.Errorf("velocity = %d, %d", vx, vy)
+	}
+	if code := binary.BigEndian.Uint16(record[9]); code != 0o4521 {
+		t.Errorf("Mode 3/A = %04o", code)
+	}
+	// STI 1, then "UA123   " in six-bit characters
+	if want := []byte{0x40, 0x54, 0x1C, 0x72, 0xCE, 0x08, 0x20}; string(record[10]) != string(want) {
+		t.Errorf("identification = % X, want % X", record[10], want)
+	}
+	if number := binary.BigEndian.Uint16(record[12]); number != 1234 {
+		t.Errorf("track number = %d", number)
+	}
+	if len(record[13]) != 2 || record[13][1]&asterixTrackStatusTSB == 0 || record[13][1]&asterixTrackStatusTSE != 0 {
+		t.Errorf("track status = % X", record[13])
+	}
+	if altitude := int16At(record[18], 0); altitude != 1600 {
+		t.Errorf("geometric altitude = %d, want 1600", altitude)
+	}
+	if flightLevel := int16At(record[19], 0); flightLevel != 400 {
+		t.Errorf("barometric altitude = %d, want 400 (FL100)", flightLevel)
+	}
+	if rate := int16At(record[20], 0); rate != 160 {
+		t.Errorf("rate of climb = %d, want 160", rate)
+	}
+
+	// Surface tracks have no transponder items
+	ship := EncodeCAT062(opts, CAT062Track{State: PlatformState{ID: "SHIP", Callsign: "MAERSK", CoTType: "a-n-S-X"}, Number: 7}, now)
+	record = parseCAT062Block(t, asterixBlocks(ASTERIXCategory062, [][]byte{ship})[0])[0]
+	for _, frn := range []int{9, 10, 18, 19, 20} {
+		if _, ok := record[frn]; ok {
+			t.Errorf("Unexpected FRN %d for a vessel", frn)
+		}
+	}
+}
+
+func TestEncodeCAT034NorthMarker(t *testing.T) {
+	block := EncodeCAT034NorthMarker(ASTERIXOptions{SAC: 25, SIC: 101, UpdatePeriod: 5 * time.Second}, time.Date(2024, 6, 1, 0, 0, 1, 0, time.UTC))
+	want := []byte{34, 0, 12, 0xE8, 25, 101, 1, 0, 0, 128, 0x02, 0x80}
+	if string(block) != string(want) {
+		t.Errorf("north marker = % X, want % X", block, want)
+	}
+}
+
+func TestAsterixBlocks_Split(t *testing.T) {
+	record := make([]byte, 100)
+	blocks := asterixBlocks(ASTERIXCategory062, [][]byte{record, record, record, record, record, record, record, record, record, record, record, record, record, record, record})
+	if len(blocks) != 2 {
+		t.Fatalf("Expected 15 records of 100 bytes to need two blocks, got %d", len(blocks))
+	}
+	for _, block := range blocks {
+		if len(block) > asterixMaxBlock || int(binary.BigEndian.Uint16(block[1:])) != len(block) {
+			t.Errorf("Bad block length %d", len(block))
+		}
+	}
+	if blocks := asterixBlocks(ASTERIXCategory062, nil); len(blocks) != 0 {
+		t.Errorf("Expected no blocks without records, got %d", len(blocks))
+	}
+}
+
+func TestASTERIXPublisher_UDP(t *testing.T) {
+	receiver, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer receiver.Close()
+
+	publisher, err := NewASTERIXPublisher("udp://"+receiver.LocalAddr().String(), ASTERIXOptions{SAC: 25, SIC: 101})
+	if err != nil {
+		t.Fatalf("NewASTERIXPublisher failed: %v", err)
+	}
+	defer publisher.Close()
+	clock := fixedClock(time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC))
+	publisher.SetClock(&clock)
+
+	receive := func() []byte {
+		t.Helper()
+		receiver.SetReadDeadline(time.Now().Add(5 * time.Second))
+		buf := make([]byte, 1500)
+		n, err := receiver.Read(buf)
+		if err != nil {
+			t.Fatalf("Failed to read ASTERIX datagram: %v", err)
+		}
+		return buf[:n]
+	}
+	scan := func(platforms ...models.Platform) []map[int][]byte {
+		t.Helper()
+		if err := publisher.PublishTracks(ConvertPlatformListToCoTStates(platforms)); err != nil {
+			t.Fatal(err)
+		}
+		if marker := receive(); marker[0] != ASTERIXCategory034 {
+			t.Fatalf("Expected a north marker first, got category %d", marker[0])
+		}
+		return parseCAT062Block(t, receive())
+	}
+
+	airliner := models.NewBoeing737_800Universal("UAL123", "UA123", models.Position{Latitude: 40.6, Longitude: -73.8, Altitude: 10000})
+	ship := models.NewContainerShipUniversal("MAERSK1", "Maersk Alabama", models.Position{Latitude: 36.9, Longitude: -76.2})
+	satellite := models.NewStarlinkSatelliteUniversal("STARLINK1", "Starlink 1", models.Position{Altitude: 550000})
+
+	records := scan(airliner, ship, satellite)
+	if len(records) != 2 {
+		t.Fatalf("Expected tracks for the aircraft and ship only, got %d", len(records))
+	}
+	number, _ := publisher.TrackNumber("UAL123")
+	if got := binary.BigEndian.Uint16(records[0][12]); got != number || number == 0 {
+		t.Errorf("track number = %d, want %d", got, number)
+	}
+
+	// Track numbers stay with the platform, and missing platforms are ended
+	records = scan(airliner)
+	if len(records) != 2 || binary.BigEndian.Uint16(records[0][12]) != number || records[0][13][1]&asterixTrackStatusTSB != 0 {
+		t.Fatalf("Expected the continuing aircraft track, got %v", records)
+	}
+	if records[1][13][1]&asterixTrackStatusTSE == 0 {
+		t.Error("Expected the ship track to be ended")
+	}
+	if _, ok := publisher.TrackNumber("MAERSK1"); ok {
+		t.Error("Expected the ended track to be forgotten")
+	}
+
+	if _, err := NewASTERIXPublisher("tcp://127.0.0.1:8600", ASTERIXOptions{}); err == nil {
+		t.Error("Expected tcp:// to be rejected for ASTERIX")
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"math"
 	"time"
+
+	"github.com/rhino11/trafficsim/internal/models"
 )
 
 // Dimension constants for CoT message generation
@@ -65,35 +67,93 @@ type CoTPrecis struct {
 
 // PlatformState represents the current state of a platform
 type PlatformState struct {
-	ID          string
-	Callsign    string
-	Latitude    float64
-	Longitude   float64
-	Altitude    float64
-	Speed       float64
-	Course      float64
-	CoTType     string
-	Affiliation string
+	ID           string
+	Callsign     string
+	Latitude     float64
+	Longitude    float64
+	Altitude     float64
+	Speed        float64
+	Course       float64
+	VerticalRate float64 // m/s, positive climbing
+	Squawk       string  // Mode 3/A code, aircraft only
+	CoTType      string
+	Affiliation  string
 }
 
 // CoTGenerator generates Cursor on Target messages
 type CoTGenerator struct {
 	staleTime time.Duration
+	clock     models.Clock // Source of event time, start and stale
+	encoding  Encoding     // Wire format of GenerateMessage
 }
 
 // NewCoTGenerator creates a new CoT message generator
 func NewCoTGenerator() *CoTGenerator {
 	return &CoTGenerator{
 		staleTime: 15 * time.Minute, // Default stale time
+		clock:     models.WallClock{},
+		encoding:  EncodingXML,
+	}
+}
+
+// SetClock sets the clock used to timestamp messages, e.g. the simulation engine
+func (g *CoTGenerator) SetClock(clock models.Clock) {
+	if clock != nil {
+		g.clock = clock
+	}
+}
+
+// SetEncoding selects the wire format produced by GenerateMessage
+func (g *CoTGenerator) SetEncoding(encoding Encoding) error {
+	encoding, err := ParseEncoding(string(encoding))
+	if err != nil {
+		return err
+	}
+	g.encoding = encoding
+	return nil
+}
+
+// GetEncoding returns the wire format produced by GenerateMessage
+func (g *CoTGenerator) GetEncoding() Encoding {
+	return g.encoding
+}
+
+// GenerateMessage creates a message from platform state in the generator's encoding, timestamped by its clock
+func (g *CoTGenerator) GenerateMessage(state PlatformState) ([]byte, error) {
+	return g.GenerateMessageAt(state, g.clock.Now())
+}
+
+// GenerateMessageAt creates a message from platform state in the generator's encoding, timestamped at now
+func (g *CoTGenerator) GenerateMessageAt(state PlatformState, now time.Time) ([]byte, error) {
+	if g.encoding == EncodingXML {
+		return g.GenerateCoTMessageAt(state, now)
 	}
+	return g.generateTakMessage(state, now, g.encoding)
 }
 
-// GenerateCoTMessage creates a CoT XML message from platform state
+// GenerateCoTMessage creates a CoT XML message from platform state, timestamped by the generator's clock
 func (g *CoTGenerator) GenerateCoTMessage(state PlatformState) ([]byte, error) {
-	now := time.Now().UTC()
+	return g.GenerateCoTMessageAt(state, g.clock.Now())
+}
+
+// GenerateCoTMessageAt creates a CoT XML message from platform state, timestamped at now
+func (g *CoTGenerator) GenerateCoTMessageAt(state PlatformState, now time.Time) ([]byte, error) {
+	xmlData, err := xml.MarshalIndent(g.buildEvent(state, now), "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	// Add XML declaration
+	xmlDeclaration := []byte(`<?xml version="1.0" encoding="UTF-8"?>` + "\n")
+	return append(xmlDeclaration, xmlData...), nil
+}
+
+// buildEvent fills in a CoT event for a platform state, timestamped at now
+func (g *CoTGenerator) buildEvent(state PlatformState, now time.Time) CoTEvent {
+	now = now.UTC()
 	staleTime := now.Add(g.staleTime)
 
-	event := CoTEvent{
+	return CoTEvent{
 		Version: "2.0",
 		UID:     fmt.Sprintf("TRAFFICSIM-%s", state.ID),
 		Type:    state.CoTType,
@@ -123,30 +183,12 @@ func (g *CoTGenerator) GenerateCoTMessage(state PlatformState) ([]byte, error) {
 			},
 		},
 	}
-
-	xmlData, err := xml.MarshalIndent(event, "", "  ")
-	if err != nil {
-		return nil, err
-	}
-
-	// Add XML declaration
-	xmlDeclaration := []byte(`<?xml version="1.0" encoding="UTF-8"?>` + "\n")
-	return append(xmlDeclaration, xmlData...), nil
 }
 
 // GenerateMILSTD2525Type generates MIL-STD-2525D type codes based on platform category
 func GenerateMILSTD2525Type(category, affiliation, dimension string) string {
-	var affiliationCode string
-	switch affiliation {
-	case "friend":
-		affiliationCode = "f"
-	case "hostile":
-		affiliationCode = "h"
-	case "neutral":
-		affiliationCode = "n"
-	case "unknown":
-		affiliationCode = "u"
-	default:
+	affiliationCode, ok := models.AffiliationCode(affiliation)
+	if !ok {
 		affiliationCode = "u"
 	}
 
@@ -363,3 +363,30 @@ func abs(x float64) float64 {
 	}
 	return x
 }
+
+// fixedClock is a models.Clock stopped at one time
+type fixedClock time.Time
+
+func (c fixedClock) Now() time.Time { return time.Time(c) }
+
+func TestCoTGenerator_SetClock(t *testing.T) {
+	generator := NewCoTGenerator()
+	generator.SetStaleTime(30 * time.Second)
+	generator.SetClock(fixedClock(time.Date(2025, 6, 4, 6, 0, 0, 0, time.UTC)))
+
+	data, err := generator.GenerateCoTMessage(PlatformState{ID: "VIPER01", CoTType: "a-f-A-M-F"})
+	if err != nil {
+		t.Fatalf("GenerateCoTMessage failed: %v", err)
+	}
+
+	var event CoTEvent
+	if err := xml.Unmarshal(data, &event); err != nil {
+		t.Fatalf("Failed to parse CoT message: %v", err)
+	}
+	if event.Time != "2025-06-04T06:00:00.000Z" || event.Start != event.Time {
+		t.Errorf("Expected time and start from the clock, got %s and %s", event.Time, event.Start)
+	}
+	if event.Stale != "2025-06-04T06:00:30.000Z" {
+		t.Errorf("Expected stale 30s after the clock, got %s", event.Stale)
+	}
+}
@@ -0,0 +1,619 @@
+package output
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"log"
+	"math"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rhino11/trafficsim/internal/models"
+)
+
+// DIS PDU types, families and sizes (IEEE 1278.1)
+const (
+	disEntityStatePDU      = 1
+	disRemoveEntityPDU     = 12
+	disEntityInfoFamily    = 1
+	disSimManagementFamily = 5
+	disEntityStateLength   = 144 // Without articulation parameters
+	disRemoveEntityLength  = 28
+	disMarkingLength       = 11
+)
+
+// DIS defaults and field values
+const (
+	DISProtocolVersion6 = 6 // IEEE 1278.1a-1998
+	DISProtocolVersion7 = 7 // IEEE 1278.1-2012
+
+	DISDefaultHeartbeat = 5 * time.Second // Entity State heartbeat from IEEE 1278.1-2012
+
+	DISForceOther    = 0
+	DISForceFriendly = 1
+	DISForceOpposing = 2
+	DISForceNeutral  = 3
+
+	DISDeadReckoningRVW = 4 // Rate of rotation, velocity, world coordinates
+
+	disAppearanceDeactivated = 1 << 23
+	disMarkingASCII          = 1
+	disCountryUSA            = 225
+)
+
+// DIS entity kinds and domains used for platforms
+const (
+	disKindPlatform     = 1
+	disDomainLand       = 1
+	disDomainAir        = 2
+	disDomainSurface    = 3
+	disDomainSubsurface = 4
+	disDomainSpace      = 5
+)
+
+// DIS publisher limits
+const (
+	disMinEntityNumber   = 1
+	disMaxEntityNumber   = 0xFFFE // 0xFFFF addresses all entities
+	disHeartbeatFraction = 4      // Heartbeat checks per interval
+)
+
+// DISOptions identifies the simulation on the DIS network
+type DISOptions struct {
+	SiteID          uint16
+	ApplicationID   uint16
+	ExerciseID      uint8
+	ProtocolVersion uint8         // 6 or 7, 0 for 7
+	Heartbeat       time.Duration // Longest gap between Entity State PDUs for an entity, 0 for 5s
+}
+
+// withDefaults fills in unset options
+func (o DISOptions) withDefaults() DISOptions {
+	if o.SiteID == 0 {
+		o.SiteID = 1
+	}
+	if o.ApplicationID == 0 {
+		o.ApplicationID = 1
+	}
+	if o.ExerciseID == 0 {
+		o.ExerciseID = 1
+	}
+	if o.ProtocolVersion == 0 {
+		o.ProtocolVersion = DISProtocolVersion7
+	}
+	if o.Heartbeat <= 0 {
+		o.Heartbeat = DISDefaultHeartbeat
+	}
+	return o
+}
+
+// DISEntityID addresses an entity as site, application and entity number
+type DISEntityID struct {
+	Site        uint16
+	Application uint16
+	Entity      uint16
+}
+
+// DISEntityState is the content of an Entity State PDU. Angles are in radians and the location,
+// velocity and acceleration are geocentric (ECEF).
+type DISEntityState struct {
+	ID              DISEntityID
+	ForceID         uint8
+	EntityType      models.DISEntityType
+	Velocity        models.ECEF // m/s
+	Location        models.ECEF // meters
+	Psi             float64     // Euler angles from the ECEF axes to the body axes
+	Theta           float64
+	Phi             float64
+	Appearance      uint32
+	DeadReckoning   uint8
+	Acceleration    models.ECEF // m/s²
+	AngularVelocity [3]float64  // rad/s about the body x, y and z axes
+	Marking         string      // Up to 11 ASCII characters
+}
+
+// PlatformToDISEntityState converts a platform to an Entity State; the entity ID is left for the
+// publisher to assign
+func PlatformToDISEntityState(platform models.Platform) DISEntityState {
+	state := platform.GetState()
+	physics := platform.GetPhysicsState()
+	pos := state.Position
+
+	attitude := physics.Attitude
+	if attitude == (models.Attitude{}) {
+		// Platforms without 3D physics only track heading and bank
+		attitude = models.Attitude{Yaw: state.Heading, Roll: state.Roll}
+	}
+	psi, theta, phi := disEulerAngles(pos.Latitude, pos.Longitude, attitude)
+
+	_, affiliation := determinePlatformCoTInfo(platform)
+	return DISEntityState{
+		ForceID:       disForceID(affiliation),
+		EntityType:    disEntityType(platform, affiliation),
+		Velocity:      models.ENUToECEF(pos.Latitude, pos.Longitude, state.Velocity.East, state.Velocity.North, state.Velocity.Up),
+		Location:      models.GeodeticToECEF(pos),
+		Psi:           psi,
+		Theta:         theta,
+		Phi:           phi,
+		DeadReckoning: DISDeadReckoningRVW,
+		Acceleration: models.ENUToECEF(pos.Latitude, pos.Longitude,
+			physics.Acceleration.East, physics.Acceleration.North, physics.Acceleration.Up),
+		AngularVelocity: [3]float64{
+			physics.AngularVelocity.RollRate * math.Pi / 180,
+			physics.AngularVelocity.PitchRate * math.Pi / 180,
+			physics.AngularVelocity.YawRate * math.Pi / 180,
+		},
+		Marking: platform.GetCallSign(),
+	}
+}
+
+// disEulerAngles converts a heading, pitch and roll relative to local north, east and down into
+// the DIS Euler angles, which rotate the ECEF axes onto the body axes in z, y, x order
+func disEulerAngles(latitude, longitude float64, attitude models.Attitude) (psi, theta, phi float64) {
+	sinLat, cosLat := math.Sincos(latitude * math.Pi / 180)
+	sinLon, cosLon := math.Sincos(longitude * math.Pi / 180)
+	sinYaw, cosYaw := math.Sincos(attitude.Yaw * math.Pi / 180)
+	sinPitch, cosPitch := math.Sincos(attitude.Pitch * math.Pi / 180)
+	sinRoll, cosRoll := math.Sincos(attitude.Roll * math.Pi / 180)
+
+	// Columns are the local north, east and down axes in ECEF
+	ned := [3][3]float64{
+		{-sinLat * cosLon, -sinLon, -cosLat * cosLon},
+		{-sinLat * sinLon, cosLon, -cosLat * sinLon},
+		{cosLat, 0, -sinLat},
+	}
+	// Body axes in north, east, down for yaw, pitch and roll applied in that order
+	body := [3][3]float64{
+		{cosYaw * cosPitch, cosYaw*sinPitch*sinRoll - sinYaw*cosRoll, cosYaw*sinPitch*cosRoll + sinYaw*sinRoll},
+		{sinYaw * cosPitch, sinYaw*sinPitch*sinRoll + cosYaw*cosRoll, sinYaw*sinPitch*cosRoll - cosYaw*sinRoll},
+		{-sinPitch, cosPitch * sinRoll, cosPitch * cosRoll},
+	}
+
+	var r [3][3]float64
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			for k := 0; k < 3; k++ {
+				r[i][j] += ned[i][k] * body[k][j]
+			}
+		}
+	}
+	psi = math.Atan2(r[1][0], r[0][0])
+	theta = math.Asin(math.Max(-1, math.Min(1, -r[2][0])))
+	phi = math.Atan2(r[2][1], r[2][2])
+	return psi, theta, phi
+}
+
+// disForceID maps a CoT affiliation to the DIS force
+func disForceID(affiliation string) uint8 {
+	switch affiliation {
+	case "friend", "assumed_friend":
+		return DISForceFriendly
+	case "hostile", "suspect", "joker", "faker":
+		return DISForceOpposing
+	case "neutral":
+		return DISForceNeutral
+	}
+	return DISForceOther
+}
+
+// disEntityType returns the type definition's dis_config entity type, or one inferred from the
+// platform's domain, category, class and affiliation
+func disEntityType(platform models.Platform, affiliation string) models.DISEntityType {
+	up, _ := platform.(*models.UniversalPlatform)
+	if up != nil && up.TypeDef != nil && up.TypeDef.DISConf.EntityType != "" {
+		if entityType, err := models.ParseDISEntityType(up.TypeDef.DISConf.EntityType); err == nil {
+			return entityType
+		}
+	}
+
+	category := ""
+	if up != nil && up.TypeDef != nil {
+		category = strings.ToLower(up.TypeDef.Category)
+	}
+	class := strings.ToLower(platform.GetClass())
+	description := category + " " + class
+	has := func(keywords ...string) bool {
+		for _, keyword := range keywords {
+			if strings.Contains(description, keyword) {
+				return true
+			}
+		}
+		return false
+	}
+
+	entityType := models.DISEntityType{Kind: disKindPlatform}
+	if strings.Contains(category, "military") || affiliation == "friend" || affiliation == "hostile" {
+		// The bundled military types are all US platforms
+		entityType.Country = disCountryUSA
+	}
+
+	switch platform.GetType() {
+	case models.PlatformTypeAirborne:
+		entityType.Domain = disDomainAir
+		switch {
+		case has("fighter", "f-16", "f-22", "f-35"):
+			entityType.Category = 1 // Fighter/Air Defense
+		case has("reaper", "drone", "unmanned", "uav"):
+			entityType.Category = 50 // Unmanned
+		case has("helicopter") && has("attack", "apache"):
+			entityType.Category = 20 // Attack Helicopter
+		case has("helicopter"):
+			entityType.Category = 21 // Utility Helicopter
+		case has("cargo", "tanker", "transport", "hercules"):
+			entityType.Category = 4 // Cargo/Tanker
+		case has("commercial", "airliner", "boeing", "airbus"):
+			entityType.Category = 57 // Non-Combatant Commercial Aircraft
+		}
+	case models.PlatformTypeMaritime:
+		entityType.Domain = disDomainSurface
+		switch {
+		case has("submarine"):
+			entityType.Domain = disDomainSubsurface
+			entityType.Category = 3 // SSN, nuclear attack
+		case has("guided_missile_destroyer", "arleigh burke"):
+			entityType.Category = 4 // Guided Missile Destroyer
+		case has("destroyer"):
+			entityType.Category = 5 // Destroyer
+		case has("crude", "tanker"):
+			entityType.Category = 86 // Large Tanker
+		case has("cargo", "container"):
+			entityType.Category = 84 // Large Freighter
+		}
+	case models.PlatformTypeLand:
+		entityType.Domain = disDomainLand
+		switch {
+		case has("tank", "abrams"):
+			entityType.Category = 1 // Tank
+		case has("armored", "armoured"):
+			entityType.Category = 2 // Armored Fighting Vehicle
+		case has("hmmwv", "tactical"):
+			entityType.Category = 6 // Small Wheeled Utility Vehicle
+		case has("semi", "class 8"):
+			entityType.Category = 85 // Multiple Unit Cargo Truck
+		case has("truck"):
+			entityType.Category = 83 // Single Unit Cargo Truck
+		case has("sedan", "car", "interceptor"):
+			entityType.Category = 81 // Car
+		}
+	case models.PlatformTypeSpace:
+		entityType.Domain = disDomainSpace
+	}
+	return entityType
+}
+
+// disHeader writes the PDU header. Version 6 pads where version 7 has the PDU status byte, which
+// is left zero either way.
+func disHeader(b []byte, opts DISOptions, pduType, family uint8, now time.Time) {
+	b[0] = opts.ProtocolVersion
+	b[1] = opts.ExerciseID
+	b[2] = pduType
+	b[3] = family
+	binary.BigEndian.PutUint32(b[4:], disTimestamp(now))
+	binary.BigEndian.PutUint16(b[8:], uint16(len(b)))
+}
+
+// disTimestamp encodes an absolute timestamp: time past the hour in units of 3600/2^31 seconds,
+// with the low bit set
+func disTimestamp(now time.Time) uint32 {
+	now = now.UTC()
+	pastHour := now.Sub(now.Truncate(time.Hour))
+	units := uint64(pastHour/time.Microsecond) << 31 / uint64(time.Hour/time.Microsecond)
+	if units >= 1<<31 {
+		units = 1<<31 - 1
+	}
+	return uint32(units)<<1 | 1
+}
+
+// putEntityID writes a site, application and entity number
+func putEntityID(b []byte, id DISEntityID) {
+	binary.BigEndian.PutUint16(b[0:], id.Site)
+	binary.BigEndian.PutUint16(b[2:], id.Application)
+	binary.BigEndian.PutUint16(b[4:], id.Entity)
+}
+
+// putEntityType writes a seven field entity type record
+func putEntityType(b []byte, t models.DISEntityType) {
+	b[0] = t.Kind
+	b[1] = t.Domain
+	binary.BigEndian.PutUint16(b[2:], t.Country)
+	b[4] = t.Category
+	b[5] = t.Subcategory
+	b[6] = t.Specific
+	b[7] = t.Extra
+}
+
+// putFloat32s writes single precision values
+func putFloat32s(b []byte, values ...float64) {
+	for i, value := range values {
+		binary.BigEndian.PutUint32(b[4*i:], math.Float32bits(float32(value)))
+	}
+}
+
+// EncodeEntityStatePDU encodes an Entity State PDU without articulation parameters
+func EncodeEntityStatePDU(opts DISOptions, state DISEntityState, now time.Time) []byte {
+	opts = opts.withDefaults()
+	b := make([]byte, disEntityStateLength)
+	disHeader(b, opts, disEntityStatePDU, disEntityInfoFamily, now)
+
+	putEntityID(b[12:], state.ID)
+	b[18] = state.ForceID
+	putEntityType(b[20:], state.EntityType)
+	putEntityType(b[28:], state.EntityType)
+	putFloat32s(b[36:], state.Velocity.X, state.Velocity.Y, state.Velocity.Z)
+	binary.BigEndian.PutUint64(b[48:], math.Float64bits(state.Location.X))
+	binary.BigEndian.PutUint64(b[56:], math.Float64bits(state.Location.Y))
+	binary.BigEndian.PutUint64(b[64:], math.Float64bits(state.Location.Z))
+	putFloat32s(b[72:], state.Psi, state.Theta, state.Phi)
+	binary.BigEndian.PutUint32(b[84:], state.Appearance)
+
+	// Dead reckoning: algorithm, 15 bytes of other parameters, acceleration and angular velocity
+	b[88] = state.DeadReckoning
+	putFloat32s(b[104:], state.Acceleration.X, state.Acceleration.Y, state.Acceleration.Z)
+	putFloat32s(b[116:], state.AngularVelocity[0], state.AngularVelocity[1], state.AngularVelocity[2])
+
+	b[128] = disMarkingASCII
+	marking := b[129 : 129+disMarkingLength]
+	for i := 0; i < len(state.Marking) && i < disMarkingLength; i++ {
+		c := state.Marking[i]
+		if c < 0x20 || c > 0x7E {
+			c = ' '
+		}
+		marking[i] = c
+	}
+	// Capabilities at 140 are left zero
+	return b
+}
+
+// getFloat32s reads single precision values
+func getFloat32s(b []byte, values ...*float64) {
+	for i, value := range values {
+		*value = float64(math.Float32frombits(binary.BigEndian.Uint32(b[4*i:])))
+	}
+}
+
+// DecodeEntityStatePDU decodes an Entity State PDU, ignoring any articulation parameters
+func DecodeEntityStatePDU(b []byte) (DISEntityState, error) {
+	if len(b) < disEntityStateLength {
+		return DISEntityState{}, fmt.Errorf("entity state PDU too short: %d bytes", len(b))
+	}
+	if b[2] != disEntityStatePDU || b[3] != disEntityInfoFamily {
+		return DISEntityState{}, fmt.Errorf("not an entity state PDU: type %d family %d", b[2], b[3])
+	}
+
+	var state DISEntityState
+	state.ID = DISEntityID{
+		Site:        binary.BigEndian.Uint16(b[12:]),
+		Application: binary.BigEndian.Uint16(b[14:]),
+		Entity:      binary.BigEndian.Uint16(b[16:]),
+	}
+	state.ForceID = b[18]
+	state.EntityType = models.DISEntityType{
+		Kind:        b[20],
+		Domain:      b[21],
+		Country:     binary.BigEndian.Uint16(b[22:]),
+		Category:    b[24],
+		Subcategory: b[25],
+		Specific:    b[26],
+		Extra:       b[27],
+	}
+	getFloat32s(b[36:], &state.Velocity.X, &state.Velocity.Y, &state.Velocity.Z)
+	state.Location = models.ECEF{
+		X: math.Float64frombits(binary.BigEndian.Uint64(b[48:])),
+		Y: math.Float64frombits(binary.BigEndian.Uint64(b[56:])),
+		Z: math.Float64frombits(binary.BigEndian.Uint64(b[64:])),
+	}
+	getFloat32s(b[72:], &state.Psi, &state.Theta, &state.Phi)
+	state.Appearance = binary.BigEndian.Uint32(b[84:])
+	state.DeadReckoning = b[88]
+	getFloat32s(b[104:], &state.Acceleration.X, &state.Acceleration.Y, &state.Acceleration.Z)
+	getFloat32s(b[116:], &state.AngularVelocity[0], &state.AngularVelocity[1], &state.AngularVelocity[2])
+	state.Marking = strings.TrimRight(string(b[129:129+disMarkingLength]), "\x00 ")
+	return state, nil
+}
+
+// EncodeRemoveEntityPDU encodes a Remove Entity PDU from the simulation to the removed entity
+func EncodeRemoveEntityPDU(opts DISOptions, entity DISEntityID, requestID uint32, now time.Time) []byte {
+	opts = opts.withDefaults()
+	b := make([]byte, disRemoveEntityLength)
+	disHeader(b, opts, disRemoveEntityPDU, disSimManagementFamily, now)
+	putEntityID(b[12:], DISEntityID{Site: opts.SiteID, Application: opts.ApplicationID})
+	putEntityID(b[18:], entity)
+	binary.BigEndian.PutUint32(b[24:], requestID)
+	return b
+}
+
+// disEntity is the publisher's record of an entity on the network
+type disEntity struct {
+	id       DISEntityID
+	lastPDU  []byte
+	lastSent time.Time // Wall clock time, for the heartbeat
+}
+
+// DISPublisher sends Entity State PDUs for platforms to a UDP broadcast or multicast address. An
+// entity's last PDU is repeated when nothing newer has been sent within the heartbeat interval, and
+// removed platforms are deactivated and announced with a Remove Entity PDU.
+type DISPublisher struct {
+	conn   *net.UDPConn
+	opts   DISOptions
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu         sync.Mutex // Guards the fields below
+	clock      models.Clock
+	entities   map[string]*disEntity
+	nextEntity uint16
+	requestID  uint32
+}
+
+// NewDISPublisher creates a publisher for a udp:// endpoint, usually a broadcast address or
+// multicast group on port 3000
+func NewDISPublisher(endpoint string, opts DISOptions) (*DISPublisher, error) {
+	ep, err := ParseEndpoint(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	if ep.Scheme != SchemeUDP {
+		return nil, fmt.Errorf("DIS output needs a udp:// endpoint, got %s", ep)
+	}
+	addr, err := net.ResolveUDPAddr("udp", ep.Address())
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve DIS address: %w", err)
+	}
+	conn, err := net.DialUDP("udp", nil, addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create UDP connection: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &DISPublisher{
+		conn:       conn,
+		opts:       opts.withDefaults(),
+		cancel:     cancel,
+		clock:      models.WallClock{},
+		entities:   make(map[string]*disEntity),
+		nextEntity: disMinEntityNumber,
+	}
+	p.wg.Add(1)
+	go p.heartbeat(ctx)
+	return p, nil
+}
+
+// SetClock sets the clock used for PDU timestamps
+func (p *DISPublisher) SetClock(clock models.Clock) {
+	if clock == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.clock = clock
+}
+
+// EntityID returns the DIS entity ID assigned to a platform, if it has been published
+func (p *DISPublisher) EntityID(platformID string) (DISEntityID, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	entity, ok := p.entities[platformID]
+	if !ok {
+		return DISEntityID{}, false
+	}
+	return entity.id, true
+}
+
+// PublishPlatform sends an Entity State PDU for a platform, assigning it an entity number the
+// first time it is seen
+func (p *DISPublisher) PublishPlatform(platform models.Platform) error {
+	state := PlatformToDISEntityState(platform)
+
+	p.mu.Lock()
+	entity, ok := p.entities[platform.GetID()]
+	if !ok {
+		entity = &disEntity{id: DISEntityID{Site: p.opts.SiteID, Application: p.opts.ApplicationID, Entity: p.nextEntity}}
+		p.entities[platform.GetID()] = entity
+		p.nextEntity++
+		if p.nextEntity > disMaxEntityNumber {
+			p.nextEntity = disMinEntityNumber
+		}
+	}
+	state.ID = entity.id
+	pdu := EncodeEntityStatePDU(p.opts, state, p.clock.Now())
+	entity.lastPDU = pdu
+	entity.lastSent = time.Now()
+	p.mu.Unlock()
+
+	return p.send(pdu, "entity state")
+}
+
+// RemoveEntity deactivates a platform's entity and sends a Remove Entity PDU for it. Platforms
+// that were never published are ignored.
+func (p *DISPublisher) RemoveEntity(platformID string) error {
+	p.mu.Lock()
+	entity, ok := p.entities[platformID]
+	if !ok {
+		p.mu.Unlock()
+		return nil
+	}
+	delete(p.entities, platformID)
+	pdus := p.removalPDUs(entity)
+	p.mu.Unlock()
+
+	for _, pdu := range pdus {
+		if err := p.send(pdu, "entity removal"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// removalPDUs returns a final deactivated Entity State PDU and a Remove Entity PDU. Callers hold mu.
+func (p *DISPublisher) removalPDUs(entity *disEntity) [][]byte {
+	now := p.clock.Now()
+	final := append([]byte(nil), entity.lastPDU...)
+	binary.BigEndian.PutUint32(final[4:], disTimestamp(now))
+	binary.BigEndian.PutUint32(final[84:], binary.BigEndian.Uint32(final[84:])|disAppearanceDeactivated)
+
+	p.requestID++
+	return [][]byte{final, EncodeRemoveEntityPDU(p.opts, entity.id, p.requestID, now)}
+}
+
+// Close removes every published entity, stops the heartbeat and closes the socket
+func (p *DISPublisher) Close() error {
+	p.cancel()
+	p.wg.Wait()
+
+	p.mu.Lock()
+	var pdus [][]byte
+	for id, entity := range p.entities {
+		pdus = append(pdus, p.removalPDUs(entity)...)
+		delete(p.entities, id)
+	}
+	p.mu.Unlock()
+
+	for _, pdu := range pdus {
+		if err := p.send(pdu, "entity removal"); err != nil {
+			log.Printf("%v", err)
+		}
+	}
+	return p.conn.Close()
+}
+
+// heartbeat repeats the last PDU of entities that have not been updated within the heartbeat interval
+func (p *DISPublisher) heartbeat(ctx context.Context) {
+	defer p.wg.Done()
+	ticker := time.NewTicker(p.opts.Heartbeat / disHeartbeatFraction)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		var due [][]byte
+		now := time.Now()
+		p.mu.Lock()
+		for _, entity := range p.entities {
+			if now.Sub(entity.lastSent) >= p.opts.Heartbeat {
+				due = append(due, entity.lastPDU)
+				entity.lastSent = now
+			}
+		}
+		p.mu.Unlock()
+
+		for _, pdu := range due {
+			if err := p.send(pdu, "entity state heartbeat"); err != nil && ctx.Err() == nil {
+				log.Printf("%v", err)
+			}
+		}
+	}
+}
+
+// send writes one PDU as a datagram
+func (p *DISPublisher) send(pdu []byte, what string) error {
+	if _, err := p.conn.Write(pdu); err != nil {
+		return fmt.Errorf("failed to send DIS %s: %w", what, err)
+	}
+	return nil
+}
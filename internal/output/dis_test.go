@@ -0,0 +1,219 @@
+package output
+
+import (
+	"encoding/binary"
+	"math"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/rhino11/trafficsim/internal/models"
+)
+
+func TestEncodeEntityStatePDU(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 30, 0, 0, time.UTC)
+	state := DISEntityState{
+		ID:              DISEntityID{Site: 42, Application: 7, Entity: 3},
+		ForceID:         DISForceFriendly,
+		EntityType:      models.DISEntityType{Kind: 1, Domain: 2, Country: 225, Category: 1, Subcategory: 3, Specific: 3},
+		Velocity:        models.ECEF{X: 1.5, Y: -2.5, Z: 100},
+		Location:        models.ECEF{X: 1115000.25, Y: -4843000.5, Z: 3983000.75},
+		Psi:             0.5,
+		Theta:           -0.25,
+		Phi:             0.125,
+		DeadReckoning:   DISDeadReckoningRVW,
+		Acceleration:    models.ECEF{Z: -9.5},
+		AngularVelocity: [3]float64{0, 0, 0.25},
+		Marking:         "VIPER01 TOO LONG",
+	}
+	pdu := EncodeEntityStatePDU(DISOptions{SiteID: 42, ApplicationID: 7, ExerciseID: 9}, state, now)
+
+	if len(pdu) != 144 || binary.BigEndian.Uint16(pdu[8:]) != 144 {
+		t.Fatalf("Expected a 144 byte PDU, got %d bytes with length field %d", len(pdu), binary.BigEndian.Uint16(pdu[8:]))
+	}
+	if pdu[0] != DISProtocolVersion7 || pdu[1] != 9 || pdu[2] != 1 || pdu[3] != 1 {
+		t.Errorf("Unexpected header % X", pdu[:4])
+	}
+	// Half past the hour is 2^30 units, shifted left with the absolute bit set
+	if timestamp := binary.BigEndian.Uint32(pdu[4:]); timestamp != 1<<31|1 {
+		t.Errorf("timestamp = %08X, want 80000001", timestamp)
+	}
+	if pdu[128] != 1 || string(pdu[129:140]) != "VIPER01 TOO" {
+		t.Errorf("Unexpected marking %q", pdu[128:140])
+	}
+
+	decoded, err := DecodeEntityStatePDU(pdu)
+	if err != nil {
+		t.Fatalf("DecodeEntityStatePDU failed: %v", err)
+	}
+	state.Marking = "VIPER01 TOO"
+	if decoded != state {
+		t.Errorf("Decoded %+v\nwant %+v", decoded, state)
+	}
+
+	if _, err := DecodeEntityStatePDU(EncodeRemoveEntityPDU(DISOptions{}, state.ID, 1, now)); err == nil {
+		t.Error("Expected a Remove Entity PDU to be rejected")
+	}
+}
+
+func TestEncodeRemoveEntityPDU(t *testing.T) {
+	pdu := EncodeRemoveEntityPDU(DISOptions{SiteID: 42, ApplicationID: 7, ProtocolVersion: DISProtocolVersion6},
+		DISEntityID{Site: 42, Application: 7, Entity: 3}, 99, time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC))
+
+	if len(pdu) != 28 || binary.BigEndian.Uint16(pdu[8:]) != 28 {
+		t.Fatalf("Expected a 28 byte PDU, got %d", len(pdu))
+	}
+	if pdu[0] != 6 || pdu[1] != 1 || pdu[2] != 12 || pdu[3] != 5 || binary.BigEndian.Uint32(pdu[4:]) != 1 {
+		t.Errorf("Unexpected header % X", pdu[:12])
+	}
+	want := []uint16{42, 7, 0, 42, 7, 3}
+	for i, value := range want {
+		if got := binary.BigEndian.Uint16(pdu[12+2*i:]); got != value {
+			t.Errorf("entity ID field %d = %d, want %d", i, got, value)
+		}
+	}
+	if binary.BigEndian.Uint32(pdu[24:]) != 99 {
+		t.Errorf("request ID = %d", binary.BigEndian.Uint32(pdu[24:]))
+	}
+}
+
+func TestDISEulerAngles(t *testing.T) {
+	tests := []struct {
+		name                string
+		latitude, longitude float64
+		attitude            models.Attitude
+		psi, theta, phi     float64
+	}{
+		// Body x along north is ECEF +Z, so the nose points straight up the polar axis
+		{"north at origin", 0, 0, models.Attitude{}, 0, -math.Pi / 2, 0},
+		// Body x along east is ECEF +Y, with body y (south) along -Z and body z (down) along -X
+		{"east at origin", 0, 0, models.Attitude{Yaw: 90}, math.Pi / 2, 0, -math.Pi / 2},
+		// At the north pole on the prime meridian, north points along -X and down along -Z
+		{"north at pole", 90, 0, models.Attitude{}, math.Pi, 0, math.Pi},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			psi, theta, phi := disEulerAngles(tt.latitude, tt.longitude, tt.attitude)
+			if angleDiff(psi, tt.psi) > 1e-6 || angleDiff(theta, tt.theta) > 1e-6 || angleDiff(phi, tt.phi) > 1e-6 {
+				t.Errorf("Euler angles = %.4f %.4f %.4f, want %.4f %.4f %.4f", psi, theta, phi, tt.psi, tt.theta, tt.phi)
+			}
+		})
+	}
+}
+
+// angleDiff returns the absolute difference between two angles in radians
+func angleDiff(a, b float64) float64 {
+	return math.Abs(math.Remainder(a-b, 2*math.Pi))
+}
+
+func TestPlatformToDISEntityState(t *testing.T) {
+	fighter := models.NewF16FightingFalconUniversal("VIPER01", "Viper 01", models.Position{Latitude: 38.9, Longitude: -77.0, Altitude: 5000})
+	fighter.State.Velocity = models.Velocity{North: 200}
+
+	state := PlatformToDISEntityState(fighter)
+	if state.EntityType != (models.DISEntityType{Kind: 1, Domain: 2, Country: 225, Category: 1}) {
+		t.Errorf("Unexpected fighter entity type %s", state.EntityType)
+	}
+	if state.ForceID != DISForceFriendly || state.Marking != fighter.GetCallSign() || state.DeadReckoning != DISDeadReckoningRVW {
+		t.Errorf("Unexpected identity %+v", state)
+	}
+	if got := models.ECEFToGeodetic(state.Location); math.Abs(got.Latitude-38.9) > 1e-9 || math.Abs(got.Altitude-5000) > 1e-3 {
+		t.Errorf("Location decodes to %+v", got)
+	}
+	if speed := math.Sqrt(state.Velocity.X*state.Velocity.X + state.Velocity.Y*state.Velocity.Y + state.Velocity.Z*state.Velocity.Z); math.Abs(speed-200) > 1e-9 {
+		t.Errorf("ECEF speed = %f, want 200", speed)
+	}
+
+	// A type's dis_config wins over inference
+	fighter.TypeDef.DISConf.EntityType = "1.2.225.1.3.3"
+	if state := PlatformToDISEntityState(fighter); state.EntityType.Subcategory != 3 || state.EntityType.Specific != 3 {
+		t.Errorf("Expected the configured entity type, got %s", state.EntityType)
+	}
+
+	destroyer := models.NewArleighBurkeDestroyerUniversal("DDG51", "Arleigh Burke", models.Position{})
+	if entityType := PlatformToDISEntityState(destroyer).EntityType; entityType.Domain != 3 || entityType.Category != 4 {
+		t.Errorf("Unexpected destroyer entity type %s", entityType)
+	}
+	airliner := models.NewBoeing737_800Universal("UAL123", "UA123", models.Position{})
+	if state := PlatformToDISEntityState(airliner); state.EntityType.Category != 57 || state.EntityType.Country != 0 || state.ForceID != DISForceNeutral {
+		t.Errorf("Unexpected airliner entity type %s, force %d", state.EntityType, state.ForceID)
+	}
+}
+
+func TestDISPublisher_UDP(t *testing.T) {
+	receiver, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer receiver.Close()
+
+	publisher, err := NewDISPublisher("udp://"+receiver.LocalAddr().String(), DISOptions{SiteID: 5, ApplicationID: 6, Heartbeat: 100 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("NewDISPublisher failed: %v", err)
+	}
+	defer publisher.Close()
+	clock := fixedClock(time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC))
+	publisher.SetClock(&clock)
+
+	receive := func() []byte {
+		t.Helper()
+		receiver.SetReadDeadline(time.Now().Add(5 * time.Second))
+		buf := make([]byte, 1500)
+		n, err := receiver.Read(buf)
+		if err != nil {
+			t.Fatalf("Failed to read DIS datagram: %v", err)
+		}
+		return buf[:n]
+	}
+
+	fighter := models.NewF16FightingFalconUniversal("VIPER01", "Viper 01", models.Position{Latitude: 38.9, Longitude: -77.0, Altitude: 5000})
+	airliner := models.NewBoeing737_800Universal("UAL123", "UA123", models.Position{})
+	for _, platform := range []models.Platform{fighter, airliner} {
+		if err := publisher.PublishPlatform(platform); err != nil {
+			t.Fatal(err)
+		}
+		state, err := DecodeEntityStatePDU(receive())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if id, _ := publisher.EntityID(platform.GetID()); state.ID != id || id.Site != 5 || id.Application != 6 {
+			t.Errorf("Unexpected entity ID %+v for %s", state.ID, platform.GetID())
+		}
+	}
+	if first, _ := publisher.EntityID("VIPER01"); first.Entity != 1 {
+		t.Errorf("Expected entity numbers to start at 1, got %d", first.Entity)
+	}
+
+	// Without updates the last PDUs are repeated
+	for i := 0; i < 2; i++ {
+		if _, err := DecodeEntityStatePDU(receive()); err != nil {
+			t.Fatalf("Expected a heartbeat: %v", err)
+		}
+	}
+
+	// Removal deactivates the entity and sends Remove Entity; heartbeats may interleave
+	id, _ := publisher.EntityID("VIPER01")
+	if err := publisher.RemoveEntity("VIPER01"); err != nil {
+		t.Fatal(err)
+	}
+	deactivated, removed := false, false
+	for !deactivated || !removed {
+		pdu := receive()
+		switch pdu[2] {
+		case disEntityStatePDU:
+			state, _ := DecodeEntityStatePDU(pdu)
+			if state.ID == id {
+				deactivated = state.Appearance&disAppearanceDeactivated != 0
+			}
+		case disRemoveEntityPDU:
+			removed = binary.BigEndian.Uint16(pdu[22:]) == id.Entity
+		}
+	}
+	if _, ok := publisher.EntityID("VIPER01"); ok {
+		t.Error("Expected the removed platform to be forgotten")
+	}
+
+	if _, err := NewDISPublisher("tcp://127.0.0.1:3000", DISOptions{}); err == nil {
+		t.Error("Expected tcp:// to be rejected for DIS")
+	}
+}
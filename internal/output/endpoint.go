@@ -0,0 +1,136 @@
+package output
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/rhino11/trafficsim/internal/models"
+)
+
+// Endpoint schemes understood by NewCoTPublisher
+const (
+	SchemeUDP = "udp" // UDP, usually a multicast group
+	SchemeTCP = "tcp" // Plain TCP stream, e.g. a TAK Server's streaming input
+	SchemeSSL = "ssl" // TLS stream with optional client certificate
+)
+
+// Endpoint is a parsed CoT endpoint URL such as "ssl://tak.example.com:8089"
+type Endpoint struct {
+	Scheme string
+	Host   string
+	Port   int
+}
+
+// Address returns the host:port of the endpoint
+func (e Endpoint) Address() string {
+	return net.JoinHostPort(e.Host, strconv.Itoa(e.Port))
+}
+
+// String returns the endpoint as a URL
+func (e Endpoint) String() string {
+	return e.Scheme + "://" + e.Address()
+}
+
+// ParseEndpoint parses a udp://, tcp:// or ssl:// endpoint URL with a host and port
+func ParseEndpoint(endpoint string) (Endpoint, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return Endpoint{}, fmt.Errorf("invalid endpoint %q: %w", endpoint, err)
+	}
+
+	scheme := strings.ToLower(u.Scheme)
+	switch scheme {
+	case SchemeUDP, SchemeTCP, SchemeSSL:
+	default:
+		return Endpoint{}, fmt.Errorf("unsupported endpoint scheme %q in %q, expected udp, tcp or ssl", u.Scheme, endpoint)
+	}
+
+	if u.Hostname() == "" || u.Port() == "" {
+		return Endpoint{}, fmt.Errorf("endpoint %q must have a host and port", endpoint)
+	}
+	port, err := strconv.Atoi(u.Port())
+	if err != nil || port < 1 || port > 65535 {
+		return Endpoint{}, fmt.Errorf("invalid port in endpoint %q", endpoint)
+	}
+
+	return Endpoint{Scheme: scheme, Host: u.Hostname(), Port: port}, nil
+}
+
+// TLSOptions locates the PEM files used for ssl:// endpoints. TAK Server client
+// certificates are usually issued as .p12 files and need converting to PEM first.
+type TLSOptions struct {
+	CertFile           string // Client certificate
+	KeyFile            string // Client private key
+	CAFile             string // CA bundle used to verify the server, system roots when empty
+	ServerName         string // Overrides the name checked against the server certificate
+	InsecureSkipVerify bool   // Skip server verification, for test servers only
+}
+
+// LoadTLSConfig builds a client TLS configuration from the options
+func LoadTLSConfig(opts TLSOptions) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         opts.ServerName,
+		InsecureSkipVerify: opts.InsecureSkipVerify, // #nosec G402 -- explicitly requested for test servers
+	}
+
+	if (opts.CertFile == "") != (opts.KeyFile == "") {
+		return nil, fmt.Errorf("client certificate and key must be given together")
+	}
+	if opts.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(opts.CertFile, opts.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	if opts.CAFile != "" {
+		pem, err := os.ReadFile(opts.CAFile) // #nosec G304 -- path comes from the operator's configuration
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in CA file %s", opts.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	return tlsConfig, nil
+}
+
+// CoTPublisher sends CoT messages for platform states to an endpoint
+type CoTPublisher interface {
+	PublishPlatformState(state PlatformState) error
+	SetClock(clock models.Clock)
+	SetEncoding(encoding Encoding) error
+	Close() error
+}
+
+// NewCoTPublisher creates a publisher for a udp://, tcp:// or ssl:// endpoint. Stream options
+// are ignored for UDP; ssl:// endpoints without opts.TLS verify the server against the system roots.
+func NewCoTPublisher(endpoint string, opts StreamOptions) (CoTPublisher, error) {
+	ep, err := ParseEndpoint(endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	switch ep.Scheme {
+	case SchemeUDP:
+		return NewMulticastPublisher(ep.Host, ep.Port)
+	case SchemeSSL:
+		if opts.TLS == nil {
+			opts.TLS = &tls.Config{MinVersion: tls.VersionTLS12}
+		}
+	default:
+		opts.TLS = nil
+	}
+	return NewStreamPublisher(ep.Address(), opts), nil
+}
@@ -0,0 +1,248 @@
+package output
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/rhino11/trafficsim/internal/models"
+)
+
+// GDL90 framing and message IDs
+const (
+	gdl90Flag          = 0x7E
+	gdl90Escape        = 0x7D
+	gdl90HeartbeatID   = 0x00
+	gdl90TrafficID     = 0x14
+	gdl90HeartbeatRate = time.Second
+)
+
+// gdl90CRCTable is the CRC-CCITT table from the GDL90 ICD
+var gdl90CRCTable = func() [256]uint16 {
+	var table [256]uint16
+	for i := range table {
+		crc := uint16(i) << 8
+		for bit := 0; bit < 8; bit++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+		table[i] = crc
+	}
+	return table
+}()
+
+// GDL90Publisher sends GDL90 traffic reports over UDP, with the once a second heartbeat that EFBs
+// need to consider the feed connected
+type GDL90Publisher struct {
+	conn   *net.UDPConn
+	clock  models.Clock
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+	mu     sync.Mutex // Guards clock
+}
+
+// NewGDL90Publisher creates a publisher for address (host:port), usually a broadcast address on port 4000
+func NewGDL90Publisher(address string) (*GDL90Publisher, error) {
+	addr, err := net.ResolveUDPAddr("udp", address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve GDL90 address: %w", err)
+	}
+	conn, err := net.DialUDP("udp", nil, addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create UDP connection: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &GDL90Publisher{conn: conn, clock: models.WallClock{}, cancel: cancel}
+	p.wg.Add(1)
+	go p.heartbeat(ctx)
+	return p, nil
+}
+
+// SetClock sets the clock used for heartbeat timestamps
+func (p *GDL90Publisher) SetClock(clock models.Clock) {
+	if clock == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.clock = clock
+}
+
+// PublishTarget sends a traffic report for a target
+func (p *GDL90Publisher) PublishTarget(target ADSBTarget) error {
+	if _, err := p.conn.Write(FrameGDL90(EncodeGDL90Traffic(target))); err != nil {
+		return fmt.Errorf("failed to send GDL90 traffic report: %w", err)
+	}
+	return nil
+}
+
+// Close stops the heartbeat and closes the socket
+func (p *GDL90Publisher) Close() error {
+	p.cancel()
+	p.wg.Wait()
+	return p.conn.Close()
+}
+
+// heartbeat sends a heartbeat immediately and then once a second until cancelled
+func (p *GDL90Publisher) heartbeat(ctx context.Context) {
+	defer p.wg.Done()
+	ticker := time.NewTicker(gdl90HeartbeatRate)
+	defer ticker.Stop()
+	for {
+		p.mu.Lock()
+		now := p.clock.Now()
+		p.mu.Unlock()
+		if _, err := p.conn.Write(FrameGDL90(EncodeGDL90Heartbeat(now))); err != nil && ctx.Err() == nil {
+			log.Printf("Failed to send GDL90 heartbeat: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// EncodeGDL90Heartbeat encodes a heartbeat reporting a valid position and UTC time, timestamped with
+// the seconds since 0000Z of now
+func EncodeGDL90Heartbeat(now time.Time) []byte {
+	now = now.UTC()
+	seconds := now.Hour()*3600 + now.Minute()*60 + now.Second()
+
+	status2 := byte(0x01) // UTC OK
+	if seconds&0x10000 != 0 {
+		status2 |= 0x80 // Timestamp bit 16
+	}
+	return []byte{
+		gdl90HeartbeatID,
+		0x81, // GPS position valid, UAT initialized
+		status2,
+		byte(seconds), byte(seconds >> 8),
+		0x00, 0x00, // No uplink or basic/long messages received
+	}
+}
+
+// EncodeGDL90Traffic encodes a traffic report (message 20) for a target
+func EncodeGDL90Traffic(target ADSBTarget) []byte {
+	message := make([]byte, 28)
+	message[0] = gdl90TrafficID
+	message[1] = 0x00 // No traffic alert, ADS-B with ICAO address
+	put24(message[2:], target.ICAO)
+	put24(message[5:], gdl90Angle(target.Latitude))
+	put24(message[8:], gdl90Angle(target.Longitude))
+
+	altitude := uint32(0xFFF)
+	if !math.IsNaN(target.Altitude) {
+		altitude = uint32(math.Max(0, math.Min(0xFFE, math.Round((target.Altitude+1000)/25))))
+	}
+	misc := byte(0x01) // True track angle, updated report
+	if !target.OnGround {
+		misc |= 0x08
+	}
+	message[11] = byte(altitude >> 4)
+	message[12] = byte(altitude&0x0F)<<4 | misc
+	message[13] = 0xA9 // NIC 10, NACp 9
+
+	horizontal := uint32(math.Max(0, math.Min(0xFFE, math.Round(target.GroundSpeed))))
+	vertical := int32(math.Max(-510, math.Min(510, math.Round(target.VerticalRate/64))))
+	message[14] = byte(horizontal >> 4)
+	message[15] = byte(horizontal&0x0F)<<4 | byte(uint32(vertical)>>8&0x0F)
+	message[16] = byte(vertical)
+	message[17] = byte(int(math.Round(math.Mod(target.Track+360, 360)*256/360)) % 256)
+	message[18] = target.Emitter
+
+	callsign := fmt.Sprintf("%-8.8s", target.Callsign)
+	copy(message[19:27], callsign)
+	message[27] = gdl90EmergencyCode(target.Squawk) << 4
+	return message
+}
+
+// gdl90Angle converts degrees to 24-bit two's complement semicircles
+func gdl90Angle(degrees float64) uint32 {
+	return uint32(int32(math.Round(degrees*(1<<23)/180))) & 0xFFFFFF
+}
+
+// gdl90EmergencyCode maps emergency squawks to the GDL90 emergency/priority code
+func gdl90EmergencyCode(squawk string) byte {
+	switch squawk {
+	case "7700":
+		return 1 // General emergency
+	case "7600":
+		return 4 // No communications
+	case "7500":
+		return 5 // Unlawful interference
+	}
+	return 0
+}
+
+// put24 writes a 24-bit big-endian value
+func put24(b []byte, value uint32) {
+	b[0] = byte(value >> 16)
+	b[1] = byte(value >> 8)
+	b[2] = byte(value)
+}
+
+// GDL90CRC computes the frame check sequence of a message
+func GDL90CRC(message []byte) uint16 {
+	var crc uint16
+	for _, b := range message {
+		crc = gdl90CRCTable[crc>>8] ^ crc<<8 ^ uint16(b)
+	}
+	return crc
+}
+
+// FrameGDL90 appends the CRC (least significant byte first), escapes flag and control bytes and adds
+// the 0x7E flags
+func FrameGDL90(message []byte) []byte {
+	crc := GDL90CRC(message)
+	payload := append(append([]byte{}, message...), byte(crc), byte(crc>>8))
+
+	frame := make([]byte, 0, len(payload)+4)
+	frame = append(frame, gdl90Flag)
+	for _, b := range payload {
+		if b == gdl90Flag || b == gdl90Escape {
+			frame = append(frame, gdl90Escape, b^0x20)
+		} else {
+			frame = append(frame, b)
+		}
+	}
+	return append(frame, gdl90Flag)
+}
+
+// ParseGDL90 removes the flags and escapes from a frame and checks its CRC, returning the message
+func ParseGDL90(frame []byte) ([]byte, error) {
+	if len(frame) < 4 || frame[0] != gdl90Flag || frame[len(frame)-1] != gdl90Flag {
+		return nil, fmt.Errorf("GDL90 frame is not delimited by 0x7E flags")
+	}
+
+	var payload []byte
+	for i := 1; i < len(frame)-1; i++ {
+		b := frame[i]
+		if b == gdl90Escape {
+			if i++; i == len(frame)-1 {
+				return nil, fmt.Errorf("GDL90 frame ends in an escape")
+			}
+			b = frame[i] ^ 0x20
+		}
+		payload = append(payload, b)
+	}
+	if len(payload) < 3 {
+		return nil, fmt.Errorf("GDL90 frame too short")
+	}
+
+	message := payload[:len(payload)-2]
+	crc := uint16(payload[len(payload)-2]) | uint16(payload[len(payload)-1])<<8
+	if crc != GDL90CRC(message) {
+		return nil, fmt.Errorf("GDL90 CRC mismatch")
+	}
+	return message, nil
+}
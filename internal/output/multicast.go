@@ -5,6 +5,8 @@ import (
 	"log"
 	"net"
 	"time"
+
+	"github.com/rhino11/trafficsim/internal/models"
 )
 
 // MulticastPublisher handles publishing CoT messages to multicast groups
@@ -40,9 +42,22 @@ func (p *MulticastPublisher) SetPublishInterval(interval time.Duration) {
 	p.interval = interval
 }
 
+// SetClock sets the clock used to timestamp published messages
+func (p *MulticastPublisher) SetClock(clock models.Clock) {
+	p.generator.SetClock(clock)
+}
+
+// SetEncoding selects CoT XML or the TAK Protocol mesh encoding for published datagrams
+func (p *MulticastPublisher) SetEncoding(encoding Encoding) error {
+	if encoding == EncodingStream {
+		return fmt.Errorf("the stream encoding needs a tcp:// or ssl:// endpoint, use mesh for UDP")
+	}
+	return p.generator.SetEncoding(encoding)
+}
+
 // PublishPlatformState publishes a single platform state as CoT message
 func (p *MulticastPublisher) PublishPlatformState(state PlatformState) error {
-	cotMessage, err := p.generator.GenerateCoTMessage(state)
+	cotMessage, err := p.generator.GenerateMessage(state)
 	if err != nil {
 		return fmt.Errorf("failed to generate CoT message: %w", err)
 	}
@@ -56,6 +71,9 @@ func (p *MulticastPublisher) PublishPlatformState(state PlatformState) error {
 }
 
 // StartPublishing starts a goroutine that continuously publishes platform states
+//
+// Deprecated: add a cot sink to a SinkManager, which schedules, filters and reports on updates for
+// every output format.
 func (p *MulticastPublisher) StartPublishing(platformStates chan PlatformState, stopChan chan bool) {
 	ticker := time.NewTicker(p.interval)
 	defer ticker.Stop()
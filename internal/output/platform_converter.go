@@ -14,21 +14,51 @@ func PlatformToCoTState(platform models.Platform) PlatformState {
 	// Extract CoT type and affiliation from platform category and type
 	cotType, affiliation := determinePlatformCoTInfo(platform)
 
+	var squawk string
+	if platform.GetType() == models.PlatformTypeAirborne {
+		squawk = models.SquawkOf(platform)
+	}
+
 	return PlatformState{
-		ID:          platform.GetID(),
-		Callsign:    platform.GetCallSign(),
-		Latitude:    state.Position.Latitude,
-		Longitude:   state.Position.Longitude,
-		Altitude:    state.Position.Altitude,
-		Speed:       state.Speed,
-		Course:      state.Heading,
-		CoTType:     cotType,
-		Affiliation: affiliation,
+		ID:           platform.GetID(),
+		Callsign:     platform.GetCallSign(),
+		Latitude:     state.Position.Latitude,
+		Longitude:    state.Position.Longitude,
+		Altitude:     state.Position.Altitude,
+		Speed:        state.Speed,
+		Course:       state.Heading,
+		VerticalRate: state.Velocity.Up,
+		Squawk:       squawk,
+		CoTType:      cotType,
+		Affiliation:  affiliation,
 	}
 }
 
-// determinePlatformCoTInfo extracts CoT type and affiliation from platform data
+// determinePlatformCoTInfo returns the CoT type and affiliation for a platform. The type definition's
+// cot_config is used when present, otherwise they are inferred from the category and class. A scenario
+// instance's affiliation overrides either.
 func determinePlatformCoTInfo(platform models.Platform) (cotType, affiliation string) {
+	up, _ := platform.(*models.UniversalPlatform)
+	if up != nil && up.TypeDef != nil && up.TypeDef.CoTConf.Type != "" {
+		cotType = up.TypeDef.CoTConf.Type
+		affiliation = strings.ToLower(up.TypeDef.CoTConf.Affiliation)
+		if affiliation == "" {
+			affiliation = models.AffiliationOf(cotType)
+		}
+	} else {
+		cotType, affiliation = inferPlatformCoTInfo(platform)
+	}
+
+	if up != nil && up.Config != nil && up.Config.Affiliation != "" {
+		affiliation = strings.ToLower(up.Config.Affiliation)
+		cotType = models.WithAffiliation(cotType, affiliation)
+	}
+	return cotType, affiliation
+}
+
+// inferPlatformCoTInfo guesses CoT type and affiliation from the platform category and class,
+// for types without a cot_config block
+func inferPlatformCoTInfo(platform models.Platform) (cotType, affiliation string) {
 	platformType := strings.ToLower(string(platform.GetType()))
 	class := strings.ToLower(platform.GetClass())
 
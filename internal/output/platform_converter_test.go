@@ -0,0 +1,47 @@
+package output
+
+import (
+	"testing"
+
+	"github.com/rhino11/trafficsim/internal/models"
+)
+
+func TestPlatformToCoTState_CoTConfig(t *testing.T) {
+	reaper := models.NewF16FightingFalconUniversal("REAPER01", "REAPER01", models.Position{Latitude: 36.2, Longitude: -115.0})
+	reaper.TypeDef.Class = "MQ-9 Reaper"
+	reaper.TypeDef.CoTConf = models.CoTConfiguration{Type: "a-f-A-M-F-Q", Affiliation: "friend"}
+
+	state := PlatformToCoTState(reaper)
+	if state.CoTType != "a-f-A-M-F-Q" || state.Affiliation != "friend" {
+		t.Errorf("Expected the cot_config type, got %s (%s)", state.CoTType, state.Affiliation)
+	}
+
+	// Scenario instances can override the affiliation
+	reaper.Config.Affiliation = "Hostile"
+	state = PlatformToCoTState(reaper)
+	if state.CoTType != "a-h-A-M-F-Q" || state.Affiliation != "hostile" {
+		t.Errorf("Expected a hostile override, got %s (%s)", state.CoTType, state.Affiliation)
+	}
+
+	// Affiliation comes from the type when cot_config leaves it out
+	reaper.Config.Affiliation = ""
+	reaper.TypeDef.CoTConf.Affiliation = ""
+	if state = PlatformToCoTState(reaper); state.Affiliation != "friend" {
+		t.Errorf("Expected affiliation from the CoT type, got %s", state.Affiliation)
+	}
+}
+
+func TestPlatformToCoTState_InferredType(t *testing.T) {
+	ship := models.NewArleighBurkeDestroyerUniversal("DDG51", "Arleigh Burke", models.Position{Latitude: 36.8, Longitude: -76.3})
+	ship.TypeDef.CoTConf = models.CoTConfiguration{}
+
+	state := PlatformToCoTState(ship)
+	if state.CoTType != "a-f-S-U-W-D" || state.Affiliation != "friend" {
+		t.Errorf("Expected the inferred destroyer type, got %s (%s)", state.CoTType, state.Affiliation)
+	}
+
+	ship.Config.Affiliation = "suspect"
+	if state = PlatformToCoTState(ship); state.CoTType != "a-s-S-U-W-D" {
+		t.Errorf("Expected the override applied to the inferred type, got %s", state.CoTType)
+	}
+}
@@ -0,0 +1,105 @@
+package output
+
+import (
+	"math"
+	"time"
+
+	"github.com/rhino11/trafficsim/internal/config"
+	"github.com/rhino11/trafficsim/internal/models"
+)
+
+// DefaultPolicyHeartbeat is the longest a publishing policy holds back a platform's report
+const DefaultPolicyHeartbeat = time.Minute
+
+// reportedState is what receivers were last told about a platform
+type reportedState struct {
+	location models.ECEF
+	velocity models.ECEF
+	heading  float64
+	speed    float64
+	at       time.Time // Simulation time of the report
+	sent     time.Time // Wall clock time it was sent, for the heartbeat
+}
+
+// publishPolicy holds back reports of platforms that receivers can still place by dead reckoning
+// from their last report, i.e. that keep a steady course and speed
+type publishPolicy struct {
+	position  float64 // meters
+	heading   float64 // degrees
+	speed     float64 // m/s
+	heartbeat time.Duration
+	reported  map[string]reportedState
+}
+
+// newPublishPolicy returns the policy described by cfg, or nil when every platform is reported at
+// every update
+func newPublishPolicy(cfg config.SinkPolicy) (*publishPolicy, error) {
+	if !cfg.Enabled() {
+		return nil, nil
+	}
+	heartbeat, err := cfg.ParseHeartbeat()
+	if err != nil {
+		return nil, err
+	}
+	if heartbeat == 0 {
+		heartbeat = DefaultPolicyHeartbeat
+	}
+	return &publishPolicy{
+		position:  cfg.PositionThreshold,
+		heading:   cfg.HeadingThreshold,
+		speed:     cfg.SpeedThreshold,
+		heartbeat: heartbeat,
+		reported:  make(map[string]reportedState),
+	}, nil
+}
+
+// due returns the platforms worth reporting at wall clock time now and records them as reported
+func (p *publishPolicy) due(platforms []models.Platform, now time.Time) []models.Platform {
+	due := platforms[:0:0]
+	for _, platform := range platforms {
+		state := platform.GetState()
+		last, known := p.reported[platform.GetID()]
+		if known && !p.changed(last, state) && now.Sub(last.sent) < p.heartbeat {
+			continue
+		}
+
+		pos := state.Position
+		p.reported[platform.GetID()] = reportedState{
+			location: models.GeodeticToECEF(pos),
+			velocity: models.ENUToECEF(pos.Latitude, pos.Longitude, state.Velocity.East, state.Velocity.North, state.Velocity.Up),
+			heading:  state.Heading,
+			speed:    state.Speed,
+			at:       state.LastUpdated,
+			sent:     now,
+		}
+		due = append(due, platform)
+	}
+	return due
+}
+
+// changed reports whether a platform has moved off its last report by more than a threshold
+func (p *publishPolicy) changed(last reportedState, state models.PlatformState) bool {
+	if p.heading > 0 && math.Abs(math.Remainder(state.Heading-last.heading, 360)) > p.heading {
+		return true
+	}
+	if p.speed > 0 && math.Abs(state.Speed-last.speed) > p.speed {
+		return true
+	}
+	return p.position > 0 && deadReckoningError(last, state) > p.position
+}
+
+// deadReckoningError returns the distance in meters between a platform's position and where a
+// receiver extrapolates it to, moving at constant velocity from the last report
+func deadReckoningError(last reportedState, state models.PlatformState) float64 {
+	elapsed := state.LastUpdated.Sub(last.at).Seconds()
+	actual := models.GeodeticToECEF(state.Position)
+	dx := actual.X - (last.location.X + last.velocity.X*elapsed)
+	dy := actual.Y - (last.location.Y + last.velocity.Y*elapsed)
+	dz := actual.Z - (last.location.Z + last.velocity.Z*elapsed)
+	return math.Sqrt(dx*dx + dy*dy + dz*dz)
+}
+
+// forget drops a platform that has left the sink's picture, so it is reported as soon as it returns
+func (p *publishPolicy) forget(platformID string) {
+	delete(p.reported, platformID)
+}
@@ -0,0 +1,151 @@
+package output
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/rhino11/trafficsim/internal/config"
+	"github.com/rhino11/trafficsim/internal/models"
+)
+
+// steamingShip returns a ship t seconds along a steady 10 m/s easterly course
+func steamingShip(t float64, start time.Time) *models.UniversalPlatform {
+	ship := models.NewContainerShipUniversal("MAERSK1", "Maersk Alabama", models.Position{})
+	lat := 36.9
+	ship.State.Position = models.Position{
+		Latitude:  lat,
+		Longitude: -76.2 + 10*t/(6378137*math.Cos(lat*math.Pi/180))*180/math.Pi,
+	}
+	ship.State.Velocity = models.Velocity{East: 10}
+	ship.State.Heading = 90
+	ship.State.Speed = 10
+	ship.State.LastUpdated = start.Add(time.Duration(t * float64(time.Second)))
+	return ship
+}
+
+func TestNewPublishPolicy(t *testing.T) {
+	if policy, err := newPublishPolicy(config.SinkPolicy{}); policy != nil || err != nil {
+		t.Errorf("Expected no policy without thresholds, got %+v (%v)", policy, err)
+	}
+	policy, err := newPublishPolicy(config.SinkPolicy{PositionThreshold: 50})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if policy.heartbeat != DefaultPolicyHeartbeat {
+		t.Errorf("Expected the default heartbeat, got %v", policy.heartbeat)
+	}
+	if _, err := newPublishPolicy(config.SinkPolicy{PositionThreshold: 50, Heartbeat: "often"}); err == nil {
+		t.Error("Expected an invalid heartbeat to be rejected")
+	}
+}
+
+func TestPublishPolicy(t *testing.T) {
+	start := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	wall := time.Now()
+
+	tests := []struct {
+		name   string
+		policy config.SinkPolicy
+		later  func() *models.UniversalPlatform // The ship 30s after its first report
+		wait   time.Duration                    // Wall clock time before the second report
+		due    bool
+	}{
+		{
+			name:   "steady course",
+			policy: config.SinkPolicy{PositionThreshold: 50, HeadingThreshold: 10, SpeedThreshold: 2},
+			later:  func() *models.UniversalPlatform { return steamingShip(30, start) },
+			wait:   30 * time.Second,
+		},
+		{
+			name:   "stopped short of the dead reckoned position",
+			policy: config.SinkPolicy{PositionThreshold: 50},
+			later: func() *models.UniversalPlatform {
+				ship := steamingShip(20, start)
+				ship.State.LastUpdated = start.Add(30 * time.Second)
+				return ship
+			},
+			wait: 30 * time.Second,
+			due:  true,
+		},
+		{
+			name:   "dead reckoning within the threshold",
+			policy: config.SinkPolicy{PositionThreshold: 150},
+			later: func() *models.UniversalPlatform {
+				ship := steamingShip(20, start)
+				ship.State.LastUpdated = start.Add(30 * time.Second)
+				return ship
+			},
+			wait: 30 * time.Second,
+		},
+		{
+			name:   "turn",
+			policy: config.SinkPolicy{HeadingThreshold: 10},
+			later: func() *models.UniversalPlatform {
+				ship := steamingShip(30, start)
+				ship.State.Heading = 105
+				return ship
+			},
+			wait: 30 * time.Second,
+			due:  true,
+		},
+		{
+			name:   "turn through north",
+			policy: config.SinkPolicy{HeadingThreshold: 10},
+			later: func() *models.UniversalPlatform {
+				ship := steamingShip(30, start)
+				ship.State.Heading = 355
+				return ship
+			},
+			wait: 30 * time.Second,
+			due:  true,
+		},
+		{
+			name:   "slowing down",
+			policy: config.SinkPolicy{SpeedThreshold: 2},
+			later: func() *models.UniversalPlatform {
+				ship := steamingShip(30, start)
+				ship.State.Speed = 5
+				return ship
+			},
+			wait: 30 * time.Second,
+			due:  true,
+		},
+		{
+			name:   "heartbeat",
+			policy: config.SinkPolicy{PositionThreshold: 50, Heartbeat: "20s"},
+			later:  func() *models.UniversalPlatform { return steamingShip(30, start) },
+			wait:   30 * time.Second,
+			due:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy, err := newPublishPolicy(tt.policy)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if due := policy.due([]models.Platform{steamingShip(0, start)}, wall); len(due) != 1 {
+				t.Fatal("Expected a new platform to be reported")
+			}
+			if due := policy.due([]models.Platform{tt.later()}, wall.Add(tt.wait)); (len(due) == 1) != tt.due {
+				t.Errorf("Reported = %v, want %v", len(due) == 1, tt.due)
+			}
+		})
+	}
+}
+
+func TestPublishPolicy_Forget(t *testing.T) {
+	start := time.Now()
+	policy, _ := newPublishPolicy(config.SinkPolicy{PositionThreshold: 50})
+	policy.due([]models.Platform{steamingShip(0, start)}, start)
+	if due := policy.due([]models.Platform{steamingShip(1, start)}, start.Add(time.Second)); len(due) != 0 {
+		t.Fatal("Expected the steady ship to be held back")
+	}
+
+	// A platform that comes back after leaving is reported straight away
+	policy.forget("MAERSK1")
+	if due := policy.due([]models.Platform{steamingShip(2, start)}, start.Add(2*time.Second)); len(due) != 1 {
+		t.Error("Expected a forgotten platform to be reported")
+	}
+}
@@ -0,0 +1,133 @@
+package output
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rhino11/trafficsim/internal/models"
+)
+
+// SBS-1 transmission types sent for each target
+const (
+	sbsIdentification   = 1 // Callsign
+	sbsAirbornePosition = 3 // Altitude, latitude and longitude
+	sbsAirborneVelocity = 4 // Ground speed, track and vertical rate
+	sbsSurveillanceID   = 6 // Altitude and squawk
+)
+
+// SBSPublisher streams SBS-1 BaseStation CSV to a TCP endpoint such as a port 30003 consumer.
+// It reuses the CoT stream transport, so messages are queued while disconnected.
+type SBSPublisher struct {
+	stream *StreamPublisher
+	mu     sync.Mutex // Guards clock
+	clock  models.Clock
+}
+
+// NewSBSPublisher creates a publisher that connects to address (host:port)
+func NewSBSPublisher(address string, opts StreamOptions) *SBSPublisher {
+	opts.TLS = nil
+	return &SBSPublisher{
+		stream: NewStreamPublisher(address, opts),
+		clock:  models.WallClock{},
+	}
+}
+
+// SetClock sets the clock used for the generated and logged timestamps
+func (p *SBSPublisher) SetClock(clock models.Clock) {
+	if clock == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.clock = clock
+}
+
+// PublishTarget queues the identification, position, velocity and squawk messages for a target
+func (p *SBSPublisher) PublishTarget(target ADSBTarget) error {
+	p.mu.Lock()
+	now := p.clock.Now()
+	p.mu.Unlock()
+	return p.stream.Send(FormatSBS(target, now))
+}
+
+// Stats returns the stream connection state and counters
+func (p *SBSPublisher) Stats() StreamStats {
+	return p.stream.Stats()
+}
+
+// Close flushes queued messages and closes the connection
+func (p *SBSPublisher) Close() error {
+	return p.stream.Close()
+}
+
+// FormatSBS returns the SBS-1 MSG lines for a target, CRLF terminated, timestamped now
+func FormatSBS(target ADSBTarget, now time.Time) []byte {
+	var builder strings.Builder
+	for _, transmission := range []int{sbsIdentification, sbsAirbornePosition, sbsAirborneVelocity, sbsSurveillanceID} {
+		builder.WriteString(formatSBSMessage(transmission, target, now))
+		builder.WriteString("\r\n")
+	}
+	return []byte(builder.String())
+}
+
+// formatSBSMessage formats one MSG line. Fields a transmission type does not carry are left empty,
+// and flags are 0 or -1, as BaseStation writes them.
+func formatSBSMessage(transmission int, target ADSBTarget, now time.Time) string {
+	now = now.UTC()
+	date, clock := now.Format("2006/01/02"), now.Format("15:04:05.000")
+	fields := []string{
+		"MSG", fmt.Sprint(transmission), "1", "1", fmt.Sprintf("%06X", target.ICAO), "1",
+		date, clock, date, clock,
+		"", "", "", "", "", "", "", "", "", "", "", "",
+	}
+	const (
+		callsign = iota + 10
+		altitude
+		groundSpeed
+		track
+		latitude
+		longitude
+		verticalRate
+		squawk
+		alert
+		emergency
+		spi
+		onGround
+	)
+
+	switch transmission {
+	case sbsIdentification:
+		fields[callsign] = target.Callsign
+	case sbsAirbornePosition:
+		fields[altitude] = fmt.Sprintf("%.0f", target.Altitude)
+		fields[latitude] = fmt.Sprintf("%.5f", target.Latitude)
+		fields[longitude] = fmt.Sprintf("%.5f", target.Longitude)
+	case sbsAirborneVelocity:
+		fields[groundSpeed] = fmt.Sprintf("%.0f", target.GroundSpeed)
+		fields[track] = fmt.Sprintf("%.0f", target.Track)
+		fields[verticalRate] = fmt.Sprintf("%.0f", target.VerticalRate)
+	case sbsSurveillanceID:
+		fields[altitude] = fmt.Sprintf("%.0f", target.Altitude)
+		fields[squawk] = target.Squawk
+	}
+	fields[alert] = "0"
+	fields[emergency] = sbsFlag(isEmergencySquawk(target.Squawk))
+	fields[spi] = "0"
+	fields[onGround] = sbsFlag(target.OnGround)
+	return strings.Join(fields, ",")
+}
+
+// sbsFlag formats a BaseStation boolean
+func sbsFlag(value bool) string {
+	if value {
+		return "-1"
+	}
+	return "0"
+}
+
+// isEmergencySquawk reports hijack, radio failure and emergency codes
+func isEmergencySquawk(squawk string) bool {
+	return squawk == "7500" || squawk == "7600" || squawk == "7700"
+}
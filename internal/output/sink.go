@@ -0,0 +1,332 @@
+package output
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rhino11/trafficsim/internal/config"
+	"github.com/rhino11/trafficsim/internal/models"
+)
+
+// Default time between sink updates
+const (
+	DefaultSinkRate = time.Second
+)
+
+// Sink writes platform reports in one format to one destination. Publish and Remove are called from
+// a single goroutine; SetClock may be called from any.
+type Sink interface {
+	// Publish sends a report for each platform the format applies to and returns how many were
+	// sent. The error joins one error per report that failed.
+	Publish(platforms []models.Platform) (int, error)
+	// Accepts reports whether the format has a report for a platform
+	Accepts(platform models.Platform) bool
+	// Remove tells receivers that a platform is no longer reported
+	Remove(platformID string) error
+	SetClock(clock models.Clock)
+	Close() error
+}
+
+// NewSink creates the sink for cfg's format and endpoint
+func NewSink(cfg config.SinkConfig) (Sink, error) {
+	rate, err := cfg.ParseRate()
+	if err != nil {
+		return nil, err
+	}
+
+	switch strings.ToLower(cfg.Format) {
+	case config.SinkFormatCoT:
+		publisher, err := newCoTSinkPublisher(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return &cotSink{publisher: publisher}, nil
+	case config.SinkFormatSBS, config.SinkFormatGDL90:
+		publisher, err := NewADSBPublisher(cfg.Format, cfg.Endpoint, StreamOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return &adsbSink{publisher: publisher}, nil
+	case config.SinkFormatAIS:
+		publisher, err := NewAISPublisher(cfg.Endpoint, StreamOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return &aisSink{publisher: publisher}, nil
+	case config.SinkFormatDIS:
+		heartbeat, err := cfg.DIS.ParseHeartbeat()
+		if err != nil {
+			return nil, err
+		}
+		publisher, err := NewDISPublisher(cfg.Endpoint, DISOptions{
+			SiteID:          uint16(cfg.DIS.SiteID),
+			ApplicationID:   uint16(cfg.DIS.ApplicationID),
+			ExerciseID:      uint8(cfg.DIS.ExerciseID),
+			ProtocolVersion: uint8(cfg.DIS.ProtocolVersion),
+			Heartbeat:       heartbeat,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return &disSink{publisher: publisher}, nil
+	case config.SinkFormatASTERIX:
+		publisher, err := NewASTERIXPublisher(cfg.Endpoint, ASTERIXOptions{
+			SAC:          uint8(cfg.ASTERIX.SAC),
+			SIC:          uint8(cfg.ASTERIX.SIC),
+			UpdatePeriod: sinkRate(cfg.Format, rate),
+		})
+		if err != nil {
+			return nil, err
+		}
+		return &asterixSink{publisher: publisher}, nil
+	default:
+		return nil, fmt.Errorf("unknown sink format %q", cfg.Format)
+	}
+}
+
+// sinkRate returns the configured rate, or the format default when it is zero
+func sinkRate(format string, rate time.Duration) time.Duration {
+	if rate > 0 {
+		return rate
+	}
+	if strings.ToLower(format) == config.SinkFormatASTERIX {
+		return ASTERIXDefaultUpdatePeriod
+	}
+	return DefaultSinkRate
+}
+
+// newCoTSinkPublisher creates a CoT publisher with the TLS, queue, backoff and encoding settings of
+// the sink's cot block
+func newCoTSinkPublisher(cfg config.SinkConfig) (CoTPublisher, error) {
+	maxBackoff, err := cfg.CoT.ParseMaxBackoff()
+	if err != nil {
+		return nil, err
+	}
+	opts := StreamOptions{QueueSize: cfg.CoT.QueueSize, MaxBackoff: maxBackoff}
+
+	if ep, err := ParseEndpoint(cfg.Endpoint); err == nil && ep.Scheme == SchemeSSL {
+		opts.TLS, err = LoadTLSConfig(TLSOptions{
+			CertFile:           cfg.CoT.TLS.CertFile,
+			KeyFile:            cfg.CoT.TLS.KeyFile,
+			CAFile:             cfg.CoT.TLS.CAFile,
+			ServerName:         cfg.CoT.TLS.ServerName,
+			InsecureSkipVerify: cfg.CoT.TLS.InsecureSkipVerify,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	encoding, err := ParseEncoding(cfg.CoT.Encoding)
+	if err != nil {
+		return nil, err
+	}
+	publisher, err := NewCoTPublisher(cfg.Endpoint, opts)
+	if err != nil {
+		return nil, err
+	}
+	if err := publisher.SetEncoding(encoding); err != nil {
+		publisher.Close()
+		return nil, err
+	}
+	return publisher, nil
+}
+
+// countErrors returns how many errors err joins
+func countErrors(err error) int {
+	if err == nil {
+		return 0
+	}
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		return len(joined.Unwrap())
+	}
+	return 1
+}
+
+// cotSink sends a CoT event for every platform
+type cotSink struct {
+	publisher CoTPublisher
+}
+
+func (s *cotSink) Publish(platforms []models.Platform) (int, error) {
+	sent := 0
+	var errs []error
+	for _, platform := range platforms {
+		if err := s.publisher.PublishPlatformState(PlatformToCoTState(platform)); err != nil {
+			errs = append(errs, fmt.Errorf("CoT message for %s: %w", platform.GetCallSign(), err))
+			continue
+		}
+		sent++
+	}
+	return sent, errors.Join(errs...)
+}
+
+func (s *cotSink) Accepts(models.Platform) bool { return true }
+func (s *cotSink) Remove(string) error          { return nil }
+func (s *cotSink) SetClock(clock models.Clock)  { s.publisher.SetClock(clock) }
+func (s *cotSink) Close() error                 { return s.publisher.Close() }
+
+// adsbSink sends an SBS-1 or GDL90 report for every aircraft
+type adsbSink struct {
+	publisher ADSBPublisher
+}
+
+func (s *adsbSink) Publish(platforms []models.Platform) (int, error) {
+	sent := 0
+	var errs []error
+	for _, platform := range platforms {
+		target, ok := PlatformToADSBTarget(platform)
+		if !ok {
+			continue
+		}
+		if err := s.publisher.PublishTarget(target); err != nil {
+			errs = append(errs, fmt.Errorf("ADS-B report for %s: %w", platform.GetCallSign(), err))
+			continue
+		}
+		sent++
+	}
+	return sent, errors.Join(errs...)
+}
+
+func (s *adsbSink) Accepts(platform models.Platform) bool {
+	_, ok := PlatformToADSBTarget(platform)
+	return ok
+}
+
+func (s *adsbSink) Remove(string) error         { return nil }
+func (s *adsbSink) SetClock(clock models.Clock) { s.publisher.SetClock(clock) }
+func (s *adsbSink) Close() error                { return s.publisher.Close() }
+
+// aisSink sends AIS reports for every vessel that is not running dark
+type aisSink struct {
+	publisher *AISPublisher
+}
+
+func (s *aisSink) Publish(platforms []models.Platform) (int, error) {
+	sent := 0
+	var errs []error
+	for _, platform := range platforms {
+		vessel, ok := PlatformToAISVessel(platform)
+		if !ok {
+			continue
+		}
+		if err := s.publisher.PublishVessel(vessel); err != nil {
+			errs = append(errs, fmt.Errorf("AIS report for %s: %w", platform.GetCallSign(), err))
+			continue
+		}
+		sent++
+	}
+	return sent, errors.Join(errs...)
+}
+
+func (s *aisSink) Accepts(platform models.Platform) bool {
+	_, ok := PlatformToAISVessel(platform)
+	return ok
+}
+
+func (s *aisSink) Remove(string) error         { return nil }
+func (s *aisSink) SetClock(clock models.Clock) { s.publisher.SetClock(clock) }
+func (s *aisSink) Close() error                { return s.publisher.Close() }
+
+// disSink sends an Entity State PDU for every platform and removes entities that leave
+type disSink struct {
+	publisher *DISPublisher
+}
+
+func (s *disSink) Publish(platforms []models.Platform) (int, error) {
+	sent := 0
+	var errs []error
+	for _, platform := range platforms {
+		if err := s.publisher.PublishPlatform(platform); err != nil {
+			errs = append(errs, fmt.Errorf("DIS entity state for %s: %w", platform.GetCallSign(), err))
+			continue
+		}
+		sent++
+	}
+	return sent, errors.Join(errs...)
+}
+
+func (s *disSink) Accepts(models.Platform) bool   { return true }
+func (s *disSink) Remove(platformID string) error { return s.publisher.RemoveEntity(platformID) }
+func (s *disSink) SetClock(clock models.Clock)    { s.publisher.SetClock(clock) }
+func (s *disSink) Close() error                   { return s.publisher.Close() }
+
+// asterixSink sends the air and surface picture as one ASTERIX update. Tracks it is not given are
+// left running until they are removed, so a publishing policy can hold back steady ones.
+type asterixSink struct {
+	publisher *ASTERIXPublisher
+}
+
+func (s *asterixSink) Publish(platforms []models.Platform) (int, error) {
+	states := ConvertPlatformListToCoTStates(platforms)
+	if err := s.publisher.UpdateTracks(states); err != nil {
+		return 0, err
+	}
+	sent := 0
+	for _, state := range states {
+		if cotDimension(state.CoTType) != "P" {
+			sent++
+		}
+	}
+	return sent, nil
+}
+
+// Accepts leaves out space platforms, which have no place in a surveillance picture
+func (s *asterixSink) Accepts(platform models.Platform) bool {
+	cotType, _ := determinePlatformCoTInfo(platform)
+	return cotDimension(cotType) != "P"
+}
+
+func (s *asterixSink) Remove(platformID string) error { return s.publisher.EndTrack(platformID) }
+func (s *asterixSink) SetClock(clock models.Clock)    { s.publisher.SetClock(clock) }
+func (s *asterixSink) Close() error                   { return s.publisher.Close() }
+
+// sinkFilter is the compiled form of a config.SinkFilter
+type sinkFilter struct {
+	types        map[models.PlatformType]bool
+	affiliations map[string]bool
+	area         *config.BoundingBox
+}
+
+func newSinkFilter(filter config.SinkFilter) sinkFilter {
+	f := sinkFilter{area: filter.Area}
+	if len(filter.Types) > 0 {
+		f.types = make(map[models.PlatformType]bool)
+		for _, platformType := range filter.Types {
+			f.types[models.PlatformType(strings.ToLower(platformType))] = true
+		}
+	}
+	if len(filter.Affiliations) > 0 {
+		f.affiliations = make(map[string]bool)
+		for _, affiliation := range filter.Affiliations {
+			f.affiliations[strings.ToLower(affiliation)] = true
+		}
+	}
+	return f
+}
+
+// matches reports whether a platform should be sent. External tracks never are, since they are
+// already on the network.
+func (f sinkFilter) matches(platform models.Platform) bool {
+	if models.IsExternalPlatform(platform) {
+		return false
+	}
+	if f.types != nil && !f.types[platform.GetType()] {
+		return false
+	}
+	if f.affiliations != nil {
+		if _, affiliation := determinePlatformCoTInfo(platform); !f.affiliations[affiliation] {
+			return false
+		}
+	}
+	if f.area != nil {
+		pos := platform.GetState().Position
+		if pos.Latitude < f.area.South || pos.Latitude > f.area.North ||
+			pos.Longitude < f.area.West || pos.Longitude > f.area.East {
+			return false
+		}
+	}
+	return true
+}
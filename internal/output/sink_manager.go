@@ -0,0 +1,355 @@
+package output
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/rhino11/trafficsim/internal/config"
+	"github.com/rhino11/trafficsim/internal/models"
+)
+
+// sinkIdleWait is how long Run sleeps when there are no sinks; adding one wakes it early
+const sinkIdleWait = time.Minute
+
+// SinkStatus reports a sink's settings and traffic
+type SinkStatus struct {
+	Name       string    `json:"name"`
+	Format     string    `json:"format"`
+	Endpoint   string    `json:"endpoint"`
+	Rate       string    `json:"rate"`
+	Updates    int64     `json:"updates"` // Snapshots published
+	Reports    int64     `json:"reports"` // Platform reports sent
+	Failed     int64     `json:"failed"`  // Reports and removals that could not be sent
+	Dropped    int64     `json:"dropped"` // Snapshots replaced by a newer one before the sink was ready
+	Saved      int64     `json:"saved"`   // Reports held back by the publishing policy
+	LastUpdate time.Time `json:"last_update,omitempty"`
+	LastError  string    `json:"last_error,omitempty"`
+}
+
+// managedSink is a sink with its own goroutine, schedule, filter and publishing policy
+type managedSink struct {
+	cfg     config.SinkConfig
+	sink    Sink
+	rate    time.Duration
+	filter  sinkFilter
+	policy  *publishPolicy         // Nil when every platform is sent at every update; owned by the goroutine
+	nextDue time.Time              // Guarded by the manager's mu
+	pending chan []models.Platform // Latest snapshot, at most one waiting
+	stop    chan struct{}
+	done    chan struct{}
+	present map[string]bool // Platforms in the last update, owned by the goroutine
+
+	mu     sync.Mutex // Guards status
+	status SinkStatus
+}
+
+// SinkManager fans engine snapshots out to any number of sinks. Each sink publishes on its own
+// goroutine at its own rate, and a sink that falls behind only ever has the newest snapshot
+// waiting, so a slow receiver never holds up the simulation or the other sinks.
+type SinkManager struct {
+	mu    sync.Mutex // Guards sinks and their schedules
+	sinks []*managedSink
+	wake  chan struct{}
+
+	clockMu sync.RWMutex
+	clock   models.Clock
+}
+
+// NewSinkManager creates a manager publishing to every sink in out, including those from the
+// per-format sections
+func NewSinkManager(out config.OutputConfig) (*SinkManager, error) {
+	m := &SinkManager{wake: make(chan struct{}, 1), clock: models.WallClock{}}
+	for _, cfg := range out.SinkConfigs() {
+		if err := m.AddSink(cfg); err != nil {
+			m.Close()
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// SetClock sets the clock sinks use for message timestamps
+func (m *SinkManager) SetClock(clock models.Clock) {
+	if clock == nil {
+		return
+	}
+	m.clockMu.Lock()
+	defer m.clockMu.Unlock()
+	m.clock = clock
+}
+
+// Now returns the time on the current clock; sinks hold the manager as their clock
+func (m *SinkManager) Now() time.Time {
+	m.clockMu.RLock()
+	defer m.clockMu.RUnlock()
+	return m.clock.Now()
+}
+
+// AddSink creates the sink described by cfg and starts publishing to it
+func (m *SinkManager) AddSink(cfg config.SinkConfig) error {
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	sink, err := NewSink(cfg)
+	if err != nil {
+		return fmt.Errorf("sink %s: %w", cfg.Label(), err)
+	}
+	if err := m.Attach(cfg, sink); err != nil {
+		sink.Close()
+		return err
+	}
+	return nil
+}
+
+// Attach starts publishing to an existing sink with the name, rate, filter and policy of cfg
+func (m *SinkManager) Attach(cfg config.SinkConfig, sink Sink) error {
+	rate, err := cfg.ParseRate()
+	if err != nil {
+		return fmt.Errorf("sink %s: %w", cfg.Label(), err)
+	}
+	rate = sinkRate(cfg.Format, rate)
+	policy, err := newPublishPolicy(cfg.Policy)
+	if err != nil {
+		return fmt.Errorf("sink %s: %w", cfg.Label(), err)
+	}
+	sink.SetClock(m)
+
+	s := &managedSink{
+		cfg:     cfg,
+		sink:    sink,
+		rate:    rate,
+		filter:  newSinkFilter(cfg.Filter),
+		policy:  policy,
+		pending: make(chan []models.Platform, 1),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+		status:  SinkStatus{Name: cfg.Label(), Format: cfg.Format, Endpoint: cfg.Endpoint, Rate: rate.String()},
+	}
+
+	m.mu.Lock()
+	for _, existing := range m.sinks {
+		if existing.cfg.Label() == cfg.Label() {
+			m.mu.Unlock()
+			return fmt.Errorf("sink %s already exists", cfg.Label())
+		}
+	}
+	m.sinks = append(m.sinks, s)
+	m.mu.Unlock()
+
+	go s.run()
+	select {
+	case m.wake <- struct{}{}:
+	default:
+	}
+	log.Printf("Sink %s publishing %s to %s every %v", cfg.Label(), cfg.Format, cfg.Endpoint, rate)
+	return nil
+}
+
+// RemoveSink stops and closes the named sink
+func (m *SinkManager) RemoveSink(name string) error {
+	m.mu.Lock()
+	var removed *managedSink
+	for i, s := range m.sinks {
+		if s.cfg.Label() == name {
+			removed = s
+			m.sinks = append(m.sinks[:i], m.sinks[i+1:]...)
+			break
+		}
+	}
+	m.mu.Unlock()
+
+	if removed == nil {
+		return fmt.Errorf("no sink named %s", name)
+	}
+	return removed.close()
+}
+
+// Status returns the state of every sink in the order they were added
+func (m *SinkManager) Status() []SinkStatus {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	statuses := make([]SinkStatus, 0, len(m.sinks))
+	for _, s := range m.sinks {
+		s.mu.Lock()
+		statuses = append(statuses, s.status)
+		s.mu.Unlock()
+	}
+	return statuses
+}
+
+// Offer hands a snapshot to every sink whose next update is due. It never waits on a sink, and
+// the platforms must not be modified afterwards since sinks read them concurrently.
+func (m *SinkManager) Offer(platforms []models.Platform) {
+	now := time.Now()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, s := range m.sinks {
+		if now.Before(s.nextDue) {
+			continue
+		}
+		s.nextDue = s.nextDue.Add(s.rate)
+		if !s.nextDue.After(now) {
+			s.nextDue = now.Add(s.rate)
+		}
+		s.offer(platforms)
+	}
+}
+
+// Run offers a snapshot from source whenever a sink is due, until ctx is cancelled. Updates are
+// skipped while source returns nil.
+func (m *SinkManager) Run(ctx context.Context, source func() []models.Platform) {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-m.wake:
+		case <-timer.C:
+		}
+
+		now := time.Now()
+		if next, ok := m.nextDue(); ok && !next.After(now) {
+			if platforms := source(); platforms != nil {
+				m.Offer(platforms)
+			}
+		}
+
+		wait := sinkIdleWait
+		if next, ok := m.nextDue(); ok {
+			wait = time.Until(next)
+		}
+		timer.Reset(max(wait, time.Millisecond))
+	}
+}
+
+// nextDue returns the earliest time a sink wants an update, if there are any sinks
+func (m *SinkManager) nextDue() (time.Time, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var next time.Time
+	for i, s := range m.sinks {
+		if i == 0 || s.nextDue.Before(next) {
+			next = s.nextDue
+		}
+	}
+	return next, len(m.sinks) > 0
+}
+
+// Close stops and closes every sink
+func (m *SinkManager) Close() error {
+	m.mu.Lock()
+	sinks := m.sinks
+	m.sinks = nil
+	m.mu.Unlock()
+
+	var errs []error
+	for _, s := range sinks {
+		if err := s.close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// offer queues a snapshot, replacing one the sink has not started on yet. Callers hold the
+// manager's mu, so there is never more than one sender.
+func (s *managedSink) offer(platforms []models.Platform) {
+	select {
+	case s.pending <- platforms:
+		return
+	default:
+	}
+	select {
+	case <-s.pending:
+		s.mu.Lock()
+		s.status.Dropped++
+		s.mu.Unlock()
+	default:
+	}
+	s.pending <- platforms
+}
+
+// run publishes queued snapshots until the sink is stopped
+func (s *managedSink) run() {
+	defer close(s.done)
+	for {
+		select {
+		case <-s.stop:
+			return
+		case platforms := <-s.pending:
+			s.publish(platforms)
+		}
+	}
+}
+
+// publish removes platforms that have left the filtered snapshot since the last update, then sends
+// those of the rest that the policy finds worth reporting
+func (s *managedSink) publish(snapshot []models.Platform) {
+	platforms := make([]models.Platform, 0, len(snapshot))
+	present := make(map[string]bool, len(snapshot))
+	for _, platform := range snapshot {
+		if s.filter.matches(platform) && s.sink.Accepts(platform) && !present[platform.GetID()] {
+			present[platform.GetID()] = true
+			platforms = append(platforms, platform)
+		}
+	}
+
+	failed := 0
+	var errs []error
+	for id := range s.present {
+		if !present[id] {
+			if s.policy != nil {
+				s.policy.forget(id)
+			}
+			if err := s.sink.Remove(id); err != nil {
+				failed++
+				errs = append(errs, fmt.Errorf("removal of %s: %w", id, err))
+			}
+		}
+	}
+	s.present = present
+
+	saved := 0
+	if s.policy != nil {
+		due := s.policy.due(platforms, time.Now())
+		saved = len(platforms) - len(due)
+		platforms = due
+	}
+
+	sent, err := s.sink.Publish(platforms)
+	if err != nil {
+		failed += countErrors(err)
+		errs = append(errs, err)
+	}
+	err = errors.Join(errs...)
+
+	s.mu.Lock()
+	s.status.Updates++
+	s.status.Reports += int64(sent)
+	s.status.Failed += int64(failed)
+	s.status.Saved += int64(saved)
+	s.status.LastUpdate = time.Now()
+	if err != nil {
+		s.status.LastError = err.Error()
+	}
+	s.mu.Unlock()
+
+	if err != nil {
+		log.Printf("Sink %s: %v", s.cfg.Label(), err)
+	}
+}
+
+// close stops the sink's goroutine and closes the sink
+func (s *managedSink) close() error {
+	close(s.stop)
+	<-s.done
+	if err := s.sink.Close(); err != nil {
+		return fmt.Errorf("sink %s: %w", s.cfg.Label(), err)
+	}
+	return nil
+}
@@ -0,0 +1,306 @@
+package output
+
+import (
+	"context"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rhino11/trafficsim/internal/config"
+	"github.com/rhino11/trafficsim/internal/models"
+)
+
+// recordingSink records what the manager asks it to do; Publish waits on gate when it is set
+type recordingSink struct {
+	gate chan struct{}
+
+	mu        sync.Mutex
+	published [][]string
+	removed   []string
+	closed    bool
+}
+
+func (s *recordingSink) Publish(platforms []models.Platform) (int, error) {
+	if s.gate != nil {
+		<-s.gate
+	}
+	ids := make([]string, len(platforms))
+	for i, platform := range platforms {
+		ids[i] = platform.GetID()
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.published = append(s.published, ids)
+	return len(ids), nil
+}
+
+func (s *recordingSink) Accepts(models.Platform) bool { return true }
+
+func (s *recordingSink) Remove(platformID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.removed = append(s.removed, platformID)
+	return nil
+}
+
+func (s *recordingSink) SetClock(models.Clock) {}
+
+func (s *recordingSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	return nil
+}
+
+// updates returns the platform IDs of each published update
+func (s *recordingSink) updates() [][]string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([][]string(nil), s.published...)
+}
+
+func TestSinkManager_FilterAndRemove(t *testing.T) {
+	manager, err := NewSinkManager(config.OutputConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sink := &recordingSink{}
+	cfg := config.SinkConfig{Name: "air", Format: config.SinkFormatCoT, Rate: "1ns", Filter: config.SinkFilter{Types: []string{"airborne"}}}
+	if err := manager.Attach(cfg, sink); err != nil {
+		t.Fatal(err)
+	}
+	if err := manager.Attach(cfg, &recordingSink{}); err == nil {
+		t.Error("Expected a second sink with the same name to be rejected")
+	}
+
+	airliner := models.NewBoeing737_800Universal("UAL123", "UA123", models.Position{Latitude: 40.6, Longitude: -73.8, Altitude: 10000})
+	fighter := models.NewF16FightingFalconUniversal("VIPER01", "Viper 01", models.Position{Latitude: 38.9, Longitude: -77.0, Altitude: 5000})
+	ship := models.NewContainerShipUniversal("MAERSK1", "Maersk Alabama", models.Position{Latitude: 36.9, Longitude: -76.2})
+	external := models.NewExternalPlatform("EXT1", "OUTSIDER", models.PlatformTypeAirborne, "a-f-A", models.ExternalInfo{Source: "cot"})
+
+	manager.Offer([]models.Platform{airliner, fighter, ship, external})
+	waitFor(t, "the first update", func() bool { return len(sink.updates()) == 1 })
+	if got := strings.Join(sink.updates()[0], ","); got != "UAL123,VIPER01" {
+		t.Errorf("Expected only the simulated aircraft, got %s", got)
+	}
+
+	// A platform that leaves the picture is removed before the next update
+	time.Sleep(time.Millisecond)
+	manager.Offer([]models.Platform{airliner, ship})
+	waitFor(t, "the second update", func() bool { return len(sink.updates()) == 2 })
+	sink.mu.Lock()
+	removed := strings.Join(sink.removed, ",")
+	sink.mu.Unlock()
+	if removed != "VIPER01" {
+		t.Errorf("Expected VIPER01 to be removed, got %q", removed)
+	}
+
+	status := manager.Status()
+	if len(status) != 1 || status[0].Name != "air" || status[0].Updates != 2 || status[0].Reports != 3 {
+		t.Errorf("Unexpected status %+v", status)
+	}
+
+	if err := manager.RemoveSink("air"); err != nil {
+		t.Fatal(err)
+	}
+	sink.mu.Lock()
+	closed := sink.closed
+	sink.mu.Unlock()
+	if !closed {
+		t.Error("Expected the removed sink to be closed")
+	}
+	if err := manager.RemoveSink("air"); err == nil {
+		t.Error("Expected an error removing an unknown sink")
+	}
+}
+
+func TestSinkManager_SlowSinkDoesNotBlock(t *testing.T) {
+	manager, _ := NewSinkManager(config.OutputConfig{})
+	defer manager.Close()
+
+	slow := &recordingSink{gate: make(chan struct{})}
+	fast := &recordingSink{}
+	manager.Attach(config.SinkConfig{Name: "slow", Format: config.SinkFormatCoT, Rate: "1ns"}, slow)
+	manager.Attach(config.SinkConfig{Name: "fast", Format: config.SinkFormatCoT, Rate: "1ns"}, fast)
+
+	// The slow sink takes the first snapshot and blocks; later ones replace each other while it waits
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		if i == 1 {
+			waitFor(t, "the slow sink to start", func() bool { return len(manager.sinks[0].pending) == 0 })
+		}
+		id := "CAR" + string(rune('A'+i))
+		manager.Offer([]models.Platform{models.NewCivilianCarUniversal(id, "Car "+id, models.Position{})})
+		waitFor(t, "the fast sink", func() bool { return len(fast.updates()) == i+1 })
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Offer waited on the slow sink for %v", elapsed)
+	}
+
+	close(slow.gate)
+	waitFor(t, "the slow sink to catch up", func() bool { return len(slow.updates()) == 2 })
+	updates := slow.updates()
+	if updates[0][0] != "CARA" || updates[1][0] != "CARE" {
+		t.Errorf("Expected the first and newest snapshots, got %v", updates)
+	}
+	for _, status := range manager.Status() {
+		if want := map[string]int64{"slow": 3, "fast": 0}[status.Name]; status.Dropped != want {
+			t.Errorf("sink %s dropped %d snapshots, want %d", status.Name, status.Dropped, want)
+		}
+	}
+}
+
+func TestSinkManager_Policy(t *testing.T) {
+	manager, _ := NewSinkManager(config.OutputConfig{})
+	defer manager.Close()
+	sink := &recordingSink{}
+	cfg := config.SinkConfig{Name: "sea", Format: config.SinkFormatCoT, Rate: "1ns", Policy: config.SinkPolicy{PositionThreshold: 50}}
+	if err := manager.Attach(cfg, sink); err != nil {
+		t.Fatal(err)
+	}
+
+	// The ship holds its course, so only its first report goes out until the car turns up
+	start := time.Now()
+	car := models.NewCivilianCarUniversal("CARA", "Car A", models.Position{Latitude: 36.9, Longitude: -76.2})
+	snapshots := [][]models.Platform{
+		{steamingShip(0, start)},
+		{steamingShip(1, start)},
+		{steamingShip(2, start), car},
+	}
+	for i, platforms := range snapshots {
+		time.Sleep(time.Millisecond)
+		manager.Offer(platforms)
+		waitFor(t, "the update", func() bool { return len(sink.updates()) == i+1 })
+	}
+
+	updates := sink.updates()
+	if len(updates[1]) != 0 || len(updates[2]) != 1 || updates[2][0] != "CARA" {
+		t.Errorf("Expected only new platforms after the first update, got %v", updates)
+	}
+	if status := manager.Status()[0]; status.Reports != 2 || status.Saved != 2 {
+		t.Errorf("Expected 2 reports and 2 saved, got %+v", status)
+	}
+}
+
+func TestSinkManager_Rate(t *testing.T) {
+	manager, _ := NewSinkManager(config.OutputConfig{})
+	defer manager.Close()
+	hourly := &recordingSink{}
+	manager.Attach(config.SinkConfig{Name: "hourly", Format: config.SinkFormatCoT, Rate: "1h"}, hourly)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go manager.Run(ctx, func() []models.Platform { return []models.Platform{} })
+
+	// The first update is sent straight away and the next is an hour off
+	waitFor(t, "the first update", func() bool { return len(hourly.updates()) == 1 })
+	manager.Offer(nil)
+	time.Sleep(50 * time.Millisecond)
+	if n := len(hourly.updates()); n != 1 {
+		t.Errorf("Expected one update within the hour, got %d", n)
+	}
+}
+
+func TestNewSink(t *testing.T) {
+	receiver, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer receiver.Close()
+	endpoint := "udp://" + receiver.LocalAddr().String()
+	receive := func() []byte {
+		t.Helper()
+		receiver.SetReadDeadline(time.Now().Add(5 * time.Second))
+		buf := make([]byte, 1500)
+		n, err := receiver.Read(buf)
+		if err != nil {
+			t.Fatalf("Nothing received: %v", err)
+		}
+		return buf[:n]
+	}
+
+	dark := models.NewArleighBurkeDestroyerUniversal("DDG51", "Arleigh Burke", models.Position{Latitude: 36.8, Longitude: -76.3})
+	dark.Config.AISDark = true
+	platforms := []models.Platform{
+		dark,
+		models.NewBoeing737_800Universal("UAL123", "UA123", models.Position{Latitude: 40.6, Longitude: -73.8, Altitude: 10000}),
+		models.NewContainerShipUniversal("MAERSK1", "Maersk Alabama", models.Position{Latitude: 36.9, Longitude: -76.2}),
+		models.NewStarlinkSatelliteUniversal("STARLINK1", "Starlink 1", models.Position{Altitude: 550000}),
+	}
+
+	// Each format reports the platforms it applies to
+	for _, tt := range []struct {
+		format string
+		sent   int
+	}{
+		{config.SinkFormatCoT, 4},
+		{config.SinkFormatGDL90, 1},   // The aircraft
+		{config.SinkFormatAIS, 1},     // The ship, with the destroyer running dark
+		{config.SinkFormatDIS, 4},     // Every entity
+		{config.SinkFormatASTERIX, 3}, // All but the satellite
+	} {
+		t.Run(tt.format, func(t *testing.T) {
+			sink, err := NewSink(config.SinkConfig{Format: tt.format, Endpoint: endpoint, DIS: config.DISConfig{Heartbeat: "1h"}})
+			if err != nil {
+				t.Fatalf("NewSink failed: %v", err)
+			}
+			sent, err := sink.Publish(platforms)
+			if err != nil || sent != tt.sent {
+				t.Errorf("Publish sent %d reports (%v), want %d", sent, err, tt.sent)
+			}
+			if err := sink.Remove("UAL123"); err != nil {
+				t.Errorf("Remove failed: %v", err)
+			}
+			sink.Close()
+			receive()
+			receiver.SetReadDeadline(time.Now().Add(50 * time.Millisecond))
+			for buf := make([]byte, 1500); ; {
+				if _, err := receiver.Read(buf); err != nil {
+					break // Drained before the next format
+				}
+			}
+		})
+	}
+
+	for _, cfg := range []config.SinkConfig{
+		{Format: config.SinkFormatSBS, Endpoint: "udp://127.0.0.1:30003"},
+		{Format: config.SinkFormatCoT, Endpoint: "tcp://127.0.0.1:1", CoT: config.CoTConfig{Encoding: "mesh"}},
+		{Format: config.SinkFormatCoT, Endpoint: "tcp://127.0.0.1:1", CoT: config.CoTConfig{MaxBackoff: "later"}},
+		{Format: config.SinkFormatCoT, Endpoint: "ssl://127.0.0.1:8089", CoT: config.CoTConfig{TLS: config.CoTTLSConfig{CertFile: "missing.pem", KeyFile: "missing.key"}}},
+		{Format: "nmea", Endpoint: endpoint},
+	} {
+		if sink, err := NewSink(cfg); err == nil {
+			sink.Close()
+			t.Errorf("Expected NewSink to reject %+v", cfg)
+		}
+	}
+}
+
+func TestSinkFilter(t *testing.T) {
+	airliner := models.NewBoeing737_800Universal("UAL123", "UA123", models.Position{Latitude: 40.6, Longitude: -73.8})
+	destroyer := models.NewArleighBurkeDestroyerUniversal("DDG51", "Arleigh Burke", models.Position{Latitude: 36.8, Longitude: -76.3})
+
+	tests := []struct {
+		name                string
+		filter              config.SinkFilter
+		airliner, destroyer bool
+	}{
+		{"everything", config.SinkFilter{}, true, true},
+		{"maritime", config.SinkFilter{Types: []string{"Maritime"}}, false, true},
+		{"friendly", config.SinkFilter{Affiliations: []string{"friend"}}, false, true},
+		{"area", config.SinkFilter{Area: &config.BoundingBox{North: 41, South: 40, East: -73, West: -74}}, true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter := newSinkFilter(tt.filter)
+			if got := filter.matches(airliner); got != tt.airliner {
+				t.Errorf("airliner matches = %v, want %v", got, tt.airliner)
+			}
+			if got := filter.matches(destroyer); got != tt.destroyer {
+				t.Errorf("destroyer matches = %v, want %v", got, tt.destroyer)
+			}
+		})
+	}
+}
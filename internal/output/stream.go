@@ -0,0 +1,285 @@
+package output
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/rhino11/trafficsim/internal/models"
+)
+
+// Stream publisher defaults
+const (
+	DefaultStreamQueueSize = 1000
+	DefaultMinBackoff      = 500 * time.Millisecond
+	DefaultMaxBackoff      = 30 * time.Second
+	defaultDialTimeout     = 10 * time.Second
+	defaultWriteTimeout    = 10 * time.Second
+)
+
+// StreamOptions configures a TCP or TLS CoT stream
+type StreamOptions struct {
+	TLS        *tls.Config   // Enables TLS when set
+	QueueSize  int           // Messages held while disconnected; the oldest are dropped when full
+	MinBackoff time.Duration // First reconnect delay, doubled after each failed attempt
+	MaxBackoff time.Duration // Longest reconnect delay
+}
+
+// StreamStats reports the state of a stream publisher
+type StreamStats struct {
+	Connected  bool   `json:"connected"`
+	Sent       uint64 `json:"sent"`
+	Dropped    uint64 `json:"dropped"`    // Messages discarded because the queue was full
+	Reconnects uint64 `json:"reconnects"` // Connections made after the first
+	Queued     int    `json:"queued"`
+	LastError  string `json:"last_error,omitempty"`
+}
+
+// StreamPublisher streams CoT messages over a persistent TCP or TLS connection, such as a
+// TAK Server input. Messages are queued and written by a background goroutine, which
+// reconnects with exponential backoff whenever the connection fails.
+type StreamPublisher struct {
+	address   string
+	opts      StreamOptions
+	generator *CoTGenerator
+	queue     chan []byte
+	ctx       context.Context // Cancelled by Close, ending dials and backoff waits
+	cancel    context.CancelFunc
+	wg        sync.WaitGroup
+
+	mu        sync.Mutex
+	stats     StreamStats
+	connected bool // Whether a connection has ever been made, to count reconnects
+	closed    bool
+}
+
+// NewStreamPublisher creates a publisher for address and starts connecting in the background
+func NewStreamPublisher(address string, opts StreamOptions) *StreamPublisher {
+	if opts.QueueSize <= 0 {
+		opts.QueueSize = DefaultStreamQueueSize
+	}
+	if opts.MinBackoff <= 0 {
+		opts.MinBackoff = DefaultMinBackoff
+	}
+	if opts.MaxBackoff < opts.MinBackoff {
+		opts.MaxBackoff = DefaultMaxBackoff
+		if opts.MaxBackoff < opts.MinBackoff {
+			opts.MaxBackoff = opts.MinBackoff
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &StreamPublisher{
+		address:   address,
+		opts:      opts,
+		generator: NewCoTGenerator(),
+		queue:     make(chan []byte, opts.QueueSize),
+		ctx:       ctx,
+		cancel:    cancel,
+	}
+	p.wg.Add(1)
+	go p.run()
+	return p
+}
+
+// SetClock sets the clock used to timestamp published messages
+func (p *StreamPublisher) SetClock(clock models.Clock) {
+	p.generator.SetClock(clock)
+}
+
+// SetEncoding selects CoT XML or the TAK Protocol stream encoding. Set it before publishing,
+// since a stream should not mix encodings.
+func (p *StreamPublisher) SetEncoding(encoding Encoding) error {
+	if encoding == EncodingMesh {
+		return fmt.Errorf("the mesh encoding is for UDP endpoints, use stream for tcp:// and ssl://")
+	}
+	return p.generator.SetEncoding(encoding)
+}
+
+// PublishPlatformState queues a platform state as a CoT message without blocking
+func (p *StreamPublisher) PublishPlatformState(state PlatformState) error {
+	cotMessage, err := p.generator.GenerateMessage(state)
+	if err != nil {
+		return fmt.Errorf("failed to generate CoT message: %w", err)
+	}
+	return p.Send(cotMessage)
+}
+
+// Send queues a raw message. When the queue is full the oldest message is dropped, since
+// a fresher track update is worth more than a stale one.
+func (p *StreamPublisher) Send(message []byte) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return fmt.Errorf("stream to %s is closed", p.address)
+	}
+
+	for {
+		select {
+		case p.queue <- message:
+			return nil
+		default:
+		}
+		select {
+		case <-p.queue:
+			p.stats.Dropped++
+		default:
+		}
+	}
+}
+
+// Stats returns a snapshot of the connection state and counters
+func (p *StreamPublisher) Stats() StreamStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	stats := p.stats
+	stats.Queued = len(p.queue)
+	return stats
+}
+
+// GetAddress returns the host:port the publisher connects to
+func (p *StreamPublisher) GetAddress() string {
+	return p.address
+}
+
+// Close stops reconnecting, writes whatever is still queued if connected, and closes the connection
+func (p *StreamPublisher) Close() error {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return nil
+	}
+	p.closed = true
+	p.cancel()
+	p.mu.Unlock()
+
+	p.wg.Wait()
+	return nil
+}
+
+// run connects, writes queued messages until the connection fails, and reconnects with backoff
+func (p *StreamPublisher) run() {
+	defer p.wg.Done()
+
+	backoff := p.opts.MinBackoff
+	var pending []byte // Message whose write failed, retried on the next connection
+	for {
+		conn, err := p.dial()
+		if err != nil {
+			p.recordError(err)
+			select {
+			case <-p.ctx.Done():
+				return
+			case <-time.After(backoff):
+			}
+			if backoff *= 2; backoff > p.opts.MaxBackoff {
+				backoff = p.opts.MaxBackoff
+			}
+			continue
+		}
+
+		backoff = p.opts.MinBackoff
+		p.setConnected(true)
+		pending, err = p.writeLoop(conn, pending)
+		conn.Close()
+		p.setConnected(false)
+		if err == nil {
+			return
+		}
+		p.recordError(err)
+		log.Printf("Stream to %s lost: %v", p.address, err)
+	}
+}
+
+// dial opens a TCP connection, wrapped in TLS when configured
+func (p *StreamPublisher) dial() (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: defaultDialTimeout}
+	if p.opts.TLS != nil {
+		tlsDialer := &tls.Dialer{NetDialer: dialer, Config: p.opts.TLS}
+		return tlsDialer.DialContext(p.ctx, "tcp", p.address)
+	}
+	return dialer.DialContext(p.ctx, "tcp", p.address)
+}
+
+// writeLoop writes pending and then queued messages until the publisher closes (returning nil)
+// or the connection fails (returning the unsent message and the error)
+func (p *StreamPublisher) writeLoop(conn net.Conn, pending []byte) ([]byte, error) {
+	// The server may send pings or nothing at all; reading until EOF notices a closed connection
+	// between writes instead of on the next write
+	lost := make(chan error, 1)
+	go func() {
+		_, err := io.Copy(io.Discard, conn)
+		if err == nil {
+			err = io.EOF
+		}
+		lost <- err
+	}()
+
+	write := func(message []byte) error {
+		if err := conn.SetWriteDeadline(time.Now().Add(defaultWriteTimeout)); err != nil {
+			return err
+		}
+		if _, err := conn.Write(message); err != nil {
+			return err
+		}
+		p.mu.Lock()
+		p.stats.Sent++
+		p.mu.Unlock()
+		return nil
+	}
+
+	if pending != nil {
+		if err := write(pending); err != nil {
+			return pending, err
+		}
+	}
+
+	for {
+		select {
+		case message := <-p.queue:
+			if err := write(message); err != nil {
+				return message, err
+			}
+		case err := <-lost:
+			return nil, err
+		case <-p.ctx.Done():
+			// Flush what is already queued before closing
+			for {
+				select {
+				case message := <-p.queue:
+					if err := write(message); err != nil {
+						return nil, nil
+					}
+				default:
+					return nil, nil
+				}
+			}
+		}
+	}
+}
+
+// setConnected records a connection change, counting connections after the first as reconnects
+func (p *StreamPublisher) setConnected(connected bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if connected {
+		if p.connected {
+			p.stats.Reconnects++
+		}
+		p.connected = true
+		p.stats.LastError = ""
+	}
+	p.stats.Connected = connected
+}
+
+// recordError keeps the most recent connection error for Stats
+func (p *StreamPublisher) recordError(err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.stats.LastError = err.Error()
+}
@@ -0,0 +1,409 @@
+package output
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"encoding/xml"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// testPKI is a throwaway CA with a server and a client certificate, written as PEM files
+type testPKI struct {
+	caPool     *x509.CertPool
+	server     tls.Certificate
+	caFile     string
+	clientCert string
+	clientKey  string
+}
+
+func newTestPKI(t *testing.T) *testPKI {
+	t.Helper()
+	dir := t.TempDir()
+
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "trafficsim test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caCert, err := x509.ParseCertificate(caDER)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	issue := func(serial int64, name string, usage x509.ExtKeyUsage) ([]byte, *ecdsa.PrivateKey) {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		template := &x509.Certificate{
+			SerialNumber: big.NewInt(serial),
+			Subject:      pkix.Name{CommonName: name},
+			NotBefore:    time.Now().Add(-time.Hour),
+			NotAfter:     time.Now().Add(time.Hour),
+			KeyUsage:     x509.KeyUsageDigitalSignature,
+			ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+			IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		}
+		der, err := x509.CreateCertificate(rand.Reader, template, caCert, &key.PublicKey, caKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return der, key
+	}
+	writePEM := func(name, blockType string, der []byte) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	keyDER := func(key *ecdsa.PrivateKey) []byte {
+		der, err := x509.MarshalECPrivateKey(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return der
+	}
+
+	serverDER, serverKey := issue(2, "localhost", x509.ExtKeyUsageServerAuth)
+	clientDER, clientKey := issue(3, "SIM-CLIENT", x509.ExtKeyUsageClientAuth)
+
+	pki := &testPKI{
+		caPool:     x509.NewCertPool(),
+		server:     tls.Certificate{Certificate: [][]byte{serverDER}, PrivateKey: serverKey},
+		caFile:     writePEM("ca.pem", "CERTIFICATE", caDER),
+		clientCert: writePEM("client.pem", "CERTIFICATE", clientDER),
+		clientKey:  writePEM("client.key", "EC PRIVATE KEY", keyDER(clientKey)),
+	}
+	pki.caPool.AddCert(caCert)
+	return pki
+}
+
+// acceptEvents accepts one connection and sends its decoded CoT events and the peer's certificate name
+func acceptEvents(t *testing.T, listener net.Listener, count int) (<-chan CoTEvent, <-chan string) {
+	t.Helper()
+	events := make(chan CoTEvent, count)
+	peers := make(chan string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			close(events)
+			return
+		}
+		defer conn.Close()
+		if tlsConn, ok := conn.(*tls.Conn); ok {
+			if err := tlsConn.Handshake(); err == nil && len(tlsConn.ConnectionState().PeerCertificates) > 0 {
+				peers <- tlsConn.ConnectionState().PeerCertificates[0].Subject.CommonName
+			}
+		}
+		decoder := xml.NewDecoder(conn)
+		for i := 0; i < count; i++ {
+			var event CoTEvent
+			if err := decoder.Decode(&event); err != nil {
+				break
+			}
+			events <- event
+		}
+		close(events)
+	}()
+	return events, peers
+}
+
+func receiveEvent(t *testing.T, events <-chan CoTEvent) CoTEvent {
+	t.Helper()
+	select {
+	case event, ok := <-events:
+		if !ok {
+			t.Fatal("Connection closed before an event arrived")
+		}
+		return event
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for a CoT event")
+	}
+	return CoTEvent{}
+}
+
+// waitFor polls cond until it holds or the test times out
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestParseEndpoint(t *testing.T) {
+	tests := []struct {
+		endpoint string
+		want     Endpoint
+		wantErr  bool
+	}{
+		{endpoint: "udp://239.2.3.1:6969", want: Endpoint{Scheme: SchemeUDP, Host: "239.2.3.1", Port: 6969}},
+		{endpoint: "tcp://tak.example.com:8087", want: Endpoint{Scheme: SchemeTCP, Host: "tak.example.com", Port: 8087}},
+		{endpoint: "SSL://[::1]:8089", want: Endpoint{Scheme: SchemeSSL, Host: "::1", Port: 8089}},
+		{endpoint: "http://tak.example.com:80", wantErr: true},
+		{endpoint: "tcp://tak.example.com", wantErr: true},
+		{endpoint: "tcp://:8087", wantErr: true},
+		{endpoint: "ssl://tak.example.com:70000", wantErr: true},
+		{endpoint: "239.2.3.1:6969", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseEndpoint(tt.endpoint)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseEndpoint(%q) expected an error, got %+v", tt.endpoint, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseEndpoint(%q) unexpected error: %v", tt.endpoint, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseEndpoint(%q) = %+v, want %+v", tt.endpoint, got, tt.want)
+		}
+	}
+
+	if got := (Endpoint{Scheme: SchemeSSL, Host: "::1", Port: 8089}).String(); got != "ssl://[::1]:8089" {
+		t.Errorf("Endpoint.String() = %q", got)
+	}
+}
+
+func TestLoadTLSConfig(t *testing.T) {
+	pki := newTestPKI(t)
+
+	tlsConfig, err := LoadTLSConfig(TLSOptions{CertFile: pki.clientCert, KeyFile: pki.clientKey, CAFile: pki.caFile, ServerName: "tak"})
+	if err != nil {
+		t.Fatalf("LoadTLSConfig failed: %v", err)
+	}
+	if len(tlsConfig.Certificates) != 1 || tlsConfig.RootCAs == nil || tlsConfig.ServerName != "tak" {
+		t.Errorf("Unexpected TLS config: %d certificates, roots %v, server name %q",
+			len(tlsConfig.Certificates), tlsConfig.RootCAs != nil, tlsConfig.ServerName)
+	}
+
+	invalid := []TLSOptions{
+		{CertFile: pki.clientCert},                          // Key missing
+		{CertFile: pki.clientCert, KeyFile: pki.caFile},     // Not a key
+		{CAFile: filepath.Join(t.TempDir(), "missing.pem")}, // Missing file
+		{CAFile: pki.clientKey},                             // No certificates
+	}
+	for _, opts := range invalid {
+		if _, err := LoadTLSConfig(opts); err == nil {
+			t.Errorf("Expected LoadTLSConfig(%+v) to fail", opts)
+		}
+	}
+}
+
+func TestStreamPublisher_TLSClientCertificate(t *testing.T) {
+	pki := newTestPKI(t)
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{
+		Certificates: []tls.Certificate{pki.server},
+		ClientCAs:    pki.caPool,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		MinVersion:   tls.VersionTLS12,
+	})
+	if err != nil {
+		t.Fatalf("Failed to start TLS listener: %v", err)
+	}
+	defer listener.Close()
+	events, peers := acceptEvents(t, listener, 2)
+
+	tlsConfig, err := LoadTLSConfig(TLSOptions{CertFile: pki.clientCert, KeyFile: pki.clientKey, CAFile: pki.caFile})
+	if err != nil {
+		t.Fatalf("LoadTLSConfig failed: %v", err)
+	}
+	publisher, err := NewCoTPublisher("ssl://"+listener.Addr().String(), StreamOptions{TLS: tlsConfig})
+	if err != nil {
+		t.Fatalf("NewCoTPublisher failed: %v", err)
+	}
+	defer publisher.Close()
+
+	for _, id := range []string{"F16_001", "DDG51"} {
+		if err := publisher.PublishPlatformState(PlatformState{ID: id, Callsign: id, CoTType: "a-f-A-M-F"}); err != nil {
+			t.Fatalf("PublishPlatformState failed: %v", err)
+		}
+	}
+
+	for _, uid := range []string{"TRAFFICSIM-F16_001", "TRAFFICSIM-DDG51"} {
+		if event := receiveEvent(t, events); event.UID != uid {
+			t.Errorf("Expected event %s, got %s", uid, event.UID)
+		}
+	}
+	if peer := <-peers; peer != "SIM-CLIENT" {
+		t.Errorf("Expected server to see client certificate SIM-CLIENT, got %q", peer)
+	}
+
+	stream := publisher.(*StreamPublisher)
+	waitFor(t, "sent count", func() bool { return stream.Stats().Sent == 2 })
+	if stats := stream.Stats(); !stats.Connected || stats.Dropped != 0 || stats.Reconnects != 0 {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+}
+
+func TestStreamPublisher_RejectedWithoutClientCertificate(t *testing.T) {
+	pki := newTestPKI(t)
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{
+		Certificates: []tls.Certificate{pki.server},
+		ClientCAs:    pki.caPool,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		MinVersion:   tls.VersionTLS12,
+	})
+	if err != nil {
+		t.Fatalf("Failed to start TLS listener: %v", err)
+	}
+	defer listener.Close()
+	events, _ := acceptEvents(t, listener, 1)
+
+	publisher := NewStreamPublisher(listener.Addr().String(), StreamOptions{
+		TLS:        &tls.Config{RootCAs: pki.caPool, MinVersion: tls.VersionTLS12},
+		MinBackoff: time.Hour, // One attempt only
+	})
+	defer publisher.Close()
+	if err := publisher.PublishPlatformState(PlatformState{ID: "F16_001"}); err != nil {
+		t.Fatalf("PublishPlatformState failed: %v", err)
+	}
+
+	select {
+	case event, ok := <-events:
+		if ok {
+			t.Fatalf("Server accepted an event from a client without a certificate: %s", event.UID)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for the handshake to fail")
+	}
+}
+
+func TestStreamPublisher_ReconnectsAfterServerCloses(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to start listener: %v", err)
+	}
+	defer listener.Close()
+
+	publisher, err := NewCoTPublisher("tcp://"+listener.Addr().String(), StreamOptions{MinBackoff: 10 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("NewCoTPublisher failed: %v", err)
+	}
+	defer publisher.Close()
+	stream := publisher.(*StreamPublisher)
+
+	// The first connection reads one event and is dropped by the server
+	events, _ := acceptEvents(t, listener, 1)
+	if err := publisher.PublishPlatformState(PlatformState{ID: "FIRST"}); err != nil {
+		t.Fatal(err)
+	}
+	if event := receiveEvent(t, events); event.UID != "TRAFFICSIM-FIRST" {
+		t.Fatalf("Unexpected first event %s", event.UID)
+	}
+
+	events, _ = acceptEvents(t, listener, 1)
+	waitFor(t, "reconnect", func() bool {
+		stats := stream.Stats()
+		return stats.Connected && stats.Reconnects == 1
+	})
+	if err := publisher.PublishPlatformState(PlatformState{ID: "SECOND"}); err != nil {
+		t.Fatal(err)
+	}
+	if event := receiveEvent(t, events); event.UID != "TRAFFICSIM-SECOND" {
+		t.Errorf("Unexpected event after reconnect %s", event.UID)
+	}
+}
+
+func TestStreamPublisher_BoundedQueueKeepsNewest(t *testing.T) {
+	// Reserve a port with nothing listening on it
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to reserve a port: %v", err)
+	}
+	address := listener.Addr().String()
+	listener.Close()
+
+	publisher := NewStreamPublisher(address, StreamOptions{QueueSize: 2, MinBackoff: 10 * time.Millisecond, MaxBackoff: 20 * time.Millisecond})
+	defer publisher.Close()
+
+	for _, id := range []string{"1", "2", "3", "4", "5"} {
+		if err := publisher.PublishPlatformState(PlatformState{ID: id}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	waitFor(t, "connection error", func() bool { return publisher.Stats().LastError != "" })
+	if stats := publisher.Stats(); stats.Dropped != 3 || stats.Queued != 2 || stats.Connected {
+		t.Fatalf("Expected 3 dropped and 2 queued while disconnected, got %+v", stats)
+	}
+
+	// Once the server appears, the two newest messages are delivered
+	listener, err = net.Listen("tcp", address)
+	if err != nil {
+		t.Skipf("Reserved port was taken: %v", err)
+	}
+	defer listener.Close()
+	events, _ := acceptEvents(t, listener, 2)
+	for _, uid := range []string{"TRAFFICSIM-4", "TRAFFICSIM-5"} {
+		if event := receiveEvent(t, events); event.UID != uid {
+			t.Errorf("Expected %s, got %s", uid, event.UID)
+		}
+	}
+}
+
+func TestStreamPublisher_Close(t *testing.T) {
+	publisher := NewStreamPublisher("127.0.0.1:1", StreamOptions{MinBackoff: time.Hour})
+
+	done := make(chan struct{})
+	go func() {
+		publisher.Close()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Close blocked while waiting to reconnect")
+	}
+
+	if err := publisher.PublishPlatformState(PlatformState{ID: "LATE"}); err == nil {
+		t.Error("Expected publishing after Close to fail")
+	}
+	if err := publisher.Close(); err != nil {
+		t.Errorf("Second Close failed: %v", err)
+	}
+}
+
+func TestNewCoTPublisher_UDP(t *testing.T) {
+	publisher, err := NewCoTPublisher("udp://127.0.0.1:6969", StreamOptions{})
+	if err != nil {
+		t.Fatalf("NewCoTPublisher failed: %v", err)
+	}
+	defer publisher.Close()
+	if _, ok := publisher.(*MulticastPublisher); !ok {
+		t.Errorf("Expected a UDP publisher, got %T", publisher)
+	}
+
+	if _, err := NewCoTPublisher("http://127.0.0.1:80", StreamOptions{}); err == nil {
+		t.Error("Expected an error for an unsupported scheme")
+	}
+}
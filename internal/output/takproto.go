@@ -0,0 +1,376 @@
+package output
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"time"
+)
+
+// Encoding selects how CoT events are put on the wire
+type Encoding string
+
+// CoT encodings. The TAK Protocol Version 1 encodings carry the same event as a protobuf
+// TakMessage, framed for UDP (mesh) or TCP (stream).
+const (
+	EncodingXML    Encoding = "xml"    // CoT XML event
+	EncodingMesh   Encoding = "mesh"   // 0xbf 0x01 0xbf header followed by the protobuf message
+	EncodingStream Encoding = "stream" // 0xbf, the varint message length, then the protobuf message
+)
+
+// takMagic starts every TAK Protocol message
+const takMagic = 0xbf
+
+// takProtocolVersion is the protobuf TAK Protocol version in the mesh header
+const takProtocolVersion = 0x01
+
+// maxTakMessageSize bounds the length read from a stream header
+const maxTakMessageSize = 1 << 20
+
+// cotTimeLayout is the CoT timestamp format used by the XML encoding
+const cotTimeLayout = "2006-01-02T15:04:05.000Z"
+
+// ParseEncoding returns the encoding for a name; empty selects XML
+func ParseEncoding(name string) (Encoding, error) {
+	switch encoding := Encoding(strings.ToLower(name)); encoding {
+	case "":
+		return EncodingXML, nil
+	case EncodingXML, EncodingMesh, EncodingStream:
+		return encoding, nil
+	}
+	return "", fmt.Errorf("unknown CoT encoding %q, expected xml, mesh or stream", name)
+}
+
+// Field numbers of the TAK Protocol Version 1 protobuf schema (takmessage.proto and friends)
+const (
+	takMessageCotEvent = 2
+
+	cotEventType      = 1
+	cotEventUID       = 5
+	cotEventSendTime  = 6
+	cotEventStartTime = 7
+	cotEventStaleTime = 8
+	cotEventHow       = 9
+	cotEventLat       = 10
+	cotEventLon       = 11
+	cotEventHae       = 12
+	cotEventCE        = 13
+	cotEventLE        = 14
+	cotEventDetail    = 15
+
+	detailContact           = 2
+	detailPrecisionLocation = 4
+	detailTrack             = 7
+
+	contactEndpoint = 1
+	contactCallsign = 2
+
+	precisionGeopointsrc = 1
+	precisionAltsrc      = 2
+
+	trackSpeed  = 1
+	trackCourse = 2
+)
+
+// Protobuf wire types
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+	wireFixed32 = 5
+)
+
+// generateTakMessage creates a TAK Protocol message from platform state, timestamped at now and
+// framed for the mesh or stream encoding
+func (g *CoTGenerator) generateTakMessage(state PlatformState, now time.Time, encoding Encoding) ([]byte, error) {
+	payload, err := MarshalTakMessage(g.buildEvent(state, now))
+	if err != nil {
+		return nil, err
+	}
+
+	switch encoding {
+	case EncodingMesh:
+		return FrameTakMesh(payload), nil
+	case EncodingStream:
+		return FrameTakStream(payload), nil
+	}
+	return nil, fmt.Errorf("%q is not a TAK Protocol encoding", encoding)
+}
+
+// FrameTakMesh prefixes a protobuf TakMessage with the mesh (UDP) header
+func FrameTakMesh(payload []byte) []byte {
+	return append([]byte{takMagic, takProtocolVersion, takMagic}, payload...)
+}
+
+// FrameTakStream prefixes a protobuf TakMessage with the stream (TCP) header
+func FrameTakStream(payload []byte) []byte {
+	frame := binary.AppendUvarint([]byte{takMagic}, uint64(len(payload)))
+	return append(frame, payload...)
+}
+
+// ParseTakMesh returns the protobuf TakMessage of a mesh datagram
+func ParseTakMesh(datagram []byte) ([]byte, error) {
+	if len(datagram) < 3 || datagram[0] != takMagic || datagram[2] != takMagic {
+		return nil, fmt.Errorf("missing TAK Protocol mesh header")
+	}
+	if datagram[1] != takProtocolVersion {
+		return nil, fmt.Errorf("unsupported TAK Protocol version %d", datagram[1])
+	}
+	return datagram[3:], nil
+}
+
+// ReadTakStream reads the next protobuf TakMessage from a stream
+func ReadTakStream(r *bufio.Reader) ([]byte, error) {
+	magic, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	if magic != takMagic {
+		return nil, fmt.Errorf("missing TAK Protocol stream header, got 0x%02x", magic)
+	}
+	length, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read TAK message length: %w", err)
+	}
+	if length > maxTakMessageSize {
+		return nil, fmt.Errorf("TAK message of %d bytes exceeds the %d byte limit", length, maxTakMessageSize)
+	}
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return nil, fmt.Errorf("failed to read TAK message: %w", err)
+	}
+	return payload, nil
+}
+
+// MarshalTakMessage encodes a CoT event as a protobuf TakMessage
+func MarshalTakMessage(event CoTEvent) ([]byte, error) {
+	var times [3]uint64
+	for i, value := range []string{event.Time, event.Start, event.Stale} {
+		t, err := time.Parse(cotTimeLayout, value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CoT time %q: %w", value, err)
+		}
+		times[i] = uint64(t.UnixMilli())
+	}
+
+	var contact, precision, track, detail, cot protoMessage
+	contact.appendString(contactEndpoint, event.Detail.Contact.Endpoint)
+	contact.appendString(contactCallsign, event.Detail.Contact.Callsign)
+	precision.appendString(precisionGeopointsrc, event.Detail.Precis.Geopointsrc)
+	precision.appendString(precisionAltsrc, event.Detail.Precis.Altsrc)
+	track.appendDouble(trackSpeed, event.Detail.Track.Speed)
+	track.appendDouble(trackCourse, event.Detail.Track.Course)
+
+	detail.appendMessage(detailContact, contact)
+	detail.appendMessage(detailPrecisionLocation, precision)
+	detail.appendMessage(detailTrack, track)
+
+	cot.appendString(cotEventType, event.Type)
+	cot.appendString(cotEventUID, event.UID)
+	cot.appendVarint(cotEventSendTime, times[0])
+	cot.appendVarint(cotEventStartTime, times[1])
+	cot.appendVarint(cotEventStaleTime, times[2])
+	cot.appendString(cotEventHow, event.How)
+	cot.appendDouble(cotEventLat, event.Point.Lat)
+	cot.appendDouble(cotEventLon, event.Point.Lon)
+	cot.appendDouble(cotEventHae, event.Point.Hae)
+	cot.appendDouble(cotEventCE, event.Point.CE)
+	cot.appendDouble(cotEventLE, event.Point.LE)
+	cot.appendMessage(cotEventDetail, detail)
+
+	var message protoMessage
+	message.appendMessage(takMessageCotEvent, cot)
+	return message, nil
+}
+
+// UnmarshalTakMessage decodes the CoT event of a protobuf TakMessage. Fields outside the
+// subset the simulator produces, such as takControl and xmlDetail, are skipped.
+func UnmarshalTakMessage(payload []byte) (CoTEvent, error) {
+	event := CoTEvent{Version: "2.0"}
+	err := walkProto(payload, func(field int, value protoValue) error {
+		if field != takMessageCotEvent {
+			return nil
+		}
+		return walkProto(value.bytes, func(field int, value protoValue) error {
+			switch field {
+			case cotEventType:
+				event.Type = string(value.bytes)
+			case cotEventUID:
+				event.UID = string(value.bytes)
+			case cotEventSendTime:
+				event.Time = formatCoTMillis(value.varint)
+			case cotEventStartTime:
+				event.Start = formatCoTMillis(value.varint)
+			case cotEventStaleTime:
+				event.Stale = formatCoTMillis(value.varint)
+			case cotEventHow:
+				event.How = string(value.bytes)
+			case cotEventLat:
+				event.Point.Lat = value.double()
+			case cotEventLon:
+				event.Point.Lon = value.double()
+			case cotEventHae:
+				event.Point.Hae = value.double()
+			case cotEventCE:
+				event.Point.CE = value.double()
+			case cotEventLE:
+				event.Point.LE = value.double()
+			case cotEventDetail:
+				return unmarshalTakDetail(value.bytes, &event.Detail)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return CoTEvent{}, err
+	}
+	if event.UID == "" {
+		return CoTEvent{}, fmt.Errorf("TAK message has no CoT event")
+	}
+	return event, nil
+}
+
+// unmarshalTakDetail decodes the typed contact, precision location and track of a Detail
+func unmarshalTakDetail(payload []byte, detail *CoTDetail) error {
+	return walkProto(payload, func(field int, value protoValue) error {
+		switch field {
+		case detailContact:
+			return walkProto(value.bytes, func(field int, value protoValue) error {
+				switch field {
+				case contactEndpoint:
+					detail.Contact.Endpoint = string(value.bytes)
+				case contactCallsign:
+					detail.Contact.Callsign = string(value.bytes)
+				}
+				return nil
+			})
+		case detailPrecisionLocation:
+			return walkProto(value.bytes, func(field int, value protoValue) error {
+				switch field {
+				case precisionGeopointsrc:
+					detail.Precis.Geopointsrc = string(value.bytes)
+				case precisionAltsrc:
+					detail.Precis.Altsrc = string(value.bytes)
+				}
+				return nil
+			})
+		case detailTrack:
+			return walkProto(value.bytes, func(field int, value protoValue) error {
+				switch field {
+				case trackSpeed:
+					detail.Track.Speed = value.double()
+				case trackCourse:
+					detail.Track.Course = value.double()
+				}
+				return nil
+			})
+		}
+		return nil
+	})
+}
+
+// formatCoTMillis formats milliseconds since the epoch as a CoT timestamp
+func formatCoTMillis(millis uint64) string {
+	return time.UnixMilli(int64(millis)).UTC().Format(cotTimeLayout)
+}
+
+// protoMessage builds a protobuf message. Zero values are omitted, as proto3 does.
+type protoMessage []byte
+
+func (m *protoMessage) appendTag(field, wireType int) {
+	*m = binary.AppendUvarint(*m, uint64(field<<3|wireType))
+}
+
+func (m *protoMessage) appendVarint(field int, value uint64) {
+	if value == 0 {
+		return
+	}
+	m.appendTag(field, wireVarint)
+	*m = binary.AppendUvarint(*m, value)
+}
+
+func (m *protoMessage) appendDouble(field int, value float64) {
+	if value == 0 {
+		return
+	}
+	m.appendTag(field, wireFixed64)
+	*m = binary.LittleEndian.AppendUint64(*m, math.Float64bits(value))
+}
+
+func (m *protoMessage) appendBytes(field int, value []byte) {
+	if len(value) == 0 {
+		return
+	}
+	m.appendTag(field, wireBytes)
+	*m = binary.AppendUvarint(*m, uint64(len(value)))
+	*m = append(*m, value...)
+}
+
+func (m *protoMessage) appendString(field int, value string) {
+	m.appendBytes(field, []byte(value))
+}
+
+func (m *protoMessage) appendMessage(field int, value protoMessage) {
+	m.appendBytes(field, value)
+}
+
+// protoValue is one decoded field value; which member is set depends on the wire type
+type protoValue struct {
+	varint uint64 // Varint, fixed64 and fixed32 values
+	bytes  []byte // Length-delimited values
+}
+
+func (v protoValue) double() float64 {
+	return math.Float64frombits(v.varint)
+}
+
+// walkProto calls fn for every field of a protobuf message
+func walkProto(data []byte, fn func(field int, value protoValue) error) error {
+	for len(data) > 0 {
+		key, n := binary.Uvarint(data)
+		if n <= 0 {
+			return fmt.Errorf("malformed protobuf field key")
+		}
+		data = data[n:]
+		field, wireType := int(key>>3), int(key&7)
+
+		var value protoValue
+		switch wireType {
+		case wireVarint:
+			value.varint, n = binary.Uvarint(data)
+			if n <= 0 {
+				return fmt.Errorf("malformed varint in field %d", field)
+			}
+			data = data[n:]
+		case wireFixed64:
+			if len(data) < 8 {
+				return fmt.Errorf("truncated fixed64 in field %d", field)
+			}
+			value.varint = binary.LittleEndian.Uint64(data)
+			data = data[8:]
+		case wireBytes:
+			length, n := binary.Uvarint(data)
+			if n <= 0 || uint64(len(data)-n) < length {
+				return fmt.Errorf("truncated bytes in field %d", field)
+			}
+			value.bytes = data[n : n+int(length)]
+			data = data[n+int(length):]
+		case wireFixed32:
+			if len(data) < 4 {
+				return fmt.Errorf("truncated fixed32 in field %d", field)
+			}
+			value.varint = uint64(binary.LittleEndian.Uint32(data))
+			data = data[4:]
+		default:
+			return fmt.Errorf("unsupported wire type %d in field %d", wireType, field)
+		}
+
+		if err := fn(field, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
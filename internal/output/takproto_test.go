@@ -0,0 +1,270 @@
+package output
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/xml"
+	"net"
+	"testing"
+	"time"
+)
+
+var takTestStates = []PlatformState{
+	{
+		ID:        "F16_001",
+		Callsign:  "VIPER01",
+		Latitude:  39.0458,
+		Longitude: -76.6413,
+		Altitude:  10000.0,
+		Speed:     250.0,
+		Course:    90.0,
+		CoTType:   "a-f-A-M-F",
+	},
+	{
+		ID:        "BUOY",
+		Callsign:  "",
+		Latitude:  0, // Zero values are omitted on the wire and must still round-trip
+		Longitude: 0,
+		CoTType:   "a-n-S-X",
+	},
+}
+
+// decodeXMLEvent generates the XML form of a state and parses it back into an event
+func decodeXMLEvent(t *testing.T, generator *CoTGenerator, state PlatformState, now time.Time) CoTEvent {
+	t.Helper()
+	message, err := generator.GenerateCoTMessageAt(state, now)
+	if err != nil {
+		t.Fatalf("GenerateCoTMessageAt failed: %v", err)
+	}
+	var event CoTEvent
+	if err := xml.Unmarshal(message, &event); err != nil {
+		t.Fatalf("Failed to parse CoT XML: %v", err)
+	}
+	event.XMLName = xml.Name{}
+	return event
+}
+
+func TestTakMessage_RoundTripMatchesXML(t *testing.T) {
+	generator := NewCoTGenerator()
+	now := time.Date(2024, 6, 1, 12, 0, 0, 123e6, time.UTC)
+
+	for _, encoding := range []Encoding{EncodingMesh, EncodingStream} {
+		if err := generator.SetEncoding(encoding); err != nil {
+			t.Fatal(err)
+		}
+		for _, state := range takTestStates {
+			message, err := generator.GenerateMessageAt(state, now)
+			if err != nil {
+				t.Fatalf("%s: GenerateMessageAt failed: %v", encoding, err)
+			}
+
+			var payload []byte
+			if encoding == EncodingMesh {
+				payload, err = ParseTakMesh(message)
+			} else {
+				payload, err = ReadTakStream(bufio.NewReader(bytes.NewReader(message)))
+			}
+			if err != nil {
+				t.Fatalf("%s: failed to unframe message: %v", encoding, err)
+			}
+
+			got, err := UnmarshalTakMessage(payload)
+			if err != nil {
+				t.Fatalf("%s: UnmarshalTakMessage failed: %v", encoding, err)
+			}
+			if want := decodeXMLEvent(t, generator, state, now); got != want {
+				t.Errorf("%s: protobuf event differs from XML\n got: %+v\nwant: %+v", encoding, got, want)
+			}
+
+			xmlMessage, _ := generator.GenerateCoTMessageAt(state, now)
+			if len(message) >= len(xmlMessage)/2 {
+				t.Errorf("%s: expected protobuf to be well under half the XML size, got %d vs %d bytes",
+					encoding, len(message), len(xmlMessage))
+			}
+		}
+	}
+}
+
+func TestMarshalTakMessage_WireFormat(t *testing.T) {
+	event := CoTEvent{
+		UID:   "X",
+		Type:  "a-f-G",
+		Time:  "1970-01-01T00:00:00.001Z",
+		Start: "1970-01-01T00:00:00.001Z",
+		Stale: "1970-01-01T00:00:00.300Z",
+		Point: CoTPoint{Lat: 1},
+	}
+	payload, err := MarshalTakMessage(event)
+	if err != nil {
+		t.Fatalf("MarshalTakMessage failed: %v", err)
+	}
+
+	want := []byte{
+		0x12, 0x1a, // TakMessage.cotEvent, 26 bytes
+		0x0a, 0x05, 'a', '-', 'f', '-', 'G', // type
+		0x2a, 0x01, 'X', // uid
+		0x30, 0x01, // sendTime 1 ms
+		0x38, 0x01, // startTime 1 ms
+		0x40, 0xac, 0x02, // staleTime 300 ms
+		0x51, 0, 0, 0, 0, 0, 0, 0xf0, 0x3f, // lat 1.0
+	}
+	if !bytes.Equal(payload, want) {
+		t.Errorf("Unexpected wire format\n got: % x\nwant: % x", payload, want)
+	}
+
+	if mesh := FrameTakMesh(payload); !bytes.Equal(mesh[:3], []byte{0xbf, 0x01, 0xbf}) || len(mesh) != len(payload)+3 {
+		t.Errorf("Unexpected mesh header % x", mesh[:3])
+	}
+	if stream := FrameTakStream(payload); !bytes.Equal(stream[:2], []byte{0xbf, 0x1c}) || len(stream) != len(payload)+2 {
+		t.Errorf("Unexpected stream header % x", stream[:2])
+	}
+	long := FrameTakStream(make([]byte, 300))
+	if !bytes.Equal(long[:3], []byte{0xbf, 0xac, 0x02}) {
+		t.Errorf("Expected a two byte varint length for 300 bytes, got % x", long[:3])
+	}
+}
+
+func TestReadTakStream_Sequence(t *testing.T) {
+	generator := NewCoTGenerator()
+	if err := generator.SetEncoding(EncodingStream); err != nil {
+		t.Fatal(err)
+	}
+
+	var stream bytes.Buffer
+	for _, state := range takTestStates {
+		message, err := generator.GenerateMessage(state)
+		if err != nil {
+			t.Fatal(err)
+		}
+		stream.Write(message)
+	}
+
+	reader := bufio.NewReader(&stream)
+	for _, state := range takTestStates {
+		payload, err := ReadTakStream(reader)
+		if err != nil {
+			t.Fatalf("ReadTakStream failed: %v", err)
+		}
+		event, err := UnmarshalTakMessage(payload)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if event.UID != "TRAFFICSIM-"+state.ID {
+			t.Errorf("Expected %s, got %s", state.ID, event.UID)
+		}
+	}
+	if _, err := ReadTakStream(reader); err == nil {
+		t.Error("Expected an error at the end of the stream")
+	}
+}
+
+func TestTakMessage_Malformed(t *testing.T) {
+	if _, err := ParseTakMesh([]byte("<?xml")); err == nil {
+		t.Error("Expected XML to be rejected as a mesh message")
+	}
+	if _, err := ParseTakMesh([]byte{0xbf, 0x02, 0xbf}); err == nil {
+		t.Error("Expected an unsupported protocol version to be rejected")
+	}
+	if _, err := ReadTakStream(bufio.NewReader(bytes.NewReader([]byte{0xbf, 0x05, 0x12}))); err == nil {
+		t.Error("Expected a truncated stream message to fail")
+	}
+	if _, err := ReadTakStream(bufio.NewReader(bytes.NewReader([]byte{0xbf, 0xff, 0xff, 0xff, 0x7f}))); err == nil {
+		t.Error("Expected an oversized stream message to be rejected")
+	}
+
+	malformed := [][]byte{
+		{0x12, 0x10, 0x0a},    // Truncated cotEvent
+		{0x12, 0x02, 0x51, 0}, // Truncated double
+		{0x13},                // Unsupported wire type
+		{},                    // No event
+	}
+	for _, payload := range malformed {
+		if _, err := UnmarshalTakMessage(payload); err == nil {
+			t.Errorf("Expected UnmarshalTakMessage(% x) to fail", payload)
+		}
+	}
+
+	// Unknown fields such as takControl are skipped
+	payload, err := MarshalTakMessage(CoTEvent{UID: "X", Time: "1970-01-01T00:00:00.000Z", Start: "1970-01-01T00:00:00.000Z", Stale: "1970-01-01T00:00:00.000Z"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	withControl := append([]byte{0x0a, 0x02, 0x08, 0x01}, payload...)
+	if event, err := UnmarshalTakMessage(withControl); err != nil || event.UID != "X" {
+		t.Errorf("Expected takControl to be skipped, got %+v, %v", event, err)
+	}
+}
+
+func TestParseEncoding(t *testing.T) {
+	for name, want := range map[string]Encoding{"": EncodingXML, "XML": EncodingXML, "mesh": EncodingMesh, "stream": EncodingStream} {
+		if got, err := ParseEncoding(name); err != nil || got != want {
+			t.Errorf("ParseEncoding(%q) = %q, %v; want %q", name, got, err, want)
+		}
+	}
+	if _, err := ParseEncoding("protobuf"); err == nil {
+		t.Error("Expected an error for an unknown encoding")
+	}
+}
+
+func TestPublisherEncodings(t *testing.T) {
+	udp, err := NewCoTPublisher("udp://127.0.0.1:6969", StreamOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer udp.Close()
+	if err := udp.SetEncoding(EncodingMesh); err != nil {
+		t.Errorf("UDP should accept mesh: %v", err)
+	}
+	if err := udp.SetEncoding(EncodingStream); err == nil {
+		t.Error("UDP should reject the stream encoding")
+	}
+
+	tcp := NewStreamPublisher("127.0.0.1:1", StreamOptions{MinBackoff: time.Hour})
+	defer tcp.Close()
+	if err := tcp.SetEncoding(EncodingMesh); err == nil {
+		t.Error("Streams should reject the mesh encoding")
+	}
+}
+
+func TestStreamPublisher_TakStreamEncoding(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to start listener: %v", err)
+	}
+	defer listener.Close()
+
+	publisher := NewStreamPublisher(listener.Addr().String(), StreamOptions{})
+	defer publisher.Close()
+	if err := publisher.SetEncoding(EncodingStream); err != nil {
+		t.Fatal(err)
+	}
+	for _, state := range takTestStates {
+		if err := publisher.PublishPlatformState(state); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	conn, err := listener.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if err := conn.SetReadDeadline(time.Now().Add(5 * time.Second)); err != nil {
+		t.Fatal(err)
+	}
+
+	reader := bufio.NewReader(conn)
+	for _, state := range takTestStates {
+		payload, err := ReadTakStream(reader)
+		if err != nil {
+			t.Fatalf("ReadTakStream failed: %v", err)
+		}
+		event, err := UnmarshalTakMessage(payload)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if event.UID != "TRAFFICSIM-"+state.ID || event.Detail.Contact.Callsign != state.Callsign {
+			t.Errorf("Unexpected event %+v for %s", event, state.ID)
+		}
+	}
+}
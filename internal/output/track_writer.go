@@ -0,0 +1,128 @@
+package output
+
+import (
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/rhino11/trafficsim/internal/models"
+)
+
+// Track output formats for batch runs
+const (
+	TrackFormatCSV = "csv"
+	TrackFormatCoT = "cot"
+)
+
+// TrackWriter records platform positions at successive simulation times
+type TrackWriter interface {
+	// WriteTracks records every platform at one sample time
+	WriteTracks(timestamp time.Time, simTime float64, platforms []models.Platform) error
+	// Flush writes any buffered records to the underlying writer
+	Flush() error
+}
+
+// NewTrackWriter creates a track writer for the named format
+func NewTrackWriter(format string, w io.Writer) (TrackWriter, error) {
+	switch format {
+	case TrackFormatCSV:
+		return &csvTrackWriter{writer: csv.NewWriter(w)}, nil
+	case TrackFormatCoT:
+		return &cotTrackWriter{writer: w, generator: NewCoTGenerator()}, nil
+	default:
+		return nil, fmt.Errorf("unknown track format %q (expected %s or %s)", format, TrackFormatCSV, TrackFormatCoT)
+	}
+}
+
+// TrackFileName returns the output file name for a track format
+func TrackFileName(format string) string {
+	if format == TrackFormatCoT {
+		return "tracks.cot"
+	}
+	return "tracks." + format
+}
+
+// sortedPlatforms returns the platforms ordered by ID so output does not depend on map order
+func sortedPlatforms(platforms []models.Platform) []models.Platform {
+	sorted := append([]models.Platform(nil), platforms...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].GetID() < sorted[j].GetID() })
+	return sorted
+}
+
+// csvTrackWriter writes one row per platform per sample
+type csvTrackWriter struct {
+	writer        *csv.Writer
+	headerWritten bool
+}
+
+var csvTrackHeader = []string{
+	"time", "sim_time", "id", "callsign", "type", "class",
+	"latitude", "longitude", "altitude", "speed", "heading",
+}
+
+func (w *csvTrackWriter) WriteTracks(timestamp time.Time, simTime float64, platforms []models.Platform) error {
+	if !w.headerWritten {
+		if err := w.writer.Write(csvTrackHeader); err != nil {
+			return err
+		}
+		w.headerWritten = true
+	}
+
+	formatFloat := func(value float64, precision int) string {
+		return strconv.FormatFloat(value, 'f', precision, 64)
+	}
+
+	stamp := timestamp.UTC().Format(time.RFC3339Nano)
+	for _, platform := range sortedPlatforms(platforms) {
+		state := platform.GetState()
+		record := []string{
+			stamp,
+			formatFloat(simTime, 3),
+			platform.GetID(),
+			platform.GetCallSign(),
+			string(platform.GetType()),
+			platform.GetClass(),
+			formatFloat(state.Position.Latitude, 7),
+			formatFloat(state.Position.Longitude, 7),
+			formatFloat(state.Position.Altitude, 2),
+			formatFloat(state.Speed, 2),
+			formatFloat(state.Heading, 2),
+		}
+		if err := w.writer.Write(record); err != nil {
+			return err
+		}
+	}
+	return w.writer.Error()
+}
+
+func (w *csvTrackWriter) Flush() error {
+	w.writer.Flush()
+	return w.writer.Error()
+}
+
+// cotTrackWriter writes one CoT event per line, as they would appear on a CoT stream
+type cotTrackWriter struct {
+	writer    io.Writer
+	generator *CoTGenerator
+}
+
+func (w *cotTrackWriter) WriteTracks(timestamp time.Time, simTime float64, platforms []models.Platform) error {
+	for _, platform := range sortedPlatforms(platforms) {
+		data, err := xml.Marshal(w.generator.buildEvent(PlatformToCoTState(platform), timestamp))
+		if err != nil {
+			return fmt.Errorf("failed to encode CoT event for %s: %w", platform.GetID(), err)
+		}
+		if _, err := w.writer.Write(append(data, '\n')); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *cotTrackWriter) Flush() error {
+	return nil
+}
@@ -0,0 +1,89 @@
+package output
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rhino11/trafficsim/internal/models"
+)
+
+func trackTestPlatforms() []models.Platform {
+	return []models.Platform{
+		models.NewArleighBurkeDestroyerUniversal("DDG51", "Arleigh Burke", models.Position{Latitude: 36.8, Longitude: -76.3}),
+		models.NewBoeing737_800Universal("AAL1", "AA1", models.Position{Latitude: 40.7, Longitude: -74.0, Altitude: 10000}),
+	}
+}
+
+func TestCSVTrackWriter(t *testing.T) {
+	var buf bytes.Buffer
+	writer, err := NewTrackWriter(TrackFormatCSV, &buf)
+	if err != nil {
+		t.Fatalf("NewTrackWriter failed: %v", err)
+	}
+
+	start := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	for _, simTime := range []float64{0, 10} {
+		if err := writer.WriteTracks(start.Add(time.Duration(simTime)*time.Second), simTime, trackTestPlatforms()); err != nil {
+			t.Fatalf("WriteTracks failed: %v", err)
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("Output is not valid CSV: %v", err)
+	}
+	if len(records) != 5 || records[0][0] != "time" {
+		t.Fatalf("Expected a header and 4 rows, got %v", records)
+	}
+	// Rows are ordered by platform ID within each sample
+	if records[1][2] != "AAL1" || records[2][2] != "DDG51" {
+		t.Errorf("Expected rows sorted by ID, got %s, %s", records[1][2], records[2][2])
+	}
+	if records[3][0] != "2024-06-01T12:00:10Z" || records[3][1] != "10.000" {
+		t.Errorf("Unexpected timestamps: %v", records[3][:2])
+	}
+	if records[1][6] != "40.7000000" || records[1][8] != "10000.00" {
+		t.Errorf("Unexpected position: %v", records[1])
+	}
+}
+
+func TestCoTTrackWriter(t *testing.T) {
+	var buf bytes.Buffer
+	writer, err := NewTrackWriter(TrackFormatCoT, &buf)
+	if err != nil {
+		t.Fatalf("NewTrackWriter failed: %v", err)
+	}
+
+	timestamp := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	if err := writer.WriteTracks(timestamp, 0, trackTestPlatforms()); err != nil {
+		t.Fatalf("WriteTracks failed: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected one event per line, got %d lines", len(lines))
+	}
+	var event CoTEvent
+	if err := xml.Unmarshal([]byte(lines[0]), &event); err != nil {
+		t.Fatalf("Line is not a CoT event: %v", err)
+	}
+	if event.UID != "TRAFFICSIM-AAL1" || event.Time != "2024-06-01T12:00:00.000Z" {
+		t.Errorf("Unexpected event: uid=%s time=%s", event.UID, event.Time)
+	}
+}
+
+func TestNewTrackWriter_UnknownFormat(t *testing.T) {
+	if _, err := NewTrackWriter("kml", &bytes.Buffer{}); err == nil {
+		t.Error("Expected error for unknown format")
+	}
+	if TrackFileName(TrackFormatCSV) != "tracks.csv" || TrackFileName(TrackFormatCoT) != "tracks.cot" {
+		t.Error("Unexpected track file names")
+	}
+}
@@ -27,6 +27,9 @@ import (
 	"github.com/rhino11/trafficsim/internal/sim"
 )
 
+// defaultBuilderScenarioDuration is the duration given to scenario builder runs that set none (seconds)
+const defaultBuilderScenarioDuration = 24 * 3600
+
 // isTestMode checks if we're running in test mode
 func isTestMode() bool {
 	return strings.Contains(os.Args[0], ".test") ||
@@ -94,17 +97,19 @@ func logPlatformUpdate(platformCount int, action string) {
 	logf("[PLATFORM] %s - Count: %d", action, platformCount)
 }
 
-// MulticastManager handles multicast CoT transmission
+// multicastSinkName names the CoT sink switched on and off through the multicast API
+const multicastSinkName = "multicast"
+
+// multicastRate is the time between multicast updates, the usual CoT reporting interval
+const multicastRate = "5s"
+
+// MulticastManager switches CoT multicast transmission on and off as an output sink
 type MulticastManager struct {
-	enabled        bool
-	conn           *net.UDPConn
-	addr           string
-	port           string
-	cotGenerator   *output.CoTGenerator
-	lastSent       time.Time
-	messagesSent   int64
-	messagesFailed int64
-	mutex          sync.RWMutex
+	addr    string
+	port    string
+	sinks   *output.SinkManager
+	enabled bool
+	mutex   sync.RWMutex
 }
 
 // MulticastStatus represents multicast transmission status
@@ -121,14 +126,57 @@ type MulticastStatus struct {
 
 // NewMulticastManager creates a new multicast manager
 func NewMulticastManager(addr, port string) *MulticastManager {
+	sinks, _ := output.NewSinkManager(config.OutputConfig{}) // No sinks yet, so nothing can fail
 	return &MulticastManager{
-		enabled:      false,
-		addr:         addr,
-		port:         port,
-		cotGenerator: output.NewCoTGenerator(),
+		addr:  addr,
+		port:  port,
+		sinks: sinks,
 	}
 }
 
+// newMulticastManager creates a multicast manager on the default CoT group, timestamped by simulation
+// time and fed with snapshots while the simulation runs
+func (s *Server) newMulticastManager() *MulticastManager {
+	mm := NewMulticastManager("239.2.3.1", "6969")
+	if s.simulation != nil {
+		mm.SetClock(s.simulation)
+		go mm.Run(s.ctx, func() []models.Platform {
+			if !s.simulation.IsRunning() {
+				return nil
+			}
+			return s.simulation.SnapshotPlatforms()
+		})
+	}
+	return mm
+}
+
+// multicast returns the multicast manager, creating it on first use
+func (s *Server) multicast() *MulticastManager {
+	s.multicastMux.Lock()
+	defer s.multicastMux.Unlock()
+	if s.multicastManager == nil {
+		s.multicastManager = s.newMulticastManager()
+	}
+	return s.multicastManager
+}
+
+// currentMulticast returns the multicast manager, nil if it has not been used
+func (s *Server) currentMulticast() *MulticastManager {
+	s.multicastMux.Lock()
+	defer s.multicastMux.Unlock()
+	return s.multicastManager
+}
+
+// SetClock sets the clock used to timestamp CoT messages
+func (mm *MulticastManager) SetClock(clock models.Clock) {
+	mm.sinks.SetClock(clock)
+}
+
+// Run sends the platforms from source while enabled, until ctx is cancelled
+func (mm *MulticastManager) Run(ctx context.Context, source func() []models.Platform) {
+	mm.sinks.Run(ctx, source)
+}
+
 // Enable enables multicast transmission
 func (mm *MulticastManager) Enable() error {
 	mm.mutex.Lock()
@@ -138,19 +186,15 @@ func (mm *MulticastManager) Enable() error {
 		return nil // Already enabled
 	}
 
-	// Parse multicast address
-	multicastAddr, err := net.ResolveUDPAddr("udp", fmt.Sprintf("%s:%s", mm.addr, mm.port))
-	if err != nil {
-		return fmt.Errorf("failed to resolve multicast address: %v", err)
-	}
-
-	// Create UDP connection
-	conn, err := net.DialUDP("udp", nil, multicastAddr)
+	err := mm.sinks.AddSink(config.SinkConfig{
+		Name:     multicastSinkName,
+		Format:   config.SinkFormatCoT,
+		Endpoint: "udp://" + net.JoinHostPort(mm.addr, mm.port),
+		Rate:     multicastRate,
+	})
 	if err != nil {
 		return fmt.Errorf("failed to create multicast connection: %v", err)
 	}
-
-	mm.conn = conn
 	mm.enabled = true
 
 	logf("[MULTICAST] Enabled on %s:%s", mm.addr, mm.port)
@@ -166,73 +210,40 @@ func (mm *MulticastManager) Disable() error {
 		return nil // Already disabled
 	}
 
-	if mm.conn != nil {
-		if err := mm.conn.Close(); err != nil {
-			logf("[MULTICAST] Error closing connection: %v", err)
-		}
-		mm.conn = nil
+	if err := mm.sinks.RemoveSink(multicastSinkName); err != nil {
+		logf("[MULTICAST] Error closing connection: %v", err)
 	}
-
 	mm.enabled = false
 	logf("[MULTICAST] Disabled")
 	return nil
 }
 
-// SendPlatformUpdates sends platform updates via multicast
-func (mm *MulticastManager) SendPlatformUpdates(platforms []models.Platform) {
-	mm.mutex.RLock()
-	defer mm.mutex.RUnlock()
-
-	if !mm.enabled || mm.conn == nil {
-		return
-	}
-
-	for _, platform := range platforms {
-		// Convert platform to CoT state
-		cotState := output.PlatformToCoTState(platform)
-
-		// Generate CoT XML message
-		cotMessage, err := mm.cotGenerator.GenerateCoTMessage(cotState)
-		if err != nil {
-			mm.messagesFailed++
-			logf("[MULTICAST] Failed to generate CoT message for %s: %v", platform.GetCallSign(), err)
-			continue
-		}
-
-		// Send the CoT XML message
-		_, err = mm.conn.Write(cotMessage)
-		if err != nil {
-			mm.messagesFailed++
-			logf("[MULTICAST] Failed to send CoT message for %s: %v", platform.GetCallSign(), err)
-		} else {
-			mm.messagesSent++
-			mm.lastSent = time.Now()
-			logf("[MULTICAST] Sent CoT message for %s (Type: %s)", platform.GetCallSign(), cotState.CoTType)
-		}
-	}
-}
-
 // GetStatus returns the current multicast status
 func (mm *MulticastManager) GetStatus() MulticastStatus {
 	mm.mutex.RLock()
 	defer mm.mutex.RUnlock()
 
 	status := MulticastStatus{
-		Enabled:        mm.enabled,
-		Connected:      mm.enabled && mm.conn != nil,
-		MessagesSent:   mm.messagesSent,
-		MessagesFailed: mm.messagesFailed,
+		Enabled:   mm.enabled,
+		Connected: mm.enabled,
 	}
-
-	if mm.enabled {
-		status.Address = mm.addr
-		status.Port = mm.port
+	if !mm.enabled {
+		return status
 	}
 
-	if !mm.lastSent.IsZero() {
-		status.LastSent = mm.lastSent.Format(time.RFC3339)
+	status.Address = mm.addr
+	status.Port = mm.port
+	for _, sink := range mm.sinks.Status() {
+		if sink.Name != multicastSinkName {
+			continue
+		}
+		status.MessagesSent = sink.Reports
+		status.MessagesFailed = sink.Failed
+		status.Error = sink.LastError
+		if !sink.LastUpdate.IsZero() {
+			status.LastSent = sink.LastUpdate.Format(time.RFC3339)
+		}
 	}
-
 	return status
 }
 
@@ -247,7 +258,8 @@ type Server struct {
 	broadcast        chan []byte
 	ctx              context.Context
 	cancel           context.CancelFunc
-	multicastManager *MulticastManager
+	multicastManager *MulticastManager // Created on first use, guarded by multicastMux
+	multicastMux     sync.Mutex
 }
 
 // Client represents a connected WebSocket client
@@ -273,10 +285,19 @@ type PlatformUpdate struct {
 
 // SimulationStatus represents simulation status
 type SimulationStatus struct {
-	Running       bool    `json:"running"`
-	Time          float64 `json:"time"`
-	PlatformCount int     `json:"platform_count"`
-	Speed         float64 `json:"speed"`
+	Running       bool      `json:"running"`
+	Time          float64   `json:"time"`
+	PlatformCount int       `json:"platform_count"`
+	Speed         float64   `json:"speed"` // Simulation time scale
+	Paused        bool      `json:"paused"`
+	Scenario      string    `json:"scenario,omitempty"`
+	Clock         time.Time `json:"clock"` // Simulated date and time
+}
+
+// SimulationControl is a runtime control command from the REST API or a WebSocket "control" message
+type SimulationControl struct {
+	Command   string  `json:"command"`              // "pause", "resume" or "set_time_scale"
+	TimeScale float64 `json:"time_scale,omitempty"` // For set_time_scale
 }
 
 // NewServer creates a new web server instance
@@ -299,6 +320,9 @@ func NewServer(cfg *config.Config, simulation *sim.Engine) *Server {
 	}
 
 	server.setupRoutes()
+	if simulation != nil {
+		simulation.OnPlatformEvent(server.broadcastPlatformEvent)
+	}
 	return server
 }
 
@@ -376,6 +400,11 @@ func (s *Server) setupRoutes() {
 	api.HandleFunc("/simulation/stop", s.handleStopSimulation).Methods("POST")
 	api.HandleFunc("/simulation/reset", s.handleResetSimulation).Methods("POST")
 	api.HandleFunc("/simulation/status", s.handleSimulationStatus).Methods("GET")
+	api.HandleFunc("/simulation/pause", s.handleSimulationControl("pause")).Methods("POST")
+	api.HandleFunc("/simulation/resume", s.handleSimulationControl("resume")).Methods("POST")
+	api.HandleFunc("/simulation/speed", s.handleSimulationControl("set_time_scale")).Methods("POST")
+	api.HandleFunc("/simulation/scenarios", s.handleGetConfigScenarios).Methods("GET")
+	api.HandleFunc("/simulation/scenario", s.handleLoadConfigScenario).Methods("POST")
 	api.HandleFunc("/stream/platforms", s.handleSSEPlatforms).Methods("GET")
 	// Multicast endpoints
 	api.HandleFunc("/multicast/status", s.handleMulticastStatus).Methods("GET")
@@ -425,6 +454,11 @@ func (s *Server) Start(port string) error {
 // Stop stops the web server
 func (s *Server) Stop() {
 	s.cancel()
+	if mm := s.currentMulticast(); mm != nil {
+		if err := mm.Disable(); err != nil {
+			log.Printf("Error disabling multicast: %v", err)
+		}
+	}
 
 	// Close all WebSocket connections with proper error handling
 	s.clientsMux.Lock()
@@ -1170,15 +1204,67 @@ func (s *Server) handleResetSimulation(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-// handleSimulationStatus returns simulation status
-func (s *Server) handleSimulationStatus(w http.ResponseWriter, r *http.Request) {
-	status := SimulationStatus{
+// simulationStatus captures the current simulation state
+func (s *Server) simulationStatus() SimulationStatus {
+	return SimulationStatus{
 		Running:       s.simulation.IsRunning(),
 		Time:          s.simulation.GetSimulationTime(),
-		PlatformCount: len(s.simulation.GetAllPlatforms()),
-		Speed:         1.0, // Default speed
+		PlatformCount: s.simulation.GetPlatformCount(),
+		Speed:         s.simulation.GetTimeScale(),
+		Paused:        s.simulation.IsPaused(),
+		Scenario:      s.simulation.GetScenario(),
+		Clock:         s.simulation.Now(),
+	}
+}
+
+// applyControl applies a runtime control command and broadcasts the resulting status
+func (s *Server) applyControl(control SimulationControl) error {
+	switch control.Command {
+	case "pause":
+		s.simulation.Pause()
+	case "resume":
+		s.simulation.Resume()
+	case "set_time_scale":
+		if err := s.simulation.SetTimeScale(control.TimeScale); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unknown control command: %q", control.Command)
 	}
 
+	logSimulationEvent("CONTROL_APPLIED", control)
+	s.broadcastSimulationStatus()
+	return nil
+}
+
+// handleSimulationControl returns a handler applying a fixed control command, or the time scale in the request body
+func (s *Server) handleSimulationControl(command string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		control := SimulationControl{Command: command}
+		if command == "set_time_scale" {
+			if err := json.NewDecoder(r.Body).Decode(&control); err != nil {
+				http.Error(w, "Invalid request format", http.StatusBadRequest)
+				return
+			}
+			control.Command = command
+		}
+
+		if err := s.applyControl(control); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(s.simulationStatus()); err != nil {
+			logWebError("Simulation control response encoding", err)
+		}
+	}
+}
+
+// handleSimulationStatus returns simulation status
+func (s *Server) handleSimulationStatus(w http.ResponseWriter, r *http.Request) {
+	status := s.simulationStatus()
+
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(status); err != nil {
 		log.Printf("Error encoding status response: %v", err)
@@ -1187,6 +1273,53 @@ func (s *Server) handleSimulationStatus(w http.ResponseWriter, r *http.Request)
 	}
 }
 
+// handleGetConfigScenarios lists the scenarios defined in the loaded configuration
+func (s *Server) handleGetConfigScenarios(w http.ResponseWriter, r *http.Request) {
+	response := map[string]interface{}{
+		"scenarios": s.simulation.GetAvailableScenarios(),
+		"active":    s.simulation.GetScenario(),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		logWebError("Config scenarios encoding", err)
+	}
+}
+
+// handleLoadConfigScenario replaces the running platforms with a configured scenario
+func (s *Server) handleLoadConfigScenario(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Name string `json:"name"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request format", http.StatusBadRequest)
+		return
+	}
+
+	if req.Name == "" {
+		http.Error(w, "Scenario name is required", http.StatusBadRequest)
+		return
+	}
+
+	if err := s.simulation.LoadScenario(req.Name); err != nil {
+		logWebError("Loading config scenario", err)
+		http.Error(w, "Error loading scenario: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.broadcastSimulationStatus()
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":         "loaded",
+		"scenario":       req.Name,
+		"platform_count": s.simulation.GetPlatformCount(),
+	}); err != nil {
+		logWebError("Load scenario response encoding", err)
+	}
+}
+
 // handleSSEPlatforms handles Server-Sent Events for platform updates
 func (s *Server) handleSSEPlatforms(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "text/event-stream")
@@ -1294,18 +1427,32 @@ func (s *Server) streamSimulationUpdates() {
 	}
 }
 
-// broadcastSimulationStatus broadcasts simulation status to all clients
-func (s *Server) broadcastSimulationStatus() {
-	status := SimulationStatus{
-		Running:       s.simulation.IsRunning(),
-		Time:          s.simulation.GetSimulationTime(),
-		PlatformCount: len(s.simulation.GetAllPlatforms()),
-		Speed:         1.0,
+// broadcastPlatformEvent broadcasts a platform being added to or removed from the simulation
+func (s *Server) broadcastPlatformEvent(event sim.PlatformEvent) {
+	message := Message{
+		Type:      string(event.Type),
+		Data:      event,
+		Timestamp: time.Now().UnixMilli(),
+	}
+
+	data, err := json.Marshal(message)
+	if err != nil {
+		log.Printf("Error marshaling platform event: %v", err)
+		return
 	}
 
+	select {
+	case s.broadcast <- data:
+	default:
+		// Channel is full, the next platform update still reflects the change
+	}
+}
+
+// broadcastSimulationStatus broadcasts simulation status to all clients
+func (s *Server) broadcastSimulationStatus() {
 	message := Message{
 		Type:      "simulation_status",
-		Data:      status,
+		Data:      s.simulationStatus(),
 		Timestamp: time.Now().UnixMilli(),
 	}
 
@@ -1499,8 +1646,19 @@ func (c *Client) handleMessage(data []byte) {
 		c.server.broadcastSimulationStatus()
 
 	case "control":
-		// Handle other simulation control messages
+		// Pause, resume and time scale changes
 		logSimulationEvent("CONTROL_MESSAGE", msg.Data)
+		var control SimulationControl
+		raw, err := json.Marshal(msg.Data)
+		if err == nil {
+			err = json.Unmarshal(raw, &control)
+		}
+		if err == nil {
+			err = c.server.applyControl(control)
+		}
+		if err != nil {
+			logWebError("Simulation control", err)
+		}
 
 	default:
 		// Log unknown message types with full context for debugging
@@ -1677,31 +1835,21 @@ func (s *Server) handleGetScenarios(w http.ResponseWriter, r *http.Request) {
 					continue
 				}
 
-				// Read the YAML file to extract metadata
+				// Read the scenario file to extract metadata
 				filePath := filepath.Join(configPath, file.Name())
-				yamlData, err := os.ReadFile(filePath)
+				scenarioFile, err := config.ReadScenarioFile(filePath)
 				if err != nil {
 					logWebError("Reading scenario file", err)
 					continue
 				}
 
-				var scenarioData map[string]interface{}
-				if err := yaml.Unmarshal(yamlData, &scenarioData); err != nil {
-					logWebError("Parsing scenario YAML", err)
-					continue
-				}
-
-				// Extract metadata
 				displayName := file.Name()
+				if scenarioFile.Metadata.Name != "" {
+					displayName = scenarioFile.Metadata.Name
+				}
 				description := "No description available"
-
-				if metadata, ok := scenarioData["metadata"].(map[string]interface{}); ok {
-					if name, ok := metadata["name"].(string); ok {
-						displayName = name
-					}
-					if desc, ok := metadata["description"].(string); ok {
-						description = desc
-					}
+				if scenarioFile.Metadata.Description != "" {
+					description = scenarioFile.Metadata.Description
 				}
 
 				scenarios = append(scenarios, map[string]interface{}{
@@ -1761,20 +1909,13 @@ func (s *Server) handleGetScenario(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	yamlData, err := os.ReadFile(scenarioPath)
+	scenarioData, err := config.ReadScenarioFile(scenarioPath)
 	if err != nil {
 		logWebError("Reading scenario file", err)
 		http.Error(w, "Error reading scenario file", http.StatusInternalServerError)
 		return
 	}
 
-	var scenarioData map[string]interface{}
-	if err := yaml.Unmarshal(yamlData, &scenarioData); err != nil {
-		logWebError("Parsing scenario YAML", err)
-		http.Error(w, "Error parsing scenario file", http.StatusInternalServerError)
-		return
-	}
-
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(scenarioData); err != nil {
 		logWebError("Scenario data encoding", err)
@@ -1785,30 +1926,27 @@ func (s *Server) handleGetScenario(w http.ResponseWriter, r *http.Request) {
 
 // handleRunScenario starts a simulation with the provided scenario data
 func (s *Server) handleRunScenario(w http.ResponseWriter, r *http.Request) {
-	var req struct {
-		Metadata  map[string]interface{}   `json:"metadata"`
-		Platforms []map[string]interface{} `json:"platforms"`
-	}
-
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+	var scenarioFile config.ScenarioFile
+	if err := json.NewDecoder(r.Body).Decode(&scenarioFile); err != nil {
 		http.Error(w, "Invalid request format", http.StatusBadRequest)
 		return
 	}
 
-	if len(req.Platforms) == 0 {
+	if len(scenarioFile.Platforms) == 0 {
 		http.Error(w, "No platforms provided in scenario", http.StatusBadRequest)
 		return
 	}
 
-	// Create platforms from the request
-	var platforms []*models.UniversalPlatform
-	for _, platformConfig := range req.Platforms {
-		platform, err := models.CreatePlatformFromConfig(platformConfig)
-		if err != nil {
-			http.Error(w, fmt.Sprintf("Failed to create platform: %v", err), http.StatusBadRequest)
-			return
-		}
-		platforms = append(platforms, platform)
+	// Scenarios from the builder carry no duration and run until stopped
+	if scenarioFile.Metadata.Duration == 0 {
+		scenarioFile.Metadata.Duration = defaultBuilderScenarioDuration
+	}
+
+	loader := config.NewScenarioLoader(findDataDir("platforms"))
+	scenario, err := loader.Resolve(&scenarioFile)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid scenario: %v", err), http.StatusBadRequest)
+		return
 	}
 
 	// Stop current simulation if running
@@ -1816,22 +1954,9 @@ func (s *Server) handleRunScenario(w http.ResponseWriter, r *http.Request) {
 		s.simulation.Stop()
 	}
 
-	// Clear existing platforms and add new ones
-	// Get all current platforms and remove them
-	currentPlatforms := s.simulation.GetAllPlatforms()
-	for _, existingPlatform := range currentPlatforms {
-		if err := s.simulation.RemovePlatform(existingPlatform.GetID()); err != nil {
-			logWebError("Removing existing platform", err)
-			continue
-		}
-	}
-
-	// Add new platforms
-	for _, platform := range platforms {
-		if err := s.simulation.AddPlatform(platform); err != nil {
-			logWebError("Adding platform to simulation", err)
-			continue
-		}
+	if err := s.simulation.LoadScenarioDefinition(scenario); err != nil {
+		http.Error(w, fmt.Sprintf("Failed to create platforms: %v", err), http.StatusBadRequest)
+		return
 	}
 
 	// Start the simulation
@@ -1848,13 +1973,28 @@ func (s *Server) handleRunScenario(w http.ResponseWriter, r *http.Request) {
 	if err := json.NewEncoder(w).Encode(map[string]interface{}{
 		"message":        "Scenario started successfully",
 		"session_id":     sessionID,
-		"platform_count": len(platforms),
+		"platform_count": len(scenario.Entries),
 		"redirect_url":   "/", // Redirect to main simulation view
 	}); err != nil {
 		logWebError("Scenario run response encoding", err)
 	}
 }
 
+// findDataDir returns the first existing data/<name> directory relative to the working directory
+func findDataDir(name string) string {
+	dataDir := filepath.Join("data", name)
+	for _, path := range []string{
+		dataDir,
+		filepath.Join("..", dataDir),
+		filepath.Join("..", "..", dataDir),
+	} {
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return dataDir
+}
+
 // handleSaveScenario saves a custom scenario to a YAML file
 func (s *Server) handleSaveScenario(w http.ResponseWriter, r *http.Request) {
 	var req struct {
@@ -1958,12 +2098,7 @@ func (s *Server) handleSaveScenario(w http.ResponseWriter, r *http.Request) {
 
 // handleMulticastStatus returns the current multicast status
 func (s *Server) handleMulticastStatus(w http.ResponseWriter, r *http.Request) {
-	if s.multicastManager == nil {
-		// Initialize multicast manager with default values if not set
-		s.multicastManager = NewMulticastManager("239.2.3.1", "6969")
-	}
-
-	status := s.multicastManager.GetStatus()
+	status := s.multicast().GetStatus()
 
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(status); err != nil {
@@ -1975,18 +2110,11 @@ func (s *Server) handleMulticastStatus(w http.ResponseWriter, r *http.Request) {
 
 // handleMulticastEnable enables multicast transmission
 func (s *Server) handleMulticastEnable(w http.ResponseWriter, r *http.Request) {
-	if s.multicastManager == nil {
-		s.multicastManager = NewMulticastManager("239.2.3.1", "6969")
-	}
-
-	if err := s.multicastManager.Enable(); err != nil {
+	if err := s.multicast().Enable(); err != nil {
 		http.Error(w, fmt.Sprintf("Failed to enable multicast: %v", err), http.StatusInternalServerError)
 		return
 	}
 
-	// Start sending platform updates via multicast
-	go s.sendMulticastUpdates()
-
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(map[string]string{"status": "enabled"}); err != nil {
 		logWebError("Multicast enable response encoding", err)
@@ -1995,7 +2123,8 @@ func (s *Server) handleMulticastEnable(w http.ResponseWriter, r *http.Request) {
 
 // handleMulticastDisable disables multicast transmission
 func (s *Server) handleMulticastDisable(w http.ResponseWriter, r *http.Request) {
-	if s.multicastManager == nil {
+	mm := s.currentMulticast()
+	if mm == nil {
 		w.Header().Set("Content-Type", "application/json")
 		if err := json.NewEncoder(w).Encode(map[string]string{"status": "already_disabled"}); err != nil {
 			logWebError("Multicast disable response encoding", err)
@@ -2004,7 +2133,7 @@ func (s *Server) handleMulticastDisable(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	if err := s.multicastManager.Disable(); err != nil {
+	if err := mm.Disable(); err != nil {
 		http.Error(w, fmt.Sprintf("Failed to disable multicast: %v", err), http.StatusInternalServerError)
 		return
 	}
@@ -2015,26 +2144,6 @@ func (s *Server) handleMulticastDisable(w http.ResponseWriter, r *http.Request)
 	}
 }
 
-// sendMulticastUpdates sends regular platform updates via multicast
-func (s *Server) sendMulticastUpdates() {
-	ticker := time.NewTicker(5 * time.Second) // Send every 5 seconds as per CoT standard
-	defer ticker.Stop()
-
-	for {
-		select {
-		case <-s.ctx.Done():
-			return
-		case <-ticker.C:
-			if s.multicastManager != nil && s.simulation.IsRunning() {
-				platforms := s.simulation.GetAllPlatforms()
-				if len(platforms) > 0 {
-					s.multicastManager.SendPlatformUpdates(platforms)
-				}
-			}
-		}
-	}
-}
-
 // ServeHTTP implements the http.Handler interface
 func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	s.router.ServeHTTP(w, r)
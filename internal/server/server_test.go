@@ -4,13 +4,17 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/rhino11/trafficsim/internal/config"
+	"github.com/rhino11/trafficsim/internal/models"
 	"github.com/rhino11/trafficsim/internal/sim"
 	"github.com/rhino11/trafficsim/internal/testutil"
 )
@@ -1063,3 +1067,257 @@ platform_types:
 		t.Error("Expected valid_aircraft platform to be loaded from YAML file")
 	}
 }
+
+func TestHandleConfigScenarios(t *testing.T) {
+	cfg := createTestConfig()
+	cfg.Platforms = config.PlatformRegistry{
+		LandTypes: config.PlatformTypeDefinitions{
+			"hmmwv": {Class: "HMMWV", Type: "land", Category: "military", MaxSpeed: 31.0, CruiseSpeed: 22.4},
+		},
+		Scenarios: map[string]config.ScenarioConfig{
+			"convoy": {
+				Name: "Convoy",
+				Instances: []config.PlatformInstance{
+					{ID: "HMV1", TypeID: "hmmwv", Name: "Lead", StartPos: config.Position{Latitude: 31.1, Longitude: -97.8}},
+					{ID: "HMV2", TypeID: "hmmwv", Name: "Trail", StartPos: config.Position{Latitude: 31.1, Longitude: -97.8}},
+				},
+			},
+		},
+	}
+	engine := sim.NewEngine(cfg)
+	server := NewServer(cfg, engine)
+
+	// List configured scenarios
+	req := httptest.NewRequest("GET", "/api/simulation/scenarios", nil)
+	rec := httptest.NewRecorder()
+	server.router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+
+	var listing struct {
+		Scenarios []string `json:"scenarios"`
+		Active    string   `json:"active"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &listing); err != nil {
+		t.Fatalf("Expected valid JSON response, got error: %v", err)
+	}
+	if len(listing.Scenarios) != 1 || listing.Scenarios[0] != "convoy" {
+		t.Errorf("Expected [convoy], got %v", listing.Scenarios)
+	}
+
+	// Load a configured scenario
+	req = httptest.NewRequest("POST", "/api/simulation/scenario", strings.NewReader(`{"name": "convoy"}`))
+	rec = httptest.NewRecorder()
+	server.router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if engine.GetPlatformCount() != 2 {
+		t.Errorf("Expected 2 platforms after loading scenario, got %d", engine.GetPlatformCount())
+	}
+	if engine.GetScenario() != "convoy" {
+		t.Errorf("Expected active scenario 'convoy', got '%s'", engine.GetScenario())
+	}
+
+	// Unknown scenario is rejected
+	req = httptest.NewRequest("POST", "/api/simulation/scenario", strings.NewReader(`{"name": "missing"}`))
+	rec = httptest.NewRecorder()
+	server.router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for unknown scenario, got %d", rec.Code)
+	}
+}
+
+func TestHandleRunScenario(t *testing.T) {
+	cfg := createTestConfig()
+	engine := sim.NewEngine(cfg)
+	server := NewServer(cfg, engine)
+	defer engine.Stop()
+
+	// Scenario builder payload: no source_file or duration
+	body := `{
+		"metadata": {"name": "Builder Scenario", "created_at": "2025-06-04T06:00:00Z"},
+		"platforms": [{
+			"id": "TRUCK-001",
+			"type": "pickup_truck",
+			"name": "Truck 1",
+			"class": "Pickup Truck",
+			"domain": "land",
+			"start_position": {"latitude": 29.76, "longitude": -95.37, "altitude": 15},
+			"mission": {"type": "transport"}
+		}]
+	}`
+
+	req := httptest.NewRequest("POST", "/api/scenario/run", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	server.router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if engine.GetPlatformCount() != 1 || !engine.IsRunning() {
+		t.Errorf("Expected 1 running platform, got %d (running=%v)", engine.GetPlatformCount(), engine.IsRunning())
+	}
+	if _, err := engine.GetPlatform("TRUCK-001"); err != nil {
+		t.Errorf("Expected builder platform to be loaded: %v", err)
+	}
+
+	// Unknown platform types are rejected
+	body = `{"metadata": {"name": "Bad"}, "platforms": [{"id": "X1", "type": "flying_saucer", "start_position": {"latitude": 0, "longitude": 0}}]}`
+	req = httptest.NewRequest("POST", "/api/scenario/run", strings.NewReader(body))
+	rec = httptest.NewRecorder()
+	server.router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for unknown platform type, got %d", rec.Code)
+	}
+}
+
+func TestPlatformEventBroadcast(t *testing.T) {
+	cfg := createTestConfig()
+	engine := sim.NewEngine(cfg)
+	server := NewServer(cfg, engine)
+
+	platform := models.NewBoeing737_800Universal("UA1", "United 1", models.Position{Latitude: 40.7, Longitude: -74.0})
+	if err := engine.AddPlatform(platform); err != nil {
+		t.Fatalf("AddPlatform failed: %v", err)
+	}
+	if err := engine.RemovePlatform("UA1"); err != nil {
+		t.Fatalf("RemovePlatform failed: %v", err)
+	}
+
+	for _, expected := range []string{"platform_added", "platform_removed"} {
+		select {
+		case data := <-server.broadcast:
+			var message struct {
+				Type string `json:"type"`
+				Data struct {
+					PlatformID string `json:"platform_id"`
+				} `json:"data"`
+			}
+			if err := json.Unmarshal(data, &message); err != nil {
+				t.Fatalf("Invalid event message: %v", err)
+			}
+			if message.Type != expected || message.Data.PlatformID != "UA1" {
+				t.Errorf("Expected %s for UA1, got %s for %s", expected, message.Type, message.Data.PlatformID)
+			}
+		default:
+			t.Fatalf("Expected %s message to be broadcast", expected)
+		}
+	}
+}
+
+func TestSimulationControlEndpoints(t *testing.T) {
+	cfg := createTestConfig()
+	engine := sim.NewEngine(cfg)
+	server := NewServer(cfg, engine)
+
+	post := func(path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", path, strings.NewReader(body))
+		rec := httptest.NewRecorder()
+		server.router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := post("/api/simulation/speed", `{"time_scale": 4}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var status SimulationStatus
+	if err := json.Unmarshal(rec.Body.Bytes(), &status); err != nil {
+		t.Fatalf("Expected status JSON: %v", err)
+	}
+	if status.Speed != 4 || engine.GetTimeScale() != 4 {
+		t.Errorf("Expected 4x time scale, got status %v and engine %v", status.Speed, engine.GetTimeScale())
+	}
+
+	if rec := post("/api/simulation/speed", `{"time_scale": 0}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for zero time scale, got %d", rec.Code)
+	}
+
+	if rec := post("/api/simulation/pause", ""); rec.Code != http.StatusOK || !engine.IsPaused() {
+		t.Errorf("Expected pause to succeed, got %d (paused=%v)", rec.Code, engine.IsPaused())
+	}
+	if rec := post("/api/simulation/resume", ""); rec.Code != http.StatusOK || engine.IsPaused() {
+		t.Errorf("Expected resume to succeed, got %d (paused=%v)", rec.Code, engine.IsPaused())
+	}
+
+	if err := server.applyControl(SimulationControl{Command: "rewind"}); err == nil {
+		t.Error("Expected error for unknown control command")
+	}
+}
+
+func TestMulticastHandlersShareManager(t *testing.T) {
+	server := NewServer(createTestConfig(), createTestEngine())
+	defer server.Stop()
+
+	// Concurrent first requests create one manager between them
+	var wg sync.WaitGroup
+	managers := make([]*MulticastManager, 8)
+	for i := range managers {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			rec := httptest.NewRecorder()
+			server.router.ServeHTTP(rec, httptest.NewRequest("GET", "/api/multicast/status", nil))
+			if rec.Code != http.StatusOK {
+				t.Errorf("Expected status 200, got %d", rec.Code)
+			}
+			managers[i] = server.currentMulticast()
+		}(i)
+	}
+	wg.Wait()
+	for _, mm := range managers {
+		if mm == nil || mm != managers[0] {
+			t.Fatal("Expected every request to use the same multicast manager")
+		}
+	}
+}
+
+func TestMulticastManager(t *testing.T) {
+	receiver, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer receiver.Close()
+	addr := receiver.LocalAddr().(*net.UDPAddr)
+
+	mm := NewMulticastManager("127.0.0.1", fmt.Sprint(addr.Port))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	airliner := models.NewBoeing737_800Universal("UAL123", "UA123", models.Position{Latitude: 40.6, Longitude: -73.8, Altitude: 10000})
+	go mm.Run(ctx, func() []models.Platform { return []models.Platform{airliner} })
+
+	if status := mm.GetStatus(); status.Enabled || status.Address != "" {
+		t.Errorf("Expected multicast to start disabled, got %+v", status)
+	}
+	if err := mm.Enable(); err != nil {
+		t.Fatalf("Enable failed: %v", err)
+	}
+
+	receiver.SetReadDeadline(time.Now().Add(5 * time.Second))
+	buf := make([]byte, 4096)
+	n, err := receiver.Read(buf)
+	if err != nil {
+		t.Fatalf("No CoT received: %v", err)
+	}
+	if !strings.Contains(string(buf[:n]), `uid="TRAFFICSIM-UAL123"`) {
+		t.Errorf("Expected a CoT event for UAL123, got %s", buf[:n])
+	}
+	for deadline := time.Now().Add(5 * time.Second); mm.GetStatus().MessagesSent != 1; time.Sleep(10 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected one message sent, got %+v", mm.GetStatus())
+		}
+	}
+
+	if err := mm.Disable(); err != nil {
+		t.Fatalf("Disable failed: %v", err)
+	}
+	if status := mm.GetStatus(); status.Enabled || status.Connected {
+		t.Errorf("Expected multicast to be disabled, got %+v", status)
+	}
+}
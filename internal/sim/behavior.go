@@ -0,0 +1,287 @@
+package sim
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+
+	"github.com/rhino11/trafficsim/internal/config"
+	"github.com/rhino11/trafficsim/internal/models"
+)
+
+// circlePatrolPoints is the number of waypoints used to approximate a circle patrol
+const circlePatrolPoints = 12
+
+// circuitLeadAngle is how far ahead of the platform (degrees around the center) a circuit steers
+const circuitLeadAngle = 30.0
+
+// Behavior drives a platform's destination from a movement pattern each tick
+type Behavior interface {
+	// Name returns the behavior pattern name, e.g. "patrol"
+	Name() string
+	// Update steers the platform before its physics step
+	Update(platform *models.UniversalPlatform, pe *PhysicsEngine)
+	// Reset restarts the pattern from the beginning
+	Reset(platform *models.UniversalPlatform)
+}
+
+// NewBehavior creates the runtime behavior for a behavior configuration
+func NewBehavior(cfg *config.BehaviorConfig, rng *rand.Rand) (Behavior, error) {
+	if cfg == nil {
+		return nil, fmt.Errorf("no behavior configuration provided")
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case cfg.Patrol != nil:
+		return &patrolBehavior{config: *cfg.Patrol}, nil
+	case cfg.CircuitFlight != nil:
+		return &circuitBehavior{config: *cfg.CircuitFlight}, nil
+	default:
+		if rng == nil {
+			return nil, fmt.Errorf("random walk behavior requires a random source")
+		}
+		seed := rng.Int63()
+		return &randomWalkBehavior{config: *cfg.RandomWalk, seed: seed, rng: rand.New(rand.NewSource(seed))}, nil
+	}
+}
+
+// patrolBehavior follows a line, box or circle pattern using the platform's route
+type patrolBehavior struct {
+	config  config.PatrolBehavior
+	started bool
+}
+
+// Name returns the behavior pattern name
+func (b *patrolBehavior) Name() string {
+	return "patrol"
+}
+
+// Update assigns the patrol route the first time the platform is updated
+func (b *patrolBehavior) Update(platform *models.UniversalPlatform, pe *PhysicsEngine) {
+	if b.started {
+		return
+	}
+
+	waypoints, mode := b.patrolRoute(pe)
+	speeds := make([]float64, len(waypoints))
+	for i := range speeds {
+		speeds[i] = b.config.Speed
+	}
+
+	if err := platform.SetRoute(waypoints, mode, speeds); err != nil {
+		logSimulationError("patrol behavior", err, platform.ID)
+		return
+	}
+
+	// LoopCount -1 patrols forever, 0 makes a single pass
+	switch {
+	case b.config.LoopCount < 0:
+		platform.RoutePlan.MaxLaps = 0
+	case b.config.LoopCount == 0:
+		platform.RoutePlan.MaxLaps = 1
+	default:
+		platform.RoutePlan.MaxLaps = b.config.LoopCount
+	}
+	b.started = true
+}
+
+// Reset restarts the patrol from its first point
+func (b *patrolBehavior) Reset(platform *models.UniversalPlatform) {
+	b.started = false
+	platform.RoutePlan = nil
+}
+
+// patrolRoute expands the configured pattern into route waypoints
+func (b *patrolBehavior) patrolRoute(pe *PhysicsEngine) ([]models.Position, models.RouteMode) {
+	points := make([]models.Position, 0, len(b.config.Points))
+	for _, point := range b.config.Points {
+		points = append(points, models.Position{
+			Latitude:  point.Latitude,
+			Longitude: point.Longitude,
+			Altitude:  point.Altitude,
+		})
+	}
+
+	switch b.config.Pattern {
+	case "box":
+		if len(points) == 2 {
+			// Two opposite corners define an axis-aligned box
+			a, c := points[0], points[1]
+			points = []models.Position{
+				a,
+				{Latitude: a.Latitude, Longitude: c.Longitude, Altitude: a.Altitude},
+				c,
+				{Latitude: c.Latitude, Longitude: a.Longitude, Altitude: c.Altitude},
+			}
+		}
+		return append(points, points[0]), models.RouteModeLoop
+	case "circle":
+		center, edge := points[0], points[1]
+		radius := horizontalDistance(pe, center, edge)
+		startBearing := pe.CalculateBearing(center, edge)
+
+		circle := make([]models.Position, 0, circlePatrolPoints+1)
+		for i := 0; i < circlePatrolPoints; i++ {
+			bearing := startBearing + float64(i)*360.0/circlePatrolPoints
+			waypoint := pe.CalculateDestinationPoint(center, bearing, radius)
+			waypoint.Altitude = edge.Altitude
+			circle = append(circle, waypoint)
+		}
+		return append(circle, circle[0]), models.RouteModeLoop
+	default:
+		// Line patrols run back and forth along the points
+		return points, models.RouteModeReverse
+	}
+}
+
+// circuitBehavior flies clockwise around a center point at a fixed radius
+type circuitBehavior struct {
+	config config.CircuitBehavior
+}
+
+// Name returns the behavior pattern name
+func (b *circuitBehavior) Name() string {
+	return "circuit"
+}
+
+// Update steers toward a point on the circle just ahead of the platform
+func (b *circuitBehavior) Update(platform *models.UniversalPlatform, pe *PhysicsEngine) {
+	center := models.Position{
+		Latitude:  b.config.Center.Latitude,
+		Longitude: b.config.Center.Longitude,
+		Altitude:  b.config.Center.Altitude,
+	}
+
+	// Lead far enough ahead that the target is never reached
+	leadAngle := circuitLeadAngle
+	threshold := pe.getArrivalThreshold(platform.PlatformType)
+	if ratio := threshold / b.config.Radius; ratio < 1 {
+		leadAngle = math.Max(leadAngle, 4*math.Asin(ratio)*180.0/math.Pi)
+	}
+	leadAngle = math.Min(leadAngle, 90)
+
+	bearing := pe.CalculateBearing(center, platform.State.Position)
+	target := pe.CalculateDestinationPoint(center, bearing+leadAngle, b.config.Radius)
+	if platform.PlatformType == models.PlatformTypeMaritime {
+		target.Altitude = 0
+	}
+
+	steerToBehaviorTarget(platform, target, b.config.Speed)
+}
+
+// Reset clears the circuit target
+func (b *circuitBehavior) Reset(platform *models.UniversalPlatform) {
+	platform.RoutePlan = nil
+}
+
+// randomWalkBehavior wanders between random points inside a bounding box
+type randomWalkBehavior struct {
+	config config.RandomWalkBehavior
+	seed   int64 // Restores rng on Reset so a reset run picks the same targets
+	rng    *rand.Rand
+}
+
+// Name returns the behavior pattern name
+func (b *randomWalkBehavior) Name() string {
+	return "random_walk"
+}
+
+// Update picks a new random target once the previous one has been reached
+func (b *randomWalkBehavior) Update(platform *models.UniversalPlatform, pe *PhysicsEngine) {
+	if platform.RoutePlan != nil && !platform.RoutePlan.Completed && platform.Destination != nil {
+		return
+	}
+
+	steerToBehaviorTarget(platform, b.nextTarget(platform, pe), b.config.Speed)
+}
+
+// Reset clears the current random target and restarts the target sequence
+func (b *randomWalkBehavior) Reset(platform *models.UniversalPlatform) {
+	platform.RoutePlan = nil
+	b.rng = rand.New(rand.NewSource(b.seed))
+}
+
+// nextTarget picks a random point inside the area, within MaxDistance of the current position when set.
+// Targets closer than the platform's turning diameter are avoided because they can be circled forever.
+func (b *randomWalkBehavior) nextTarget(platform *models.UniversalPlatform, pe *PhysicsEngine) models.Position {
+	area := b.config.Area
+	from := platform.State.Position
+	minDistance := 2 * turningRadius(platform, pe)
+	if b.config.MaxDistance > 0 {
+		minDistance = math.Min(minDistance, b.config.MaxDistance)
+	}
+	stepWithinArea := b.config.MaxDistance > 0 && insideArea(from, area)
+
+	// Retry a few times before settling for the last candidate, clamped to the area
+	var target models.Position
+	for attempt := 0; attempt < 10; attempt++ {
+		if stepWithinArea {
+			bearing := b.rng.Float64() * 360.0
+			distance := minDistance + b.rng.Float64()*(b.config.MaxDistance-minDistance)
+			target = pe.CalculateDestinationPoint(from, bearing, distance)
+		} else {
+			target = models.Position{
+				Latitude:  area.South + b.rng.Float64()*(area.North-area.South),
+				Longitude: area.West + b.rng.Float64()*(area.East-area.West),
+				Altitude:  from.Altitude,
+			}
+		}
+
+		if insideArea(target, area) && horizontalDistance(pe, from, target) >= minDistance {
+			return target
+		}
+	}
+
+	target.Latitude = math.Max(area.South, math.Min(area.North, target.Latitude))
+	target.Longitude = math.Max(area.West, math.Min(area.East, target.Longitude))
+	return target
+}
+
+// steerToBehaviorTarget makes target the platform's single-waypoint route at the given speed
+func steerToBehaviorTarget(platform *models.UniversalPlatform, target models.Position, speed float64) {
+	if platform.RoutePlan == nil || len(platform.Route) != 1 || len(platform.RoutePlan.Speeds) != 1 {
+		if err := platform.SetRoute([]models.Position{target}, models.RouteModeHold, []float64{speed}); err != nil {
+			logSimulationError("behavior target", err, platform.ID)
+		}
+		return
+	}
+
+	platform.Route[0] = target
+	platform.RoutePlan.Speeds[0] = speed
+	platform.RoutePlan.CurrentIndex = 0
+	platform.RoutePlan.Completed = false
+	platform.Destination = &target
+}
+
+// insideArea reports whether a position lies within a bounding box
+func insideArea(pos models.Position, area config.BoundingBox) bool {
+	return pos.Latitude >= area.South && pos.Latitude <= area.North &&
+		pos.Longitude >= area.West && pos.Longitude <= area.East
+}
+
+// horizontalDistance returns the great-circle distance between two positions ignoring altitude
+func horizontalDistance(pe *PhysicsEngine, from, to models.Position) float64 {
+	to.Altitude = from.Altitude
+	return pe.CalculateGreatCircleDistance(from, to)
+}
+
+// turningRadius returns the platform's turning radius, using the same defaults as the physics engine
+func turningRadius(platform *models.UniversalPlatform, pe *PhysicsEngine) float64 {
+	performance := platform.TypeDef.Performance
+	if performance.TurningRadius > 0 {
+		return performance.TurningRadius
+	}
+
+	switch platform.PlatformType {
+	case models.PlatformTypeAirborne:
+		bankAngle := 30.0 * math.Pi / 180.0
+		return (performance.CruiseSpeed * performance.CruiseSpeed) / (pe.GravityAccel * math.Tan(bankAngle))
+	case models.PlatformTypeMaritime:
+		return platform.TypeDef.Physical.Length * 6
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,265 @@
+package sim
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/rhino11/trafficsim/internal/config"
+	"github.com/rhino11/trafficsim/internal/models"
+)
+
+// runBehavior advances a platform under a behavior for the given number of one-second steps
+func runBehavior(t *testing.T, pe *PhysicsEngine, behavior Behavior, platform *models.UniversalPlatform, steps int, check func(step int)) {
+	t.Helper()
+
+	for step := 0; step < steps; step++ {
+		behavior.Update(platform, pe)
+		if err := pe.CalculateMovement(platform, time.Second); err != nil {
+			t.Fatalf("Step %d: CalculateMovement failed: %v", step, err)
+		}
+		if check != nil {
+			check(step)
+		}
+	}
+}
+
+func TestCircuitBehaviorStaysWithinRadius(t *testing.T) {
+	pe := NewPhysicsEngine()
+	center := config.Position{Latitude: 40.0, Longitude: -74.0, Altitude: 3000}
+
+	tests := []struct {
+		name     string
+		platform *models.UniversalPlatform
+		radius   float64
+	}{
+		{
+			name:     "aircraft",
+			platform: models.NewBoeing737_800Universal("CIRCUIT-AIR", "Circuit 1", models.Position{}),
+			radius:   20000,
+		},
+		{
+			name:     "vessel",
+			platform: models.NewArleighBurkeDestroyerUniversal("CIRCUIT-SEA", "Circuit", models.Position{}),
+			radius:   3000,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			behavior, err := NewBehavior(&config.BehaviorConfig{
+				CircuitFlight: &config.CircuitBehavior{Center: center, Radius: tt.radius},
+			}, nil)
+			if err != nil {
+				t.Fatalf("NewBehavior failed: %v", err)
+			}
+
+			// Start on the circle heading clockwise along it
+			centerPos := models.Position{Latitude: center.Latitude, Longitude: center.Longitude, Altitude: center.Altitude}
+			tt.platform.State.Position = pe.CalculateDestinationPoint(centerPos, 0, tt.radius)
+			tt.platform.State.Heading = 90
+			tt.platform.State.Speed = tt.platform.TypeDef.Performance.CruiseSpeed
+
+			previousBearing := 0.0
+			traversed := 0.0
+			maxDistance := 0.0
+
+			// Six hours of simulated time
+			runBehavior(t, pe, behavior, tt.platform, 6*3600, func(step int) {
+				distance := horizontalDistance(pe, centerPos, tt.platform.State.Position)
+				maxDistance = math.Max(maxDistance, distance)
+
+				bearing := pe.CalculateBearing(centerPos, tt.platform.State.Position)
+				traversed += math.Mod(bearing-previousBearing+540, 360) - 180
+				previousBearing = bearing
+			})
+
+			if maxDistance > tt.radius*1.05 {
+				t.Errorf("Platform left the circuit: max distance %.0fm for radius %.0fm", maxDistance, tt.radius)
+			}
+			if traversed < 720 {
+				t.Errorf("Expected at least two clockwise laps, traversed %.0f degrees", traversed)
+			}
+		})
+	}
+}
+
+func TestPatrolBehaviorLoopCount(t *testing.T) {
+	pe := NewPhysicsEngine()
+	points := []config.Position{
+		{Latitude: 36.80, Longitude: -76.30},
+		{Latitude: 36.82, Longitude: -76.30},
+	}
+
+	t.Run("finite", func(t *testing.T) {
+		behavior, err := NewBehavior(&config.BehaviorConfig{
+			Patrol: &config.PatrolBehavior{Pattern: "line", Points: points, LoopCount: 2},
+		}, nil)
+		if err != nil {
+			t.Fatalf("NewBehavior failed: %v", err)
+		}
+
+		platform := createRouteTestPlatform(t, models.PlatformTypeMaritime, models.RouteModeOnce)
+		platform.RoutePlan = nil
+		runBehavior(t, pe, behavior, platform, 4*3600, nil)
+
+		if platform.RoutePlan.LapsCompleted != 2 || !platform.RoutePlan.Completed {
+			t.Errorf("Expected patrol to complete after 2 laps, got %d laps (completed=%v)",
+				platform.RoutePlan.LapsCompleted, platform.RoutePlan.Completed)
+		}
+		if distance := horizontalDistance(pe, platform.State.Position, platform.Route[0]); distance > 50 {
+			t.Errorf("Expected platform to hold at the first patrol point, %.0fm away", distance)
+		}
+	})
+
+	t.Run("infinite", func(t *testing.T) {
+		behavior, err := NewBehavior(&config.BehaviorConfig{
+			Patrol: &config.PatrolBehavior{Pattern: "line", Points: points, LoopCount: -1},
+		}, nil)
+		if err != nil {
+			t.Fatalf("NewBehavior failed: %v", err)
+		}
+
+		platform := createRouteTestPlatform(t, models.PlatformTypeMaritime, models.RouteModeOnce)
+		platform.RoutePlan = nil
+		runBehavior(t, pe, behavior, platform, 4*3600, nil)
+
+		if platform.RoutePlan.Completed {
+			t.Error("Infinite patrol should never complete")
+		}
+		if platform.RoutePlan.LapsCompleted < 3 {
+			t.Errorf("Expected infinite patrol to keep lapping, got %d laps", platform.RoutePlan.LapsCompleted)
+		}
+	})
+}
+
+func TestPatrolBehaviorPatterns(t *testing.T) {
+	pe := NewPhysicsEngine()
+	platform := createRouteTestPlatform(t, models.PlatformTypeMaritime, models.RouteModeOnce)
+
+	box, err := NewBehavior(&config.BehaviorConfig{
+		Patrol: &config.PatrolBehavior{
+			Pattern: "box",
+			Points: []config.Position{
+				{Latitude: 36.80, Longitude: -76.30},
+				{Latitude: 36.90, Longitude: -76.20},
+			},
+		},
+	}, nil)
+	if err != nil {
+		t.Fatalf("NewBehavior failed: %v", err)
+	}
+	box.Update(platform, pe)
+
+	if len(platform.Route) != 5 || platform.RoutePlan.Mode != models.RouteModeLoop {
+		t.Fatalf("Expected closed 4-corner loop, got %d waypoints in %s mode", len(platform.Route), platform.RoutePlan.Mode)
+	}
+	if platform.Route[1].Latitude != 36.80 || platform.Route[1].Longitude != -76.20 {
+		t.Errorf("Unexpected second corner: %+v", platform.Route[1])
+	}
+
+	circle, err := NewBehavior(&config.BehaviorConfig{
+		Patrol: &config.PatrolBehavior{
+			Pattern: "circle",
+			Points: []config.Position{
+				{Latitude: 36.80, Longitude: -76.30},
+				{Latitude: 36.85, Longitude: -76.30},
+			},
+		},
+	}, nil)
+	if err != nil {
+		t.Fatalf("NewBehavior failed: %v", err)
+	}
+	circle.Update(platform, pe)
+
+	center := models.Position{Latitude: 36.80, Longitude: -76.30}
+	radius := horizontalDistance(pe, center, models.Position{Latitude: 36.85, Longitude: -76.30})
+	for i, waypoint := range platform.Route {
+		if diff := math.Abs(horizontalDistance(pe, center, waypoint) - radius); diff > 1 {
+			t.Errorf("Waypoint %d is %.1fm off the circle", i, diff)
+		}
+	}
+}
+
+func TestRandomWalkBehaviorStaysInArea(t *testing.T) {
+	pe := NewPhysicsEngine()
+	area := config.BoundingBox{North: 36.90, South: 36.80, East: -76.20, West: -76.30}
+
+	behavior, err := NewBehavior(&config.BehaviorConfig{
+		RandomWalk: &config.RandomWalkBehavior{Area: area, MaxDistance: 3000},
+	}, rand.New(rand.NewSource(42)))
+	if err != nil {
+		t.Fatalf("NewBehavior failed: %v", err)
+	}
+
+	platform := createRouteTestPlatform(t, models.PlatformTypeMaritime, models.RouteModeOnce)
+	platform.RoutePlan = nil
+	platform.State.Position = models.Position{Latitude: 36.85, Longitude: -76.25}
+
+	targets := make(map[models.Position]bool)
+	runBehavior(t, pe, behavior, platform, 6*3600, func(step int) {
+		if platform.Destination != nil {
+			targets[*platform.Destination] = true
+			if !insideArea(*platform.Destination, area) {
+				t.Fatalf("Step %d: target %+v outside area", step, *platform.Destination)
+			}
+		}
+	})
+
+	if len(targets) < 5 {
+		t.Errorf("Expected the walk to pick several targets, got %d", len(targets))
+	}
+
+	if _, err := NewBehavior(&config.BehaviorConfig{
+		RandomWalk: &config.RandomWalkBehavior{Area: area},
+	}, nil); err == nil {
+		t.Error("Expected error for random walk without a random source")
+	}
+}
+
+func TestEngineScenarioBehaviors(t *testing.T) {
+	cfg := createScenarioTestConfig()
+	bravo := cfg.Platforms.Scenarios["bravo"]
+	bravo.Instances[0].Behavior = &config.BehaviorConfig{
+		CircuitFlight: &config.CircuitBehavior{
+			Center: config.Position{Latitude: 36.8, Longitude: -76.2},
+			Radius: 5000,
+		},
+	}
+	engine := NewEngine(cfg)
+
+	if err := engine.LoadScenario("bravo"); err != nil {
+		t.Fatalf("LoadScenario failed: %v", err)
+	}
+	if err := engine.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer engine.Stop()
+
+	if err := engine.Update(time.Second); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+
+	status, err := engine.GetPlatformStatus("DDG51")
+	if err != nil {
+		t.Fatalf("GetPlatformStatus failed: %v", err)
+	}
+	if status.Behavior != "circuit" || status.Destination == nil {
+		t.Errorf("Expected circuit behavior to set a destination, got behavior %q", status.Behavior)
+	}
+
+	// A direct destination takes the platform off its behavior
+	if err := engine.SetDestinationForPlatform("DDG51", models.Position{Latitude: 37.0, Longitude: -76.0}); err != nil {
+		t.Fatalf("SetDestinationForPlatform failed: %v", err)
+	}
+	status, _ = engine.GetPlatformStatus("DDG51")
+	if status.Behavior != "" {
+		t.Errorf("Expected behavior to be cleared, got %q", status.Behavior)
+	}
+
+	invalid := &config.BehaviorConfig{CircuitFlight: &config.CircuitBehavior{}}
+	if err := engine.SetBehaviorForPlatform("DDG51", invalid); err == nil {
+		t.Error("Expected error for circuit without a radius")
+	}
+}
@@ -3,6 +3,8 @@ package sim
 import (
 	"fmt"
 	"log"
+	"math"
+	"math/rand"
 	"os"
 	"strings"
 	"sync"
@@ -10,6 +12,7 @@ import (
 
 	"github.com/rhino11/trafficsim/internal/config"
 	"github.com/rhino11/trafficsim/internal/models"
+	"github.com/rhino11/trafficsim/internal/terrain"
 )
 
 // isTestMode checks if we're running in test mode
@@ -56,14 +59,38 @@ type Engine struct {
 	config         *config.Config
 	physics        *PhysicsEngine
 	platforms      map[string]models.Platform
+	behaviors      map[string]Behavior // Keyed by platform ID, guarded by platformsMux
 	platformsMux   sync.RWMutex
 	isRunning      bool
 	runningMux     sync.RWMutex
 	updateTicker   *time.Ticker
 	stopCh         chan struct{}
 	simulationTime float64
+	timeScale      float64       // Simulation seconds per wall-clock second, guarded by timeMux
+	paused         bool          // Guarded by timeMux
+	startTime      time.Time     // Simulated date and time at t=0, guarded by timeMux
+	startTimeSet   bool          // startTime came from configuration or a scenario, guarded by timeMux
+	maxDuration    time.Duration // Longest RunFor may advance simulation time; zero is unlimited, guarded by timeMux
 	timeMux        sync.RWMutex
 	updateInterval time.Duration
+	scenarioName   string
+	rng            *rand.Rand // Engine-owned random source, guarded by rngMux
+	seed           int64
+	rngMux         sync.Mutex
+
+	// Scenario timing, guarded by platformsMux
+	roster           []rosterEntry
+	schedule         eventSchedule
+	despawnOnArrival map[string]bool
+
+	eventHandlers    []PlatformEventHandler
+	eventHandlersMux sync.RWMutex
+
+	// Parallel platform updates; stepMux serializes steps and guards the fields below. Anything
+	// that changes platforms a step may be moving, other than its State, holds it too.
+	stepMux   sync.Mutex
+	workers   int
+	updateBuf []platformUpdate
 
 	// Performance tracking
 	updateCount     int64
@@ -71,6 +98,22 @@ type Engine struct {
 	lastPerfLog     time.Time
 }
 
+// maxPhysicsStep is the largest time step passed to Update; accelerated ticks are split into steps this size
+const maxPhysicsStep = time.Second
+
+// SeededStartTime is the simulated date and time at t=0 of seeded runs without a start time, the
+// J2000 epoch, so their output does not depend on when they are run
+var SeededStartTime = time.Date(2000, time.January, 1, 12, 0, 0, 0, time.UTC)
+
+// rosterEntry is a scenario platform with its spawn timing, kept so Reset can replay the schedule
+type rosterEntry struct {
+	platform         models.Platform
+	behavior         Behavior
+	spawnTime        float64
+	despawnTime      float64
+	despawnOnArrival bool
+}
+
 // NewEngine creates a new simulation engine
 func NewEngine(cfg *config.Config) *Engine {
 	updateInterval := time.Second / 60 // 60 FPS default
@@ -80,13 +123,68 @@ func NewEngine(cfg *config.Config) *Engine {
 		}
 	}
 
-	return &Engine{
-		config:         cfg,
-		physics:        NewPhysicsEngine(),
-		platforms:      make(map[string]models.Platform),
-		stopCh:         make(chan struct{}),
-		updateInterval: updateInterval,
+	timeScale := 1.0
+	if cfg != nil && cfg.Simulation.TimeScale > 0 {
+		timeScale = cfg.Simulation.TimeScale
+	}
+
+	workers := defaultWorkers()
+	if cfg != nil && cfg.Simulation.Workers > 0 {
+		workers = cfg.Simulation.Workers
 	}
+
+	seed := time.Now().UnixNano()
+	if cfg != nil && cfg.Simulation.Seed != 0 {
+		seed = cfg.Simulation.Seed
+	}
+
+	startTime := time.Now().UTC().Truncate(time.Second)
+	startTimeSet := false
+	var maxDuration time.Duration
+	if cfg != nil {
+		if parsed, err := cfg.Simulation.ParseStartTime(); err != nil {
+			logf("Ignoring start time: %v", err)
+		} else if !parsed.IsZero() {
+			startTime, startTimeSet = parsed, true
+		}
+		if parsed, err := cfg.Simulation.ParseMaxDuration(); err != nil {
+			logf("Ignoring max duration: %v", err)
+		} else {
+			maxDuration = parsed
+		}
+		if !startTimeSet && cfg.Simulation.Seed != 0 {
+			startTime = SeededStartTime
+		}
+	}
+
+	engine := &Engine{
+		config:           cfg,
+		physics:          NewPhysicsEngine(),
+		platforms:        make(map[string]models.Platform),
+		behaviors:        make(map[string]Behavior),
+		despawnOnArrival: make(map[string]bool),
+		stopCh:           make(chan struct{}),
+		rng:              rand.New(rand.NewSource(seed)),
+		seed:             seed,
+		updateInterval:   updateInterval,
+		timeScale:        timeScale,
+		startTime:        startTime,
+		startTimeSet:     startTimeSet,
+		maxDuration:      maxDuration,
+		workers:          workers,
+	}
+	engine.physics.Clock = engine
+	if cfg != nil {
+		if cfg.Simulation.TerrainClearance > 0 {
+			engine.physics.TerrainClearance = cfg.Simulation.TerrainClearance
+		}
+		if cfg.Simulation.TerrainDir != "" {
+			if err := engine.LoadTerrain(cfg.Simulation.TerrainDir); err != nil {
+				logf("Ignoring terrain: %v", err)
+			}
+		}
+	}
+	return engine
 }
 
 // Start begins the simulation loop
@@ -134,23 +232,23 @@ func (e *Engine) Reset() error {
 		e.Stop()
 	}
 
+	e.stepMux.Lock()
 	e.timeMux.Lock()
 	e.simulationTime = 0
+	startTime := e.startTime
 	e.timeMux.Unlock()
 
-	// Reset all platforms to their initial positions
+	// Reset all platforms to their initial positions and replay the scenario spawn schedule
 	e.platformsMux.Lock()
-	for _, platform := range e.platforms {
-		if universalPlatform, ok := platform.(*models.UniversalPlatform); ok {
-			universalPlatform.State.Position = universalPlatform.Config.StartPosition
-			universalPlatform.State.Speed = 0
-			universalPlatform.State.Heading = 0
-			universalPlatform.State.Velocity = models.Velocity{}
-			universalPlatform.MissionTime = 0
-			universalPlatform.State.LastUpdated = time.Now()
-		}
+	for _, entry := range e.roster {
+		e.resetPlatform(entry.platform, entry.behavior, startTime)
+	}
+	e.scheduleRosterLocked()
+	for id, platform := range e.platforms {
+		e.resetPlatform(platform, e.behaviors[id], startTime)
 	}
 	e.platformsMux.Unlock()
+	e.stepMux.Unlock()
 
 	if wasRunning {
 		return e.Start()
@@ -160,6 +258,28 @@ func (e *Engine) Reset() error {
 	return nil
 }
 
+// resetPlatform returns a platform and its behavior to their initial state at the given clock time
+func (e *Engine) resetPlatform(platform models.Platform, behavior Behavior, now time.Time) {
+	universalPlatform, ok := platform.(*models.UniversalPlatform)
+	if !ok || universalPlatform.IsExternal() {
+		return
+	}
+
+	universalPlatform.State.Position = universalPlatform.Config.StartPosition
+	universalPlatform.State.Speed = 0
+	universalPlatform.State.Heading = 0
+	universalPlatform.State.Velocity = models.Velocity{}
+	universalPlatform.MissionTime = 0
+	universalPlatform.State.LastUpdated = now
+	universalPlatform.ResetRoute()
+	if behavior != nil {
+		behavior.Reset(universalPlatform)
+	}
+	if universalPlatform.IsOrbiting() {
+		stampOrbit(universalPlatform, now)
+	}
+}
+
 // IsRunning returns whether the simulation is currently running
 func (e *Engine) IsRunning() bool {
 	e.runningMux.RLock()
@@ -174,33 +294,151 @@ func (e *Engine) GetSimulationTime() float64 {
 	return e.simulationTime
 }
 
+// SetSeed reseeds the engine's random source. Runs with the same seed and scenario are reproducible
+// when the seed is set before the scenario is loaded; without a start time they start at
+// SeededStartTime rather than the wall clock.
+func (e *Engine) SetSeed(seed int64) {
+	e.rngMux.Lock()
+	e.seed = seed
+	e.rng = rand.New(rand.NewSource(seed))
+	e.rngMux.Unlock()
+
+	e.timeMux.Lock()
+	defer e.timeMux.Unlock()
+	if !e.startTimeSet {
+		e.startTime = SeededStartTime
+	}
+}
+
+// GetSeed returns the seed of the engine's random source
+func (e *Engine) GetSeed() int64 {
+	e.rngMux.Lock()
+	defer e.rngMux.Unlock()
+	return e.seed
+}
+
+// newRand returns a random source seeded from the engine's source. Each consumer gets its own source
+// so the values it draws do not depend on the order other consumers are updated in.
+func (e *Engine) newRand() *rand.Rand {
+	e.rngMux.Lock()
+	defer e.rngMux.Unlock()
+	return rand.New(rand.NewSource(e.rng.Int63()))
+}
+
+// Now returns the simulated date and time: the start time advanced by simulation time.
+// Engine implements models.Clock, so it can timestamp output such as CoT messages.
+func (e *Engine) Now() time.Time {
+	e.timeMux.RLock()
+	defer e.timeMux.RUnlock()
+	return e.clockAt(e.simulationTime)
+}
+
+// clockAt returns the date and time at a simulation time in seconds. timeMux must be held.
+func (e *Engine) clockAt(simTime float64) time.Time {
+	return e.startTime.Add(time.Duration(simTime * float64(time.Second)))
+}
+
+// stampPlatforms sets every loaded platform's last update time to the current clock time
+func (e *Engine) stampPlatforms() {
+	now := e.Now()
+	e.platformsMux.Lock()
+	defer e.platformsMux.Unlock()
+	for _, entry := range e.roster {
+		stampPlatform(entry.platform, now)
+	}
+	for _, platform := range e.platforms {
+		stampPlatform(platform, now)
+	}
+}
+
+// stampPlatform sets a platform's last update time, moving orbiting platforms to where they are then
+func stampPlatform(platform models.Platform, now time.Time) {
+	if universalPlatform, ok := platform.(*models.UniversalPlatform); ok {
+		universalPlatform.State.LastUpdated = now
+		if universalPlatform.IsOrbiting() {
+			stampOrbit(universalPlatform, now)
+		}
+		return
+	}
+	state := platform.GetState()
+	state.LastUpdated = now
+	platform.UpdateState(state)
+}
+
+// stampOrbit moves an orbiting platform to its position at the given clock time. A failed
+// propagation, e.g. an element set past reentry, leaves the platform where it was.
+func stampOrbit(platform *models.UniversalPlatform, now time.Time) {
+	if err := platform.UpdateOrbit(now); err != nil {
+		logf("Platform %s: %v", platform.ID, err)
+	}
+}
+
+// GetStartTime returns the simulated date and time at t=0
+func (e *Engine) GetStartTime() time.Time {
+	e.timeMux.RLock()
+	defer e.timeMux.RUnlock()
+	return e.startTime
+}
+
+// GetMaxDuration returns the longest simulation time RunFor will advance to; zero means unlimited
+func (e *Engine) GetMaxDuration() time.Duration {
+	e.timeMux.RLock()
+	defer e.timeMux.RUnlock()
+	return e.maxDuration
+}
+
 // AddPlatform adds a platform to the simulation
 func (e *Engine) AddPlatform(platform models.Platform) error {
+	now := e.Now()
 	e.platformsMux.Lock()
-	defer e.platformsMux.Unlock()
 
 	id := platform.GetID()
 	if _, exists := e.platforms[id]; exists {
+		e.platformsMux.Unlock()
 		return fmt.Errorf("platform with ID %s already exists", id)
 	}
 
+	stampPlatform(platform, now)
 	e.platforms[id] = platform
 	logPlatformOperation("ADD", id, platform)
+	added := eventPlatform(platform) // Copied before the next step can move it
+	e.platformsMux.Unlock()
+
+	e.emitPlatformEvents([]PlatformEvent{{
+		Type:           PlatformEventAdded,
+		PlatformID:     id,
+		Platform:       added,
+		Reason:         "manual",
+		SimulationTime: e.GetSimulationTime(),
+	}})
 	return nil
 }
 
 // RemovePlatform removes a platform from the simulation
 func (e *Engine) RemovePlatform(id string) error {
 	e.platformsMux.Lock()
-	defer e.platformsMux.Unlock()
-
 	if _, exists := e.platforms[id]; !exists {
+		e.platformsMux.Unlock()
 		return fmt.Errorf("platform with ID %s not found", id)
 	}
+	e.removePlatformLocked(id)
+	e.platformsMux.Unlock()
+
+	e.emitPlatformEvents([]PlatformEvent{{
+		Type:           PlatformEventRemoved,
+		PlatformID:     id,
+		Reason:         "manual",
+		SimulationTime: e.GetSimulationTime(),
+	}})
+	return nil
+}
 
+// removePlatformLocked removes a platform and its behavior; platformsMux must be held
+func (e *Engine) removePlatformLocked(id string) {
 	delete(e.platforms, id)
+	delete(e.behaviors, id)
+	delete(e.despawnOnArrival, id)
 	logPlatformOperation("REMOVE", id, nil)
-	return nil
 }
 
 // GetPlatform returns a platform by ID
@@ -229,18 +467,457 @@ func (e *Engine) GetAllPlatforms() []models.Platform {
 	return platforms
 }
 
+// SnapshotPlatforms returns copies of the platforms taken between steps, so other goroutines can
+// read them while the simulation moves on. Platforms other than UniversalPlatform are returned as is,
+// and a copy's type definition, configuration and orbit are shared with the live platform, so they
+// must only be read.
+func (e *Engine) SnapshotPlatforms() []models.Platform {
+	e.stepMux.Lock()
+	defer e.stepMux.Unlock()
+	e.platformsMux.RLock()
+	defer e.platformsMux.RUnlock()
+
+	platforms := make([]models.Platform, 0, len(e.platforms))
+	for _, platform := range e.platforms {
+		if up, ok := platform.(*models.UniversalPlatform); ok {
+			platform = snapshotPlatform(up)
+		}
+		platforms = append(platforms, platform)
+	}
+	return platforms
+}
+
+// snapshotPlatform copies a platform, with its own route and destination for behaviors to steer
+// while the copy is read
+func snapshotPlatform(up *models.UniversalPlatform) *models.UniversalPlatform {
+	snapshot := *up
+	snapshot.Route = append([]models.Position(nil), up.Route...)
+	if up.RoutePlan != nil {
+		plan := *up.RoutePlan
+		plan.Speeds = append([]float64(nil), up.RoutePlan.Speeds...)
+		snapshot.RoutePlan = &plan
+	}
+	if up.Destination != nil {
+		destination := *up.Destination
+		snapshot.Destination = &destination
+	}
+	return &snapshot
+}
+
+// SetScenario selects the configured scenario used by LoadPlatformsFromConfig
+func (e *Engine) SetScenario(name string) {
+	e.platformsMux.Lock()
+	defer e.platformsMux.Unlock()
+	e.scenarioName = name
+}
+
+// GetScenario returns the name of the active scenario (empty when none is selected)
+func (e *Engine) GetScenario() string {
+	e.platformsMux.RLock()
+	defer e.platformsMux.RUnlock()
+	return e.scenarioName
+}
+
+// GetAvailableScenarios returns the names of all scenarios defined in the configuration
+func (e *Engine) GetAvailableScenarios() []string {
+	if e.config == nil {
+		return []string{}
+	}
+	return e.config.Platforms.ScenarioNames()
+}
+
 // LoadPlatformsFromConfig loads platforms from configuration
 func (e *Engine) LoadPlatformsFromConfig() error {
 	if e.config == nil {
 		return fmt.Errorf("no configuration provided")
 	}
 
-	// This would load platforms from the configuration
-	// For now, we'll create some example platforms
-	if err := e.createExamplePlatforms(); err != nil {
-		return fmt.Errorf("failed to create example platforms: %w", err)
+	scenarioName := e.resolveScenarioName()
+	if scenarioName == "" {
+		// No scenarios configured, fall back to the built-in example platforms
+		if err := e.createExamplePlatforms(); err != nil {
+			return fmt.Errorf("failed to create example platforms: %w", err)
+		}
+		return nil
+	}
+
+	return e.LoadScenario(scenarioName)
+}
+
+// resolveScenarioName picks the scenario to load: an explicit selection first,
+// then the configured default, then the first scenario by name
+func (e *Engine) resolveScenarioName() string {
+	if selected := e.GetScenario(); selected != "" {
+		return selected
+	}
+	if e.config.Simulation.Scenario != "" {
+		return e.config.Simulation.Scenario
+	}
+	if names := e.config.Platforms.ScenarioNames(); len(names) > 0 {
+		return names[0]
+	}
+	return ""
+}
+
+// LoadScenario replaces all platforms with the instances of a configured scenario
+func (e *Engine) LoadScenario(name string) error {
+	if e.config == nil {
+		return fmt.Errorf("no configuration provided")
+	}
+
+	factory := config.NewPlatformFactory(&e.config.Platforms)
+	factory.SetRand(e.newRand())
+	instances, err := factory.ScenarioInstances(name)
+	if err != nil {
+		return fmt.Errorf("failed to load scenario %s: %w", name, err)
+	}
+	platforms, err := factory.CreateInstances(instances)
+	if err != nil {
+		return fmt.Errorf("failed to load scenario %s: %w", name, err)
+	}
+
+	if err := e.replacePlatforms(name, platforms, instances); err != nil {
+		return err
+	}
+	e.SetEnvironment(nil)
+	return nil
+}
+
+// LoadScenarioFile replaces all platforms with those of a data/configs scenario file
+func (e *Engine) LoadScenarioFile(path string) error {
+	scenario, err := config.NewScenarioLoader(config.PlatformsDirFor(path)).Load(path)
+	if err != nil {
+		return fmt.Errorf("failed to load scenario file %s: %w", path, err)
+	}
+	return e.LoadScenarioDefinition(scenario)
+}
+
+// LoadScenarioDefinition replaces all platforms with those of a resolved scenario file
+func (e *Engine) LoadScenarioDefinition(scenario *config.Scenario) error {
+	platforms, err := scenario.CreatePlatforms()
+	if err != nil {
+		return fmt.Errorf("failed to load scenario %s: %w", scenario.Metadata.Name, err)
+	}
+	// Scenario files may set their own start time, length and time acceleration
+	startTime, err := scenario.Metadata.ParseStartTime()
+	if err != nil {
+		return err
+	}
+	environmentStart := startTime
+	if environmentStart.IsZero() {
+		environmentStart = e.GetStartTime()
+	}
+	environment, err := NewEnvironment(scenario.Environment, scenario.Weather, environmentStart)
+	if err != nil {
+		return fmt.Errorf("failed to load scenario %s: %w", scenario.Metadata.Name, err)
+	}
+
+	// The clock is set before the platforms are placed so orbits without an epoch start from it
+	e.timeMux.Lock()
+	previousStart, previousSet, previousDuration := e.startTime, e.startTimeSet, e.maxDuration
+	if !startTime.IsZero() {
+		e.startTime, e.startTimeSet = startTime, true
+	}
+	if scenario.Metadata.Duration > 0 {
+		e.maxDuration = time.Duration(scenario.Metadata.Duration * float64(time.Second))
+	}
+	e.timeMux.Unlock()
+	if err := e.replacePlatforms(scenario.Metadata.Name, platforms, scenario.Instances()); err != nil {
+		e.timeMux.Lock()
+		e.startTime, e.startTimeSet, e.maxDuration = previousStart, previousSet, previousDuration
+		e.timeMux.Unlock()
+		return err
+	}
+	e.SetEnvironment(environment)
+
+	if scenario.Metadata.TimeAcceleration > 0 {
+		return e.SetTimeScale(scenario.Metadata.TimeAcceleration)
+	}
+	return nil
+}
+
+// SetEnvironment sets the wind and current that carry aircraft and ships; nil is calm
+func (e *Engine) SetEnvironment(environment *Environment) {
+	e.stepMux.Lock()
+	defer e.stepMux.Unlock()
+	e.physics.Environment = environment
+}
+
+// GetEnvironment returns the wind and current fields, nil when calm
+func (e *Engine) GetEnvironment() *Environment {
+	e.stepMux.Lock()
+	defer e.stepMux.Unlock()
+	return e.physics.Environment
+}
+
+// LoadTerrain reads the elevation tiles in a directory for land platforms and aircraft to follow.
+// Platforms where no tile covers the ground move over flat ground.
+func (e *Engine) LoadTerrain(dir string) error {
+	model, err := terrain.Open(dir)
+	if err != nil {
+		return err
+	}
+	if model.Tiles() == 0 {
+		logf("No terrain tiles in %s, the ground is flat", dir)
+	} else {
+		logf("Loaded %d terrain tiles from %s", model.Tiles(), dir)
+	}
+	e.SetTerrain(model)
+	return nil
+}
+
+// SetTerrain sets the ground elevation model; nil is flat
+func (e *Engine) SetTerrain(model *terrain.Model) {
+	e.stepMux.Lock()
+	defer e.stepMux.Unlock()
+	e.physics.Terrain = model
+}
+
+// GetTerrain returns the ground elevation model, nil when the ground is flat
+func (e *Engine) GetTerrain() *terrain.Model {
+	e.stepMux.Lock()
+	defer e.stepMux.Unlock()
+	return e.physics.Terrain
+}
+
+// groundElevation returns the ground height under a position from the terrain model
+func (e *Engine) groundElevation(position models.Position) (float64, bool) {
+	e.stepMux.Lock()
+	physics := *e.physics
+	e.stepMux.Unlock()
+	return physics.GroundElevation(position)
+}
+
+// replacePlatforms swaps in a new set of platforms and the behaviors configured on their instances
+func (e *Engine) replacePlatforms(name string, platforms []models.Platform, instances []config.PlatformInstance) error {
+	loaded := make(map[string]models.Platform, len(platforms))
+	for _, platform := range platforms {
+		id := platform.GetID()
+		if _, exists := loaded[id]; exists {
+			return fmt.Errorf("scenario %s contains duplicate platform ID %s", name, id)
+		}
+		loaded[id] = platform
+	}
+
+	behaviors, err := e.createBehaviors(name, loaded, instances)
+	if err != nil {
+		return err
+	}
+
+	timing := make(map[string]config.PlatformInstance, len(instances))
+	for _, instance := range instances {
+		timing[instance.ID] = instance
+	}
+
+	roster := make([]rosterEntry, 0, len(platforms))
+	for _, platform := range platforms {
+		instance := timing[platform.GetID()]
+		roster = append(roster, rosterEntry{
+			platform:         platform,
+			behavior:         behaviors[platform.GetID()],
+			spawnTime:        instance.SpawnTime,
+			despawnTime:      instance.DespawnTime,
+			despawnOnArrival: instance.DespawnOnArrival,
+		})
+	}
+
+	e.stepMux.Lock()
+	defer e.stepMux.Unlock()
+	e.platformsMux.Lock()
+	external := make(map[string]models.Platform)
+	for id, platform := range e.platforms {
+		if _, replaced := loaded[id]; !replaced && models.IsExternalPlatform(platform) {
+			external[id] = platform // Live tracks outlast scenario changes
+		}
+	}
+	e.platforms = make(map[string]models.Platform, len(platforms)+len(external))
+	for id, platform := range external {
+		e.platforms[id] = platform
+	}
+	e.behaviors = make(map[string]Behavior, len(behaviors))
+	e.roster = roster
+	e.scheduleRosterLocked()
+	e.scenarioName = name
+	e.platformsMux.Unlock()
+
+	e.timeMux.Lock()
+	e.simulationTime = 0
+	e.timeMux.Unlock()
+	e.stampPlatforms()
+
+	logPlatformOperation("LOAD_SCENARIO", name, len(loaded))
+	return nil
+}
+
+// scheduleRosterLocked puts the scenario roster back at t=0: platforms without a spawn time are added
+// and later spawns and despawns are queued. platformsMux must be held.
+func (e *Engine) scheduleRosterLocked() {
+	e.schedule = eventSchedule{}
+	e.despawnOnArrival = make(map[string]bool)
+
+	for _, entry := range e.roster {
+		id := entry.platform.GetID()
+		if entry.spawnTime > 0 {
+			delete(e.platforms, id)
+			delete(e.behaviors, id)
+			e.schedule.add(&scheduledEvent{
+				time:     entry.spawnTime,
+				action:   scheduledSpawn,
+				id:       id,
+				platform: entry.platform,
+				behavior: entry.behavior,
+			})
+		} else {
+			e.platforms[id] = entry.platform
+			if entry.behavior != nil {
+				e.behaviors[id] = entry.behavior
+			}
+		}
+
+		if entry.despawnTime > 0 {
+			e.schedule.add(&scheduledEvent{time: entry.despawnTime, action: scheduledDespawn, id: id})
+		}
+		if entry.despawnOnArrival {
+			e.despawnOnArrival[id] = true
+		}
+	}
+}
+
+// updateSchedule removes platforms that have arrived and fires the spawns and despawns due by simTime
+func (e *Engine) updateSchedule(simTime float64, now time.Time) []PlatformEvent {
+	e.platformsMux.Lock()
+	defer e.platformsMux.Unlock()
+
+	var events []PlatformEvent
+	for id := range e.despawnOnArrival {
+		universalPlatform, ok := e.platforms[id].(*models.UniversalPlatform)
+		if !ok || !hasArrived(universalPlatform) {
+			continue
+		}
+		e.removePlatformLocked(id)
+		events = append(events, PlatformEvent{
+			Type: PlatformEventRemoved, PlatformID: id, Reason: "arrival", SimulationTime: simTime,
+		})
+	}
+
+	for _, event := range e.schedule.popDue(simTime) {
+		switch event.action {
+		case scheduledSpawn:
+			if _, exists := e.platforms[event.id]; exists {
+				logSimulationError("scheduled spawn", fmt.Errorf("platform already exists"), event.id)
+				continue
+			}
+			stampPlatform(event.platform, now)
+			e.platforms[event.id] = event.platform
+			if event.behavior != nil {
+				e.behaviors[event.id] = event.behavior
+			}
+			logPlatformOperation("SPAWN", event.id, simTime)
+			events = append(events, PlatformEvent{
+				Type: PlatformEventAdded, PlatformID: event.id, Platform: eventPlatform(event.platform),
+				Reason: "spawn", SimulationTime: simTime,
+			})
+		case scheduledDespawn:
+			if _, exists := e.platforms[event.id]; !exists {
+				continue
+			}
+			e.removePlatformLocked(event.id)
+			events = append(events, PlatformEvent{
+				Type: PlatformEventRemoved, PlatformID: event.id, Reason: "despawn", SimulationTime: simTime,
+			})
+		}
+	}
+
+	return events
+}
+
+// OnPlatformEvent registers a handler for platforms being added to or removed from the simulation
+func (e *Engine) OnPlatformEvent(handler PlatformEventHandler) {
+	e.eventHandlersMux.Lock()
+	defer e.eventHandlersMux.Unlock()
+	e.eventHandlers = append(e.eventHandlers, handler)
+}
+
+// eventPlatform returns the copy of a platform handed to event handlers, which may read it while
+// later steps move the live platform
+func eventPlatform(platform models.Platform) models.Platform {
+	if up, ok := platform.(*models.UniversalPlatform); ok {
+		return snapshotPlatform(up)
+	}
+	return platform
+}
+
+// emitPlatformEvents passes events to every registered handler. No engine locks may be held.
+func (e *Engine) emitPlatformEvents(events []PlatformEvent) {
+	if len(events) == 0 {
+		return
 	}
 
+	e.eventHandlersMux.RLock()
+	handlers := append([]PlatformEventHandler(nil), e.eventHandlers...)
+	e.eventHandlersMux.RUnlock()
+
+	for _, event := range events {
+		for _, handler := range handlers {
+			handler(event)
+		}
+	}
+}
+
+// createBehaviors builds the behaviors configured on scenario instances
+func (e *Engine) createBehaviors(name string, platforms map[string]models.Platform, instances []config.PlatformInstance) (map[string]Behavior, error) {
+	behaviors := make(map[string]Behavior)
+	for _, instance := range instances {
+		if instance.Behavior == nil {
+			continue
+		}
+
+		if _, ok := platforms[instance.ID].(*models.UniversalPlatform); !ok {
+			return nil, fmt.Errorf("platform %s does not support behaviors", instance.ID)
+		}
+
+		behavior, err := NewBehavior(instance.Behavior, e.newRand())
+		if err != nil {
+			return nil, fmt.Errorf("scenario %s, platform %s: invalid behavior: %w", name, instance.ID, err)
+		}
+		behaviors[instance.ID] = behavior
+	}
+	return behaviors, nil
+}
+
+// SetBehaviorForPlatform assigns a behavior pattern to a platform, or clears it when cfg is nil
+func (e *Engine) SetBehaviorForPlatform(id string, cfg *config.BehaviorConfig) error {
+	e.stepMux.Lock()
+	defer e.stepMux.Unlock()
+	e.platformsMux.Lock()
+	defer e.platformsMux.Unlock()
+
+	platform, exists := e.platforms[id]
+	if !exists {
+		return fmt.Errorf("platform with ID %s not found", id)
+	}
+	universalPlatform, ok := platform.(*models.UniversalPlatform)
+	if !ok {
+		return fmt.Errorf("platform %s does not support behaviors", id)
+	}
+	if err := checkControllable(platform); err != nil {
+		return err
+	}
+
+	if cfg == nil {
+		delete(e.behaviors, id)
+		universalPlatform.RoutePlan = nil
+		logPlatformOperation("CLEAR_BEHAVIOR", id, nil)
+		return nil
+	}
+
+	behavior, err := NewBehavior(cfg, e.newRand())
+	if err != nil {
+		return fmt.Errorf("invalid behavior for platform %s: %w", id, err)
+	}
+	behavior.Reset(universalPlatform)
+	e.behaviors[id] = behavior
+	logPlatformOperation("SET_BEHAVIOR", id, behavior.Name())
 	return nil
 }
 
@@ -294,6 +971,13 @@ func (e *Engine) createExamplePlatforms() error {
 		"1234",
 		models.Position{Latitude: 0, Longitude: 0, Altitude: 550000},
 	)
+	if elements, sized := satellite.TypeDef.Performance.OrbitElements(0, 0, 0); !sized {
+		logf("Error setting satellite orbit: type %s has no orbital altitude or period", satellite.TypeDef.Class)
+	} else if orbit, err := models.NewKeplerOrbit(elements); err != nil {
+		logf("Error setting satellite orbit: %v", err)
+	} else {
+		satellite.Orbit = orbit
+	}
 
 	if err := e.AddPlatform(satellite); err != nil {
 		return err
@@ -309,25 +993,81 @@ func (e *Engine) Update(deltaTime time.Duration) error {
 		return fmt.Errorf("simulation is not running")
 	}
 
-	e.platformsMux.RLock()
-	platforms := make([]models.Platform, 0, len(e.platforms))
-	for _, platform := range e.platforms {
-		platforms = append(platforms, platform)
+	e.step(deltaTime)
+	return nil
+}
+
+// RunFor advances the simulation by simDuration in fixed steps as fast as possible, without the
+// real-time loop. The final step is shortened to land exactly on simDuration, and the run stops early
+// once simulation time reaches the maximum duration. It returns the simulation time actually advanced.
+func (e *Engine) RunFor(simDuration, step time.Duration) (time.Duration, error) {
+	if step <= 0 {
+		return 0, fmt.Errorf("step must be positive, got %v", step)
+	}
+	if simDuration < 0 {
+		return 0, fmt.Errorf("duration cannot be negative, got %v", simDuration)
+	}
+	if e.IsRunning() {
+		return 0, fmt.Errorf("cannot run in fixed steps while the real-time simulation is running")
 	}
-	e.platformsMux.RUnlock()
 
-	// Update all platforms using physics engine
-	for _, platform := range platforms {
-		if err := e.physics.CalculateMovement(platform, deltaTime); err != nil {
-			logf("Error updating platform %s: %v", platform.GetID(), err)
+	if remaining, limited := e.remainingDuration(); limited && simDuration > remaining {
+		simDuration = remaining
+	}
+
+	var elapsed time.Duration
+	for elapsed < simDuration {
+		delta := step
+		if simDuration-elapsed < delta {
+			delta = simDuration - elapsed
 		}
+		e.step(delta)
+		elapsed += delta
 	}
+	return elapsed, nil
+}
 
-	// Update simulation time
+// remainingDuration returns the simulation time left before the maximum duration, and whether there is a limit
+func (e *Engine) remainingDuration() (time.Duration, bool) {
+	e.timeMux.RLock()
+	defer e.timeMux.RUnlock()
+
+	if e.maxDuration <= 0 {
+		return 0, false
+	}
+	remaining := e.maxDuration - time.Duration(e.simulationTime*float64(time.Second))
+	if remaining < 0 {
+		remaining = 0
+	}
+	return remaining, true
+}
+
+// step moves every platform forward by deltaTime and fires any scheduled spawns and despawns
+func (e *Engine) step(deltaTime time.Duration) {
+	// Handlers run once the step is done, so they can call back into the engine
+	e.emitPlatformEvents(e.stepLocked(deltaTime))
+}
+
+// stepLocked moves the platforms under stepMux and returns the spawns and despawns it made
+func (e *Engine) stepLocked(deltaTime time.Duration) []PlatformEvent {
+	e.stepMux.Lock()
+	defer e.stepMux.Unlock()
+
+	// Advance the clock first so moved platforms are stamped with the end of the step
 	e.timeMux.Lock()
 	e.simulationTime += deltaTime.Seconds()
+	simTime := e.simulationTime
+	now := e.clockAt(simTime)
 	e.timeMux.Unlock()
 
+	// Behaviors steer and physics moves each platform, spread across the workers
+	physics := *e.physics
+	physics.Clock = stepClock(now)
+	e.updatePlatforms(e.collectUpdates(), &physics, deltaTime)
+
+	// Spawn and despawn platforms whose time has come
+	events := e.updateSchedule(simTime, now)
+
 	// Performance tracking
 	e.updateCount++
 	e.totalUpdateTime += deltaTime
@@ -336,8 +1076,7 @@ func (e *Engine) Update(deltaTime time.Duration) error {
 		logSimulationPerformance(int(e.updateCount), avgUpdateTime, len(e.platforms))
 		e.lastPerfLog = time.Now()
 	}
-
-	return nil
+	return events
 }
 
 // simulationLoop runs the main simulation update loop
@@ -358,13 +1097,80 @@ func (e *Engine) simulationLoop() {
 			deltaTime := currentTime.Sub(lastUpdate)
 			lastUpdate = currentTime
 
-			if err := e.Update(deltaTime); err != nil {
+			if err := e.advance(deltaTime); err != nil {
 				log.Printf("Simulation update error: %v", err)
 			}
 		}
 	}
 }
 
+// advance moves the simulation forward by a wall-clock interval scaled by the time scale.
+// Simulation time is frozen while paused; large scaled intervals are split into maxPhysicsStep updates.
+func (e *Engine) advance(wallDelta time.Duration) error {
+	e.timeMux.RLock()
+	paused, scale := e.paused, e.timeScale
+	e.timeMux.RUnlock()
+
+	if paused {
+		return nil
+	}
+
+	remaining := time.Duration(float64(wallDelta) * scale)
+	for remaining > 0 {
+		step := remaining
+		if step > maxPhysicsStep {
+			step = maxPhysicsStep
+		}
+		if err := e.Update(step); err != nil {
+			return err
+		}
+		remaining -= step
+	}
+	return nil
+}
+
+// SetTimeScale sets how many simulation seconds pass per wall-clock second (e.g. 2 for double speed, 0.5 for slow motion)
+func (e *Engine) SetTimeScale(scale float64) error {
+	if scale <= 0 || math.IsInf(scale, 0) || math.IsNaN(scale) {
+		return fmt.Errorf("time scale must be positive, got %v", scale)
+	}
+
+	e.timeMux.Lock()
+	e.timeScale = scale
+	e.timeMux.Unlock()
+
+	logf("Simulation time scale set to %.2fx", scale)
+	return nil
+}
+
+// GetTimeScale returns the simulation seconds per wall-clock second
+func (e *Engine) GetTimeScale() float64 {
+	e.timeMux.RLock()
+	defer e.timeMux.RUnlock()
+	return e.timeScale
+}
+
+// Pause freezes simulation time without stopping the update loop
+func (e *Engine) Pause() {
+	e.timeMux.Lock()
+	defer e.timeMux.Unlock()
+	e.paused = true
+}
+
+// Resume continues a paused simulation
+func (e *Engine) Resume() {
+	e.timeMux.Lock()
+	defer e.timeMux.Unlock()
+	e.paused = false
+}
+
+// IsPaused returns whether simulation time is frozen
+func (e *Engine) IsPaused() bool {
+	e.timeMux.RLock()
+	defer e.timeMux.RUnlock()
+	return e.paused
+}
+
 // SetUpdateInterval changes the simulation update frequency
 func (e *Engine) SetUpdateInterval(interval time.Duration) {
 	e.updateInterval = interval
@@ -413,7 +1219,13 @@ func (e *Engine) GetStatistics() SimulationStatistics {
 		SimulationTime: e.GetSimulationTime(),
 		IsRunning:      e.IsRunning(),
 		UpdateInterval: e.updateInterval,
+		Scenario:       e.scenarioName,
+		PendingSpawns:  e.schedule.pendingSpawns(),
+		TimeScale:      e.GetTimeScale(),
+		Paused:         e.IsPaused(),
+		Workers:        e.GetWorkers(),
 	}
+	stats.ExternalPlatforms = e.countExternalLocked()
 
 	// Count by type
 	for _, platform := range e.platforms {
@@ -444,19 +1256,36 @@ type SimulationStatistics struct {
 	SimulationTime    float64       `json:"simulation_time"`
 	IsRunning         bool          `json:"is_running"`
 	UpdateInterval    time.Duration `json:"update_interval"`
+	Scenario          string        `json:"scenario,omitempty"`
+	PendingSpawns     int           `json:"pending_spawns,omitempty"` // Scenario platforms not yet spawned
+	TimeScale         float64       `json:"time_scale"`
+	Paused            bool          `json:"paused"`
+	Workers           int           `json:"workers"`                      // Goroutines sharing each update step
+	ExternalPlatforms int           `json:"external_platforms,omitempty"` // Read-only tracks from outside feeds
 }
 
 // SetDestinationForPlatform sets a destination for a specific platform
 func (e *Engine) SetDestinationForPlatform(id string, destination models.Position) error {
+	e.stepMux.Lock()
+	defer e.stepMux.Unlock()
+
 	platform, err := e.GetPlatform(id)
 	if err != nil {
 		return err
 	}
+	if err := checkControllable(platform); err != nil {
+		return err
+	}
 
 	if universalPlatform, ok := platform.(*models.UniversalPlatform); ok {
 		if err := universalPlatform.SetDestination(destination); err != nil {
 			return fmt.Errorf("failed to set destination for platform %s: %w", id, err)
 		}
+		// A direct destination overrides any route or behavior being followed
+		universalPlatform.RoutePlan = nil
+		e.platformsMux.Lock()
+		delete(e.behaviors, id)
+		e.platformsMux.Unlock()
 		logPlatformOperation("SET_DESTINATION", id, destination)
 		return nil
 	}
@@ -464,6 +1293,37 @@ func (e *Engine) SetDestinationForPlatform(id string, destination models.Positio
 	return fmt.Errorf("platform %s does not support destination setting", id)
 }
 
+// SetRouteForPlatform assigns a waypoint route to a specific platform
+func (e *Engine) SetRouteForPlatform(id string, waypoints []models.Position, mode models.RouteMode, speeds []float64) error {
+	e.stepMux.Lock()
+	defer e.stepMux.Unlock()
+
+	platform, err := e.GetPlatform(id)
+	if err != nil {
+		return err
+	}
+	if err := checkControllable(platform); err != nil {
+		return err
+	}
+
+	universalPlatform, ok := platform.(*models.UniversalPlatform)
+	if !ok {
+		return fmt.Errorf("platform %s does not support route following", id)
+	}
+
+	if err := universalPlatform.SetRoute(waypoints, mode, speeds); err != nil {
+		return fmt.Errorf("failed to set route for platform %s: %w", id, err)
+	}
+
+	// An explicit route replaces any behavior pattern
+	e.platformsMux.Lock()
+	delete(e.behaviors, id)
+	e.platformsMux.Unlock()
+
+	logPlatformOperation("SET_ROUTE", id, len(waypoints))
+	return nil
+}
+
 // GetPlatformStatus returns detailed status for a platform
 func (e *Engine) GetPlatformStatus(id string) (*PlatformStatus, error) {
 	platform, err := e.GetPlatform(id)
@@ -497,6 +1357,19 @@ func (e *Engine) GetPlatformStatus(id string) (*PlatformStatus, error) {
 		)
 	}
 
+	status.RouteProgress = e.physics.CalculateRouteProgress(universalPlatform)
+
+	if ground, ok := e.groundElevation(universalPlatform.State.Position); ok {
+		agl := universalPlatform.State.Position.Altitude - ground
+		status.GroundElevation, status.AltitudeAGL = &ground, &agl
+	}
+
+	e.platformsMux.RLock()
+	if behavior, exists := e.behaviors[id]; exists {
+		status.Behavior = behavior.Name()
+	}
+	e.platformsMux.RUnlock()
+
 	return status, nil
 }
 
@@ -508,10 +1381,14 @@ type PlatformStatus struct {
 	Position              models.Position     `json:"position"`
 	Destination           *models.Position    `json:"destination,omitempty"`
 	DistanceToDestination float64             `json:"distance_to_destination,omitempty"`
+	RouteProgress         *RouteProgress      `json:"route_progress,omitempty"`
+	Behavior              string              `json:"behavior,omitempty"`
 	Velocity              models.Velocity     `json:"velocity"`
 	Speed                 float64             `json:"speed"`
 	Heading               float64             `json:"heading"`
 	FuelRemaining         float64             `json:"fuel_remaining"`
 	SystemStatus          models.SystemStatus `json:"system_status"`
 	LastUpdated           time.Time           `json:"last_updated"`
+	GroundElevation       *float64            `json:"ground_elevation,omitempty"` // Meters above mean sea level, where terrain is loaded
+	AltitudeAGL           *float64            `json:"altitude_agl,omitempty"`     // Meters above the ground, where terrain is loaded
 }
@@ -0,0 +1,615 @@
+package sim
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/rhino11/trafficsim/internal/config"
+	"github.com/rhino11/trafficsim/internal/models"
+)
+
+// createScenarioTestConfig creates a configuration with two small scenarios
+func createScenarioTestConfig() *config.Config {
+	return &config.Config{
+		Simulation: config.SimulationConfig{
+			UpdateInterval: "100ms",
+			TimeScale:      1.0,
+		},
+		Platforms: config.PlatformRegistry{
+			AirborneTypes: config.PlatformTypeDefinitions{
+				"boeing_737_800": {
+					Name:        "Boeing 737-800",
+					Class:       "Boeing 737-800",
+					Type:        "airborne",
+					Category:    "commercial",
+					MaxSpeed:    257.0,
+					CruiseSpeed: 230.0,
+					MaxAltitude: 12500.0,
+					Length:      39.5,
+					Width:       35.8,
+					Mass:        79010.0,
+				},
+			},
+			MaritimeTypes: config.PlatformTypeDefinitions{
+				"arleigh_burke_ddg": {
+					Name:           "Arleigh Burke-class Destroyer",
+					Class:          "Arleigh Burke-class",
+					Type:           "maritime",
+					Category:       "military",
+					MaxSpeed:       15.4,
+					CruiseSpeed:    10.3,
+					Length:         155.0,
+					Width:          20.0,
+					Mass:           9200000.0,
+					CallSignPrefix: "NAVY",
+					CallSignFormat: "{prefix}{id}",
+				},
+			},
+			Scenarios: map[string]config.ScenarioConfig{
+				"alpha": {
+					Name: "Alpha",
+					Instances: []config.PlatformInstance{
+						{
+							ID:       "AAL1",
+							TypeID:   "boeing_737_800",
+							Name:     "American 1",
+							StartPos: config.Position{Latitude: 40.7, Longitude: -74.0, Altitude: 10000},
+							Destination: &config.Position{
+								Latitude: 25.8, Longitude: -80.2, Altitude: 11000,
+							},
+						},
+					},
+				},
+				"bravo": {
+					Name: "Bravo",
+					Instances: []config.PlatformInstance{
+						{
+							ID:       "DDG51",
+							TypeID:   "arleigh_burke_ddg",
+							Name:     "USS Arleigh Burke",
+							StartPos: config.Position{Latitude: 36.8, Longitude: -76.3},
+						},
+						{
+							ID:       "AAL2",
+							TypeID:   "boeing_737_800",
+							Name:     "American 2",
+							StartPos: config.Position{Latitude: 38.9, Longitude: -77.0, Altitude: 9000},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestLoadPlatformsFromConfig_NoScenariosUsesExamples(t *testing.T) {
+	engine := NewEngine(&config.Config{})
+
+	if err := engine.LoadPlatformsFromConfig(); err != nil {
+		t.Fatalf("LoadPlatformsFromConfig failed: %v", err)
+	}
+
+	if engine.GetPlatformCount() == 0 {
+		t.Error("Expected example platforms when no scenarios are configured")
+	}
+
+	if engine.GetScenario() != "" {
+		t.Errorf("Expected no active scenario, got %s", engine.GetScenario())
+	}
+}
+
+func TestLoadPlatformsFromConfig_DefaultsToFirstScenario(t *testing.T) {
+	engine := NewEngine(createScenarioTestConfig())
+
+	if err := engine.LoadPlatformsFromConfig(); err != nil {
+		t.Fatalf("LoadPlatformsFromConfig failed: %v", err)
+	}
+
+	if engine.GetScenario() != "alpha" {
+		t.Errorf("Expected scenario 'alpha', got '%s'", engine.GetScenario())
+	}
+
+	if engine.GetPlatformCount() != 1 {
+		t.Fatalf("Expected 1 platform, got %d", engine.GetPlatformCount())
+	}
+
+	platform, err := engine.GetPlatform("AAL1")
+	if err != nil {
+		t.Fatalf("Expected platform AAL1: %v", err)
+	}
+
+	status, err := engine.GetPlatformStatus(platform.GetID())
+	if err != nil {
+		t.Fatalf("GetPlatformStatus failed: %v", err)
+	}
+	if status.Destination == nil {
+		t.Error("Expected destination from scenario instance")
+	}
+}
+
+func TestLoadPlatformsFromConfig_ConfiguredDefault(t *testing.T) {
+	cfg := createScenarioTestConfig()
+	cfg.Simulation.Scenario = "bravo"
+	engine := NewEngine(cfg)
+
+	if err := engine.LoadPlatformsFromConfig(); err != nil {
+		t.Fatalf("LoadPlatformsFromConfig failed: %v", err)
+	}
+
+	if engine.GetScenario() != "bravo" {
+		t.Errorf("Expected scenario 'bravo', got '%s'", engine.GetScenario())
+	}
+
+	if engine.GetPlatformCount() != 2 {
+		t.Errorf("Expected 2 platforms, got %d", engine.GetPlatformCount())
+	}
+
+	platform, err := engine.GetPlatform("DDG51")
+	if err != nil {
+		t.Fatalf("Expected platform DDG51: %v", err)
+	}
+	if platform.GetCallSign() != "NAVYDDG51" {
+		t.Errorf("Expected generated callsign NAVYDDG51, got %s", platform.GetCallSign())
+	}
+}
+
+func TestLoadPlatformsFromConfig_SelectedScenario(t *testing.T) {
+	cfg := createScenarioTestConfig()
+	cfg.Simulation.Scenario = "alpha"
+	engine := NewEngine(cfg)
+	engine.SetScenario("bravo")
+
+	if err := engine.LoadPlatformsFromConfig(); err != nil {
+		t.Fatalf("LoadPlatformsFromConfig failed: %v", err)
+	}
+
+	if engine.GetScenario() != "bravo" {
+		t.Errorf("Expected explicit selection 'bravo' to win, got '%s'", engine.GetScenario())
+	}
+}
+
+func TestLoadScenario_UnknownScenario(t *testing.T) {
+	engine := NewEngine(createScenarioTestConfig())
+	engine.SetScenario("missing")
+
+	if err := engine.LoadPlatformsFromConfig(); err == nil {
+		t.Error("Expected error for unknown scenario")
+	}
+}
+
+func TestLoadScenario_ReplacesPlatforms(t *testing.T) {
+	engine := NewEngine(createScenarioTestConfig())
+
+	if err := engine.LoadScenario("bravo"); err != nil {
+		t.Fatalf("LoadScenario failed: %v", err)
+	}
+	if err := engine.LoadScenario("alpha"); err != nil {
+		t.Fatalf("LoadScenario failed: %v", err)
+	}
+
+	if engine.GetPlatformCount() != 1 {
+		t.Errorf("Expected platforms to be replaced, got %d", engine.GetPlatformCount())
+	}
+
+	if _, err := engine.GetPlatform("DDG51"); err == nil {
+		t.Error("Expected platforms from previous scenario to be removed")
+	}
+
+	stats := engine.GetStatistics()
+	if stats.Scenario != "alpha" {
+		t.Errorf("Expected statistics scenario 'alpha', got '%s'", stats.Scenario)
+	}
+}
+
+func TestLoadScenarioFile(t *testing.T) {
+	engine := NewEngine(createScenarioTestConfig())
+
+	if err := engine.LoadScenarioFile("../../data/configs/military_exercise.yaml"); err != nil {
+		t.Fatalf("LoadScenarioFile failed: %v", err)
+	}
+
+	// Ground forces and VIPER02 spawn later in the exercise
+	if engine.GetPlatformCount() != 2 || engine.GetStatistics().PendingSpawns != 3 {
+		t.Errorf("Expected 2 platforms and 3 pending spawns, got %d and %d",
+			engine.GetPlatformCount(), engine.GetStatistics().PendingSpawns)
+	}
+	if engine.GetTimeScale() != 2.0 {
+		t.Errorf("Expected the scenario's 2x time acceleration, got %v", engine.GetTimeScale())
+	}
+	if engine.GetScenario() != "Joint Military Exercise" {
+		t.Errorf("Expected scenario 'Joint Military Exercise', got '%s'", engine.GetScenario())
+	}
+
+	status, err := engine.GetPlatformStatus("VIPER01")
+	if err != nil {
+		t.Fatalf("GetPlatformStatus failed: %v", err)
+	}
+	if status.Behavior != "circuit" {
+		t.Errorf("Expected combat air patrol to run as a circuit, got %q", status.Behavior)
+	}
+
+	// The exercise's surface wind blows from the northeast
+	wind := engine.GetEnvironment().Wind(models.Position{}, engine.GetStartTime())
+	if math.Abs(wind.East+7.7*math.Sqrt2/2) > 1e-6 || math.Abs(wind.North+7.7*math.Sqrt2/2) > 1e-6 {
+		t.Errorf("Unexpected surface wind %+v", wind)
+	}
+
+	if err := engine.LoadScenarioFile("../../data/configs/missing.yaml"); err == nil {
+		t.Error("Expected error for missing scenario file")
+	}
+}
+
+func TestGetAvailableScenarios(t *testing.T) {
+	engine := NewEngine(createScenarioTestConfig())
+
+	names := engine.GetAvailableScenarios()
+	if len(names) != 2 || names[0] != "alpha" || names[1] != "bravo" {
+		t.Errorf("Expected sorted scenarios [alpha bravo], got %v", names)
+	}
+
+	if len(NewEngine(nil).GetAvailableScenarios()) != 0 {
+		t.Error("Expected no scenarios without configuration")
+	}
+}
+
+func TestGetPlatformStatus_RouteProgress(t *testing.T) {
+	cfg := createScenarioTestConfig()
+	bravo := cfg.Platforms.Scenarios["bravo"]
+	bravo.Instances[0].Route = []config.Position{
+		{Latitude: 36.9, Longitude: -76.3},
+		{Latitude: 36.9, Longitude: -76.1},
+	}
+	bravo.Instances[0].RouteMode = "loop"
+	engine := NewEngine(cfg)
+
+	if err := engine.LoadScenario("bravo"); err != nil {
+		t.Fatalf("LoadScenario failed: %v", err)
+	}
+
+	status, err := engine.GetPlatformStatus("DDG51")
+	if err != nil {
+		t.Fatalf("GetPlatformStatus failed: %v", err)
+	}
+	if status.RouteProgress == nil {
+		t.Fatal("Expected route progress for routed platform")
+	}
+	if status.RouteProgress.TotalWaypoints != 2 || status.RouteProgress.DistanceRemaining <= 0 {
+		t.Errorf("Unexpected route progress: %+v", status.RouteProgress)
+	}
+
+	// A direct destination cancels the route
+	if err := engine.SetDestinationForPlatform("DDG51", models.Position{Latitude: 37.0, Longitude: -76.0}); err != nil {
+		t.Fatalf("SetDestinationForPlatform failed: %v", err)
+	}
+	status, _ = engine.GetPlatformStatus("DDG51")
+	if status.RouteProgress != nil {
+		t.Error("Expected route progress to be cleared by direct destination")
+	}
+}
+
+func TestTimeScaleAndPause(t *testing.T) {
+	cfg := createScenarioTestConfig()
+	cfg.Simulation.TimeScale = 2.0
+	cfg.Simulation.UpdateInterval = "1h" // Keep the loop from racing the manual steps below
+	engine := NewEngine(cfg)
+
+	if engine.GetTimeScale() != 2.0 {
+		t.Errorf("Expected time scale from configuration, got %v", engine.GetTimeScale())
+	}
+	if err := engine.LoadScenario("bravo"); err != nil {
+		t.Fatalf("LoadScenario failed: %v", err)
+	}
+	if err := engine.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer engine.Stop()
+
+	if err := engine.advance(500 * time.Millisecond); err != nil {
+		t.Fatalf("advance failed: %v", err)
+	}
+	if simTime := engine.GetSimulationTime(); math.Abs(simTime-1.0) > 1e-9 {
+		t.Errorf("Expected 1s of simulation time at 2x, got %v", simTime)
+	}
+
+	engine.Pause()
+	if err := engine.advance(time.Second); err != nil {
+		t.Fatalf("advance failed: %v", err)
+	}
+	if simTime := engine.GetSimulationTime(); math.Abs(simTime-1.0) > 1e-9 {
+		t.Errorf("Expected simulation time to be frozen while paused, got %v", simTime)
+	}
+	if !engine.GetStatistics().Paused {
+		t.Error("Expected statistics to report the pause")
+	}
+
+	// Accelerated ticks are split into physics steps of at most one second
+	engine.Resume()
+	if err := engine.SetTimeScale(100); err != nil {
+		t.Fatalf("SetTimeScale failed: %v", err)
+	}
+	updatesBefore := engine.updateCount
+	if err := engine.advance(100 * time.Millisecond); err != nil {
+		t.Fatalf("advance failed: %v", err)
+	}
+	if simTime := engine.GetSimulationTime(); math.Abs(simTime-11.0) > 1e-9 {
+		t.Errorf("Expected 11s of simulation time, got %v", simTime)
+	}
+	if steps := engine.updateCount - updatesBefore; steps != 10 {
+		t.Errorf("Expected 10 one-second physics steps, got %d", steps)
+	}
+
+	for _, scale := range []float64{0, -1, math.Inf(1), math.NaN()} {
+		if err := engine.SetTimeScale(scale); err == nil {
+			t.Errorf("Expected error for time scale %v", scale)
+		}
+	}
+}
+
+func TestRunFor(t *testing.T) {
+	cfg := createScenarioTestConfig()
+	cfg.Simulation.MaxDuration = "90s"
+	cfg.Simulation.StartTime = "2024-06-01T12:00:00Z"
+	bravo := cfg.Platforms.Scenarios["bravo"]
+	bravo.Instances[1].SpawnTime = 30
+
+	engine := NewEngine(cfg)
+	if want := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC); !engine.GetStartTime().Equal(want) {
+		t.Errorf("Expected start time %v, got %v", want, engine.GetStartTime())
+	}
+	if err := engine.LoadScenario("bravo"); err != nil {
+		t.Fatalf("LoadScenario failed: %v", err)
+	}
+
+	// A partial final step lands exactly on the requested duration
+	ran, err := engine.RunFor(45*time.Second, 10*time.Second)
+	if err != nil {
+		t.Fatalf("RunFor failed: %v", err)
+	}
+	if ran != 45*time.Second || engine.updateCount != 5 {
+		t.Errorf("Expected 45s in 5 steps, got %v in %d", ran, engine.updateCount)
+	}
+	if _, err := engine.GetPlatform("AAL2"); err != nil {
+		t.Errorf("Expected scheduled spawn during RunFor: %v", err)
+	}
+
+	// The run stops at max_duration
+	ran, err = engine.RunFor(time.Minute, time.Second)
+	if err != nil {
+		t.Fatalf("RunFor failed: %v", err)
+	}
+	if ran != 45*time.Second || engine.GetSimulationTime() != 90 {
+		t.Errorf("Expected to stop at 90s, ran %v to %vs", ran, engine.GetSimulationTime())
+	}
+	if ran, _ = engine.RunFor(time.Minute, time.Second); ran != 0 {
+		t.Errorf("Expected no progress past max_duration, got %v", ran)
+	}
+
+	if _, err := engine.RunFor(time.Minute, 0); err == nil {
+		t.Error("Expected error for a zero step")
+	}
+	if err := engine.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer engine.Stop()
+	if _, err := engine.RunFor(time.Second, time.Second); err == nil {
+		t.Error("Expected error while the real-time loop is running")
+	}
+}
+
+func TestRunFor_ScenarioFileTiming(t *testing.T) {
+	engine := NewEngine(createScenarioTestConfig())
+	if err := engine.LoadScenarioFile("../../data/configs/military_exercise.yaml"); err != nil {
+		t.Fatalf("LoadScenarioFile failed: %v", err)
+	}
+
+	if engine.GetMaxDuration() != 8*time.Hour {
+		t.Errorf("Expected the scenario duration to set max duration, got %v", engine.GetMaxDuration())
+	}
+	if want := time.Date(2025, 6, 4, 6, 0, 0, 0, time.UTC); !engine.GetStartTime().Equal(want) {
+		t.Errorf("Expected scenario start time %v, got %v", want, engine.GetStartTime())
+	}
+}
+
+func TestEngineSeed(t *testing.T) {
+	cfg := createScenarioTestConfig()
+	cfg.Simulation.Seed = 1234
+	if seed := NewEngine(cfg).GetSeed(); seed != 1234 {
+		t.Errorf("Expected seed from configuration, got %d", seed)
+	}
+
+	area := config.BoundingBox{North: 37.5, South: 36.5, East: -75.5, West: -76.5}
+	behavior := &config.BehaviorConfig{RandomWalk: &config.RandomWalkBehavior{Area: area, MaxDistance: 5000}}
+
+	// Platforms draw the same random walk for the same seed
+	targets := func(seed int64) (first, second models.Position) {
+		engine := NewEngine(createScenarioTestConfig())
+		engine.SetSeed(seed)
+		if err := engine.LoadScenario("bravo"); err != nil {
+			t.Fatalf("LoadScenario failed: %v", err)
+		}
+		if err := engine.SetBehaviorForPlatform("DDG51", behavior); err != nil {
+			t.Fatalf("SetBehaviorForPlatform failed: %v", err)
+		}
+		platform, _ := engine.GetPlatform("DDG51")
+		destination := func() models.Position {
+			if _, err := engine.RunFor(time.Second, time.Second); err != nil {
+				t.Fatalf("RunFor failed: %v", err)
+			}
+			return *platform.(*models.UniversalPlatform).Destination
+		}
+		first = destination()
+
+		// Reset replays the same targets
+		if err := engine.Reset(); err != nil {
+			t.Fatalf("Reset failed: %v", err)
+		}
+		second = destination()
+		return first, second
+	}
+
+	first, afterReset := targets(99)
+	again, _ := targets(99)
+	if first != again {
+		t.Errorf("Expected the same target for the same seed, got %+v and %+v", first, again)
+	}
+	if first != afterReset {
+		t.Errorf("Expected Reset to replay the random walk, got %+v and %+v", first, afterReset)
+	}
+	if other, _ := targets(100); other == first {
+		t.Error("Expected a different seed to pick a different target")
+	}
+}
+
+func TestEngineClock(t *testing.T) {
+	cfg := createScenarioTestConfig()
+	cfg.Simulation.StartTime = "2025-06-04T06:00:00Z"
+	cfg.Simulation.TimeScale = 4.0
+	bravo := cfg.Platforms.Scenarios["bravo"]
+	bravo.Instances[0].Destination = &config.Position{Latitude: 36.9, Longitude: -76.3}
+	bravo.Instances[1].SpawnTime = 30
+
+	engine := NewEngine(cfg)
+	if err := engine.LoadScenario("bravo"); err != nil {
+		t.Fatalf("LoadScenario failed: %v", err)
+	}
+	start := time.Date(2025, 6, 4, 6, 0, 0, 0, time.UTC)
+	if !engine.Now().Equal(start) {
+		t.Errorf("Expected the clock to start at %v, got %v", start, engine.Now())
+	}
+
+	// Accelerated wall time advances the clock by scaled simulation time
+	if err := engine.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	if err := engine.advance(15 * time.Second); err != nil {
+		t.Fatalf("advance failed: %v", err)
+	}
+	engine.Stop()
+	if want := start.Add(time.Minute); !engine.Now().Equal(want) {
+		t.Errorf("Expected %v after 15s at 4x, got %v", want, engine.Now())
+	}
+
+	destroyer, _ := engine.GetPlatform("DDG51")
+	if stamp := destroyer.GetState().LastUpdated; !stamp.Equal(start.Add(time.Minute)) {
+		t.Errorf("Expected moving platform stamped with the simulation clock, got %v", stamp)
+	}
+	airliner, _ := engine.GetPlatform("AAL2")
+	if stamp := airliner.GetState().LastUpdated; !stamp.Equal(start.Add(30 * time.Second)) {
+		t.Errorf("Expected spawned platform stamped at its spawn time, got %v", stamp)
+	}
+
+	if err := engine.Reset(); err != nil {
+		t.Fatalf("Reset failed: %v", err)
+	}
+	destroyer, _ = engine.GetPlatform("DDG51")
+	if !engine.Now().Equal(start) || !destroyer.GetState().LastUpdated.Equal(start) {
+		t.Errorf("Expected reset to rewind the clock to %v, got %v", start, engine.Now())
+	}
+}
+
+func TestLoadScenarioFile_KeplerOrbits(t *testing.T) {
+	engine := NewEngine(createScenarioTestConfig())
+	if err := engine.LoadScenarioFile("../../data/configs/global_traffic_demo.yaml"); err != nil {
+		t.Fatalf("LoadScenarioFile failed: %v", err)
+	}
+
+	// Orbital element routes without an epoch hold from the scenario start
+	platform, err := engine.GetPlatform("STARLINK1234")
+	if err != nil {
+		t.Fatal(err)
+	}
+	satellite := platform.(*models.UniversalPlatform)
+	orbit, ok := satellite.Orbit.(*models.KeplerOrbit)
+	if !ok {
+		t.Fatalf("Expected a Keplerian orbit, got %T", satellite.Orbit)
+	}
+	start := engine.GetStartTime()
+	if !orbit.Epoch().Equal(start) || !satellite.State.LastUpdated.Equal(start) {
+		t.Fatalf("Expected the orbit anchored at the scenario start %v, got %v", start, orbit.Epoch())
+	}
+	if math.Abs(satellite.State.Position.Latitude-53) > 0.5 {
+		t.Errorf("Expected the satellite at its northernmost point, got %+v", satellite.State.Position)
+	}
+
+	if _, err := engine.RunFor(10*time.Minute, 10*time.Second); err != nil {
+		t.Fatal(err)
+	}
+	want, _, _ := orbit.Propagate(start.Add(10 * time.Minute))
+	got := satellite.GetState()
+	if got.Position != want || got.Speed < 7e3 {
+		t.Errorf("Position after 10 minutes = %+v, want %+v", got.Position, want)
+	}
+
+	gps, err := engine.GetPlatform("GPS15")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if altitude := gps.GetState().Position.Altitude; math.Abs(altitude-20.2e6) > 50e3 {
+		t.Errorf("GPS altitude = %.0f km, want about 20,200 km", altitude/1000)
+	}
+}
+
+func TestLoadScenarioFile_Constellation(t *testing.T) {
+	engine := NewEngine(createScenarioTestConfig())
+	if err := engine.LoadScenarioFile("../../data/configs/starlink_shell.yaml"); err != nil {
+		t.Fatalf("LoadScenarioFile failed: %v", err)
+	}
+	if count := len(engine.GetAllPlatforms()); count != 1584 {
+		t.Fatalf("Expected 1584 satellites, got %d", count)
+	}
+	if _, err := engine.RunFor(time.Minute, 10*time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	// Satellites of one plane stay a slot apart as the shell turns
+	first, _ := engine.GetPlatform("STARLINK-1-1")
+	second, _ := engine.GetPlatform("STARLINK-1-2")
+	a, b := models.GeodeticToECEF(first.GetState().Position), models.GeodeticToECEF(second.GetState().Position)
+	separation := math.Sqrt((a.X-b.X)*(a.X-b.X) + (a.Y-b.Y)*(a.Y-b.Y) + (a.Z-b.Z)*(a.Z-b.Z))
+	if want := 2 * (models.WGS84SemiMajorAxis + 550e3) * math.Sin(math.Pi/22); math.Abs(separation-want) > 0.01*want {
+		t.Errorf("Neighbouring satellites are %.0f km apart, want about %.0f km", separation/1000, want/1000)
+	}
+}
+
+func TestLoadScenarioFile_OrbitalTracks(t *testing.T) {
+	engine := NewEngine(createScenarioTestConfig())
+	if err := engine.LoadScenarioFile("../../data/configs/orbital_tracks.yaml"); err != nil {
+		t.Fatalf("LoadScenarioFile failed: %v", err)
+	}
+
+	platform, err := engine.GetPlatform("ISS")
+	if err != nil {
+		t.Fatal(err)
+	}
+	iss := platform.(*models.UniversalPlatform)
+	start := engine.GetStartTime()
+	if !iss.IsOrbiting() || !iss.State.LastUpdated.Equal(start) {
+		t.Fatalf("Expected the ISS on its orbit at the scenario start, got %+v", iss.State)
+	}
+
+	// Twenty minutes later the station is where SGP4 puts it, a quarter of an orbit along
+	if _, err := engine.RunFor(20*time.Minute, 10*time.Second); err != nil {
+		t.Fatal(err)
+	}
+	want, _, err := iss.Orbit.Propagate(start.Add(20 * time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := iss.GetState()
+	if math.Abs(got.Position.Latitude-want.Latitude) > 1e-9 || math.Abs(got.Position.Longitude-want.Longitude) > 1e-9 {
+		t.Errorf("Position after 20 minutes = %+v, want %+v", got.Position, want)
+	}
+	if got.Position.Altitude < 390e3 || got.Position.Altitude > 440e3 || got.Speed < 7e3 {
+		t.Errorf("Unexpected orbital state %+v", got)
+	}
+
+	// Resetting returns the station to its position at the start time
+	if err := engine.Reset(); err != nil {
+		t.Fatal(err)
+	}
+	first, _, _ := iss.Orbit.Propagate(start)
+	if iss.State.Position != first {
+		t.Errorf("Position after reset = %+v, want %+v", iss.State.Position, first)
+	}
+}
@@ -0,0 +1,183 @@
+package sim
+
+import (
+	"math"
+	"time"
+
+	"github.com/rhino11/trafficsim/internal/config"
+	"github.com/rhino11/trafficsim/internal/models"
+)
+
+// Environment holds the wind that carries aircraft and the current that carries ships. Fields are
+// read-only once built, so the update workers share them freely.
+type Environment struct {
+	wind    *flowField
+	current *flowField
+	start   time.Time // Time variation is measured from here, normally the scenario start
+}
+
+// flowField is a wind or current field in altitude layers, each uniform or gridded
+type flowField struct {
+	layers    []flowLayer // In order of altitude
+	variation *config.FlowVariation
+}
+
+// flowLayer is the horizontal field at one altitude
+type flowLayer struct {
+	altitude float64
+	uniform  models.Velocity // Flow toward, used without a grid
+	grid     *flowGrid
+}
+
+// flowGrid holds flow vectors at evenly spaced points, rows from north to south
+type flowGrid struct {
+	area    config.BoundingBox
+	vectors [][]models.Velocity
+}
+
+// NewEnvironment builds the wind and current fields of a scenario. The weather's surface wind is
+// used as a uniform wind when the environment has none. It returns nil for a calm scenario.
+func NewEnvironment(environment *config.EnvironmentConfig, weather *config.WeatherConfig, start time.Time) (*Environment, error) {
+	if environment != nil {
+		if err := environment.Validate(); err != nil {
+			return nil, err
+		}
+	} else {
+		environment = &config.EnvironmentConfig{}
+	}
+
+	env := &Environment{start: start}
+	if environment.Wind != nil {
+		env.wind = newFlowField(environment.Wind, true)
+	} else if weather != nil && weather.WindSpeed > 0 {
+		env.wind = newFlowField(&config.FlowConfig{Speed: weather.WindSpeed, Direction: weather.WindDirection}, true)
+	}
+	if environment.Current != nil {
+		env.current = newFlowField(environment.Current, false)
+	}
+	if env.wind == nil && env.current == nil {
+		return nil, nil
+	}
+	return env, nil
+}
+
+// newFlowField converts a field's speeds and directions to flow vectors. Wind directions are where
+// the wind blows from, current directions where it sets toward.
+func newFlowField(cfg *config.FlowConfig, from bool) *flowField {
+	toward := func(speed, direction float64) models.Velocity {
+		if from {
+			direction += 180
+		}
+		sin, cos := math.Sincos(direction * math.Pi / 180)
+		return models.Velocity{East: speed * sin, North: speed * cos}
+	}
+	layer := func(altitude, speed, direction float64, grid *config.FlowGrid) flowLayer {
+		l := flowLayer{altitude: altitude, uniform: toward(speed, direction)}
+		if grid != nil {
+			l.grid = &flowGrid{area: grid.Area, vectors: make([][]models.Velocity, len(grid.Speeds))}
+			for row := range grid.Speeds {
+				l.grid.vectors[row] = make([]models.Velocity, len(grid.Speeds[row]))
+				for column, speed := range grid.Speeds[row] {
+					l.grid.vectors[row][column] = toward(speed, grid.Directions[row][column])
+				}
+			}
+		}
+		return l
+	}
+
+	field := &flowField{variation: cfg.Variation}
+	if len(cfg.Layers) == 0 {
+		field.layers = []flowLayer{layer(0, cfg.Speed, cfg.Direction, cfg.Grid)}
+		return field
+	}
+	for _, l := range cfg.Layers {
+		field.layers = append(field.layers, layer(l.Altitude, l.Speed, l.Direction, l.Grid))
+	}
+	return field
+}
+
+// Wind returns the velocity of the air at a position and time
+func (env *Environment) Wind(position models.Position, t time.Time) models.Velocity {
+	if env == nil || env.wind == nil {
+		return models.Velocity{}
+	}
+	return env.wind.at(position, t.Sub(env.start).Seconds())
+}
+
+// Current returns the velocity of the water at a position and time
+func (env *Environment) Current(position models.Position, t time.Time) models.Velocity {
+	if env == nil || env.current == nil {
+		return models.Velocity{}
+	}
+	return env.current.at(position, t.Sub(env.start).Seconds())
+}
+
+// at returns the flow at a position, interpolated between the layers around its altitude, swung
+// by the variation at elapsed seconds
+func (f *flowField) at(position models.Position, elapsed float64) models.Velocity {
+	flow := f.layers[0].at(position)
+	for i := 1; i < len(f.layers) && position.Altitude > f.layers[i-1].altitude; i++ {
+		below, above := f.layers[i-1], f.layers[i]
+		if position.Altitude >= above.altitude {
+			flow = above.at(position)
+			continue
+		}
+		flow = lerpVelocity(below.at(position), above.at(position),
+			(position.Altitude-below.altitude)/(above.altitude-below.altitude))
+		break
+	}
+
+	if f.variation == nil {
+		return flow
+	}
+	phase := math.Sin(2 * math.Pi * elapsed / f.variation.Period)
+	speed := math.Hypot(flow.East, flow.North)
+	if speed == 0 {
+		return flow
+	}
+	scale := math.Max(0, speed+f.variation.Speed*phase) / speed
+	sin, cos := math.Sincos(f.variation.Direction * phase * math.Pi / 180)
+	return models.Velocity{
+		East:  (flow.East*cos + flow.North*sin) * scale,
+		North: (flow.North*cos - flow.East*sin) * scale,
+	}
+}
+
+// at returns the layer's flow at a position
+func (l flowLayer) at(position models.Position) models.Velocity {
+	if l.grid == nil {
+		return l.uniform
+	}
+	return l.grid.at(position)
+}
+
+// at interpolates the grid bilinearly, holding the edge values outside its area
+func (g *flowGrid) at(position models.Position) models.Velocity {
+	fraction := func(value, low, high float64, points int) (int, float64) {
+		if points < 2 || high <= low {
+			return 0, 0
+		}
+		scaled := math.Max(0, math.Min(1, (value-low)/(high-low))) * float64(points-1)
+		index := math.Min(math.Floor(scaled), float64(points-2))
+		return int(index), scaled - index
+	}
+	row, rowFraction := fraction(g.area.North-position.Latitude, 0, g.area.North-g.area.South, len(g.vectors))
+	column, columnFraction := fraction(position.Longitude, g.area.West, g.area.East, len(g.vectors[0]))
+
+	sample := func(r, c int) models.Velocity {
+		r = min(r, len(g.vectors)-1)
+		c = min(c, len(g.vectors[0])-1)
+		return g.vectors[r][c]
+	}
+	north := lerpVelocity(sample(row, column), sample(row, column+1), columnFraction)
+	south := lerpVelocity(sample(row+1, column), sample(row+1, column+1), columnFraction)
+	return lerpVelocity(north, south, rowFraction)
+}
+
+// lerpVelocity interpolates linearly between two horizontal vectors
+func lerpVelocity(a, b models.Velocity, fraction float64) models.Velocity {
+	return models.Velocity{
+		East:  a.East + (b.East-a.East)*fraction,
+		North: a.North + (b.North-a.North)*fraction,
+	}
+}
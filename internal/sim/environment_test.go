@@ -0,0 +1,198 @@
+package sim
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/rhino11/trafficsim/internal/config"
+	"github.com/rhino11/trafficsim/internal/models"
+)
+
+var environmentStart = time.Date(2025, 6, 4, 12, 0, 0, 0, time.UTC)
+
+func assertFlow(t *testing.T, name string, got models.Velocity, east, north float64) {
+	t.Helper()
+	if math.Abs(got.East-east) > 1e-6 || math.Abs(got.North-north) > 1e-6 {
+		t.Errorf("%s = %+v, want east %v, north %v", name, got, east, north)
+	}
+}
+
+func TestNewEnvironment_Calm(t *testing.T) {
+	env, err := NewEnvironment(nil, &config.WeatherConfig{Conditions: "clear"}, environmentStart)
+	if err != nil || env != nil {
+		t.Fatalf("Expected a calm scenario to have no environment, got %+v, %v", env, err)
+	}
+	assertFlow(t, "Wind", env.Wind(models.Position{}, environmentStart), 0, 0)
+
+	if _, err := NewEnvironment(&config.EnvironmentConfig{Wind: &config.FlowConfig{Speed: -1}}, nil, environmentStart); err == nil {
+		t.Error("Expected a negative wind speed to be rejected")
+	}
+}
+
+func TestEnvironment_WeatherWind(t *testing.T) {
+	// A westerly blows toward the east; currents are given the way they set
+	env, err := NewEnvironment(&config.EnvironmentConfig{
+		Current: &config.FlowConfig{Speed: 2, Direction: 180},
+	}, &config.WeatherConfig{WindSpeed: 10, WindDirection: 270}, environmentStart)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertFlow(t, "Wind", env.Wind(models.Position{Altitude: 5000}, environmentStart), 10, 0)
+	assertFlow(t, "Current", env.Current(models.Position{}, environmentStart), 0, -2)
+}
+
+func TestEnvironment_Layers(t *testing.T) {
+	env, err := NewEnvironment(&config.EnvironmentConfig{Wind: &config.FlowConfig{Layers: []config.FlowLayer{
+		{Altitude: 0, Speed: 10, Direction: 180},
+		{Altitude: 10000, Speed: 30, Direction: 180},
+		{Altitude: 12000, Speed: 50, Direction: 270},
+	}}}, nil, environmentStart)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		altitude    float64
+		east, north float64
+	}{
+		{-100, 0, 10},
+		{5000, 0, 20},
+		{10000, 0, 30},
+		{11000, 25, 15},
+		{20000, 50, 0},
+	}
+	for _, tt := range tests {
+		assertFlow(t, "Wind", env.Wind(models.Position{Altitude: tt.altitude}, environmentStart), tt.east, tt.north)
+	}
+}
+
+func TestEnvironment_Grid(t *testing.T) {
+	env, err := NewEnvironment(&config.EnvironmentConfig{Current: &config.FlowConfig{Grid: &config.FlowGrid{
+		Area:       config.BoundingBox{North: 37, South: 36, East: -75, West: -76},
+		Speeds:     [][]float64{{0, 2}, {2, 4}},
+		Directions: [][]float64{{90, 90}, {90, 90}},
+	}}}, nil, environmentStart)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		latitude  float64
+		longitude float64
+		east      float64
+	}{
+		{"north-west corner", 37, -76, 0},
+		{"south-east corner", 36, -75, 4},
+		{"center", 36.5, -75.5, 2},
+		{"north edge", 37, -75.25, 1.5},
+		{"outside, held at the edge", 40, -70, 2},
+	}
+	for _, tt := range tests {
+		got := env.Current(models.Position{Latitude: tt.latitude, Longitude: tt.longitude}, environmentStart)
+		assertFlow(t, tt.name, got, tt.east, 0)
+	}
+}
+
+func TestEnvironment_Variation(t *testing.T) {
+	env, err := NewEnvironment(&config.EnvironmentConfig{Wind: &config.FlowConfig{
+		Speed:     10,
+		Direction: 180,
+		Variation: &config.FlowVariation{Period: 3600, Speed: 5, Direction: 90},
+	}}, nil, environmentStart)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// At the start, a quarter and three quarters of the period
+	assertFlow(t, "Wind at the start", env.Wind(models.Position{}, environmentStart), 0, 10)
+	assertFlow(t, "Wind after 15 minutes", env.Wind(models.Position{}, environmentStart.Add(15*time.Minute)), 15, 0)
+	assertFlow(t, "Wind after 45 minutes", env.Wind(models.Position{}, environmentStart.Add(45*time.Minute)), -5, 0)
+}
+
+func TestPhysics_AircraftInCrosswind(t *testing.T) {
+	pe := NewPhysicsEngine()
+	pe.Environment, _ = NewEnvironment(nil, &config.WeatherConfig{WindSpeed: 30, WindDirection: 270}, environmentStart)
+	pe.Clock = stepClock(environmentStart)
+
+	// Flying north at 200 m/s with a 30 m/s wind from the west
+	aircraft := &models.UniversalPlatform{
+		ID:           "CRAB",
+		PlatformType: models.PlatformTypeAirborne,
+		State: models.PlatformState{
+			Position: models.Position{Latitude: 40, Longitude: -75, Altitude: 5000},
+			Heading:  0,
+			Speed:    200,
+		},
+		TypeDef: &models.PlatformTypeDefinition{Performance: models.PerformanceCharacteristics{
+			MaxSpeed: 250, CruiseSpeed: 200, TurningRadius: 1000,
+		}},
+		Destination: &models.Position{Latitude: 42, Longitude: -75, Altitude: 5000},
+	}
+	for i := 0; i < 60; i++ {
+		if err := pe.CalculateMovement(aircraft, time.Second); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The aircraft crabs into the wind, so its track over the ground stays north at the same
+	// ground speed and it does not drift off the meridian
+	state := aircraft.State
+	if math.Abs(math.Remainder(state.Heading, 360)) > 0.5 {
+		t.Errorf("Track = %.2f°, want north", state.Heading)
+	}
+	if math.Abs(state.Speed-math.Sqrt(200*200-30*30)) > 0.5 {
+		t.Errorf("Ground speed = %.1f m/s, want %.1f", state.Speed, math.Sqrt(200*200-30*30))
+	}
+	if math.Abs(state.Position.Longitude+75) > 0.001 {
+		t.Errorf("Aircraft drifted to longitude %v", state.Position.Longitude)
+	}
+
+	// With the wind behind it, the ground speed is airspeed plus wind
+	aircraft.Destination = &models.Position{Latitude: state.Position.Latitude, Longitude: -70, Altitude: 5000}
+	for i := 0; i < 120; i++ {
+		if err := pe.CalculateMovement(aircraft, time.Second); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if math.Abs(aircraft.State.Speed-230) > 1 || math.Abs(aircraft.State.Velocity.East-230) > 1 {
+		t.Errorf("Downwind ground speed = %.1f m/s, want 230", aircraft.State.Speed)
+	}
+}
+
+func TestPhysics_ShipDriftsWithCurrent(t *testing.T) {
+	pe := NewPhysicsEngine()
+	pe.Environment, _ = NewEnvironment(&config.EnvironmentConfig{
+		Current: &config.FlowConfig{Speed: 1, Direction: 90},
+	}, nil, environmentStart)
+	pe.Clock = stepClock(environmentStart)
+
+	ship := &models.UniversalPlatform{
+		ID:           "ADRIFT",
+		PlatformType: models.PlatformTypeMaritime,
+		State:        models.PlatformState{Position: models.Position{Latitude: 0, Longitude: 0}},
+		TypeDef:      &models.PlatformTypeDefinition{},
+	}
+	for i := 0; i < 600; i++ {
+		if err := pe.CalculateMovement(ship, time.Second); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if drift := pe.CalculateGreatCircleDistance(models.Position{}, ship.State.Position); math.Abs(drift-600) > 1 {
+		t.Errorf("Ship drifted %.1f m, want 600", drift)
+	}
+	if ship.State.Heading != 90 || ship.State.Speed != 1 {
+		t.Errorf("Expected course and speed over the ground of the current, got %v° at %v m/s", ship.State.Heading, ship.State.Speed)
+	}
+
+	// Weather can be switched off
+	pe.EnableWeather = false
+	position := ship.State.Position
+	if err := pe.CalculateMovement(ship, time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if ship.State.Position != position {
+		t.Error("Expected no drift with weather disabled")
+	}
+}
@@ -0,0 +1,110 @@
+package sim
+
+import (
+	"container/heap"
+
+	"github.com/rhino11/trafficsim/internal/models"
+)
+
+// PlatformEventType identifies a change to the set of simulated platforms
+type PlatformEventType string
+
+const (
+	PlatformEventAdded   PlatformEventType = "platform_added"
+	PlatformEventRemoved PlatformEventType = "platform_removed"
+)
+
+// PlatformEvent reports a platform being added to or removed from the simulation
+type PlatformEvent struct {
+	Type           PlatformEventType `json:"type"`
+	PlatformID     string            `json:"platform_id"`
+	Platform       models.Platform   `json:"platform,omitempty"` // Copy of the platform as added, set for added platforms
+	Reason         string            `json:"reason"`             // e.g. "spawn", "despawn", "arrival", "api"
+	SimulationTime float64           `json:"simulation_time"`
+}
+
+// PlatformEventHandler receives platform events; it is called without engine locks held
+type PlatformEventHandler func(event PlatformEvent)
+
+// scheduledAction is what happens to a platform when its scheduled event fires
+type scheduledAction int
+
+const (
+	scheduledSpawn scheduledAction = iota
+	scheduledDespawn
+)
+
+// scheduledEvent is a platform spawn or despawn at a simulation time
+type scheduledEvent struct {
+	time     float64 // seconds after scenario start
+	seq      int     // insertion order, keeps events at the same time stable
+	action   scheduledAction
+	id       string
+	platform models.Platform // Platform to add, for spawns
+	behavior Behavior        // Behavior to start with the platform, for spawns
+}
+
+// eventQueue is a min-heap of scheduled events ordered by simulation time
+type eventQueue []*scheduledEvent
+
+func (q eventQueue) Len() int { return len(q) }
+
+func (q eventQueue) Less(i, j int) bool {
+	if q[i].time != q[j].time {
+		return q[i].time < q[j].time
+	}
+	return q[i].seq < q[j].seq
+}
+
+func (q eventQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+func (q *eventQueue) Push(x interface{}) { *q = append(*q, x.(*scheduledEvent)) }
+
+func (q *eventQueue) Pop() interface{} {
+	old := *q
+	event := old[len(old)-1]
+	old[len(old)-1] = nil
+	*q = old[:len(old)-1]
+	return event
+}
+
+// eventSchedule orders spawn and despawn events by simulation time
+type eventSchedule struct {
+	queue   eventQueue
+	nextSeq int
+}
+
+// add queues an event
+func (s *eventSchedule) add(event *scheduledEvent) {
+	event.seq = s.nextSeq
+	s.nextSeq++
+	heap.Push(&s.queue, event)
+}
+
+// popDue removes and returns the events due at or before simTime, in order
+func (s *eventSchedule) popDue(simTime float64) []*scheduledEvent {
+	var due []*scheduledEvent
+	for s.queue.Len() > 0 && s.queue[0].time <= simTime {
+		due = append(due, heap.Pop(&s.queue).(*scheduledEvent))
+	}
+	return due
+}
+
+// pendingSpawns returns the number of platforms still waiting to spawn
+func (s *eventSchedule) pendingSpawns() int {
+	count := 0
+	for _, event := range s.queue {
+		if event.action == scheduledSpawn {
+			count++
+		}
+	}
+	return count
+}
+
+// hasArrived reports whether a platform has reached its destination or the end of its route
+func hasArrived(platform *models.UniversalPlatform) bool {
+	if platform.RoutePlan != nil {
+		return platform.RoutePlan.Completed
+	}
+	return platform.Destination == nil
+}
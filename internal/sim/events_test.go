@@ -0,0 +1,213 @@
+package sim
+
+import (
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rhino11/trafficsim/internal/config"
+	"github.com/rhino11/trafficsim/internal/models"
+)
+
+// eventRecorder collects platform events emitted by an engine
+type eventRecorder struct {
+	mu     sync.Mutex
+	events []PlatformEvent
+}
+
+func (r *eventRecorder) record(event PlatformEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+}
+
+func (r *eventRecorder) list() []PlatformEvent {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]PlatformEvent(nil), r.events...)
+}
+
+// stepEngine runs count one-second updates
+func stepEngine(t *testing.T, engine *Engine, count int) {
+	t.Helper()
+	for i := 0; i < count; i++ {
+		if err := engine.Update(time.Second); err != nil {
+			t.Fatalf("Update failed: %v", err)
+		}
+	}
+}
+
+func TestScheduledSpawnAndDespawn(t *testing.T) {
+	cfg := createScenarioTestConfig()
+	bravo := cfg.Platforms.Scenarios["bravo"]
+	bravo.Instances[1].SpawnTime = 10
+	bravo.Instances[1].DespawnTime = 20
+
+	engine := NewEngine(cfg)
+	recorder := &eventRecorder{}
+	engine.OnPlatformEvent(recorder.record)
+
+	if err := engine.LoadScenario("bravo"); err != nil {
+		t.Fatalf("LoadScenario failed: %v", err)
+	}
+	if err := engine.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer engine.Stop()
+
+	if engine.GetPlatformCount() != 1 || engine.GetStatistics().PendingSpawns != 1 {
+		t.Fatalf("Expected 1 platform and 1 pending spawn at t=0, got %d and %d",
+			engine.GetPlatformCount(), engine.GetStatistics().PendingSpawns)
+	}
+
+	stepEngine(t, engine, 9)
+	if _, err := engine.GetPlatform("AAL2"); err == nil {
+		t.Error("AAL2 spawned before its spawn time")
+	}
+
+	stepEngine(t, engine, 1)
+	if _, err := engine.GetPlatform("AAL2"); err != nil {
+		t.Errorf("AAL2 should have spawned at t=10: %v", err)
+	}
+
+	stepEngine(t, engine, 10)
+	if _, err := engine.GetPlatform("AAL2"); err == nil {
+		t.Error("AAL2 should have despawned at t=20")
+	}
+
+	events := recorder.list()
+	if len(events) != 2 {
+		t.Fatalf("Expected spawn and despawn events, got %+v", events)
+	}
+	if events[0].Type != PlatformEventAdded || events[0].Reason != "spawn" || events[0].SimulationTime != 10 {
+		t.Errorf("Unexpected spawn event: %+v", events[0])
+	}
+	if events[1].Type != PlatformEventRemoved || events[1].Reason != "despawn" || events[1].PlatformID != "AAL2" {
+		t.Errorf("Unexpected despawn event: %+v", events[1])
+	}
+
+	// Reset replays the schedule from the start
+	engine.Stop()
+	if err := engine.Reset(); err != nil {
+		t.Fatalf("Reset failed: %v", err)
+	}
+	if engine.GetPlatformCount() != 1 || engine.GetStatistics().PendingSpawns != 1 {
+		t.Errorf("Expected reset to restore the t=0 roster, got %d platforms and %d pending spawns",
+			engine.GetPlatformCount(), engine.GetStatistics().PendingSpawns)
+	}
+}
+
+func TestPlatformEventHandlersCallEngine(t *testing.T) {
+	cfg := createScenarioTestConfig()
+	bravo := cfg.Platforms.Scenarios["bravo"]
+	bravo.Instances[1].SpawnTime = 2
+
+	engine := NewEngine(cfg)
+	engine.SetUpdateInterval(time.Millisecond)
+	added := make(chan PlatformEvent, 4)
+	engine.OnPlatformEvent(func(event PlatformEvent) {
+		// Handlers may call back into the engine and encode the platform while it keeps moving
+		engine.SnapshotPlatforms()
+		if _, err := json.Marshal(event); err != nil {
+			t.Errorf("Marshal failed: %v", err)
+		}
+		if event.Type == PlatformEventAdded {
+			added <- event
+		}
+	})
+	if err := engine.LoadScenario("bravo"); err != nil {
+		t.Fatalf("LoadScenario failed: %v", err)
+	}
+	if err := engine.SetTimeScale(100); err != nil {
+		t.Fatalf("SetTimeScale failed: %v", err)
+	}
+	if err := engine.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer engine.Stop()
+
+	for _, id := range []string{"AAL2", "MANUAL"} {
+		if id == "MANUAL" {
+			if err := engine.AddPlatform(models.NewBoeing737_800Universal(id, id, models.Position{Latitude: 36, Longitude: -76, Altitude: 9000})); err != nil {
+				t.Fatalf("AddPlatform failed: %v", err)
+			}
+		}
+		select {
+		case event := <-added:
+			live, err := engine.GetPlatform(id)
+			if event.PlatformID != id || err != nil || event.Platform == live {
+				t.Errorf("Expected a copy of %s in the added event, got %+v", id, event)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("No added event for %s", id)
+		}
+	}
+}
+
+func TestDespawnOnArrival(t *testing.T) {
+	cfg := createScenarioTestConfig()
+	bravo := cfg.Platforms.Scenarios["bravo"]
+	bravo.Instances[0].Destination = &config.Position{Latitude: 36.81, Longitude: -76.3}
+	bravo.Instances[0].DespawnOnArrival = true
+
+	engine := NewEngine(cfg)
+	recorder := &eventRecorder{}
+	engine.OnPlatformEvent(recorder.record)
+
+	if err := engine.LoadScenario("bravo"); err != nil {
+		t.Fatalf("LoadScenario failed: %v", err)
+	}
+	if err := engine.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer engine.Stop()
+
+	// About 1.1km at destroyer speeds
+	for i := 0; i < 600; i++ {
+		stepEngine(t, engine, 1)
+		if _, err := engine.GetPlatform("DDG51"); err != nil {
+			break
+		}
+	}
+
+	if _, err := engine.GetPlatform("DDG51"); err == nil {
+		t.Fatal("Expected DDG51 to be removed on arrival")
+	}
+	events := recorder.list()
+	if len(events) != 1 || events[0].Reason != "arrival" {
+		t.Errorf("Expected one arrival event, got %+v", events)
+	}
+	if _, err := engine.GetPlatform("AAL2"); err != nil {
+		t.Error("Platforms without despawn_on_arrival should remain")
+	}
+}
+
+func TestScenarioFileSpawnTimes(t *testing.T) {
+	engine := NewEngine(createScenarioTestConfig())
+	if err := engine.LoadScenarioFile("../../data/configs/military_exercise.yaml"); err != nil {
+		t.Fatalf("LoadScenarioFile failed: %v", err)
+	}
+	if err := engine.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer engine.Stop()
+
+	// VIPER02 launches five minutes after VIPER01
+	stepEngine(t, engine, 299)
+	if _, err := engine.GetPlatform("VIPER02"); err == nil {
+		t.Error("VIPER02 spawned before its 5 minute delay")
+	}
+	stepEngine(t, engine, 1)
+	if _, err := engine.GetPlatform("VIPER02"); err != nil {
+		t.Errorf("VIPER02 should have spawned at 300s: %v", err)
+	}
+
+	status, err := engine.GetPlatformStatus("VIPER02")
+	if err != nil {
+		t.Fatalf("GetPlatformStatus failed: %v", err)
+	}
+	if status.Behavior != "circuit" {
+		t.Errorf("Expected spawned platform to start its behavior, got %q", status.Behavior)
+	}
+}
@@ -0,0 +1,115 @@
+package sim
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/rhino11/trafficsim/internal/models"
+)
+
+// ExternalTrack is a position report for a platform the simulation does not control, e.g. a live CoT track
+type ExternalTrack struct {
+	ID       string
+	Callsign string
+	Type     models.PlatformType
+	CoTType  string
+	Source   string // Feed the report came from, e.g. "cot"
+	Position models.Position
+	Speed    float64   // m/s
+	Heading  float64   // degrees true
+	Time     time.Time // When the report was made
+	Stale    time.Time // When the track ages out without a newer report
+}
+
+// UpdateExternalTrack adds an external platform for a new track or moves the existing one. External
+// platforms are read-only: physics and behaviors skip them, and they cannot be given routes.
+func (e *Engine) UpdateExternalTrack(track ExternalTrack) (models.Platform, error) {
+	if track.ID == "" {
+		return nil, fmt.Errorf("external track has no ID")
+	}
+	info := models.ExternalInfo{Source: track.Source, UID: track.ID, Stale: track.Stale}
+
+	e.platformsMux.Lock()
+	if existing, exists := e.platforms[track.ID]; exists {
+		universalPlatform, ok := existing.(*models.UniversalPlatform)
+		if !ok || !universalPlatform.IsExternal() {
+			e.platformsMux.Unlock()
+			return nil, fmt.Errorf("platform %s is simulated and cannot be updated by an external track", track.ID)
+		}
+		universalPlatform.SetExternalState(track.Position, track.Speed, track.Heading, track.Time)
+		universalPlatform.External = &info
+		if track.Callsign != "" {
+			universalPlatform.CallSign = track.Callsign
+			universalPlatform.Config.Name = track.Callsign
+		}
+		e.platformsMux.Unlock()
+		return universalPlatform, nil
+	}
+
+	platform := models.NewExternalPlatform(track.ID, track.Callsign, track.Type, track.CoTType, info)
+	platform.SetExternalState(track.Position, track.Speed, track.Heading, track.Time)
+	e.platforms[track.ID] = platform
+	logPlatformOperation("ADD_EXTERNAL", track.ID, track.Source)
+	added := eventPlatform(platform) // Copied before the next report can move it
+	e.platformsMux.Unlock()
+
+	e.emitPlatformEvents([]PlatformEvent{{
+		Type:           PlatformEventAdded,
+		PlatformID:     track.ID,
+		Platform:       added,
+		Reason:         "external",
+		SimulationTime: e.GetSimulationTime(),
+	}})
+	return platform, nil
+}
+
+// ExpireExternalTracks removes external platforms whose stale time is before now and returns how many
+// were removed. now is on the feed's clock, normally wall time, not the simulation clock.
+func (e *Engine) ExpireExternalTracks(now time.Time) int {
+	e.platformsMux.Lock()
+	var expired []string
+	for id, platform := range e.platforms {
+		universalPlatform, ok := platform.(*models.UniversalPlatform)
+		if ok && universalPlatform.IsExternal() && universalPlatform.External.Stale.Before(now) {
+			e.removePlatformLocked(id)
+			expired = append(expired, id)
+		}
+	}
+	e.platformsMux.Unlock()
+
+	simTime := e.GetSimulationTime()
+	events := make([]PlatformEvent, 0, len(expired))
+	for _, id := range expired {
+		events = append(events, PlatformEvent{
+			Type: PlatformEventRemoved, PlatformID: id, Reason: "stale", SimulationTime: simTime,
+		})
+	}
+	e.emitPlatformEvents(events)
+	return len(expired)
+}
+
+// GetExternalPlatformCount returns the number of external tracks currently shown
+func (e *Engine) GetExternalPlatformCount() int {
+	e.platformsMux.RLock()
+	defer e.platformsMux.RUnlock()
+	return e.countExternalLocked()
+}
+
+// countExternalLocked counts external platforms; platformsMux must be held
+func (e *Engine) countExternalLocked() int {
+	count := 0
+	for _, platform := range e.platforms {
+		if models.IsExternalPlatform(platform) {
+			count++
+		}
+	}
+	return count
+}
+
+// checkControllable rejects commands that would steer an external platform
+func checkControllable(platform models.Platform) error {
+	if models.IsExternalPlatform(platform) {
+		return fmt.Errorf("platform %s is an external track and cannot be controlled", platform.GetID())
+	}
+	return nil
+}
@@ -0,0 +1,155 @@
+package sim
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rhino11/trafficsim/internal/config"
+	"github.com/rhino11/trafficsim/internal/models"
+)
+
+func newExternalTrack(id string, stale time.Time) ExternalTrack {
+	return ExternalTrack{
+		ID:       id,
+		Callsign: "HAWK21",
+		Type:     models.PlatformTypeAirborne,
+		CoTType:  "a-h-A-M-F",
+		Source:   "cot",
+		Position: models.Position{Latitude: 36.9, Longitude: -76.1, Altitude: 5000},
+		Speed:    200,
+		Heading:  90,
+		Time:     stale.Add(-time.Minute),
+		Stale:    stale,
+	}
+}
+
+func TestUpdateExternalTrack(t *testing.T) {
+	engine := NewEngine(createScenarioTestConfig())
+	if err := engine.LoadScenario("alpha"); err != nil {
+		t.Fatalf("LoadScenario failed: %v", err)
+	}
+	recorder := &eventRecorder{}
+	engine.OnPlatformEvent(recorder.record)
+
+	stale := time.Date(2024, 6, 1, 12, 5, 0, 0, time.UTC)
+	track := newExternalTrack("ANDROID-1", stale)
+	if _, err := engine.UpdateExternalTrack(track); err != nil {
+		t.Fatalf("UpdateExternalTrack failed: %v", err)
+	}
+
+	platform, err := engine.GetPlatform("ANDROID-1")
+	if err != nil {
+		t.Fatalf("External platform not added: %v", err)
+	}
+	universalPlatform := platform.(*models.UniversalPlatform)
+	if !universalPlatform.IsExternal() || universalPlatform.GetCallSign() != "HAWK21" || universalPlatform.GetType() != models.PlatformTypeAirborne {
+		t.Errorf("Unexpected external platform %+v", universalPlatform)
+	}
+	if events := recorder.list(); len(events) != 1 || events[0].Type != PlatformEventAdded || events[0].Reason != "external" {
+		t.Errorf("Expected one external add event, got %+v", events)
+	}
+	if stats := engine.GetStatistics(); stats.ExternalPlatforms != 1 || stats.TotalPlatforms != 2 {
+		t.Errorf("Expected 1 external of 2 platforms, got %d of %d", stats.ExternalPlatforms, stats.TotalPlatforms)
+	}
+
+	// A newer report moves the track without another add event
+	track.Position.Latitude = 37.0
+	track.Callsign = "HAWK22"
+	if _, err := engine.UpdateExternalTrack(track); err != nil {
+		t.Fatalf("UpdateExternalTrack failed: %v", err)
+	}
+	if state := universalPlatform.GetState(); state.Position.Latitude != 37.0 || state.Velocity.East < 199 {
+		t.Errorf("Expected updated position and eastward velocity, got %+v", state)
+	}
+	if universalPlatform.GetCallSign() != "HAWK22" || len(recorder.list()) != 1 {
+		t.Errorf("Expected callsign update without a second add event")
+	}
+
+	// Simulated platforms cannot be overwritten by a feed
+	if _, err := engine.UpdateExternalTrack(newExternalTrack("AAL1", stale)); err == nil {
+		t.Error("Expected an external track to be refused for a simulated platform ID")
+	}
+	if _, err := engine.UpdateExternalTrack(ExternalTrack{}); err == nil {
+		t.Error("Expected an error for a track without an ID")
+	}
+}
+
+func TestExternalPlatformsAreReadOnly(t *testing.T) {
+	engine := NewEngine(createScenarioTestConfig())
+	if err := engine.LoadScenario("alpha"); err != nil {
+		t.Fatalf("LoadScenario failed: %v", err)
+	}
+	stale := time.Now().Add(time.Hour)
+	if _, err := engine.UpdateExternalTrack(newExternalTrack("ANDROID-1", stale)); err != nil {
+		t.Fatal(err)
+	}
+	platform, _ := engine.GetPlatform("ANDROID-1")
+	before := platform.GetState()
+
+	// Physics never moves an external platform, even at speed
+	if _, err := engine.RunFor(10*time.Second, time.Second); err != nil {
+		t.Fatalf("RunFor failed: %v", err)
+	}
+	if after := platform.GetState(); after.Position != before.Position || !after.LastUpdated.Equal(before.LastUpdated) {
+		t.Errorf("External platform moved from %+v to %+v", before.Position, after.Position)
+	}
+
+	target := models.Position{Latitude: 37, Longitude: -76}
+	if err := engine.SetDestinationForPlatform("ANDROID-1", target); err == nil {
+		t.Error("Expected SetDestinationForPlatform to refuse an external platform")
+	}
+	if err := engine.SetRouteForPlatform("ANDROID-1", []models.Position{target}, models.RouteModeOnce, nil); err == nil {
+		t.Error("Expected SetRouteForPlatform to refuse an external platform")
+	}
+	behavior := &config.BehaviorConfig{CircuitFlight: &config.CircuitBehavior{Center: config.Position{Latitude: 37, Longitude: -76}, Radius: 1000}}
+	if err := engine.SetBehaviorForPlatform("ANDROID-1", behavior); err == nil {
+		t.Error("Expected SetBehaviorForPlatform to refuse an external platform")
+	}
+
+	// Reset and scenario changes leave live tracks alone
+	if err := engine.Reset(); err != nil {
+		t.Fatal(err)
+	}
+	if err := engine.LoadScenario("bravo"); err != nil {
+		t.Fatal(err)
+	}
+	platform, err := engine.GetPlatform("ANDROID-1")
+	if err != nil {
+		t.Fatal("External platform removed by a scenario change")
+	}
+	if platform.GetState().Position != before.Position {
+		t.Error("External platform moved by Reset")
+	}
+}
+
+func TestExpireExternalTracks(t *testing.T) {
+	engine := NewEngine(createScenarioTestConfig())
+	recorder := &eventRecorder{}
+	engine.OnPlatformEvent(recorder.record)
+
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	for id, stale := range map[string]time.Time{"EARLY": now.Add(time.Minute), "LATE": now.Add(10 * time.Minute)} {
+		if _, err := engine.UpdateExternalTrack(newExternalTrack(id, stale)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if removed := engine.ExpireExternalTracks(now); removed != 0 {
+		t.Errorf("Expected no tracks stale yet, removed %d", removed)
+	}
+	if removed := engine.ExpireExternalTracks(now.Add(5 * time.Minute)); removed != 1 {
+		t.Errorf("Expected one stale track, removed %d", removed)
+	}
+	if _, err := engine.GetPlatform("EARLY"); err == nil {
+		t.Error("Expected EARLY to age out")
+	}
+	if engine.GetExternalPlatformCount() != 1 {
+		t.Errorf("Expected LATE to remain, got %d external platforms", engine.GetExternalPlatformCount())
+	}
+
+	events := recorder.list()
+	last := events[len(events)-1]
+	if last.Type != PlatformEventRemoved || last.PlatformID != "EARLY" || last.Reason != "stale" {
+		t.Errorf("Expected a stale removal event for EARLY, got %+v", last)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/rhino11/trafficsim/internal/models"
+	"github.com/rhino11/trafficsim/internal/terrain"
 )
 
 // Platform type constants
@@ -25,8 +26,13 @@ type PhysicsEngine struct {
 
 	// Simulation parameters
 	TimeStep      time.Duration
-	EnableWeather bool
-	EnableTerrain bool
+	EnableWeather bool         // Carry aircraft with the wind and ships with the current of Environment
+	EnableTerrain bool         // Put land platforms on the ground of Terrain and keep aircraft clear of it
+	Clock         models.Clock // Timestamps state updates; the engine sets it to simulation time
+	Environment   *Environment // Wind and current; nil is calm
+
+	Terrain          *terrain.Model // Ground elevation; nil, or a position no tile covers, is flat
+	TerrainClearance float64        // Meters aircraft keep above the ground en route
 }
 
 // NewPhysicsEngine creates a new physics engine with realistic constants
@@ -37,44 +43,82 @@ func NewPhysicsEngine() *PhysicsEngine {
 		AirDensity:    1.225,     // kg/m³
 		SeaLevelPress: 101325.0,  // Pa
 		TimeStep:      time.Second,
-		EnableWeather: false, // Start simple
-		EnableTerrain: false, // Start simple
+		EnableWeather: true,
+		EnableTerrain: true,
+		Clock:         models.WallClock{},
+
+		TerrainClearance: DefaultTerrainClearance,
+	}
+}
+
+// now returns the time to stamp state updates with
+func (pe *PhysicsEngine) now() time.Time {
+	if pe.Clock == nil {
+		return time.Now().UTC()
 	}
+	return pe.Clock.Now()
 }
 
 // CalculateMovement performs physics-based movement calculation for a platform
 func (pe *PhysicsEngine) CalculateMovement(platform models.Platform, deltaTime time.Duration) error {
 	// Try to cast to UniversalPlatform for enhanced physics
 	if universalPlatform, ok := platform.(*models.UniversalPlatform); ok {
-		return pe.updateUniversalPlatform(universalPlatform, deltaTime)
+		if err := pe.updateUniversalPlatform(universalPlatform, deltaTime); err != nil {
+			return err
+		}
+		pe.followTerrain(universalPlatform)
+		return nil
 	}
 
-	// Fallback to platform's own Update method for all platforms
-	return platform.Update(deltaTime)
+	// Fallback to platform's own Update method for all platforms, stamped with the physics clock
+	if err := platform.Update(deltaTime); err != nil {
+		return err
+	}
+	state := platform.GetState()
+	state.LastUpdated = pe.now()
+	platform.UpdateState(state)
+	return nil
 }
 
 // updateUniversalPlatform handles movement for the new universal platform system
 func (pe *PhysicsEngine) updateUniversalPlatform(platform *models.UniversalPlatform, deltaTime time.Duration) error {
 	deltaSeconds := deltaTime.Seconds()
 
-	// Skip movement if no destination
+	// Orbits are propagated to the current time rather than stepped
+	if platform.IsOrbiting() {
+		return platform.UpdateOrbit(pe.now())
+	}
+
+	// Skip movement if no destination; ships without one drift with the current
 	if platform.Destination == nil {
+		if platform.PlatformType == models.PlatformTypeMaritime {
+			pe.drift(platform, deltaSeconds)
+		}
 		return nil
 	}
 
-	// Calculate distance and bearing to destination
-	distance := pe.CalculateGreatCircleDistance(
-		platform.State.Position,
-		*platform.Destination,
-	)
+	// Calculate distance and bearing to destination; land platforms are on the ground wherever
+	// they are, so only their horizontal distance counts
+	distance := pe.destinationDistance(platform)
 
-	// Check if we've reached the destination
+	// Check if we've reached the destination (or the current route waypoint)
 	arrivalThreshold := pe.getArrivalThreshold(platform.PlatformType)
 	if distance < arrivalThreshold {
-		platform.State.Position = *platform.Destination
-		platform.Destination = nil
-		platform.State.Speed = 0
-		return nil
+		switch pe.handleWaypointArrival(platform) {
+		case arrivalNextWaypoint:
+			distance = pe.destinationDistance(platform)
+		case arrivalOrbit:
+			// Keep flying around the hold point
+		case arrivalStationKeep:
+			platform.State.Position = *platform.Destination
+			platform.State.Speed = 0
+			return nil
+		default:
+			platform.State.Position = *platform.Destination
+			platform.Destination = nil
+			platform.State.Speed = 0
+			return nil
+		}
 	}
 
 	// Calculate desired movement vector
@@ -113,11 +157,16 @@ func (pe *PhysicsEngine) updateAircraftPhysics(platform *models.UniversalPlatfor
 		turningRadius = (cruiseSpeed * cruiseSpeed) / (pe.GravityAccel * math.Tan(bankAngle))
 	}
 
+	// Steer and accelerate through the air, crabbing into any crosswind to hold the track
+	wind := pe.flow(platform)
+	heading, airspeed := throughFlow(platform.State, wind)
+	targetSpeed := pe.routeTargetSpeed(platform, math.Min(cruiseSpeed, maxSpeed))
+
 	// Apply heading change with turning constraints
 	newHeading := pe.applyTurningConstraints(
-		platform.State.Heading,
-		bearing,
-		platform.State.Speed,
+		heading,
+		correctForFlow(bearing, targetSpeed, wind),
+		airspeed,
 		turningRadius,
 		deltaSeconds,
 	)
@@ -137,17 +186,17 @@ func (pe *PhysicsEngine) updateAircraftPhysics(platform *models.UniversalPlatfor
 	}
 
 	// Apply speed control with acceleration limits
-	targetSpeed := math.Min(cruiseSpeed, maxSpeed)
 	platform.State.Speed = pe.applyAcceleration(
-		platform.State.Speed,
+		airspeed,
 		targetSpeed,
 		platform.TypeDef.Performance.Acceleration,
 		deltaSeconds,
 	)
 
-	// Update position
+	// Update position with the motion over the ground
+	addFlow(&platform.State, wind)
 	pe.updatePosition(&platform.State, deltaSeconds)
-	platform.State.LastUpdated = time.Now()
+	platform.State.LastUpdated = pe.now()
 
 	return nil
 }
@@ -163,11 +212,16 @@ func (pe *PhysicsEngine) updateMaritimePhysics(platform *models.UniversalPlatfor
 		turningRadius = platform.TypeDef.Physical.Length * 6 // 6x ship length
 	}
 
+	// Steer through the water, heading up into any cross current to hold the track
+	current := pe.flow(platform)
+	heading, waterSpeed := throughFlow(platform.State, current)
+	targetSpeed := pe.routeTargetSpeed(platform, cruiseSpeed)
+
 	// Apply heading change (ships turn slowly)
 	newHeading := pe.applyTurningConstraints(
-		platform.State.Heading,
-		bearing,
-		platform.State.Speed,
+		heading,
+		correctForFlow(bearing, targetSpeed, current),
+		waterSpeed,
 		turningRadius,
 		deltaSeconds,
 	)
@@ -183,21 +237,22 @@ func (pe *PhysicsEngine) updateMaritimePhysics(platform *models.UniversalPlatfor
 	}
 
 	platform.State.Speed = pe.applyAcceleration(
-		platform.State.Speed,
-		cruiseSpeed,
+		waterSpeed,
+		targetSpeed,
 		acceleration,
 		deltaSeconds,
 	)
 
-	// Update position
+	// Update position with the motion over the ground
+	addFlow(&platform.State, current)
 	pe.updatePosition(&platform.State, deltaSeconds)
-	platform.State.LastUpdated = time.Now()
+	platform.State.LastUpdated = pe.now()
 
 	return nil
 }
 
 // updateLandPhysics implements realistic land vehicle movement
-func (pe *PhysicsEngine) updateLandPhysics(platform *models.UniversalPlatform, bearing, distance, deltaSeconds float64) error {
+func (pe *PhysicsEngine) updateLandPhysics(platform *models.UniversalPlatform, bearing, _ /* distance */, deltaSeconds float64) error {
 	// Land vehicles have terrain constraints
 	cruiseSpeed := platform.TypeDef.Performance.CruiseSpeed
 
@@ -211,22 +266,20 @@ func (pe *PhysicsEngine) updateLandPhysics(platform *models.UniversalPlatform, b
 	)
 	platform.State.Heading = newHeading
 
-	// Apply gradient constraints
-	if pe.EnableTerrain {
-		pe.applyGradientConstraints(platform, distance)
-	}
-
 	// Apply acceleration
 	platform.State.Speed = pe.applyAcceleration(
 		platform.State.Speed,
-		cruiseSpeed,
+		pe.routeTargetSpeed(platform, cruiseSpeed),
 		platform.TypeDef.Performance.Acceleration,
 		deltaSeconds,
 	)
 
+	// Apply gradient constraints
+	pe.applyGradientConstraints(platform, deltaSeconds)
+
 	// Update position
 	pe.updatePosition(&platform.State, deltaSeconds)
-	platform.State.LastUpdated = time.Now()
+	platform.State.LastUpdated = pe.now()
 
 	return nil
 }
@@ -246,7 +299,7 @@ func (pe *PhysicsEngine) updateSpacePhysics(platform *models.UniversalPlatform,
 
 	// Update position in orbit
 	pe.updateOrbitalPosition(&platform.State, deltaSeconds)
-	platform.State.LastUpdated = time.Now()
+	platform.State.LastUpdated = pe.now()
 
 	return nil
 }
@@ -255,17 +308,98 @@ func (pe *PhysicsEngine) updateSpacePhysics(platform *models.UniversalPlatform,
 func (pe *PhysicsEngine) updateGenericPhysics(platform *models.UniversalPlatform, bearing, _ /* distance */, deltaSeconds float64) error {
 	// Basic movement
 	platform.State.Heading = bearing
-	platform.State.Speed = platform.TypeDef.Performance.CruiseSpeed
+	platform.State.Speed = pe.routeTargetSpeed(platform, platform.TypeDef.Performance.CruiseSpeed)
 
 	// Update position
 	pe.updatePosition(&platform.State, deltaSeconds)
-	platform.State.LastUpdated = time.Now()
+	platform.State.LastUpdated = pe.now()
 
 	return nil
 }
 
 // Helper methods
 
+// flow returns the wind carrying an aircraft or the current carrying a ship, zero for other
+// platforms or with weather disabled
+func (pe *PhysicsEngine) flow(platform *models.UniversalPlatform) models.Velocity {
+	if !pe.EnableWeather || pe.Environment == nil {
+		return models.Velocity{}
+	}
+	switch platform.PlatformType {
+	case models.PlatformTypeAirborne:
+		return pe.Environment.Wind(platform.State.Position, pe.now())
+	case models.PlatformTypeMaritime:
+		return pe.Environment.Current(platform.State.Position, pe.now())
+	}
+	return models.Velocity{}
+}
+
+// drift carries a ship without a destination along with the current
+func (pe *PhysicsEngine) drift(platform *models.UniversalPlatform, deltaSeconds float64) {
+	current := pe.flow(platform)
+	if current.East == 0 && current.North == 0 {
+		return
+	}
+	platform.State.Speed = 0
+	addFlow(&platform.State, current)
+	pe.updatePosition(&platform.State, deltaSeconds)
+	platform.State.LastUpdated = pe.now()
+}
+
+// throughFlow returns the heading and speed through the air or water of a platform whose state
+// holds its track and speed over the ground
+func throughFlow(state models.PlatformState, flow models.Velocity) (heading, speed float64) {
+	if flow.East == 0 && flow.North == 0 {
+		return state.Heading, state.Speed
+	}
+	sin, cos := math.Sincos(state.Heading * math.Pi / 180)
+	east := state.Speed*sin - flow.East
+	north := state.Speed*cos - flow.North
+	speed = math.Hypot(east, north)
+	if speed == 0 {
+		return state.Heading, 0
+	}
+	return math.Mod(math.Atan2(east, north)*180/math.Pi+360, 360), speed
+}
+
+// addFlow turns a state's heading and speed through the air or water into its track and speed
+// over the ground
+func addFlow(state *models.PlatformState, flow models.Velocity) {
+	if flow.East == 0 && flow.North == 0 {
+		return
+	}
+	sin, cos := math.Sincos(state.Heading * math.Pi / 180)
+	east := state.Speed*sin + flow.East
+	north := state.Speed*cos + flow.North
+	state.Speed = math.Hypot(east, north)
+	if state.Speed > 0 {
+		state.Heading = math.Mod(math.Atan2(east, north)*180/math.Pi+360, 360)
+	}
+}
+
+// correctForFlow returns the heading that holds a track at speed through a crossing flow. A flow
+// too strong to hold the track against leaves the heading at the track.
+func correctForFlow(track, speed float64, flow models.Velocity) float64 {
+	if speed <= 0 || (flow.East == 0 && flow.North == 0) {
+		return track
+	}
+	sin, cos := math.Sincos(track * math.Pi / 180)
+	crossing := flow.East*cos - flow.North*sin // Toward the right of the track
+	if math.Abs(crossing) >= speed {
+		return track
+	}
+	return math.Mod(track-math.Asin(crossing/speed)*180/math.Pi+360, 360)
+}
+
+// destinationDistance returns how far a platform is from its destination, ignoring altitude for
+// land platforms
+func (pe *PhysicsEngine) destinationDistance(platform *models.UniversalPlatform) float64 {
+	if platform.PlatformType == models.PlatformTypeLand {
+		return horizontalDistance(pe, platform.State.Position, *platform.Destination)
+	}
+	return pe.CalculateGreatCircleDistance(platform.State.Position, *platform.Destination)
+}
+
 func (pe *PhysicsEngine) getArrivalThreshold(platformType models.PlatformType) float64 {
 	switch platformType {
 	case models.PlatformTypeAirborne:
@@ -326,15 +460,6 @@ func (pe *PhysicsEngine) applyAcceleration(currentSpeed, targetSpeed, accelerati
 	}
 }
 
-func (pe *PhysicsEngine) applyGradientConstraints(platform *models.UniversalPlatform, _ /* distance */ float64) {
-	// Apply terrain gradient constraints for land vehicles
-	maxGradient := platform.TypeDef.Performance.MaxGradient
-	if maxGradient > 0 {
-		// Implement gradient checking logic here
-		// For now, this is a placeholder
-	}
-}
-
 func (pe *PhysicsEngine) updatePosition(state *models.PlatformState, deltaTime float64) {
 	// Convert heading to radians
 	headingRad := state.Heading * math.Pi / 180.0
@@ -406,3 +531,23 @@ func (pe *PhysicsEngine) CalculateBearing(from, to models.Position) float64 {
 	bearing := math.Atan2(y, x) * 180.0 / math.Pi
 	return math.Mod(bearing+360, 360)
 }
+
+// CalculateDestinationPoint returns the position reached by travelling distance meters from a start position on a bearing
+func (pe *PhysicsEngine) CalculateDestinationPoint(from models.Position, bearing, distance float64) models.Position {
+	lat1 := from.Latitude * math.Pi / 180.0
+	lon1 := from.Longitude * math.Pi / 180.0
+	bearingRad := bearing * math.Pi / 180.0
+	angularDistance := distance / pe.EarthRadius
+
+	lat2 := math.Asin(math.Sin(lat1)*math.Cos(angularDistance) +
+		math.Cos(lat1)*math.Sin(angularDistance)*math.Cos(bearingRad))
+	lon2 := lon1 + math.Atan2(math.Sin(bearingRad)*math.Sin(angularDistance)*math.Cos(lat1),
+		math.Cos(angularDistance)-math.Sin(lat1)*math.Sin(lat2))
+
+	longitude := math.Mod(lon2*180.0/math.Pi+540, 360) - 180
+	return models.Position{
+		Latitude:  lat2 * 180.0 / math.Pi,
+		Longitude: longitude,
+		Altitude:  from.Altitude,
+	}
+}
@@ -0,0 +1,141 @@
+package sim
+
+import (
+	"github.com/rhino11/trafficsim/internal/models"
+)
+
+// arrivalAction describes what a platform does when it reaches its current destination
+type arrivalAction int
+
+const (
+	arrivalStop         arrivalAction = iota // Snap to the destination and stop
+	arrivalStationKeep                       // Snap to the destination and keep it as the destination
+	arrivalOrbit                             // Keep flying around the destination (aircraft cannot stop)
+	arrivalNextWaypoint                      // Continue toward the next route waypoint
+)
+
+// RouteProgress summarizes how far a platform has progressed along its route
+type RouteProgress struct {
+	Mode              models.RouteMode `json:"mode"`
+	CurrentWaypoint   int              `json:"current_waypoint"`
+	TotalWaypoints    int              `json:"total_waypoints"`
+	Direction         int              `json:"direction"`
+	LapsCompleted     int              `json:"laps_completed"`
+	Completed         bool             `json:"completed"`
+	DistanceRemaining float64          `json:"distance_remaining"` // meters to the end of the current pass
+}
+
+// handleWaypointArrival advances the platform's route after it reaches its destination
+func (pe *PhysicsEngine) handleWaypointArrival(platform *models.UniversalPlatform) arrivalAction {
+	plan := platform.RoutePlan
+	if plan == nil || len(platform.Route) == 0 {
+		return arrivalStop
+	}
+
+	if plan.Completed {
+		return pe.stationKeepingAction(platform)
+	}
+	if plan.Direction == 0 {
+		plan.Direction = 1
+	}
+
+	last := len(platform.Route) - 1
+	next := plan.CurrentIndex + plan.Direction
+	if next >= 0 && next <= last {
+		pe.steerToWaypoint(platform, next)
+		return arrivalNextWaypoint
+	}
+
+	// Reached an end of the route
+	switch {
+	case plan.Mode == models.RouteModeLoop && last > 0:
+		plan.LapsCompleted++
+		if plan.MaxLaps > 0 && plan.LapsCompleted >= plan.MaxLaps {
+			plan.Completed = true
+			return pe.stationKeepingAction(platform)
+		}
+		pe.steerToWaypoint(platform, 0)
+		return arrivalNextWaypoint
+	case plan.Mode == models.RouteModeReverse && last > 0:
+		if plan.Direction < 0 {
+			plan.LapsCompleted++
+			if plan.MaxLaps > 0 && plan.LapsCompleted >= plan.MaxLaps {
+				plan.Completed = true
+				return pe.stationKeepingAction(platform)
+			}
+		}
+		plan.Direction = -plan.Direction
+		pe.steerToWaypoint(platform, plan.CurrentIndex+plan.Direction)
+		return arrivalNextWaypoint
+	case plan.Mode == models.RouteModeOnce:
+		plan.Completed = true
+		return arrivalStop
+	default:
+		// Hold mode, or a single-waypoint loop/reverse route, keeps station at the end
+		plan.Completed = true
+		return pe.stationKeepingAction(platform)
+	}
+}
+
+// stationKeepingAction returns how a platform holds position at its final waypoint
+func (pe *PhysicsEngine) stationKeepingAction(platform *models.UniversalPlatform) arrivalAction {
+	if platform.PlatformType == models.PlatformTypeAirborne {
+		return arrivalOrbit
+	}
+	return arrivalStationKeep
+}
+
+// steerToWaypoint makes the given route waypoint the platform's active destination
+func (pe *PhysicsEngine) steerToWaypoint(platform *models.UniversalPlatform, index int) {
+	platform.RoutePlan.CurrentIndex = index
+	waypoint := platform.Route[index]
+	platform.Destination = &waypoint
+}
+
+// routeTargetSpeed returns the current leg speed when one is set, capped at maxSpeed
+func (pe *PhysicsEngine) routeTargetSpeed(platform *models.UniversalPlatform, defaultSpeed float64) float64 {
+	legSpeed := platform.CurrentLegSpeed()
+	if legSpeed <= 0 {
+		return defaultSpeed
+	}
+
+	if maxSpeed := platform.TypeDef.Performance.MaxSpeed; maxSpeed > 0 && legSpeed > maxSpeed {
+		return maxSpeed
+	}
+	return legSpeed
+}
+
+// CalculateRouteProgress reports route progress for a platform, or nil when it has no route
+func (pe *PhysicsEngine) CalculateRouteProgress(platform *models.UniversalPlatform) *RouteProgress {
+	plan := platform.RoutePlan
+	if plan == nil || len(platform.Route) == 0 {
+		return nil
+	}
+
+	progress := &RouteProgress{
+		Mode:            plan.Mode,
+		CurrentWaypoint: plan.CurrentIndex,
+		TotalWaypoints:  len(platform.Route),
+		Direction:       plan.Direction,
+		LapsCompleted:   plan.LapsCompleted,
+		Completed:       plan.Completed,
+	}
+
+	if plan.Completed {
+		return progress
+	}
+
+	direction := plan.Direction
+	if direction == 0 {
+		direction = 1
+	}
+
+	// Distance to the active waypoint plus the remaining legs in the current direction
+	remaining := pe.CalculateGreatCircleDistance(platform.State.Position, platform.Route[plan.CurrentIndex])
+	for i := plan.CurrentIndex; i+direction >= 0 && i+direction < len(platform.Route); i += direction {
+		remaining += pe.CalculateGreatCircleDistance(platform.Route[i], platform.Route[i+direction])
+	}
+	progress.DistanceRemaining = remaining
+
+	return progress
+}
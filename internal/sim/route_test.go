@@ -0,0 +1,202 @@
+package sim
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rhino11/trafficsim/internal/models"
+)
+
+// createRouteTestPlatform creates a platform sitting on the first of three waypoints
+func createRouteTestPlatform(t *testing.T, platformType models.PlatformType, mode models.RouteMode) *models.UniversalPlatform {
+	t.Helper()
+
+	waypoints := []models.Position{
+		{Latitude: 36.80, Longitude: -76.30},
+		{Latitude: 36.85, Longitude: -76.30},
+		{Latitude: 36.85, Longitude: -76.25},
+	}
+
+	platform := &models.UniversalPlatform{
+		ID:           "route-test",
+		PlatformType: platformType,
+		State: models.PlatformState{
+			Position: waypoints[0],
+			Speed:    10,
+		},
+		TypeDef: &models.PlatformTypeDefinition{
+			Performance: models.PerformanceCharacteristics{
+				MaxSpeed:      20,
+				CruiseSpeed:   10,
+				TurningRadius: 500,
+				Acceleration:  1.0,
+			},
+		},
+	}
+
+	if err := platform.SetRoute(waypoints, mode, nil); err != nil {
+		t.Fatalf("SetRoute failed: %v", err)
+	}
+	return platform
+}
+
+// arriveAtCurrentWaypoint places the platform on its active waypoint and runs one physics step
+func arriveAtCurrentWaypoint(t *testing.T, pe *PhysicsEngine, platform *models.UniversalPlatform) {
+	t.Helper()
+
+	platform.State.Position = *platform.Destination
+	if err := pe.CalculateMovement(platform, time.Second); err != nil {
+		t.Fatalf("CalculateMovement failed: %v", err)
+	}
+}
+
+func TestRouteOnceAdvancesAndStops(t *testing.T) {
+	pe := NewPhysicsEngine()
+	platform := createRouteTestPlatform(t, models.PlatformTypeMaritime, models.RouteModeOnce)
+
+	arriveAtCurrentWaypoint(t, pe, platform)
+	if platform.RoutePlan.CurrentIndex != 1 {
+		t.Fatalf("Expected to steer toward waypoint 1, got %d", platform.RoutePlan.CurrentIndex)
+	}
+	if *platform.Destination != platform.Route[1] {
+		t.Error("Expected destination to be waypoint 1")
+	}
+
+	arriveAtCurrentWaypoint(t, pe, platform)
+	arriveAtCurrentWaypoint(t, pe, platform)
+
+	if !platform.RoutePlan.Completed {
+		t.Error("Expected route to be completed")
+	}
+	if platform.Destination != nil {
+		t.Error("Expected destination to be cleared after final waypoint")
+	}
+	if platform.State.Speed != 0 {
+		t.Errorf("Expected platform to stop, got speed %f", platform.State.Speed)
+	}
+}
+
+func TestRouteLoopCountsLaps(t *testing.T) {
+	pe := NewPhysicsEngine()
+	platform := createRouteTestPlatform(t, models.PlatformTypeMaritime, models.RouteModeLoop)
+
+	// 0 -> 1 -> 2 -> 0 -> 1
+	for i := 0; i < 4; i++ {
+		arriveAtCurrentWaypoint(t, pe, platform)
+	}
+
+	if platform.RoutePlan.LapsCompleted != 1 {
+		t.Errorf("Expected 1 lap, got %d", platform.RoutePlan.LapsCompleted)
+	}
+	if platform.RoutePlan.CurrentIndex != 1 {
+		t.Errorf("Expected to steer toward waypoint 1, got %d", platform.RoutePlan.CurrentIndex)
+	}
+	if platform.RoutePlan.Completed {
+		t.Error("Loop route should never complete")
+	}
+}
+
+func TestRouteReverseRetracesWaypoints(t *testing.T) {
+	pe := NewPhysicsEngine()
+	platform := createRouteTestPlatform(t, models.PlatformTypeMaritime, models.RouteModeReverse)
+
+	expected := []struct {
+		index     int
+		direction int
+	}{
+		{1, 1},  // arrived at 0
+		{2, 1},  // arrived at 1
+		{1, -1}, // arrived at 2, turn around
+		{0, -1}, // arrived at 1
+		{1, 1},  // arrived at 0, lap complete
+	}
+
+	for i, want := range expected {
+		arriveAtCurrentWaypoint(t, pe, platform)
+		if platform.RoutePlan.CurrentIndex != want.index || platform.RoutePlan.Direction != want.direction {
+			t.Fatalf("Step %d: expected index %d direction %d, got index %d direction %d",
+				i, want.index, want.direction, platform.RoutePlan.CurrentIndex, platform.RoutePlan.Direction)
+		}
+	}
+
+	if platform.RoutePlan.LapsCompleted != 1 {
+		t.Errorf("Expected 1 lap, got %d", platform.RoutePlan.LapsCompleted)
+	}
+}
+
+func TestRouteHoldKeepsStation(t *testing.T) {
+	pe := NewPhysicsEngine()
+
+	vessel := createRouteTestPlatform(t, models.PlatformTypeMaritime, models.RouteModeHold)
+	for i := 0; i < 3; i++ {
+		arriveAtCurrentWaypoint(t, pe, vessel)
+	}
+
+	if !vessel.RoutePlan.Completed {
+		t.Error("Expected hold route to be completed")
+	}
+	if vessel.Destination == nil || *vessel.Destination != vessel.Route[2] {
+		t.Error("Expected vessel to keep the final waypoint as its destination")
+	}
+	if vessel.State.Speed != 0 {
+		t.Errorf("Expected vessel to hold position, got speed %f", vessel.State.Speed)
+	}
+
+	// Aircraft cannot stop, so they keep flying around the hold point
+	aircraft := createRouteTestPlatform(t, models.PlatformTypeAirborne, models.RouteModeHold)
+	for i := 0; i < 3; i++ {
+		arriveAtCurrentWaypoint(t, pe, aircraft)
+	}
+
+	if aircraft.Destination == nil {
+		t.Fatal("Expected aircraft to keep its hold point")
+	}
+	if aircraft.State.Speed <= 0 {
+		t.Error("Expected aircraft to keep flying while holding")
+	}
+}
+
+func TestRouteLegSpeed(t *testing.T) {
+	pe := NewPhysicsEngine()
+	platform := createRouteTestPlatform(t, models.PlatformTypeMaritime, models.RouteModeOnce)
+	platform.RoutePlan.Speeds = []float64{0, 5, 50}
+
+	if speed := pe.routeTargetSpeed(platform, 10); speed != 10 {
+		t.Errorf("Expected cruise speed for unset leg, got %f", speed)
+	}
+
+	platform.RoutePlan.CurrentIndex = 1
+	if speed := pe.routeTargetSpeed(platform, 10); speed != 5 {
+		t.Errorf("Expected leg speed 5, got %f", speed)
+	}
+
+	platform.RoutePlan.CurrentIndex = 2
+	if speed := pe.routeTargetSpeed(platform, 10); speed != 20 {
+		t.Errorf("Expected leg speed capped at max speed 20, got %f", speed)
+	}
+}
+
+func TestCalculateRouteProgress(t *testing.T) {
+	pe := NewPhysicsEngine()
+
+	if pe.CalculateRouteProgress(&models.UniversalPlatform{}) != nil {
+		t.Error("Expected no progress for platform without a route")
+	}
+
+	platform := createRouteTestPlatform(t, models.PlatformTypeMaritime, models.RouteModeLoop)
+	arriveAtCurrentWaypoint(t, pe, platform)
+
+	progress := pe.CalculateRouteProgress(platform)
+	if progress == nil {
+		t.Fatal("Expected route progress")
+	}
+	if progress.CurrentWaypoint != 1 || progress.TotalWaypoints != 3 {
+		t.Errorf("Expected waypoint 1 of 3, got %d of %d", progress.CurrentWaypoint, progress.TotalWaypoints)
+	}
+
+	expected := pe.CalculateGreatCircleDistance(platform.State.Position, platform.Route[1]) +
+		pe.CalculateGreatCircleDistance(platform.Route[1], platform.Route[2])
+	if diff := progress.DistanceRemaining - expected; diff > 1 || diff < -1 {
+		t.Errorf("Expected distance remaining %f, got %f", expected, progress.DistanceRemaining)
+	}
+}
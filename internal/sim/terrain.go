@@ -0,0 +1,96 @@
+package sim
+
+import (
+	"math"
+
+	"github.com/rhino11/trafficsim/internal/models"
+)
+
+// DefaultTerrainClearance is the height in meters aircraft keep above the ground en route, about
+// 500 ft
+const DefaultTerrainClearance = 150.0
+
+// terrainProbeDistance is the shortest distance ahead a land vehicle checks the slope over, about
+// the spacing of 1 arc-second elevation samples
+const terrainProbeDistance = 30.0
+
+// gradientDeflections are the turns off the intended heading a land vehicle tries, smallest first,
+// when the slope ahead is too steep
+var gradientDeflections = []float64{0, 15, -15, 30, -30, 45, -45, 60, -60, 75, -75, 90, -90}
+
+// GroundElevation returns the ground height above mean sea level at a position. It reports false
+// with terrain disabled or where no tile covers the position.
+func (pe *PhysicsEngine) GroundElevation(position models.Position) (float64, bool) {
+	if !pe.EnableTerrain || pe.Terrain == nil {
+		return 0, false
+	}
+	return pe.Terrain.Elevation(position.Latitude, position.Longitude)
+}
+
+// followTerrain puts land platforms on the ground and keeps aircraft clear of it. Positions without
+// elevation data are left alone.
+func (pe *PhysicsEngine) followTerrain(platform *models.UniversalPlatform) {
+	ground, ok := pe.GroundElevation(platform.State.Position)
+	if !ok {
+		return
+	}
+
+	switch platform.PlatformType {
+	case models.PlatformTypeLand:
+		platform.State.Position.Altitude = ground
+	case models.PlatformTypeAirborne:
+		floor := ground
+		if pe.enRoute(platform) {
+			floor += pe.TerrainClearance
+		}
+		platform.State.Position.Altitude = math.Max(platform.State.Position.Altitude, floor)
+	}
+}
+
+// enRoute reports whether an aircraft is flying to a point at least the terrain clearance above
+// the ground. Aircraft landing or heading down to low level are only kept above the ground.
+func (pe *PhysicsEngine) enRoute(platform *models.UniversalPlatform) bool {
+	if platform.Destination == nil {
+		return false
+	}
+	ground, _ := pe.GroundElevation(*platform.Destination)
+	return platform.Destination.Altitude-ground >= pe.TerrainClearance
+}
+
+// applyGradientConstraints keeps a land vehicle off slopes steeper than its maximum gradient. It
+// turns off a blocked heading by the smallest deflection that finds a passable slope, and stops
+// when there is none.
+func (pe *PhysicsEngine) applyGradientConstraints(platform *models.UniversalPlatform, deltaSeconds float64) {
+	maxGradient := platform.TypeDef.Performance.MaxGradient
+	if maxGradient <= 0 || platform.State.Speed <= 0 {
+		return
+	}
+	position := platform.State.Position
+	ground, ok := pe.GroundElevation(position)
+	if !ok {
+		return
+	}
+
+	// The slope is checked over this step and, for short steps, far enough ahead to see it coming
+	probes := []float64{platform.State.Speed * deltaSeconds}
+	if probes[0] < terrainProbeDistance {
+		probes = append(probes, terrainProbeDistance)
+	}
+	passable := func(heading float64) bool {
+		for _, probe := range probes {
+			ahead, ok := pe.GroundElevation(pe.CalculateDestinationPoint(position, heading, probe))
+			if ok && math.Atan(math.Abs(ahead-ground)/probe)*180/math.Pi > maxGradient {
+				return false
+			}
+		}
+		return true
+	}
+	for _, deflection := range gradientDeflections {
+		heading := math.Mod(platform.State.Heading+deflection+360, 360)
+		if passable(heading) {
+			platform.State.Heading = heading
+			return
+		}
+	}
+	platform.State.Speed = 0
+}
@@ -0,0 +1,228 @@
+package sim
+
+import (
+	"encoding/binary"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rhino11/trafficsim/internal/config"
+	"github.com/rhino11/trafficsim/internal/models"
+	"github.com/rhino11/trafficsim/internal/terrain"
+)
+
+// writeTerrain writes a 3 arc-second SRTM tile for the square degree north-east of 37°N 76°W and
+// opens it
+func writeTerrain(t *testing.T, elevation func(latitude, longitude float64) float64) (string, *terrain.Model) {
+	t.Helper()
+	const side = 1201
+	data := make([]byte, 2*side*side)
+	for row := 0; row < side; row++ {
+		for column := 0; column < side; column++ {
+			sample := int16(math.Round(elevation(38-float64(row)/(side-1), -76+float64(column)/(side-1))))
+			binary.BigEndian.PutUint16(data[2*(row*side+column):], uint16(sample))
+		}
+	}
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "N37W076.hgt"), data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	model, err := terrain.Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir, model
+}
+
+// metersNorth converts a distance north to degrees of latitude
+func metersNorth(meters float64) float64 {
+	return meters / 6371000 * 180 / math.Pi
+}
+
+func newLandVehicle(id string, position models.Position, maxGradient float64) *models.UniversalPlatform {
+	return &models.UniversalPlatform{
+		ID:           id,
+		PlatformType: models.PlatformTypeLand,
+		State:        models.PlatformState{Position: position},
+		TypeDef: &models.PlatformTypeDefinition{Performance: models.PerformanceCharacteristics{
+			MaxSpeed: 20, CruiseSpeed: 10, MaxGradient: maxGradient,
+		}},
+	}
+}
+
+func TestPhysics_LandFollowsTerrain(t *testing.T) {
+	pe := NewPhysicsEngine()
+	_, pe.Terrain = writeTerrain(t, func(latitude, longitude float64) float64 { return 100 + (latitude-37)*500 })
+
+	// Parked and driving vehicles sit on the ground
+	parked := newLandVehicle("PARKED", models.Position{Latitude: 37.5, Longitude: -75.5, Altitude: 0}, 30)
+	driving := newLandVehicle("DRIVING", models.Position{Latitude: 37.2, Longitude: -75.5, Altitude: 5000}, 30)
+	driving.Destination = &models.Position{Latitude: 37.4, Longitude: -75.5}
+	for i := 0; i < 60; i++ {
+		for _, vehicle := range []*models.UniversalPlatform{parked, driving} {
+			if err := pe.CalculateMovement(vehicle, time.Second); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	for _, vehicle := range []*models.UniversalPlatform{parked, driving} {
+		want := 100 + (vehicle.State.Position.Latitude-37)*500
+		if math.Abs(vehicle.State.Position.Altitude-want) > 0.5 {
+			t.Errorf("%s altitude = %.1f m, want the ground at %.1f m", vehicle.ID, vehicle.State.Position.Altitude, want)
+		}
+	}
+
+	// Outside the tiles, or with terrain disabled, the altitude is left alone
+	outside := newLandVehicle("OUTSIDE", models.Position{Latitude: 40, Longitude: -75.5, Altitude: 42}, 30)
+	if err := pe.CalculateMovement(outside, time.Second); err != nil || outside.State.Position.Altitude != 42 {
+		t.Errorf("Expected the altitude outside the tiles to stay 42 m, got %v, %v", outside.State.Position.Altitude, err)
+	}
+	pe.EnableTerrain = false
+	parked.State.Position.Altitude = 7
+	if err := pe.CalculateMovement(parked, time.Second); err != nil || parked.State.Position.Altitude != 7 {
+		t.Errorf("Expected terrain to be ignored when disabled, got %v, %v", parked.State.Position.Altitude, err)
+	}
+}
+
+func TestPhysics_GradientConstraints(t *testing.T) {
+	// A 2 km ridge rising at 26.6° (a 1 in 2 slope) from 37.5°N
+	base := 37.5
+	width := metersNorth(2000)
+	pe := NewPhysicsEngine()
+	_, pe.Terrain = writeTerrain(t, func(latitude, longitude float64) float64 {
+		return math.Max(0, math.Min(latitude-base, width)) / width * 1000
+	})
+
+	drive := func(maxGradient float64) (*models.UniversalPlatform, float64) {
+		vehicle := newLandVehicle("CLIMBER", models.Position{Latitude: base - metersNorth(300), Longitude: -75.5}, maxGradient)
+		vehicle.Destination = &models.Position{Latitude: base + width + metersNorth(500), Longitude: -75.5}
+		steepest := 0.0
+		for i := 0; i < 900 && vehicle.Destination != nil; i++ {
+			before := vehicle.State.Position
+			if err := pe.CalculateMovement(vehicle, time.Second); err != nil {
+				t.Fatal(err)
+			}
+			if run := horizontalDistance(pe, before, vehicle.State.Position); run > 1 {
+				climb := vehicle.State.Position.Altitude - before.Altitude
+				steepest = math.Max(steepest, math.Atan(math.Abs(climb)/run)*180/math.Pi)
+			}
+		}
+		return vehicle, steepest
+	}
+
+	// A tank drives straight up
+	tank, steepest := drive(60)
+	if tank.Destination != nil || steepest < 25 {
+		t.Errorf("Expected a 60° vehicle to climb the ridge directly, steepest slope %.1f°", steepest)
+	}
+
+	// A truck climbs it across the slope, never steeper than it can manage
+	truck, steepest := drive(15)
+	if truck.State.Position.Altitude < 999 {
+		t.Errorf("Expected a 15° vehicle to reach the top of the ridge, at %.0f m", truck.State.Position.Altitude)
+	}
+	if steepest > 15.5 {
+		t.Errorf("A 15° vehicle drove up a %.1f° slope", steepest)
+	}
+}
+
+func TestPhysics_GradientBlocked(t *testing.T) {
+	// A summit falling away at 45° all round
+	pe := NewPhysicsEngine()
+	_, pe.Terrain = writeTerrain(t, func(latitude, longitude float64) float64 {
+		north := (latitude - 37.5) * math.Pi / 180 * 6371000
+		east := (longitude + 75.5) * math.Pi / 180 * 6371000 * math.Cos(37.5*math.Pi/180)
+		return math.Max(0, 2000-math.Hypot(north, east))
+	})
+
+	vehicle := newLandVehicle("STRANDED", models.Position{Latitude: 37.5, Longitude: -75.5}, 20)
+	vehicle.Destination = &models.Position{Latitude: 37.6, Longitude: -75.5}
+	for i := 0; i < 10; i++ {
+		if err := pe.CalculateMovement(vehicle, time.Second); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if vehicle.State.Speed != 0 || horizontalDistance(pe, vehicle.State.Position, models.Position{Latitude: 37.5, Longitude: -75.5}) > 1 {
+		t.Errorf("Expected a 20° vehicle to stay on a 45° summit, moved at %v m/s to %+v", vehicle.State.Speed, vehicle.State.Position)
+	}
+}
+
+func TestPhysics_AircraftTerrainClearance(t *testing.T) {
+	pe := NewPhysicsEngine()
+	_, pe.Terrain = writeTerrain(t, func(latitude, longitude float64) float64 { return 1000 })
+
+	newAircraft := func(altitude, destinationAltitude float64) *models.UniversalPlatform {
+		return &models.UniversalPlatform{
+			ID:           "LOWLEVEL",
+			PlatformType: models.PlatformTypeAirborne,
+			State:        models.PlatformState{Position: models.Position{Latitude: 37.2, Longitude: -75.5, Altitude: altitude}, Speed: 100},
+			TypeDef: &models.PlatformTypeDefinition{Performance: models.PerformanceCharacteristics{
+				MaxSpeed: 150, CruiseSpeed: 100, ClimbRate: 10,
+			}},
+			Destination: &models.Position{Latitude: 37.8, Longitude: -75.5, Altitude: destinationAltitude},
+		}
+	}
+
+	// En route, an aircraft below the clearance is lifted to it
+	enRoute := newAircraft(900, 3000)
+	if err := pe.CalculateMovement(enRoute, time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if got := enRoute.State.Position.Altitude; got < 1000+DefaultTerrainClearance {
+		t.Errorf("En route altitude = %.0f m, want at least %.0f m", got, 1000+DefaultTerrainClearance)
+	}
+
+	// Descending to land, it is only kept above the ground
+	landing := newAircraft(1050, 1000)
+	for i := 0; i < 10; i++ {
+		if err := pe.CalculateMovement(landing, time.Second); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := landing.State.Position.Altitude; got < 1000 || got >= 1000+DefaultTerrainClearance {
+		t.Errorf("Landing altitude = %.0f m, want it down near the ground at 1000 m", got)
+	}
+}
+
+func TestEngine_Terrain(t *testing.T) {
+	dir, _ := writeTerrain(t, func(latitude, longitude float64) float64 { return 250 })
+	engine := NewEngine(&config.Config{Simulation: config.SimulationConfig{TerrainDir: dir, TerrainClearance: 300}})
+	if engine.GetTerrain().Tiles() != 1 || engine.physics.TerrainClearance != 300 {
+		t.Fatalf("Expected 1 tile and a 300 m clearance, got %d and %v", engine.GetTerrain().Tiles(), engine.physics.TerrainClearance)
+	}
+
+	aircraft := &models.UniversalPlatform{
+		ID:           "AGL",
+		PlatformType: models.PlatformTypeAirborne,
+		State:        models.PlatformState{Position: models.Position{Latitude: 37.5, Longitude: -75.5, Altitude: 1000}},
+		TypeDef:      &models.PlatformTypeDefinition{},
+		Config:       &models.PlatformConfiguration{Name: "AGL"},
+	}
+	if err := engine.AddPlatform(aircraft); err != nil {
+		t.Fatal(err)
+	}
+	status, err := engine.GetPlatformStatus("AGL")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.GroundElevation == nil || *status.GroundElevation != 250 || status.AltitudeAGL == nil || *status.AltitudeAGL != 750 {
+		t.Errorf("Expected ground at 250 m and 750 m AGL, got %v and %v", status.GroundElevation, status.AltitudeAGL)
+	}
+
+	// Outside the tiles the status has no ground
+	aircraft.State.Position.Latitude = 45
+	if status, _ := engine.GetPlatformStatus("AGL"); status.GroundElevation != nil || status.AltitudeAGL != nil {
+		t.Error("Expected no ground elevation outside the tiles")
+	}
+
+	// A missing directory leaves the ground flat
+	engine = NewEngine(&config.Config{Simulation: config.SimulationConfig{TerrainDir: filepath.Join(dir, "missing")}})
+	if engine.GetTerrain() != nil {
+		t.Error("Expected no terrain from a missing directory")
+	}
+	if err := engine.LoadTerrain(filepath.Join(dir, "missing")); err == nil {
+		t.Error("Expected LoadTerrain to report a missing directory")
+	}
+}
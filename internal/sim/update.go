@@ -0,0 +1,104 @@
+package sim
+
+import (
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/rhino11/trafficsim/internal/models"
+)
+
+// minPlatformsPerWorker keeps small simulations on one goroutine, where fanning out costs more than it saves
+const minPlatformsPerWorker = 256
+
+// platformUpdate is one platform and its behavior, updated together by a single worker
+type platformUpdate struct {
+	platform models.Platform
+	behavior Behavior
+}
+
+// stepClock is a models.Clock fixed at the end of the current step, shared read-only by the workers
+type stepClock time.Time
+
+func (c stepClock) Now() time.Time { return time.Time(c) }
+
+// defaultWorkers returns the worker count used when none is configured
+func defaultWorkers() int {
+	return runtime.GOMAXPROCS(0)
+}
+
+// SetWorkers sets how many goroutines share the platform updates of each step; 0 or less uses GOMAXPROCS
+func (e *Engine) SetWorkers(workers int) {
+	if workers <= 0 {
+		workers = defaultWorkers()
+	}
+	e.stepMux.Lock()
+	defer e.stepMux.Unlock()
+	e.workers = workers
+}
+
+// GetWorkers returns the number of update workers
+func (e *Engine) GetWorkers() int {
+	e.stepMux.Lock()
+	defer e.stepMux.Unlock()
+	return e.workers
+}
+
+// collectUpdates snapshots the simulated platforms and behaviors to update this step into the reusable buffer.
+// stepMux must be held.
+func (e *Engine) collectUpdates() []platformUpdate {
+	e.platformsMux.RLock()
+	defer e.platformsMux.RUnlock()
+
+	updates := e.updateBuf[:0]
+	for id, platform := range e.platforms {
+		if models.IsExternalPlatform(platform) {
+			continue // Moved only by their feed
+		}
+		updates = append(updates, platformUpdate{platform: platform, behavior: e.behaviors[id]})
+	}
+	e.updateBuf = updates
+	return updates
+}
+
+// updatePlatforms runs behaviors and physics for every platform, split into contiguous shards across
+// the workers. Each platform is touched by exactly one worker, and the physics engine is shared read-only.
+func (e *Engine) updatePlatforms(updates []platformUpdate, physics *PhysicsEngine, deltaTime time.Duration) {
+	workers := e.workers
+	if maxWorkers := len(updates) / minPlatformsPerWorker; workers > maxWorkers {
+		workers = maxWorkers
+	}
+	if workers <= 1 {
+		updatePlatformShard(updates, physics, deltaTime)
+		return
+	}
+
+	var wg sync.WaitGroup
+	shardSize := (len(updates) + workers - 1) / workers
+	for start := 0; start < len(updates); start += shardSize {
+		end := start + shardSize
+		if end > len(updates) {
+			end = len(updates)
+		}
+		wg.Add(1)
+		go func(shard []platformUpdate) {
+			defer wg.Done()
+			updatePlatformShard(shard, physics, deltaTime)
+		}(updates[start:end])
+	}
+	wg.Wait()
+}
+
+// updatePlatformShard lets each behavior steer its platform, then moves the platform
+func updatePlatformShard(shard []platformUpdate, physics *PhysicsEngine, deltaTime time.Duration) {
+	for _, update := range shard {
+		if update.behavior != nil {
+			if universalPlatform, ok := update.platform.(*models.UniversalPlatform); ok {
+				update.behavior.Update(universalPlatform, physics)
+			}
+		}
+		if err := physics.CalculateMovement(update.platform, deltaTime); err != nil {
+			logf("Error updating platform %s: %v", update.platform.GetID(), err)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package sim
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/rhino11/trafficsim/internal/config"
+	"github.com/rhino11/trafficsim/internal/models"
+)
+
+// createLoadTestEngine creates an engine with count moving platforms of every domain, a quarter of them
+// on random walks, drawn from a fixed seed so engines built with the same arguments match
+func createLoadTestEngine(tb testing.TB, count, workers int) *Engine {
+	tb.Helper()
+	engine := NewEngine(createScenarioTestConfig())
+	engine.SetWorkers(workers)
+
+	rng := rand.New(rand.NewSource(1))
+	walk := &config.BehaviorConfig{RandomWalk: &config.RandomWalkBehavior{
+		Area:        config.BoundingBox{North: 50, South: 25, East: -70, West: -120},
+		MaxDistance: 20000,
+	}}
+
+	for i := 0; i < count; i++ {
+		id := fmt.Sprintf("P%05d", i)
+		start := models.Position{Latitude: 25 + rng.Float64()*25, Longitude: -120 + rng.Float64()*50}
+		var platform *models.UniversalPlatform
+		switch i % 4 {
+		case 0:
+			start.Altitude = 10000
+			platform = models.NewBoeing737_800Universal(id, id, start)
+		case 1:
+			platform = models.NewArleighBurkeDestroyerUniversal(id, id, start)
+		case 2:
+			platform = models.NewM1A2AbramsUniversal(id, id, start)
+		default:
+			start.Altitude = 550000
+			platform = models.NewStarlinkSatelliteUniversal(id, id, start)
+		}
+		destination := models.Position{Latitude: start.Latitude + 1, Longitude: start.Longitude + 1, Altitude: start.Altitude}
+		if err := platform.SetDestination(destination); err != nil {
+			tb.Fatalf("SetDestination failed: %v", err)
+		}
+
+		engine.platforms[id] = platform
+		if i%4 == 1 {
+			behavior, err := NewBehavior(walk, rand.New(rand.NewSource(int64(i))))
+			if err != nil {
+				tb.Fatalf("NewBehavior failed: %v", err)
+			}
+			engine.behaviors[id] = behavior
+		}
+	}
+	return engine
+}
+
+func TestParallelUpdateMatchesSerial(t *testing.T) {
+	const count = 2000
+	serial := createLoadTestEngine(t, count, 1)
+	parallel := createLoadTestEngine(t, count, 8)
+
+	for _, engine := range []*Engine{serial, parallel} {
+		if _, err := engine.RunFor(30*time.Second, time.Second); err != nil {
+			t.Fatalf("RunFor failed: %v", err)
+		}
+	}
+
+	for id, platform := range serial.platforms {
+		want, got := platform.GetState(), parallel.platforms[id].GetState()
+		if want.Position != got.Position || want.Speed != got.Speed || want.Heading != got.Heading {
+			t.Fatalf("Platform %s diverged: serial %+v, parallel %+v", id, want.Position, got.Position)
+		}
+		if !got.LastUpdated.Equal(parallel.Now()) {
+			t.Fatalf("Platform %s stamped %v, expected the step clock %v", id, got.LastUpdated, parallel.Now())
+		}
+	}
+}
+
+func TestSetWorkers(t *testing.T) {
+	cfg := createScenarioTestConfig()
+	cfg.Simulation.Workers = 3
+	engine := NewEngine(cfg)
+	if engine.GetWorkers() != 3 || engine.GetStatistics().Workers != 3 {
+		t.Errorf("Expected 3 workers from configuration, got %d", engine.GetWorkers())
+	}
+
+	engine.SetWorkers(0)
+	if engine.GetWorkers() != defaultWorkers() {
+		t.Errorf("Expected 0 to select GOMAXPROCS workers, got %d", engine.GetWorkers())
+	}
+}
+
+// benchmarkStep measures one-second update steps for a platform count and worker count
+func benchmarkStep(b *testing.B, count, workers int) {
+	engine := createLoadTestEngine(b, count, workers)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		engine.step(time.Second)
+	}
+	b.ReportMetric(float64(count*b.N)/b.Elapsed().Seconds(), "platforms/s")
+}
+
+func BenchmarkEngineStep(b *testing.B) {
+	workerCounts := []int{1, 4}
+	if procs := defaultWorkers(); procs != 1 && procs != 4 {
+		workerCounts = append(workerCounts, procs)
+	}
+
+	for _, count := range []int{1000, 10000, 50000} {
+		for _, workers := range workerCounts {
+			b.Run(fmt.Sprintf("platforms=%d/workers=%d", count, workers), func(b *testing.B) {
+				benchmarkStep(b, count, workers)
+			})
+		}
+	}
+}
+
+func TestSnapshotPlatforms(t *testing.T) {
+	engine := createLoadTestEngine(t, 400, 2)
+	engine.SetUpdateInterval(time.Millisecond)
+	if err := engine.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer engine.Stop()
+
+	// Snapshots are read and routes and destinations changed while the engine keeps stepping; the
+	// race detector checks snapshots are copies and changes wait for the step
+	route := []models.Position{{Latitude: 30, Longitude: -100}, {Latitude: 31, Longitude: -101}}
+	walk := &config.BehaviorConfig{RandomWalk: &config.RandomWalkBehavior{
+		Area: config.BoundingBox{North: 50, South: 25, East: -70, West: -120},
+	}}
+	deadline := time.Now().Add(200 * time.Millisecond)
+	for i := 0; time.Now().Before(deadline); i++ {
+		if err := engine.SetRouteForPlatform(fmt.Sprintf("P%05d", 4*(i%100)+2), route, models.RouteModeLoop, nil); err != nil {
+			t.Fatalf("SetRouteForPlatform failed: %v", err)
+		}
+		if err := engine.SetBehaviorForPlatform(fmt.Sprintf("P%05d", 4*(i%100)+1), walk); err != nil {
+			t.Fatalf("SetBehaviorForPlatform failed: %v", err)
+		}
+		destination := models.Position{Latitude: 40, Longitude: -90, Altitude: 10000}
+		if err := engine.SetDestinationForPlatform(fmt.Sprintf("P%05d", 4*(i%100)), destination); err != nil {
+			t.Fatalf("SetDestinationForPlatform failed: %v", err)
+		}
+
+		snapshot := engine.SnapshotPlatforms()
+		if len(snapshot) != 400 {
+			t.Fatalf("Expected 400 platforms, got %d", len(snapshot))
+		}
+		for _, platform := range snapshot {
+			if live, _ := engine.GetPlatform(platform.GetID()); platform == live {
+				t.Fatal("Expected a copy, got the live platform")
+			}
+			_ = platform.GetState().Position
+			if up, ok := platform.(*models.UniversalPlatform); ok && up.Destination != nil && len(up.Route) > 0 {
+				_ = *up.Destination
+				_ = up.Route[0]
+			}
+		}
+	}
+}
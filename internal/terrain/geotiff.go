@@ -0,0 +1,461 @@
+package terrain
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// TIFF and GeoTIFF tags read from elevation tiles
+const (
+	tagImageWidth      = 256
+	tagImageLength     = 257
+	tagBitsPerSample   = 258
+	tagCompression     = 259
+	tagStripOffsets    = 273
+	tagSamplesPerPixel = 277
+	tagRowsPerStrip    = 278
+	tagStripByteCounts = 279
+	tagPredictor       = 317
+	tagTileWidth       = 322
+	tagTileLength      = 323
+	tagTileOffsets     = 324
+	tagTileByteCounts  = 325
+	tagSampleFormat    = 339
+	tagModelPixelScale = 33550
+	tagModelTiepoint   = 33922
+	tagGeoKeyDirectory = 34735
+	tagGDALNoData      = 42113
+)
+
+// GeoTIFF keys and the values the reader accepts
+const (
+	keyModelType          = 1024
+	keyRasterType         = 1025
+	modelTypeGeographic   = 2
+	rasterTypePixelIsArea = 1
+)
+
+// Compression schemes, predictors and sample formats the reader decodes
+const (
+	compressionNone       = 1
+	compressionLZW        = 5
+	compressionDeflate    = 8
+	compressionDeflateOld = 32946
+	predictorNone         = 1
+	predictorHorizontal   = 2
+	sampleFormatUnsigned  = 1
+	sampleFormatSigned    = 2
+	sampleFormatFloat     = 3
+)
+
+// TIFF file structure
+const (
+	tiffClassicMagic       = 42
+	tiffBigMagic           = 43
+	tiffEntrySize          = 12
+	tiffInlineValueMaximum = 4
+	tiffTypeASCII          = 2
+)
+
+// tiffTypeSizes gives the bytes in one value of each TIFF field type
+var tiffTypeSizes = map[uint16]int{1: 1, 2: 1, 3: 2, 4: 4, 5: 8, 6: 1, 7: 1, 8: 2, 9: 4, 10: 8, 11: 4, 12: 8}
+
+// geoTIFF is the layout of a single band, geographic GeoTIFF
+type geoTIFF struct {
+	order         binary.ByteOrder
+	width, height int
+	bitsPerSample int
+	sampleFormat  int
+	compression   int
+	predictor     int
+
+	// Strips are blocks as wide as the image
+	blockWidth, blockHeight int
+	offsets, byteCounts     []int64
+
+	grid   grid // Placement of the samples, without them
+	noData *float64
+}
+
+// indexGeoTIFF reads a GeoTIFF's header to place it
+func indexGeoTIFF(path string) (*tile, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	layout, err := readGeoTIFF(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	g := layout.grid
+	return &tile{
+		path: path,
+		bounds: bounds{
+			north: g.north,
+			south: g.north - float64(g.rows-1)*g.latStep,
+			east:  g.west + float64(g.columns-1)*g.lonStep,
+			west:  g.west,
+		},
+		load: loadGeoTIFF,
+	}, nil
+}
+
+// loadGeoTIFF reads a GeoTIFF's samples
+func loadGeoTIFF(path string) (*grid, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	layout, err := readGeoTIFF(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	g, err := layout.read(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return g, nil
+}
+
+// tiffField is a raw IFD entry
+type tiffField struct {
+	typ   uint16
+	count int
+	data  []byte
+}
+
+// readGeoTIFF reads the first image file directory and the georeferencing of a classic TIFF
+func readGeoTIFF(r io.ReaderAt) (*geoTIFF, error) {
+	header := make([]byte, 8)
+	if _, err := r.ReadAt(header, 0); err != nil {
+		return nil, fmt.Errorf("failed to read TIFF header: %w", err)
+	}
+	var order binary.ByteOrder
+	switch string(header[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return nil, fmt.Errorf("not a TIFF file")
+	}
+	switch order.Uint16(header[2:]) {
+	case tiffClassicMagic:
+	case tiffBigMagic:
+		return nil, fmt.Errorf("BigTIFF is not supported")
+	default:
+		return nil, fmt.Errorf("not a TIFF file")
+	}
+
+	fields, err := readIFD(r, order, int64(order.Uint32(header[4:])))
+	if err != nil {
+		return nil, err
+	}
+	number := func(tag uint16, fallback int) int {
+		if values := fieldNumbers(order, fields[tag]); len(values) > 0 {
+			return int(values[0])
+		}
+		return fallback
+	}
+
+	t := &geoTIFF{
+		order:         order,
+		width:         number(tagImageWidth, 0),
+		height:        number(tagImageLength, 0),
+		bitsPerSample: number(tagBitsPerSample, 1),
+		sampleFormat:  number(tagSampleFormat, sampleFormatUnsigned),
+		compression:   number(tagCompression, compressionNone),
+		predictor:     number(tagPredictor, predictorNone),
+	}
+	if t.width < 1 || t.height < 1 {
+		return nil, fmt.Errorf("TIFF has no image")
+	}
+	if samples := number(tagSamplesPerPixel, 1); samples != 1 {
+		return nil, fmt.Errorf("elevation GeoTIFFs must have one band, this has %d", samples)
+	}
+	if err := t.checkEncoding(); err != nil {
+		return nil, err
+	}
+
+	offsetTag, countTag := uint16(tagStripOffsets), uint16(tagStripByteCounts)
+	t.blockWidth, t.blockHeight = t.width, number(tagRowsPerStrip, t.height)
+	if _, tiled := fields[tagTileOffsets]; tiled {
+		offsetTag, countTag = tagTileOffsets, tagTileByteCounts
+		t.blockWidth, t.blockHeight = number(tagTileWidth, 0), number(tagTileLength, 0)
+	}
+	t.blockHeight = min(t.blockHeight, t.height)
+	for _, value := range fieldNumbers(order, fields[offsetTag]) {
+		t.offsets = append(t.offsets, int64(value))
+	}
+	for _, value := range fieldNumbers(order, fields[countTag]) {
+		t.byteCounts = append(t.byteCounts, int64(value))
+	}
+	if t.blockWidth < 1 || t.blockHeight < 1 {
+		return nil, fmt.Errorf("TIFF has invalid strip or tile dimensions")
+	}
+	blocks := ceilDiv(t.width, t.blockWidth) * ceilDiv(t.height, t.blockHeight)
+	if len(t.offsets) != blocks || len(t.byteCounts) != blocks {
+		return nil, fmt.Errorf("TIFF has %d strip or tile offsets, expected %d", len(t.offsets), blocks)
+	}
+
+	if err := t.georeference(fields); err != nil {
+		return nil, err
+	}
+	if field, ok := fields[tagGDALNoData]; ok && field.typ == tiffTypeASCII {
+		text := strings.TrimSpace(strings.TrimRight(string(field.data), "\x00"))
+		if value, err := strconv.ParseFloat(text, 64); err == nil {
+			t.noData = &value
+		}
+	}
+	return t, nil
+}
+
+// checkEncoding rejects sample types, compression and predictors the reader cannot decode
+func (t *geoTIFF) checkEncoding() error {
+	switch t.sampleFormat {
+	case sampleFormatUnsigned, sampleFormatSigned:
+		if t.bitsPerSample != 8 && t.bitsPerSample != 16 && t.bitsPerSample != 32 {
+			return fmt.Errorf("%d-bit integer samples are not supported", t.bitsPerSample)
+		}
+	case sampleFormatFloat:
+		if t.bitsPerSample != 32 && t.bitsPerSample != 64 {
+			return fmt.Errorf("%d-bit floating point samples are not supported", t.bitsPerSample)
+		}
+	default:
+		return fmt.Errorf("sample format %d is not supported", t.sampleFormat)
+	}
+	switch t.compression {
+	case compressionNone, compressionLZW, compressionDeflate, compressionDeflateOld:
+	default:
+		return fmt.Errorf("compression %d is not supported; use none, LZW or Deflate", t.compression)
+	}
+	switch t.predictor {
+	case predictorNone:
+	case predictorHorizontal:
+		if t.sampleFormat == sampleFormatFloat {
+			return fmt.Errorf("the horizontal predictor is not supported for floating point samples")
+		}
+	default:
+		return fmt.Errorf("predictor %d is not supported", t.predictor)
+	}
+	return nil
+}
+
+// georeference places the samples from the pixel scale and tiepoint. Only geographic
+// coordinates in degrees are supported.
+func (t *geoTIFF) georeference(fields map[uint16]tiffField) error {
+	scale := fieldNumbers(t.order, fields[tagModelPixelScale])
+	tiepoint := fieldNumbers(t.order, fields[tagModelTiepoint])
+	if len(scale) < 2 || len(tiepoint) < 6 {
+		return fmt.Errorf("GeoTIFF must have a pixel scale and tiepoint")
+	}
+	if scale[0] <= 0 || scale[1] <= 0 {
+		return fmt.Errorf("GeoTIFF pixel scale must be positive")
+	}
+
+	pixelIsArea := true
+	if keys := fieldNumbers(t.order, fields[tagGeoKeyDirectory]); len(keys) >= 4 {
+		for i := 4; i+3 < len(keys) && i < 4+4*int(keys[3]); i += 4 {
+			// Short values are held in the directory itself
+			if keys[i+1] != 0 {
+				continue
+			}
+			switch int(keys[i]) {
+			case keyModelType:
+				if int(keys[i+3]) != modelTypeGeographic {
+					return fmt.Errorf("GeoTIFF must be in geographic coordinates, not model type %v", keys[i+3])
+				}
+			case keyRasterType:
+				pixelIsArea = int(keys[i+3]) == rasterTypePixelIsArea
+			}
+		}
+	}
+
+	// Tiepoint raster coordinates are of pixel corners for areas and of sample points for points
+	column, row := tiepoint[0], tiepoint[1]
+	if pixelIsArea {
+		column, row = column-0.5, row-0.5
+	}
+	t.grid = grid{
+		north:   tiepoint[4] + row*scale[1],
+		west:    tiepoint[3] - column*scale[0],
+		latStep: scale[1],
+		lonStep: scale[0],
+		rows:    t.height,
+		columns: t.width,
+	}
+	return nil
+}
+
+// read decodes every strip or tile into the grid's samples
+func (t *geoTIFF) read(r io.ReaderAt) (*grid, error) {
+	g := t.grid
+	g.samples = make([]float32, g.rows*g.columns)
+	size := t.bitsPerSample / 8
+	across := ceilDiv(t.width, t.blockWidth)
+
+	for block := range t.offsets {
+		raw := make([]byte, t.byteCounts[block])
+		if _, err := r.ReadAt(raw, t.offsets[block]); err != nil {
+			return nil, fmt.Errorf("failed to read block %d: %w", block, err)
+		}
+		data, err := t.decompress(raw)
+		if err != nil {
+			return nil, fmt.Errorf("block %d: %w", block, err)
+		}
+
+		rowBytes := t.blockWidth * size
+		if t.predictor == predictorHorizontal {
+			for start := 0; start+rowBytes <= len(data); start += rowBytes {
+				undoHorizontalDifferencing(data[start:start+rowBytes], t.order, size)
+			}
+		}
+
+		top, left := block/across*t.blockHeight, block%across*t.blockWidth
+		for y := 0; y < t.blockHeight && top+y < t.height; y++ {
+			for x := 0; x < t.blockWidth && left+x < t.width; x++ {
+				offset := y*rowBytes + x*size
+				if offset+size > len(data) {
+					return nil, fmt.Errorf("block %d is shorter than its %dx%d samples", block, t.blockWidth, t.blockHeight)
+				}
+				g.samples[(top+y)*g.columns+left+x] = t.sample(data[offset : offset+size])
+			}
+		}
+	}
+	return &g, nil
+}
+
+// decompress expands one strip or tile
+func (t *geoTIFF) decompress(raw []byte) ([]byte, error) {
+	switch t.compression {
+	case compressionLZW:
+		return decodeLZW(raw)
+	case compressionDeflate, compressionDeflateOld:
+		reader, err := zlib.NewReader(bytes.NewReader(raw))
+		if err != nil {
+			return nil, err
+		}
+		defer reader.Close()
+		return io.ReadAll(reader)
+	default:
+		return raw, nil
+	}
+}
+
+// sample converts one raw sample to meters, NaN for no data
+func (t *geoTIFF) sample(data []byte) float32 {
+	var value float64
+	switch {
+	case t.sampleFormat == sampleFormatFloat && t.bitsPerSample == 32:
+		value = float64(math.Float32frombits(t.order.Uint32(data)))
+	case t.sampleFormat == sampleFormatFloat:
+		value = math.Float64frombits(t.order.Uint64(data))
+	case t.bitsPerSample == 8 && t.sampleFormat == sampleFormatSigned:
+		value = float64(int8(data[0]))
+	case t.bitsPerSample == 8:
+		value = float64(data[0])
+	case t.bitsPerSample == 16 && t.sampleFormat == sampleFormatSigned:
+		value = float64(int16(t.order.Uint16(data)))
+	case t.bitsPerSample == 16:
+		value = float64(t.order.Uint16(data))
+	case t.sampleFormat == sampleFormatSigned:
+		value = float64(int32(t.order.Uint32(data)))
+	default:
+		value = float64(t.order.Uint32(data))
+	}
+	if t.noData != nil && value == *t.noData {
+		return float32(math.NaN())
+	}
+	return float32(value)
+}
+
+// undoHorizontalDifferencing turns a row of differences from the previous sample back into samples
+func undoHorizontalDifferencing(row []byte, order binary.ByteOrder, size int) {
+	for i := size; i+size <= len(row); i += size {
+		switch size {
+		case 1:
+			row[i] += row[i-1]
+		case 2:
+			order.PutUint16(row[i:], order.Uint16(row[i:])+order.Uint16(row[i-2:]))
+		case 4:
+			order.PutUint32(row[i:], order.Uint32(row[i:])+order.Uint32(row[i-4:]))
+		}
+	}
+}
+
+// readIFD reads the fields of an image file directory
+func readIFD(r io.ReaderAt, order binary.ByteOrder, offset int64) (map[uint16]tiffField, error) {
+	countData := make([]byte, 2)
+	if _, err := r.ReadAt(countData, offset); err != nil {
+		return nil, fmt.Errorf("failed to read TIFF directory: %w", err)
+	}
+	entries := make([]byte, int(order.Uint16(countData))*tiffEntrySize)
+	if _, err := r.ReadAt(entries, offset+2); err != nil {
+		return nil, fmt.Errorf("failed to read TIFF directory: %w", err)
+	}
+
+	fields := make(map[uint16]tiffField)
+	for i := 0; i < len(entries); i += tiffEntrySize {
+		entry := entries[i : i+tiffEntrySize]
+		field := tiffField{typ: order.Uint16(entry[2:]), count: int(order.Uint32(entry[4:]))}
+		size, known := tiffTypeSizes[field.typ]
+		if !known {
+			continue
+		}
+		length := size * field.count
+		if length <= tiffInlineValueMaximum {
+			field.data = entry[8 : 8+length]
+		} else {
+			field.data = make([]byte, length)
+			if _, err := r.ReadAt(field.data, int64(order.Uint32(entry[8:]))); err != nil {
+				return nil, fmt.Errorf("failed to read TIFF tag %d: %w", order.Uint16(entry), err)
+			}
+		}
+		fields[order.Uint16(entry)] = field
+	}
+	return fields, nil
+}
+
+// fieldNumbers returns the values of a numeric field
+func fieldNumbers(order binary.ByteOrder, field tiffField) []float64 {
+	values := make([]float64, 0, field.count)
+	for i := 0; i < field.count; i++ {
+		switch field.typ {
+		case 1, 7:
+			values = append(values, float64(field.data[i]))
+		case 3:
+			values = append(values, float64(order.Uint16(field.data[2*i:])))
+		case 4:
+			values = append(values, float64(order.Uint32(field.data[4*i:])))
+		case 6:
+			values = append(values, float64(int8(field.data[i])))
+		case 8:
+			values = append(values, float64(int16(order.Uint16(field.data[2*i:]))))
+		case 9:
+			values = append(values, float64(int32(order.Uint32(field.data[4*i:]))))
+		case 11:
+			values = append(values, float64(math.Float32frombits(order.Uint32(field.data[4*i:]))))
+		case 12:
+			values = append(values, math.Float64frombits(order.Uint64(field.data[8*i:])))
+		default:
+			return nil
+		}
+	}
+	return values
+}
+
+// ceilDiv divides rounding up
+func ceilDiv(a, b int) int {
+	return (a + b - 1) / b
+}
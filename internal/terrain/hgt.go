@@ -0,0 +1,109 @@
+package terrain
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// hgtVoid marks SRTM samples without data
+const hgtVoid = -32768
+
+// indexHGT places an SRTM tile from its name, such as N37W076.hgt for the square degree whose
+// south-west corner is 37°N 76°W, and checks that its size is that of a square grid
+func indexHGT(path string) (*tile, error) {
+	south, west, err := parseHGTName(filepath.Base(path))
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := hgtSize(info.Size()); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &tile{
+		path:   path,
+		bounds: bounds{north: south + 1, south: south, east: west + 1, west: west},
+		load:   loadHGT,
+	}, nil
+}
+
+// parseHGTName returns the south-west corner named by an SRTM file name
+func parseHGTName(name string) (south, west float64, err error) {
+	stem := strings.ToUpper(strings.TrimSuffix(name, filepath.Ext(name)))
+	if len(stem) != 7 {
+		return 0, 0, fmt.Errorf("SRTM tile name %s is not like N37W076.hgt", name)
+	}
+	latitude, latErr := strconv.Atoi(stem[1:3])
+	longitude, lonErr := strconv.Atoi(stem[4:7])
+	if latErr != nil || lonErr != nil || latitude > 89 || longitude > 179 {
+		return 0, 0, fmt.Errorf("SRTM tile name %s is not like N37W076.hgt", name)
+	}
+	switch stem[0] {
+	case 'N':
+	case 'S':
+		latitude = -latitude
+	default:
+		return 0, 0, fmt.Errorf("SRTM tile name %s must start with N or S", name)
+	}
+	switch stem[3] {
+	case 'E':
+	case 'W':
+		longitude = -longitude
+	default:
+		return 0, 0, fmt.Errorf("SRTM tile name %s must have E or W before the longitude", name)
+	}
+	return float64(latitude), float64(longitude), nil
+}
+
+// hgtSize returns the samples along each side of a tile from its file size: 1201 for 3 arc-second
+// and 3601 for 1 arc-second tiles
+func hgtSize(bytes int64) (int, error) {
+	side := int(math.Round(math.Sqrt(float64(bytes / 2))))
+	if side < 2 || int64(side)*int64(side)*2 != bytes {
+		return 0, fmt.Errorf("SRTM tile size %d bytes is not a square grid of 16-bit samples", bytes)
+	}
+	return side, nil
+}
+
+// loadHGT reads an SRTM tile's big-endian 16-bit samples. Rows run from north to south and the
+// outer rows and columns lie on the tile's edges, overlapping its neighbors.
+func loadHGT(path string) (*grid, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	side, err := hgtSize(int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	south, west, err := parseHGTName(filepath.Base(path))
+	if err != nil {
+		return nil, err
+	}
+
+	g := &grid{
+		north:   south + 1,
+		west:    west,
+		latStep: 1 / float64(side-1),
+		lonStep: 1 / float64(side-1),
+		rows:    side,
+		columns: side,
+		samples: make([]float32, side*side),
+	}
+	for i := range g.samples {
+		sample := int16(binary.BigEndian.Uint16(data[2*i:]))
+		if sample == hgtVoid {
+			g.samples[i] = float32(math.NaN())
+			continue
+		}
+		g.samples[i] = float32(sample)
+	}
+	return g, nil
+}
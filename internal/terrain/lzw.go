@@ -0,0 +1,77 @@
+package terrain
+
+import "fmt"
+
+// TIFF LZW codes
+const (
+	lzwClear    = 256
+	lzwEnd      = 257
+	lzwFirst    = 258
+	lzwMinWidth = 9
+	lzwMaxWidth = 12
+)
+
+// decodeLZW expands TIFF LZW data. Unlike GIF LZW, which compress/lzw reads, codes are packed most
+// significant bit first and widen one code early, as the table is about to fill rather than once
+// it has.
+func decodeLZW(src []byte) ([]byte, error) {
+	var (
+		out      []byte
+		table    [][]byte
+		previous []byte
+		width    = lzwMinWidth
+		bits     uint32
+		held     int
+		position int
+	)
+	reset := func() {
+		table = table[:0]
+		for i := 0; i < lzwFirst; i++ {
+			table = append(table, []byte{byte(i)})
+		}
+		width = lzwMinWidth
+		previous = nil
+	}
+	reset()
+
+	for {
+		for held < width && position < len(src) {
+			bits = bits<<8 | uint32(src[position])
+			held += 8
+			position++
+		}
+		if held < width {
+			// Some writers leave out the end code
+			return out, nil
+		}
+		code := int(bits>>(held-width)) & (1<<width - 1)
+		held -= width
+
+		switch {
+		case code == lzwClear:
+			reset()
+			continue
+		case code == lzwEnd:
+			return out, nil
+		}
+
+		var entry []byte
+		switch {
+		case code < lzwClear || (code >= lzwFirst && code < len(table)):
+			entry = table[code]
+		case code == len(table) && previous != nil:
+			entry = append(append(make([]byte, 0, len(previous)+1), previous...), previous[0])
+		default:
+			return nil, fmt.Errorf("invalid LZW code %d", code)
+		}
+		out = append(out, entry...)
+
+		if previous != nil && len(table) < 1<<lzwMaxWidth {
+			table = append(table, append(append(make([]byte, 0, len(previous)+1), previous...), entry[0]))
+		}
+		previous = entry
+		if len(table)+1 >= 1<<width && width < lzwMaxWidth {
+			width++
+		}
+	}
+}
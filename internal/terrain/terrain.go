@@ -0,0 +1,165 @@
+// Package terrain reads ground elevation from SRTM .hgt and GeoTIFF tiles in a local directory
+package terrain
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Model answers ground elevation queries from the tiles in a directory. Tiles are indexed when the
+// model is opened and their samples read on first use, so a directory can hold far more of the
+// world than a scenario touches. A Model is safe for concurrent use.
+type Model struct {
+	dir   string
+	tiles []*tile // In file name order; the first tile covering a point answers for it
+}
+
+// tile is one elevation file and the area it covers
+type tile struct {
+	path   string
+	bounds bounds
+	load   func(path string) (*grid, error)
+
+	once sync.Once
+	grid *grid
+	err  error
+}
+
+// bounds is a tile's extent in degrees
+type bounds struct {
+	north, south, east, west float64
+}
+
+// contains reports whether a point is inside the bounds, edges included
+func (b bounds) contains(latitude, longitude float64) bool {
+	return latitude <= b.north && latitude >= b.south && longitude <= b.east && longitude >= b.west
+}
+
+// grid holds elevation samples at evenly spaced points, rows from north to south and columns from
+// west to east. Voids are NaN.
+type grid struct {
+	north, west      float64 // Position of the first sample
+	latStep, lonStep float64 // Degrees between samples
+	rows, columns    int
+	samples          []float32
+}
+
+// Open indexes the .hgt, .tif and .tiff tiles in a directory. A directory without tiles gives a
+// model that knows no elevations.
+func Open(dir string) (*Model, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read terrain directory: %w", err)
+	}
+
+	model := &Model{dir: dir}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		var t *tile
+		switch strings.ToLower(filepath.Ext(entry.Name())) {
+		case ".hgt":
+			t, err = indexHGT(path)
+		case ".tif", ".tiff":
+			t, err = indexGeoTIFF(path)
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		model.tiles = append(model.tiles, t)
+	}
+	sort.Slice(model.tiles, func(i, j int) bool { return model.tiles[i].path < model.tiles[j].path })
+	return model, nil
+}
+
+// Dir returns the directory the tiles were read from
+func (m *Model) Dir() string {
+	return m.dir
+}
+
+// Tiles returns the number of tiles in the model
+func (m *Model) Tiles() int {
+	if m == nil {
+		return 0
+	}
+	return len(m.tiles)
+}
+
+// Elevation returns the ground height above mean sea level in meters at a position, interpolated
+// between the samples around it. It reports false where no tile covers the position or the tile
+// has a void there.
+func (m *Model) Elevation(latitude, longitude float64) (float64, bool) {
+	if m == nil {
+		return 0, false
+	}
+	for _, t := range m.tiles {
+		if !t.bounds.contains(latitude, longitude) {
+			continue
+		}
+		g, err := t.samples()
+		if err != nil {
+			continue
+		}
+		if elevation, ok := g.at(latitude, longitude); ok {
+			return elevation, true
+		}
+	}
+	return 0, false
+}
+
+// samples reads the tile's samples the first time they are needed
+func (t *tile) samples() (*grid, error) {
+	t.once.Do(func() {
+		t.grid, t.err = t.load(t.path)
+	})
+	return t.grid, t.err
+}
+
+// at interpolates the grid bilinearly, leaving out voids. It reports false outside the grid or
+// when all four samples around the position are voids.
+func (g *grid) at(latitude, longitude float64) (float64, bool) {
+	row := (g.north - latitude) / g.latStep
+	column := (longitude - g.west) / g.lonStep
+	if row < 0 || column < 0 || row > float64(g.rows-1) || column > float64(g.columns-1) {
+		return 0, false
+	}
+
+	r := min(int(row), g.rows-2)
+	c := min(int(column), g.columns-2)
+	r, c = max(r, 0), max(c, 0)
+	rowFraction, columnFraction := row-float64(r), column-float64(c)
+
+	var sum, weights float64
+	for _, corner := range [4]struct {
+		r, c   int
+		weight float64
+	}{
+		{r, c, (1 - rowFraction) * (1 - columnFraction)},
+		{r, c + 1, (1 - rowFraction) * columnFraction},
+		{r + 1, c, rowFraction * (1 - columnFraction)},
+		{r + 1, c + 1, rowFraction * columnFraction},
+	} {
+		if corner.r >= g.rows || corner.c >= g.columns {
+			continue
+		}
+		sample := g.samples[corner.r*g.columns+corner.c]
+		if math.IsNaN(float64(sample)) {
+			continue
+		}
+		sum += float64(sample) * corner.weight
+		weights += corner.weight
+	}
+	if weights == 0 {
+		return 0, false
+	}
+	return sum / weights, true
+}
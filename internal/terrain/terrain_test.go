@@ -0,0 +1,492 @@
+package terrain
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// ramp is the test terrain: rising 1000 m per degree north and 100 m per degree east of 37°N 76°W
+func ramp(latitude, longitude float64) float64 {
+	return (latitude-37)*1000 + (longitude+76)*100
+}
+
+// writeHGT writes an SRTM tile of side samples following ramp
+func writeHGT(t *testing.T, dir, name string, side int, void func(row, column int) bool) {
+	t.Helper()
+	south, west, err := parseHGTName(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := make([]byte, 2*side*side)
+	for row := 0; row < side; row++ {
+		for column := 0; column < side; column++ {
+			sample := int16(math.Round(ramp(south+1-float64(row)/float64(side-1), west+float64(column)/float64(side-1))))
+			if void != nil && void(row, column) {
+				sample = hgtVoid
+			}
+			binary.BigEndian.PutUint16(data[2*(row*side+column):], uint16(sample))
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, name), data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func assertElevation(t *testing.T, model *Model, latitude, longitude, want float64) {
+	t.Helper()
+	got, ok := model.Elevation(latitude, longitude)
+	if !ok || math.Abs(got-want) > 0.01 {
+		t.Errorf("Elevation(%v, %v) = %v, %v, want %v", latitude, longitude, got, ok, want)
+	}
+}
+
+func TestOpen_HGT(t *testing.T) {
+	dir := t.TempDir()
+	writeHGT(t, dir, "N37W076.hgt", 11, func(row, column int) bool { return row == 5 && column == 5 })
+	writeHGT(t, dir, "N37W077.hgt", 11, nil)
+	if err := os.WriteFile(filepath.Join(dir, "README.txt"), []byte("not a tile"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	model, err := Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if model.Tiles() != 2 || model.Dir() != dir {
+		t.Fatalf("Expected 2 tiles from %s, got %d from %s", dir, model.Tiles(), model.Dir())
+	}
+
+	// On samples, between them, on the shared edge and in the western tile
+	assertElevation(t, model, 37, -76, 0)
+	assertElevation(t, model, 38, -75, 1100)
+	assertElevation(t, model, 37.25, -75.75, 275)
+	assertElevation(t, model, 37.3, -76, 300)
+	assertElevation(t, model, 37.5, -76.5, 450)
+
+	// Voids are left out of the interpolation
+	assertElevation(t, model, 37.45, -75.5, 450)
+	if elevation, ok := model.Elevation(37.5, -75.5); ok {
+		t.Errorf("Expected no elevation on a void sample, got %v", elevation)
+	}
+
+	// Outside the tiles there is no elevation
+	if _, ok := model.Elevation(40, -75.5); ok {
+		t.Error("Expected no elevation outside the tiles")
+	}
+	var none *Model
+	if _, ok := none.Elevation(37.5, -75.5); ok || none.Tiles() != 0 {
+		t.Error("Expected a nil model to have no tiles")
+	}
+}
+
+func TestOpen_Errors(t *testing.T) {
+	if _, err := Open(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("Expected a missing directory to be rejected")
+	}
+
+	empty, err := Open(t.TempDir())
+	if err != nil || empty.Tiles() != 0 {
+		t.Fatalf("Expected an empty directory to give an empty model, got %v", err)
+	}
+	if _, ok := empty.Elevation(37, -76); ok {
+		t.Error("Expected no elevation without tiles")
+	}
+
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr string
+	}{
+		{"N37W076.hgt", make([]byte, 99), "square"},
+		{"tile.hgt", make([]byte, 8), "N37W076"},
+		{"X37W076.hgt", make([]byte, 8), "N or S"},
+		{"elevation.tif", []byte("not a tiff"), "not a TIFF"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(dir, tt.name), tt.data, 0o600); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := Open(dir); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Open() error = %v, want one mentioning %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseHGTName(t *testing.T) {
+	tests := []struct {
+		name        string
+		south, west float64
+	}{
+		{"N37W076.hgt", 37, -76},
+		{"S34E151.HGT", -34, 151},
+		{"n00e000.hgt", 0, 0},
+	}
+	for _, tt := range tests {
+		south, west, err := parseHGTName(tt.name)
+		if err != nil || south != tt.south || west != tt.west {
+			t.Errorf("parseHGTName(%s) = %v, %v, %v, want %v, %v", tt.name, south, west, err, tt.south, tt.west)
+		}
+	}
+}
+
+// tiffOptions describe a test GeoTIFF of 41×21 samples every 0.025° from 37.5°N 76°W, following ramp
+type tiffOptions struct {
+	compression  int
+	predictor    int
+	tiled        bool
+	float        bool
+	pixelIsPoint bool
+	bigEndian    bool
+	noData       string
+	modelType    uint16
+}
+
+// writeGeoTIFF writes a single band GeoTIFF in strips of 8 rows or tiles of 16×16 samples
+func writeGeoTIFF(t *testing.T, path string, options tiffOptions) {
+	t.Helper()
+	const width, height, step = 41, 21, 0.025
+	var order binary.ByteOrder = binary.LittleEndian
+	magic := "II"
+	if options.bigEndian {
+		order, magic = binary.BigEndian, "MM"
+	}
+	size := 2
+	if options.float {
+		size = 4
+	}
+
+	sample := func(row, column int) []byte {
+		elevation := ramp(37.5-float64(row)*step, -76+float64(column)*step)
+		data := make([]byte, size)
+		switch {
+		case options.noData != "" && row == 10 && column == 20:
+			if options.float {
+				order.PutUint32(data, math.Float32bits(-9999))
+			} else {
+				order.PutUint16(data, uint16(0xffff&-9999))
+			}
+		case options.float:
+			order.PutUint32(data, math.Float32bits(float32(elevation)))
+		default:
+			order.PutUint16(data, uint16(int16(math.Round(elevation))))
+		}
+		return data
+	}
+
+	blockWidth, blockHeight := width, 8
+	if options.tiled {
+		blockWidth, blockHeight = 16, 16
+	}
+	var blocks [][]byte
+	for top := 0; top < height; top += blockHeight {
+		for left := 0; left < width; left += blockWidth {
+			var block []byte
+			for y := top; y < top+blockHeight && (options.tiled || y < height); y++ {
+				row := make([]byte, 0, blockWidth*size)
+				for x := left; x < left+blockWidth; x++ {
+					if y < height && x < width {
+						row = append(row, sample(y, x)...)
+					} else {
+						row = append(row, make([]byte, size)...)
+					}
+				}
+				if options.predictor == predictorHorizontal {
+					for i := len(row) - size; i >= size; i -= size {
+						order.PutUint16(row[i:], order.Uint16(row[i:])-order.Uint16(row[i-size:]))
+					}
+				}
+				block = append(block, row...)
+			}
+			switch options.compression {
+			case compressionDeflate:
+				var buffer bytes.Buffer
+				writer := zlib.NewWriter(&buffer)
+				_, _ = writer.Write(block)
+				_ = writer.Close()
+				block = buffer.Bytes()
+			case compressionLZW:
+				block = encodeLZW(block)
+			}
+			blocks = append(blocks, block)
+		}
+	}
+
+	type entry struct {
+		tag, typ uint16
+		values   []byte
+		count    int
+	}
+	shorts := func(values ...uint16) []byte {
+		data := make([]byte, 2*len(values))
+		for i, v := range values {
+			order.PutUint16(data[2*i:], v)
+		}
+		return data
+	}
+	longs := func(values ...uint32) []byte {
+		data := make([]byte, 4*len(values))
+		for i, v := range values {
+			order.PutUint32(data[4*i:], v)
+		}
+		return data
+	}
+	doubles := func(values ...float64) []byte {
+		data := make([]byte, 8*len(values))
+		for i, v := range values {
+			order.PutUint64(data[8*i:], math.Float64bits(v))
+		}
+		return data
+	}
+
+	// Blocks go straight after the header
+	offset := uint32(8)
+	var offsets, counts []uint32
+	var body []byte
+	for _, block := range blocks {
+		offsets = append(offsets, offset+uint32(len(body)))
+		counts = append(counts, uint32(len(block)))
+		body = append(body, block...)
+		if len(body)%2 == 1 {
+			body = append(body, 0)
+		}
+	}
+
+	originLatitude, originLongitude := 37.5, -76.0
+	rasterType := uint16(rasterTypePixelIsArea)
+	if options.pixelIsPoint {
+		rasterType = 2
+	} else {
+		originLatitude, originLongitude = originLatitude+step/2, originLongitude-step/2
+	}
+	modelType := options.modelType
+	if modelType == 0 {
+		modelType = modelTypeGeographic
+	}
+	format := uint16(sampleFormatSigned)
+	if options.float {
+		format = sampleFormatFloat
+	}
+	compression := options.compression
+	if compression == 0 {
+		compression = compressionNone
+	}
+	predictor := options.predictor
+	if predictor == 0 {
+		predictor = predictorNone
+	}
+
+	entries := []entry{
+		{tagImageWidth, 3, shorts(width), 1},
+		{tagImageLength, 3, shorts(height), 1},
+		{tagBitsPerSample, 3, shorts(uint16(8 * size)), 1},
+		{tagCompression, 3, shorts(uint16(compression)), 1},
+	}
+	if options.tiled {
+		entries = append(entries,
+			entry{tagSamplesPerPixel, 3, shorts(1), 1},
+			entry{tagPredictor, 3, shorts(uint16(predictor)), 1},
+			entry{tagTileWidth, 3, shorts(uint16(blockWidth)), 1},
+			entry{tagTileLength, 3, shorts(uint16(blockHeight)), 1},
+			entry{tagTileOffsets, 4, longs(offsets...), len(offsets)},
+			entry{tagTileByteCounts, 4, longs(counts...), len(counts)},
+		)
+	} else {
+		entries = append(entries,
+			entry{tagStripOffsets, 4, longs(offsets...), len(offsets)},
+			entry{tagSamplesPerPixel, 3, shorts(1), 1},
+			entry{tagRowsPerStrip, 3, shorts(uint16(blockHeight)), 1},
+			entry{tagStripByteCounts, 4, longs(counts...), len(counts)},
+			entry{tagPredictor, 3, shorts(uint16(predictor)), 1},
+		)
+	}
+	entries = append(entries,
+		entry{tagSampleFormat, 3, shorts(format), 1},
+		entry{tagModelPixelScale, 12, doubles(step, step, 0), 3},
+		entry{tagModelTiepoint, 12, doubles(0, 0, 0, originLongitude, originLatitude, 0), 6},
+		entry{tagGeoKeyDirectory, 3, shorts(1, 1, 0, 2, keyModelType, 0, 1, modelType, keyRasterType, 0, 1, rasterType), 12},
+	)
+	if options.noData != "" {
+		entries = append(entries, entry{tagGDALNoData, tiffTypeASCII, []byte(options.noData + "\x00"), len(options.noData) + 1})
+	}
+
+	// Values too long for an entry follow the blocks, then the directory
+	extra := offset + uint32(len(body))
+	for i := range entries {
+		if len(entries[i].values) > tiffInlineValueMaximum {
+			position := extra
+			body = append(body, entries[i].values...)
+			if len(body)%2 == 1 {
+				body = append(body, 0)
+			}
+			extra = offset + uint32(len(body))
+			entries[i].values = longs(position)
+		}
+	}
+	directory := shorts(uint16(len(entries)))
+	for _, e := range entries {
+		raw := make([]byte, tiffEntrySize)
+		order.PutUint16(raw, e.tag)
+		order.PutUint16(raw[2:], e.typ)
+		order.PutUint32(raw[4:], uint32(e.count))
+		copy(raw[8:], e.values)
+		directory = append(directory, raw...)
+	}
+	directory = append(directory, longs(0)...)
+
+	header := append([]byte(magic), shorts(tiffClassicMagic)...)
+	header = append(header, longs(extra)...)
+	file := append(append(header, body...), directory...)
+	if err := os.WriteFile(path, file, 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// encodeLZW compresses data the way TIFF writers do, as the counterpart of decodeLZW
+func encodeLZW(data []byte) []byte {
+	var (
+		out   []byte
+		bits  uint32
+		held  int
+		width = lzwMinWidth
+	)
+	emit := func(code int) {
+		bits = bits<<width | uint32(code)
+		held += width
+		for held >= 8 {
+			out = append(out, byte(bits>>(held-8)))
+			held -= 8
+		}
+	}
+
+	var table map[string]int
+	next := lzwFirst
+	reset := func() {
+		table = make(map[string]int)
+		for i := 0; i < lzwClear; i++ {
+			table[string([]byte{byte(i)})] = i
+		}
+		next, width = lzwFirst, lzwMinWidth
+	}
+	reset()
+	emit(lzwClear)
+
+	current := ""
+	for _, b := range data {
+		extended := current + string([]byte{b})
+		if _, ok := table[extended]; ok || current == "" {
+			current = extended
+			continue
+		}
+		emit(table[current])
+		table[extended] = next
+		next++
+		if next >= 1<<width && width < lzwMaxWidth {
+			width++
+		}
+		if next == 1<<lzwMaxWidth-2 {
+			emit(lzwClear)
+			reset()
+		}
+		current = string([]byte{b})
+	}
+	if current != "" {
+		emit(table[current])
+		if next+1 >= 1<<width && width < lzwMaxWidth {
+			width++
+		}
+	}
+	emit(lzwEnd)
+	if held > 0 {
+		out = append(out, byte(bits<<(8-held)))
+	}
+	return out
+}
+
+func TestOpen_GeoTIFF(t *testing.T) {
+	tests := []struct {
+		name    string
+		options tiffOptions
+	}{
+		{"uncompressed", tiffOptions{}},
+		{"big-endian", tiffOptions{bigEndian: true}},
+		{"deflate", tiffOptions{compression: compressionDeflate}},
+		{"lzw with predictor", tiffOptions{compression: compressionLZW, predictor: predictorHorizontal}},
+		{"tiled", tiffOptions{tiled: true, compression: compressionDeflate, predictor: predictorHorizontal}},
+		{"float", tiffOptions{float: true, compression: compressionLZW}},
+		{"pixel is point", tiffOptions{pixelIsPoint: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeGeoTIFF(t, filepath.Join(dir, "elevation.tif"), tt.options)
+			model, err := Open(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assertElevation(t, model, 37.5, -76, 500)
+			assertElevation(t, model, 37, -75, 100)
+			assertElevation(t, model, 37.25, -75.5, 300)
+			assertElevation(t, model, 37.1, -75.1, 190)
+			if _, ok := model.Elevation(37.6, -75.5); ok {
+				t.Error("Expected no elevation north of the tile")
+			}
+		})
+	}
+}
+
+func TestOpen_GeoTIFFNoData(t *testing.T) {
+	for _, float := range []bool{false, true} {
+		dir := t.TempDir()
+		writeGeoTIFF(t, filepath.Join(dir, "elevation.tiff"), tiffOptions{float: float, noData: "-9999"})
+		model, err := Open(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if elevation, ok := model.Elevation(37.25, -75.5); ok {
+			t.Errorf("Expected no elevation on a no data sample, got %v", elevation)
+		}
+		want := 303.0 // Between the void and a sample rounded from 302.5
+		if float {
+			want = 302.5
+		}
+		assertElevation(t, model, 37.25, -75.49, want)
+	}
+}
+
+func TestOpen_GeoTIFFProjected(t *testing.T) {
+	dir := t.TempDir()
+	writeGeoTIFF(t, filepath.Join(dir, "utm.tif"), tiffOptions{modelType: 1})
+	if _, err := Open(dir); err == nil || !strings.Contains(err.Error(), "geographic") {
+		t.Errorf("Expected a projected GeoTIFF to be rejected, got %v", err)
+	}
+}
+
+func TestDecodeLZW(t *testing.T) {
+	// Long enough to widen the codes to 12 bits and clear the table
+	random := make([]byte, 20000)
+	rand.New(rand.NewSource(1)).Read(random)
+	for _, data := range [][]byte{[]byte("TOBEORNOTTOBEORTOBEORNOT"), bytes.Repeat([]byte{7}, 5000), random} {
+		got, err := decodeLZW(encodeLZW(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, data) {
+			t.Errorf("Round trip of %d bytes gave %d different bytes", len(data), len(got))
+		}
+	}
+
+	if _, err := decodeLZW([]byte{0x80, 0x7f, 0xff}); err == nil {
+		t.Error("Expected an undefined code to be rejected")
+	}
+}
@@ -47,6 +47,7 @@ class DataStreamer {
         this.onPlatformUpdateCallback = null;
         this.onConnectionStatusCallback = null;
         this.onSimulationStatusCallback = null;
+        this.onPlatformEventCallback = null;
         this.onStatsUpdateCallback = null;
         this.onPerformanceUpdateCallback = null;
 
@@ -93,6 +94,11 @@ class DataStreamer {
         this.onSimulationStatusCallback = callback;
     }
 
+    // Platform added/removed events (scheduled spawns, despawns and arrivals)
+    onPlatformEvent(callback) {
+        this.onPlatformEventCallback = callback;
+    }
+
     onStatsUpdate(callback) {
         this.onStatsUpdateCallback = callback;
     }
@@ -385,6 +391,15 @@ class DataStreamer {
                     this.onSimulationStatusCallback(message.data);
                 }
                 break;
+            case 'platform_added':
+            case 'platform_removed':
+                if (message.type === 'platform_removed' && message.data) {
+                    this.lastPlatformStates.delete(message.data.platform_id);
+                }
+                if (this.onPlatformEventCallback) {
+                    this.onPlatformEventCallback(message.data);
+                }
+                break;
             case 'pong':
                 this.handlePongMessage(message);
                 break;
@@ -602,6 +617,48 @@ class DataStreamer {
         }
     }
 
+    // Runtime control: pause, resume and time scale
+    async sendControl(control) {
+        if (this.connectionType === 'websocket' && this.websocket && this.websocket.readyState === WebSocket.OPEN) {
+            this.websocket.send(JSON.stringify({
+                type: 'control',
+                data: control,
+                timestamp: Date.now()
+            }));
+            return;
+        }
+
+        // Fallback to HTTP
+        const endpoints = {
+            pause: '/api/simulation/pause',
+            resume: '/api/simulation/resume',
+            set_time_scale: '/api/simulation/speed'
+        };
+        const response = await fetch(endpoints[control.command], {
+            method: 'POST',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify(control)
+        });
+
+        if (!response.ok) {
+            throw new Error(`HTTP ${response.status}: ${response.statusText}`);
+        }
+
+        return response.json();
+    }
+
+    setTimeScale(scale) {
+        return this.sendControl({ command: 'set_time_scale', time_scale: scale });
+    }
+
+    pauseSimulation() {
+        return this.sendControl({ command: 'pause' });
+    }
+
+    resumeSimulation() {
+        return this.sendControl({ command: 'resume' });
+    }
+
     // Viewport and filter updates
     updateViewport(bounds) {
         if (this.connectionType === 'websocket' && this.websocket && this.websocket.readyState === WebSocket.OPEN) {
@@ -229,6 +229,17 @@ describe('DataStreamer', () => {
             );
         });
 
+        it('should send time scale changes as control messages', async () => {
+            dataStreamer.connect();
+            mockWebSocket.onopen();
+
+            await dataStreamer.setTimeScale(2);
+
+            const sent = JSON.parse(mockWebSocket.send.mock.calls.at(-1)[0]);
+            expect(sent.type).toBe('control');
+            expect(sent.data).toEqual({ command: 'set_time_scale', time_scale: 2 });
+        });
+
         it('should handle pong responses and update latency', () => {
             const timestamp = Date.now() - 100; // 100ms ago
             dataStreamer.connect();
@@ -359,6 +370,24 @@ describe('DataStreamer', () => {
             expect(statusCallback).toHaveBeenCalledWith(status);
         });
 
+        it('should process platform add and remove events', () => {
+            const eventCallback = jest.fn();
+            dataStreamer.onPlatformEvent(eventCallback);
+            dataStreamer.lastPlatformStates.set('VIPER02', { id: 'VIPER02' });
+
+            const removed = {
+                type: 'platform_removed',
+                platform_id: 'VIPER02',
+                reason: 'despawn',
+                simulation_time: 3600
+            };
+
+            dataStreamer.handleMessage({ type: 'platform_removed', data: removed });
+
+            expect(eventCallback).toHaveBeenCalledWith(removed);
+            expect(dataStreamer.lastPlatformStates.has('VIPER02')).toBe(false);
+        });
+
         it('should handle delta compression', () => {
             dataStreamer.options.enableDeltaCompression = true;
             const platformCallback = jest.fn();